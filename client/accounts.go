@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account products.
+const (
+	ProductCurrent = "current"
+	ProductSavings = "savings"
+)
+
+type Account struct {
+	Number   string `json:"number"`
+	Product  string `json:"product"`
+	Currency string `json:"currency"`
+	Balance  int64  `json:"balance"` // Minor units.
+}
+
+type CreateAccountResponse struct {
+	ID      uuid.UUID `json:"id"`
+	Number  string    `json:"number"`
+	Product string    `json:"product"`
+}
+
+// CreateAccount opens an account for a customer with approved KYC. The
+// product is ProductCurrent or ProductSavings, empty for current.
+func (c *Client) CreateAccount(ctx context.Context, customerID uuid.UUID, product string) (CreateAccountResponse, error) {
+	var res CreateAccountResponse
+	body := struct {
+		Product string `json:"product,omitempty"`
+	}{product}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/accounts", customerID: customerID, body: body}, &res)
+	return res, err
+}
+
+// ListAccounts returns the customer's accounts.
+func (c *Client) ListAccounts(ctx context.Context, customerID uuid.UUID) ([]Account, error) {
+	var res struct {
+		Accounts []Account `json:"accounts"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/accounts", customerID: customerID}, &res)
+	return res.Accounts, err
+}
+
+type Transaction struct {
+	TransactionID    uuid.UUID  `json:"transaction_id"`
+	Kind             string     `json:"kind"`
+	Status           string     `json:"status"`
+	Amount           int64      `json:"amount"` // Minor units, negative for debits, includes the fee.
+	Fee              int64      `json:"fee,omitempty"`
+	Currency         string     `json:"currency"`
+	Description      string     `json:"description"`
+	ReversalOf       *uuid.UUID `json:"reversal_of,omitempty"`
+	ReversedAmount   int64      `json:"reversed_amount,omitempty"`
+	AdjustmentReason *string    `json:"adjustment_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ListAccountTransactions iterates over the account's history, newest first.
+// Pages of pageSize (0 for the server default) are fetched as needed.
+func (c *Client) ListAccountTransactions(ctx context.Context, customerID uuid.UUID, number string, pageSize int) *Iterator[Transaction] {
+	return newIterator(func(cursor string) ([]Transaction, string, error) {
+		query := url.Values{}
+		if pageSize > 0 {
+			query.Set("limit", strconv.Itoa(pageSize))
+		}
+		if cursor != "" {
+			query.Set("after", cursor)
+		}
+		var res struct {
+			Transactions []Transaction `json:"transactions"`
+			NextCursor   *int64        `json:"next_cursor"`
+		}
+		req := request{
+			method:     http.MethodGet,
+			path:       "/accounts/" + url.PathEscape(number) + "/transactions",
+			query:      query,
+			customerID: customerID,
+		}
+		if err := c.do(ctx, req, &res); err != nil {
+			return nil, "", err
+		}
+		if res.NextCursor == nil {
+			return res.Transactions, "", nil
+		}
+		return res.Transactions, strconv.FormatInt(*res.NextCursor, 10), nil
+	})
+}
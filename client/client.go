@@ -0,0 +1,193 @@
+// Package client is the Go SDK of the best-wallet API.
+//
+// Requests are signed with the client app's HMAC key (see
+// middleware.HMACVerifier for the scheme), money movements get an
+// idempotency key so they can be retried safely, and retriable failures are
+// retried with backoff. Errors returned by the API are *APIError, match them
+// with errors.Is against ErrNotFound, ErrConflict...
+//
+//	c := client.New(client.Config{BaseURL: "https://wallet.example.com", KeyID: keyID, Key: key})
+//	res, err := c.Transfer(ctx, customerID, client.TransferRequest{FromAccount: from, ToAccount: to, Amount: "12.34"})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/util"
+)
+
+// Headers of the public API.
+const (
+	keyIDHeader          = "BestWallet-Key-ID"
+	signatureHeader      = "BestWallet-Signature"
+	customerIDHeader     = "BestWallet-Customer-ID"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+type Config struct {
+	BaseURL string // e.g. "https://wallet.example.com", without /api/v1.
+	KeyID   string // ID of the client app's HMAC key.
+	Key     []byte // The HMAC key, exchanged ahead of time.
+
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+	// MaxRetries of a retriable failure, defaults to 3. Negative disables
+	// retries.
+	MaxRetries int
+	// RetryBase and RetryLimit bound the backoff between retries, they
+	// default to 200ms and 5s.
+	RetryBase  time.Duration
+	RetryLimit time.Duration
+}
+
+func New(conf Config) *Client {
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if conf.MaxRetries == 0 {
+		conf.MaxRetries = 3
+	}
+	if conf.RetryBase == 0 {
+		conf.RetryBase = 200 * time.Millisecond
+	}
+	if conf.RetryLimit == 0 {
+		conf.RetryLimit = 5 * time.Second
+	}
+	conf.BaseURL = strings.TrimSuffix(conf.BaseURL, "/")
+
+	return &Client{conf: conf}
+}
+
+// Client calls the public API (/api/v1) on behalf of a client app. It's safe
+// for concurrent use.
+type Client struct {
+	conf Config
+}
+
+// request is a single API call, sent once or more if it's retried.
+type request struct {
+	method         string
+	path           string // Relative to /api/v1, escaped.
+	query          url.Values
+	customerID     uuid.UUID // Sent if not uuid.Nil.
+	idempotencyKey string    // Sent if not empty, makes POSTs retriable.
+	body           any       // Encoded as JSON if not nil.
+}
+
+// do sends req and decodes a 2xx response body into out (if not nil). Other
+// responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, body)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("can't decode %s %s response: %w", req.method, req.path, err)
+			}
+			return nil
+		}
+		if err == nil {
+			err = decodeError(resp)
+		}
+
+		if attempt >= c.conf.MaxRetries || !c.retriable(ctx, req, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(util.Backoff(attempt+1, c.conf.RetryBase, c.conf.RetryLimit)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, body []byte) (*http.Response, error) {
+	u := c.conf.BaseURL + "/api/v1" + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("User-Agent", "BestWallet-Go/1.0")
+	httpReq.Header.Set(keyIDHeader, c.conf.KeyID)
+	httpReq.Header.Set(signatureHeader, Sign(body, c.conf.Key))
+	if req.customerID != uuid.Nil {
+		httpReq.Header.Set(customerIDHeader, req.customerID.String())
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set(idempotencyKeyHeader, req.idempotencyKey)
+	}
+
+	return c.conf.HTTPClient.Do(httpReq)
+}
+
+// retriable tells if err is worth another attempt of req. Requests that
+// change state are retried only with an idempotency key, the server can't
+// tell a retry from a new request otherwise.
+func (c *Client) retriable(ctx context.Context, req request, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if req.method != http.MethodGet && req.idempotencyKey == "" {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return true // No response, the network or the server is having trouble.
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sign computes the BestWallet-Signature of a request body.
+func Sign(body, key []byte) string {
+	return util.ComputeSignature(body, key)
+}
+
+// newIdempotencyKey is used when the caller didn't pick a key, it protects
+// the retries made by the client but not retries made by the caller.
+func newIdempotencyKey(key string) string {
+	if key != "" {
+		return key
+	}
+	return uuid.NewString()
+}
+
+// drain lets the connection be reused.
+func drain(r io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(r, 64<<10))
+	r.Close()
+}
@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/router"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+const testKeyID = "test-key"
+
+// newTestServer serves the real router, wrapped by wrap (if not nil) to
+// inject failures. The store is empty, so the requests sent are rejected by
+// the handlers while validating them.
+func newTestServer(t *testing.T, key []byte, wrap func(next http.Handler) http.Handler) *httptest.Server {
+	r := router.New(router.Config{
+		Store: repo.NewMemory(),
+		HMACKeys: func(_ context.Context, keyID string) ([]byte, bool, error) {
+			return key, keyID == testKeyID, nil
+		},
+	})
+	var h http.Handler = r
+	if wrap != nil {
+		h = wrap(r)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, baseURL string, key []byte) *Client {
+	return New(Config{BaseURL: baseURL, KeyID: testKeyID, Key: key, RetryBase: time.Millisecond, RetryLimit: time.Millisecond})
+}
+
+func newKey(t *testing.T) []byte {
+	key, err := util.NewKeyHMAC(64)
+	if err != nil {
+		t.Fatalf("error generating a key: %s", err)
+	}
+	return key
+}
+
+// sameAccountTransfer passes the signature check and is rejected by the
+// transfer handler while validating the body.
+var sameAccountTransfer = TransferRequest{
+	FromAccount: "5b0d1c9e-5d0f-4a43-9f2b-1c6f5a0e8d11",
+	ToAccount:   "5b0d1c9e-5d0f-4a43-9f2b-1c6f5a0e8d11",
+	Amount:      "12.34",
+}
+
+func TestClient_SignsRequests(t *testing.T) {
+	// Arrange.
+	key := newKey(t)
+	srv := newTestServer(t, key, nil)
+	c := newTestClient(t, srv.URL, key)
+
+	// Act.
+	_, err := c.Transfer(context.Background(), uuid.New(), sameAccountTransfer)
+
+	// Assert: the handler got to read the body.
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %v", err)
+	}
+	if !errors.Is(err, ErrBadRequest) || apiErr.Message != "can't transfer to the same account" {
+		t.Fatalf("unexpected error %d %q", apiErr.StatusCode, apiErr.Message)
+	}
+}
+
+func TestClient_WrongKey(t *testing.T) {
+	// Arrange.
+	var attempts int
+	srv := newTestServer(t, newKey(t), func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			next.ServeHTTP(w, r)
+		})
+	})
+	c := newTestClient(t, srv.URL, newKey(t))
+
+	// Act.
+	_, err := c.Transfer(context.Background(), uuid.New(), sameAccountTransfer)
+
+	// Assert.
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int // 503s before the real router answers.
+		send         func(c *Client) error
+		wantAttempts int
+		wantErr      error
+	}{
+		{
+			name:     "money movement, recovers",
+			failures: 2,
+			send: func(c *Client) error {
+				_, err := c.Transfer(context.Background(), uuid.New(), sameAccountTransfer)
+				return err
+			},
+			wantAttempts: 3,
+			wantErr:      ErrBadRequest,
+		},
+		{
+			name:     "money movement, gives up",
+			failures: 10,
+			send: func(c *Client) error {
+				_, err := c.Transfer(context.Background(), uuid.New(), sameAccountTransfer)
+				return err
+			},
+			wantAttempts: 4,
+			wantErr:      ErrServer,
+		},
+		{
+			name:     "read, recovers",
+			failures: 1,
+			send: func(c *Client) error {
+				_, err := c.QuoteFee(context.Background(), uuid.New(), "deposit", "1.00", "")
+				return err
+			},
+			wantAttempts: 2,
+			wantErr:      ErrBadRequest,
+		},
+		{
+			name:     "no idempotency key, not retried",
+			failures: 1,
+			send: func(c *Client) error {
+				_, err := c.CreateAccount(context.Background(), uuid.New(), "checking")
+				return err
+			},
+			wantAttempts: 1,
+			wantErr:      ErrServer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			var mu sync.Mutex
+			var attempts int
+			keys := map[string]bool{}
+			key := newKey(t)
+			srv := newTestServer(t, key, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					attempts++
+					keys[r.Header.Get(idempotencyKeyHeader)] = true
+					fail := attempts <= tt.failures
+					mu.Unlock()
+					if fail {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			c := newTestClient(t, srv.URL, key)
+
+			// Act.
+			err := tt.send(c)
+
+			// Assert.
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Fatalf("expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+			if len(keys) != 1 {
+				t.Fatalf("expected the same idempotency key on every attempt, got %v", keys)
+			}
+		})
+	}
+}
+
+func TestClient_LimitExceeded(t *testing.T) {
+	// Arrange.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"limit_exceeded","message":"daily withdrawal limit exceeded","requested":"600.00",` +
+			`"kind":"withdrawal","period":"daily","currency":"EUR","limit":"1000.00","used":"500.00","remaining":"500.00","resets_at":"2024-03-02T00:00:00Z"}`))
+	}))
+	t.Cleanup(srv.Close)
+	c := newTestClient(t, srv.URL, newKey(t))
+
+	// Act.
+	_, err := c.Withdraw(context.Background(), uuid.New(), uuid.NewString(), WithdrawRequest{Amount: "600.00"})
+
+	// Assert.
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrLimitExceeded) || !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected a limit exceeded error, got %v", err)
+	}
+	if apiErr.Message != "daily withdrawal limit exceeded" || apiErr.Limit.Remaining != "500.00" || apiErr.Limit.Requested != "600.00" {
+		t.Fatalf("unexpected limit %q %+v", apiErr.Message, apiErr.Limit)
+	}
+}
+
+func TestClient_ListAccountTransactions(t *testing.T) {
+	// Arrange: 5 transactions served 2 per page, the cursor is the index.
+	var txs []Transaction
+	for i := 0; i < 5; i++ {
+		txs = append(txs, Transaction{TransactionID: uuid.New(), Amount: int64(i)})
+	}
+	key := newKey(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(signatureHeader) != Sign(nil, key) || r.URL.Query().Get("limit") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		from, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		to := min(from+2, len(txs))
+		res := map[string]any{"transactions": txs[from:to]}
+		if to < len(txs) {
+			res["next_cursor"] = to
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	c := newTestClient(t, srv.URL, key)
+
+	// Act.
+	var got []Transaction
+	it := c.ListAccountTransactions(context.Background(), uuid.New(), uuid.NewString(), 2)
+	for it.Next() {
+		got = append(got, it.Value())
+	}
+
+	// Assert.
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != len(txs) {
+		t.Fatalf("expected %d transactions, got %d", len(txs), len(got))
+	}
+	for i := range txs {
+		if got[i].TransactionID != txs[i].TransactionID {
+			t.Fatalf("transaction %d: expected %s, got %s", i, txs[i].TransactionID, got[i].TransactionID)
+		}
+	}
+}
+
+func TestIterator_StopsOnError(t *testing.T) {
+	// Arrange.
+	boom := errors.New("boom")
+	var fetches int
+	it := newIterator(func(cursor string) ([]int, string, error) {
+		fetches++
+		if cursor == "" {
+			return []int{1, 2}, "next", nil
+		}
+		return nil, "", boom
+	})
+
+	// Act.
+	var got []int
+	for it.Next() {
+		got = append(got, it.Value())
+	}
+
+	// Assert.
+	if !errors.Is(it.Err(), boom) || len(got) != 2 || fetches != 2 {
+		t.Fatalf("unexpected result %v, %v after %d fetches", got, it.Err(), fetches)
+	}
+	if it.Next() {
+		t.Fatal("expected Next to stay false after an error")
+	}
+}
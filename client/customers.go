@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateCustomerRequest struct {
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	Email            string    `json:"email"`
+	ResidenceAddress string    `json:"residence_address"`
+	BirthDate        time.Time `json:"birth_date"`
+	Phone            string    `json:"phone,omitempty"`  // Optional, enables SMS notifications.
+	Locale           string    `json:"locale,omitempty"` // Optional, defaults to "en".
+}
+
+// CreateCustomer registers a customer and returns their ID. KYC runs in the
+// background, see GetCustomerKYC.
+func (c *Client) CreateCustomer(ctx context.Context, req CreateCustomerRequest) (uuid.UUID, error) {
+	var res struct {
+		ID uuid.UUID `json:"id"`
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/customers", body: req}, &res)
+	return res.ID, err
+}
+
+type CustomerKYC struct {
+	CustomerID uuid.UUID  `json:"customer_id"`
+	Status     string     `json:"status"` // pending, in_progress, approved or rejected.
+	Tier       string     `json:"tier"`   // standard or enhanced.
+	Timeline   []KYCEvent `json:"timeline"`
+}
+
+// KYCEvent is a move of the KYC status, From is nil when the customer was
+// created.
+type KYCEvent struct {
+	From       *string   `json:"from"`
+	To         string    `json:"to"`
+	Reason     string    `json:"reason"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// GetCustomerKYC returns the KYC status of a customer with its timeline,
+// oldest first.
+func (c *Client) GetCustomerKYC(ctx context.Context, customerID uuid.UUID) (CustomerKYC, error) {
+	var res CustomerKYC
+	err := c.do(ctx, request{method: http.MethodGet, path: "/customers/" + customerID.String() + "/kyc"}, &res)
+	return res, err
+}
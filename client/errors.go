@@ -0,0 +1,88 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Errors to match an *APIError with errors.Is.
+var (
+	ErrBadRequest    = errors.New("bad request")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrLimitExceeded = errors.New("limit exceeded")
+	ErrServer        = errors.New("server error")
+)
+
+// APIError is a non-2xx response of the API.
+type APIError struct {
+	StatusCode int
+	// Message explains the error, it's empty for 401 and 500 responses and
+	// for malformed JSON bodies.
+	Message string
+	// Limit is set when a money movement would exceed a limit, see GetLimits.
+	Limit *LimitExceeded
+}
+
+// LimitExceeded is the limit a money movement would exceed.
+type LimitExceeded struct {
+	Requested string `json:"requested"`
+	LimitUsage
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("best-wallet: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("best-wallet: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrLimitExceeded:
+		return e.Limit != nil
+	case ErrServer:
+		return e.StatusCode >= 500
+	default:
+		return false
+	}
+}
+
+// decodeError reads an error response. The body is a JSON string with the
+// message, the limit_exceeded object, or nothing.
+func decodeError(resp *http.Response) error {
+	defer drain(resp.Body)
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil || len(raw) == 0 {
+		return apiErr
+	}
+	if json.Unmarshal(raw, &apiErr.Message) == nil {
+		return apiErr
+	}
+	var limit struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		LimitExceeded
+	}
+	if json.Unmarshal(raw, &limit) == nil && limit.Error == "limit_exceeded" {
+		apiErr.Message, apiErr.Limit = limit.Message, &limit.LimitExceeded
+	}
+	return apiErr
+}
@@ -0,0 +1,47 @@
+package client
+
+// Iterator walks a paginated list, fetching pages as needed:
+//
+//	it := c.ListAccountTransactions(ctx, customerID, number, 0)
+//	for it.Next() {
+//		tx := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator[T any] struct {
+	fetch  func(cursor string) (page []T, next string, err error)
+	page   []T
+	cursor string
+	done   bool // No more pages to fetch.
+	value  T
+	err    error
+}
+
+func newIterator[T any](fetch func(cursor string) ([]T, string, error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch}
+}
+
+// Next advances to the next item, it returns false at the end of the list or
+// on an error.
+func (it *Iterator[T]) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.page, it.cursor, it.err = it.fetch(it.cursor)
+		it.done = it.cursor == ""
+	}
+	it.value, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Value is the current item.
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err is the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Money movements are initiated right away and cleared in the background,
+// the returned status is usually pending. They're sent with an idempotency
+// key: pass your own to make retries of your own (e.g. after a crash) safe,
+// otherwise the client picks one that covers its own retries.
+
+type DepositRequest struct {
+	Amount         string `json:"amount"` // Decimal e.g. "12.34".
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"-"`
+}
+
+type DepositResponse struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Status        string    `json:"status"`
+}
+
+// Deposit moves money into the wallet, to one of the customer's accounts.
+func (c *Client) Deposit(ctx context.Context, customerID uuid.UUID, number string, req DepositRequest) (DepositResponse, error) {
+	var res DepositResponse
+	err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           "/accounts/" + url.PathEscape(number) + "/deposit",
+		customerID:     customerID,
+		idempotencyKey: newIdempotencyKey(req.IdempotencyKey),
+		body:           req,
+	}, &res)
+	return res, err
+}
+
+type WithdrawRequest struct {
+	Amount         string `json:"amount"` // Decimal e.g. "12.34".
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"-"`
+}
+
+type WithdrawResponse struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Status        string    `json:"status"`
+	Fee           string    `json:"fee"`
+}
+
+// Withdraw moves money out of the wallet, from one of the customer's
+// accounts.
+func (c *Client) Withdraw(ctx context.Context, customerID uuid.UUID, number string, req WithdrawRequest) (WithdrawResponse, error) {
+	var res WithdrawResponse
+	err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           "/accounts/" + url.PathEscape(number) + "/withdraw",
+		customerID:     customerID,
+		idempotencyKey: newIdempotencyKey(req.IdempotencyKey),
+		body:           req,
+	}, &res)
+	return res, err
+}
+
+type TransferRequest struct {
+	FromAccount    string `json:"from_account"` // Must belong to the customer.
+	ToAccount      string `json:"to_account"`
+	Amount         string `json:"amount"` // Decimal e.g. "12.34".
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"-"`
+}
+
+type TransferResponse struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Status        string    `json:"status"`
+	Fee           string    `json:"fee"`
+}
+
+// Transfer moves money between accounts in the wallet.
+func (c *Client) Transfer(ctx context.Context, customerID uuid.UUID, req TransferRequest) (TransferResponse, error) {
+	var res TransferResponse
+	err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           "/accounts/transfer",
+		customerID:     customerID,
+		idempotencyKey: newIdempotencyKey(req.IdempotencyKey),
+		body:           req,
+	}, &res)
+	return res, err
+}
+
+type ReverseRequest struct {
+	Amount         string `json:"amount,omitempty"` // Decimal e.g. "12.34", empty reverses everything that's left.
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"-"`
+}
+
+type ReverseResponse struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Status        string    `json:"status"`
+	ReversalOf    uuid.UUID `json:"reversal_of"`
+	Amount        string    `json:"amount"`
+}
+
+// ReverseTransaction refunds a transaction the customer received, fully or
+// partially.
+func (c *Client) ReverseTransaction(ctx context.Context, customerID, transactionID uuid.UUID, req ReverseRequest) (ReverseResponse, error) {
+	var res ReverseResponse
+	err := c.do(ctx, request{
+		method:         http.MethodPost,
+		path:           "/transactions/" + transactionID.String() + "/reverse",
+		customerID:     customerID,
+		idempotencyKey: newIdempotencyKey(req.IdempotencyKey),
+		body:           req,
+	}, &res)
+	return res, err
+}
+
+type FeeQuote struct {
+	Kind          string `json:"kind"`
+	Currency      string `json:"currency"`
+	Amount        string `json:"amount"`
+	Fee           string `json:"fee"`
+	Total         string `json:"total"`          // Debited from the account.
+	FreeRemaining int    `json:"free_remaining"` // Free movements left this month after this one.
+}
+
+// QuoteFee tells what a "transfer" or "withdrawal" of amount would cost. The
+// currency defaults to EUR when empty.
+func (c *Client) QuoteFee(ctx context.Context, customerID uuid.UUID, kind, amount, currency string) (FeeQuote, error) {
+	query := url.Values{"kind": {kind}, "amount": {amount}}
+	if currency != "" {
+		query.Set("currency", currency)
+	}
+	var res FeeQuote
+	err := c.do(ctx, request{method: http.MethodGet, path: "/fees/quote", query: query, customerID: customerID}, &res)
+	return res, err
+}
+
+type LimitUsage struct {
+	Kind      string    `json:"kind"`   // deposit, withdrawal or transfer_out.
+	Period    string    `json:"period"` // daily or monthly.
+	Currency  string    `json:"currency"`
+	Limit     string    `json:"limit"`
+	Used      string    `json:"used"`
+	Remaining string    `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+type Limits struct {
+	Tier   string       `json:"tier"`
+	Limits []LimitUsage `json:"limits"`
+}
+
+// GetLimits returns the customer's limits and how much of them is used.
+func (c *Client) GetLimits(ctx context.Context, customerID uuid.UUID) (Limits, error) {
+	var res Limits
+	err := c.do(ctx, request{method: http.MethodGet, path: "/limits", customerID: customerID}, &res)
+	return res, err
+}
@@ -0,0 +1,51 @@
+// Command accrue-interest runs the interest batch for a single date, e.g. to
+// redo a day by hand. The server runs the same batch daily on its own. It's
+// safe to run again for a date that was already processed.
+//
+//	accrue-interest -date 2024-03-31
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
+
+	"github.com/detod/best-wallet/internal/job"
+)
+
+func main() {
+	var ctx = context.Background()
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	dateRaw := flag.String("date", yesterday, "the day to accrue interest for (UTC), capitalizes the month if it's the last day")
+	flag.Parse()
+	date, err := time.Parse(time.DateOnly, *dateRaw)
+	if err != nil {
+		log.Fatal("Can't parse date: ", err)
+	}
+
+	// Postgres.
+	pgxConf, err := pgxpool.ParseConfig(os.Getenv("POSTGRES_CONN_STRING"))
+	if err != nil {
+		log.Fatal("Can't parse pgx config: ", err)
+	}
+	pgxConf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxgoogleuuid.Register(conn.TypeMap()) // So we can use google/uuid type with pgx.
+		return nil
+	}
+	db, err := pgxpool.NewWithConfig(ctx, pgxConf)
+	if err != nil {
+		log.Fatal("Can't create pgx pool: ", err)
+	}
+	defer db.Close()
+
+	if err := job.NewAccrueInterest(db, 0).RunForDate(ctx, date); err != nil {
+		log.Fatal("Can't accrue interest: ", err)
+	}
+}
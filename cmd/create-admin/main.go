@@ -0,0 +1,66 @@
+// Command create-admin adds a back office user and prints its bearer token.
+// It bootstraps the first admin, who then adds the others through the admin
+// API (POST /admin/v1/users).
+//
+//	create-admin -email ops@example.com -role admin
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func main() {
+	var ctx = context.Background()
+
+	email := flag.String("email", "", "email of the user")
+	role := flag.String("role", string(domain.AdminRoleViewer), "viewer, operator or admin")
+	flag.Parse()
+	if *email == "" {
+		log.Fatal("Missing -email")
+	}
+
+	// Postgres.
+	pgxConf, err := pgxpool.ParseConfig(os.Getenv("POSTGRES_CONN_STRING"))
+	if err != nil {
+		log.Fatal("Can't parse pgx config: ", err)
+	}
+	pgxConf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxgoogleuuid.Register(conn.TypeMap()) // So we can use google/uuid type with pgx.
+		return nil
+	}
+	db, err := pgxpool.NewWithConfig(ctx, pgxConf)
+	if err != nil {
+		log.Fatal("Can't create pgx pool: ", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		log.Fatal("Can't begin transaction: ", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := audit.Set(ctx, tx, audit.System("cmd:create-admin"), "create-admin", ""); err != nil {
+		log.Fatal("Can't set audit actor: ", err)
+	}
+	user, token, err := admin.CreateUser(ctx, tx, *email, domain.AdminRole(*role))
+	if err != nil {
+		log.Fatal("Can't create user: ", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Fatal("Can't commit: ", err)
+	}
+
+	fmt.Printf("Created %s (%s) %s, token (shown only once):\n%s\n", user.Email, user.Role, user.ID, token)
+}
@@ -0,0 +1,125 @@
+// Command reconcile checks the ledger for inconsistencies, see package
+// reconcile. It writes a JSON report, optionally Prometheus metrics, stores
+// the report in the DB and posts an alert to ALERT_WEBHOOK_URL if anything
+// is off.
+//
+// Exits with 2 if discrepancies were found, 1 if it couldn't finish.
+//
+//	reconcile -report report.json -metrics /var/lib/node_exporter/reconcile.prom
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
+
+	"github.com/detod/best-wallet/internal/reconcile"
+)
+
+func main() {
+	var ctx = context.Background()
+
+	account := flag.String("account", "", "only check the account with this ID or number")
+	reportPath := flag.String("report", "-", "where to write the JSON report, - for stdout")
+	metricsPath := flag.String("metrics", "", "where to write Prometheus metrics, skipped if empty")
+	flag.Parse()
+
+	// Postgres.
+	pgxConf, err := pgxpool.ParseConfig(os.Getenv("POSTGRES_CONN_STRING"))
+	if err != nil {
+		log.Fatal("Can't parse pgx config: ", err)
+	}
+	pgxConf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxgoogleuuid.Register(conn.TypeMap()) // So we can use google/uuid type with pgx.
+		return nil
+	}
+	db, err := pgxpool.NewWithConfig(ctx, pgxConf)
+	if err != nil {
+		log.Fatal("Can't create pgx pool: ", err)
+	}
+	defer db.Close()
+
+	var opts reconcile.Options
+	if *account != "" {
+		var id uuid.UUID
+		sql := `SELECT id FROM accounts WHERE id::text = $1 OR number = $1`
+		if err := db.QueryRow(ctx, sql, *account).Scan(&id); err != nil {
+			log.Fatal("Can't find account: ", err)
+		}
+		opts.AccountID = &id
+	}
+
+	// All checks see the same snapshot, money keeps moving meanwhile.
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Fatal("Can't begin transaction: ", err)
+	}
+	report, err := reconcile.Run(ctx, tx, opts)
+	tx.Rollback(ctx)
+	if err != nil {
+		log.Fatal("Can't reconcile: ", err)
+	}
+
+	// Report.
+	out := os.Stdout
+	if *reportPath != "-" {
+		if out, err = os.Create(*reportPath); err != nil {
+			log.Fatal("Can't create report: ", err)
+		}
+		defer out.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal("Can't write report: ", err)
+	}
+	if err := reconcile.Save(ctx, db, report); err != nil {
+		log.Println("Can't store report: ", err)
+	}
+
+	// Metrics.
+	if *metricsPath != "" {
+		// Write and rename, so the collector never reads a partial file.
+		tmp := *metricsPath + ".tmp"
+		f, err := os.Create(tmp)
+		if err != nil {
+			log.Fatal("Can't create metrics file: ", err)
+		}
+		if err := reconcile.WriteMetrics(f, report); err != nil {
+			log.Fatal("Can't write metrics: ", err)
+		}
+		f.Close()
+		if err := os.Rename(tmp, *metricsPath); err != nil {
+			log.Fatal("Can't write metrics: ", err)
+		}
+	}
+
+	if report.OK() {
+		log.Printf("Reconciled %d transactions, %d accounts, %d holds: no discrepancies",
+			report.TransactionsChecked, report.AccountsChecked, report.HoldsChecked)
+		return
+	}
+
+	// Alerts.
+	for _, d := range report.Discrepancies {
+		log.Println("DISCREPANCY", d)
+	}
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		client := &http.Client{Timeout: 10 * time.Second}
+		if err := reconcile.SendAlert(ctx, client, url, reconcile.NewAlert(report)); err != nil {
+			log.Println("Can't send alert: ", err)
+		}
+	} else {
+		log.Println("WARNING: ALERT_WEBHOOK_URL not set, nobody was alerted")
+	}
+	os.Exit(2)
+}
@@ -2,18 +2,32 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
 
-	"github.com/detod/best-wallet/internal/handler"
-	"github.com/detod/best-wallet/internal/middleware"
+	dbmigrations "github.com/detod/best-wallet/db-migrations"
+	"github.com/detod/best-wallet/internal/apikey"
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/consumer"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+	"github.com/detod/best-wallet/internal/migrate"
+	"github.com/detod/best-wallet/internal/notify"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/router"
+	"github.com/detod/best-wallet/internal/screening"
+	"github.com/detod/best-wallet/internal/util"
+	"github.com/detod/best-wallet/internal/webhook"
 )
 
 func main() {
@@ -34,6 +48,24 @@ func main() {
 		log.Fatal("Can't create pgx pool: ", err)
 	}
 
+	// Schema. `server migrate ...` manages it, the server itself refuses to
+	// run against a schema older than the one it was built for.
+	migrator, err := migrate.New(db, dbmigrations.FS)
+	if err != nil {
+		log.Fatal("Can't load migrations: ", err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(ctx, migrator, os.Args[2:])
+		return
+	}
+	schema, err := migrator.Status(ctx)
+	if err != nil {
+		log.Fatal("Can't read schema version: ", err)
+	}
+	if err := schema.Check(); err != nil {
+		log.Fatal(err)
+	}
+
 	// Redis.
 	redis := redis.NewClient(&redis.Options{Addr: os.Getenv("REDIS_ADDR")})
 	if _, err := redis.Ping(ctx).Result(); err != nil { // TODO configure redis.
@@ -43,35 +75,204 @@ func main() {
 	// TODO tracing.
 	// TODO catch signals and shutdown gracefully.
 
-	// Handlers.
-	createCustomer := handler.NewCreateCustomer(db)
-	createAccount := handler.NewCreateAccount(db)
-	listAccounts := handler.NewListAccounts(db)
-	deposit := handler.NewDeposit()
-	withdraw := handler.NewWithdraw()
-	transfer := handler.NewTransfer()
+	// Customer PII, encrypted with data keys wrapped by a local KMS. The KEKs
+	// come from PII_KEYS, or the file in PII_KEYS_FILE.
+	piiKeys := os.Getenv("PII_KEYS")
+	if path := os.Getenv("PII_KEYS_FILE"); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal("Can't read PII_KEYS_FILE: ", err)
+		}
+		piiKeys = strings.TrimSpace(string(raw))
+	}
+	keks, kekID, err := blob.ParseKeys(piiKeys)
+	if err != nil {
+		log.Fatal("Can't parse PII keys: ", err)
+	}
+	kms, err := pii.NewLocalKMS(keks, kekID)
+	if err != nil {
+		log.Fatal("Invalid PII keys: ", err)
+	}
+	blindIndexKey, err := base64.StdEncoding.DecodeString(os.Getenv("PII_BLIND_INDEX_KEY"))
+	if err != nil {
+		log.Fatal("Can't parse PII_BLIND_INDEX_KEY: ", err)
+	}
+	piiCipher, err := pii.NewCipher(kms, blindIndexKey)
+	if err != nil {
+		log.Fatal("Invalid PII_BLIND_INDEX_KEY: ", err)
+	}
+	reencryptCustomers := job.NewReencryptCustomers(db, piiCipher, time.Minute)
+	go util.Recover(func() { reencryptCustomers.Run(ctx) })
 
-	// Middleware.
-	hmacVerifier := middleware.HMACVerifier(nil)
+	// Sanctions and PEP screening.
+	thresholds := screening.DefaultThresholds
+	if v := os.Getenv("SCREENING_STRONG_THRESHOLD"); v != "" {
+		if thresholds.Strong, err = strconv.ParseFloat(v, 64); err != nil {
+			log.Fatal("Can't parse SCREENING_STRONG_THRESHOLD: ", err)
+		}
+	}
+	if v := os.Getenv("SCREENING_REVIEW_THRESHOLD"); v != "" {
+		if thresholds.Review, err = strconv.ParseFloat(v, 64); err != nil {
+			log.Fatal("Can't parse SCREENING_REVIEW_THRESHOLD: ", err)
+		}
+	}
+	if err := thresholds.Validate(); err != nil {
+		log.Fatal("Invalid screening thresholds: ", err)
+	}
+	screener := screening.NewScreener(thresholds)
+	if dir := os.Getenv("WATCHLIST_DIR"); dir != "" {
+		rescreen := job.NewRescreenCustomers(db, screener, piiCipher)
+		loader := job.NewWatchlistLoader(dir, time.Minute, screener, rescreen)
+		if _, err := loader.Load(); err != nil { // Don't serve before lists are loaded.
+			log.Fatal("Can't load watchlists: ", err)
+		}
+		go util.Recover(func() { loader.Run(ctx) })
+	} else {
+		log.Println("WARNING: WATCHLIST_DIR not set, customers will not be screened")
+	}
 
-	// Routing.
-	r := gin.Default()
-	v1 := r.Group("/api/v1")
-	{
-		v1.POST("/customers", hmacVerifier, createCustomer.Handle) // Create customer.
-
-		v1.POST("/accounts", hmacVerifier, createAccount.Handle)             // Open a new personal account for a customer.
-		v1.GET("/accounts", hmacVerifier, listAccounts.Handle)               // List all accounts for a customer.
-		v1.POST("/accounts/:number/deposit", hmacVerifier, deposit.Handle)   // Money coming into the wallet.
-		v1.POST("/accounts/:number/withdraw", hmacVerifier, withdraw.Handle) // Money leaving the wallet.
-		v1.POST("/accounts/transfer", hmacVerifier, transfer.Handle)         // Money moving within the wallet.
-	}
-
-	// TODO deep healthchecks.
-	r.GET("/ping", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "pong",
+	// Notifications. Channels that aren't configured go to the log sink.
+	templates, err := notify.LoadTemplates()
+	if err != nil {
+		log.Fatal("Can't load notification templates: ", err)
+	}
+	notifications := notify.NewOutbox(templates, piiCipher)
+	logSink := notify.NewLogNotifier(os.Stdout)
+	if path := os.Getenv("NOTIFICATION_LOG_FILE"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Fatal("Can't open notification log file: ", err)
+		}
+		defer f.Close()
+		logSink = notify.NewLogNotifier(f)
+	}
+	notifiers := map[notify.Channel]notify.Notifier{
+		notify.ChannelEmail: logSink,
+		notify.ChannelSMS:   logSink,
+	}
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		notifiers[notify.ChannelEmail] = notify.NewSMTPNotifier(notify.SMTPConfig{
+			Addr:     addr,
+			From:     os.Getenv("SMTP_FROM"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		})
+	}
+	if url := os.Getenv("SMS_GATEWAY_URL"); url != "" {
+		notifiers[notify.ChannelSMS] = notify.NewSMSGatewayNotifier(notify.SMSGatewayConfig{
+			URL:    url,
+			Token:  os.Getenv("SMS_GATEWAY_TOKEN"),
+			Sender: os.Getenv("SMS_SENDER"),
+		})
+	}
+	dispatchNotifications := job.NewDispatchNotifications(db, notifiers, 5*time.Second)
+	go util.Recover(func() { dispatchNotifications.Run(ctx) })
+
+	// KYC documents, encrypted at rest in the blob store.
+	var blobs blob.BlobStore
+	if endpoint := os.Getenv("BLOB_S3_ENDPOINT"); endpoint != "" {
+		blobs = blob.NewS3Store(blob.S3Config{
+			Endpoint:  endpoint,
+			Region:    os.Getenv("BLOB_S3_REGION"),
+			Bucket:    os.Getenv("BLOB_S3_BUCKET"),
+			AccessKey: os.Getenv("BLOB_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("BLOB_S3_SECRET_KEY"),
+		}, &http.Client{Timeout: 30 * time.Second})
+	} else {
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "blobs"
+		}
+		if blobs, err = blob.NewFSStore(dir); err != nil {
+			log.Fatal("Can't create blob store: ", err)
+		}
+	}
+	documentKeys, documentKeyID, err := blob.ParseKeys(os.Getenv("DOCUMENT_ENCRYPTION_KEYS"))
+	if err != nil {
+		log.Fatal("Can't parse DOCUMENT_ENCRYPTION_KEYS: ", err)
+	}
+	documents, err := blob.NewEncryptedStore(blobs, documentKeys, documentKeyID)
+	if err != nil {
+		log.Fatal("Invalid DOCUMENT_ENCRYPTION_KEYS: ", err)
+	}
+
+	// KYT. Customer payments at or above the threshold wait for an operator.
+	kytThreshold, err := domain.ParseAmount("10000.00")
+	if err != nil {
+		log.Fatal("Can't parse default KYT threshold: ", err)
+	}
+	if v := os.Getenv("KYT_REVIEW_THRESHOLD"); v != "" {
+		if kytThreshold, err = domain.ParseAmount(v); err != nil {
+			log.Fatal("Can't parse KYT_REVIEW_THRESHOLD: ", err)
+		}
+	}
+
+	// Events. State changes write events to the outbox table, the relay
+	// publishes them to a redis stream and consumer groups process them.
+	eventStream := os.Getenv("EVENT_STREAM")
+	if eventStream == "" {
+		eventStream = "bestwallet:events"
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Fatal("Can't read hostname: ", err)
+	}
+	relayOutbox := job.NewRelayOutbox(db, redis, eventStream, time.Second)
+	go util.Recover(func() { relayOutbox.Run(ctx) })
+	consumers := []struct {
+		group       string
+		concurrency int
+		handler     consumer.Handler
+	}{
+		{"kyc", 10, consumer.NewRunKYC(db, screener, piiCipher, time.Minute)},
+		{"notifications", 1, consumer.NewNotifyCustomer(db, notifications)},
+		{"webhooks", 1, consumer.NewFanOutWebhooks(db)},
+		{"clearing", 10, consumer.NewClearTransactions(db, kytThreshold)},
+	}
+	for _, cons := range consumers {
+		runner := consumer.NewRunner(redis, consumer.RunnerConfig{
+			Stream:      eventStream,
+			Group:       cons.group,
+			Consumer:    hostname,
+			Concurrency: cons.concurrency,
+			ClaimIdle:   5 * time.Minute,
+			MaxAttempts: 10,
+		}, cons.handler)
+		go util.Recover(func() {
+			if err := runner.Run(ctx); err != nil {
+				log.Fatal("Can't run consumer: ", err)
+			}
 		})
+	}
+
+	// Webhooks.
+	deliverWebhooks := job.NewDeliverWebhooks(db, webhook.NewSender(10*time.Second), 5*time.Second)
+	go util.Recover(func() { deliverWebhooks.Run(ctx) })
+
+	// Scheduled transfers.
+	executeScheduledTransfers := job.NewExecuteScheduledTransfers(db, hostname, 10*time.Second)
+	go util.Recover(func() { executeScheduledTransfers.Run(ctx) })
+
+	// Interest on savings accounts.
+	accrueInterest := job.NewAccrueInterest(db, time.Hour)
+	go util.Recover(func() { accrueInterest.Run(ctx) })
+
+	// Monthly statements.
+	generateStatements := job.NewGenerateStatements(db, time.Hour)
+	go util.Recover(func() { generateStatements.Run(ctx) })
+
+	// Audit log.
+	sealAuditLog := job.NewSealAuditLog(db, 5*time.Second)
+	go util.Recover(func() { sealAuditLog.Run(ctx) })
+
+	// Routing.
+	r := router.New(router.Config{
+		DB:        db,
+		PII:       piiCipher,
+		Documents: documents,
+		HMACKeys: func(ctx context.Context, keyID string) ([]byte, bool, error) {
+			return apikey.FindSecret(ctx, db, keyID)
+		},
 	})
 
 	// Serve HTTP.
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/detod/best-wallet/internal/migrate"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+  up        apply all pending migrations
+  down [n]  revert the last n migrations, 1 by default
+  status    print the schema version and pending migrations`
+
+// runMigrate handles `server migrate ...` and exits non-zero on failure.
+func runMigrate(ctx context.Context, m *migrate.Migrator, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatal("Can't migrate up: ", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				os.Exit(2)
+			}
+			steps = n
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatal("Can't migrate down: ", err)
+		}
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal("Can't read schema version: ", err)
+		}
+		fmt.Printf("version: %d", status.Version)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Printf("\nlatest:  %d\n", status.Latest)
+		for _, mig := range status.Pending {
+			fmt.Printf("pending: %d_%s\n", mig.Version, mig.Name)
+		}
+		if err := status.Check(); err != nil {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
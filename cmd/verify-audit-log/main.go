@@ -0,0 +1,93 @@
+// Command verify-audit-log walks the audit log hash chain and reports records
+// that were deleted or edited, see package audit. Keep the head it prints
+// somewhere safe (outside of this DB) and pass it as -anchor next time, so
+// records deleted from the end of the chain are caught too.
+//
+// Exits with 2 if the chain is broken, 1 if it couldn't finish.
+//
+//	verify-audit-log -anchor 1234:9f86d0...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
+
+	"github.com/detod/best-wallet/internal/audit"
+)
+
+func main() {
+	var ctx = context.Background()
+
+	anchorRaw := flag.String("anchor", "", "head from a previous run, <seq>:<hex hash>")
+	reportPath := flag.String("report", "-", "where to write the JSON report, - for stdout")
+	flag.Parse()
+
+	var anchor *audit.Head
+	if *anchorRaw != "" {
+		head, err := audit.ParseHead(*anchorRaw)
+		if err != nil {
+			log.Fatal("Can't parse anchor: ", err)
+		}
+		anchor = &head
+	}
+
+	// Postgres.
+	pgxConf, err := pgxpool.ParseConfig(os.Getenv("POSTGRES_CONN_STRING"))
+	if err != nil {
+		log.Fatal("Can't parse pgx config: ", err)
+	}
+	pgxConf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxgoogleuuid.Register(conn.TypeMap()) // So we can use google/uuid type with pgx.
+		return nil
+	}
+	db, err := pgxpool.NewWithConfig(ctx, pgxConf)
+	if err != nil {
+		log.Fatal("Can't create pgx pool: ", err)
+	}
+	defer db.Close()
+
+	// The sealer keeps extending the chain meanwhile, work on a snapshot.
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Fatal("Can't begin transaction: ", err)
+	}
+	report, err := audit.Verify(ctx, tx, anchor)
+	tx.Rollback(ctx)
+	if err != nil {
+		log.Fatal("Can't verify audit log: ", err)
+	}
+
+	// Report.
+	out := os.Stdout
+	if *reportPath != "-" {
+		if out, err = os.Create(*reportPath); err != nil {
+			log.Fatal("Can't create report: ", err)
+		}
+		defer out.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal("Can't write report: ", err)
+	}
+
+	if report.Unsealed > 0 {
+		log.Printf("%d records not sealed yet, oldest from %s", report.Unsealed, report.OldestUnsealedAt)
+	}
+	if report.OK() {
+		log.Printf("Verified %d records, head %s", report.Checked, report.Head)
+		return
+	}
+
+	for _, p := range report.Problems {
+		log.Printf("BROKEN %s at %d: %s", p.Kind, p.Seq, p.Detail)
+	}
+	os.Exit(2)
+}
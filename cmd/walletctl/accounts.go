@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/reconcile"
+)
+
+type accountView struct {
+	ID           uuid.UUID             `json:"id" db:"id"`
+	Number       string                `json:"number" db:"number"`
+	CustomerID   *uuid.UUID            `json:"customer_id" db:"customer_id"`
+	Kind         domain.AccountKind    `json:"kind" db:"kind"`
+	Product      domain.AccountProduct `json:"product" db:"product"`
+	Currency     string                `json:"currency" db:"currency"`
+	Balance      int64                 `json:"balance" db:"balance"`
+	Status       domain.AccountStatus  `json:"status" db:"status"`
+	StatusReason *string               `json:"status_reason" db:"status_reason"`
+	CreatedAt    time.Time             `json:"created_at" db:"created_at"`
+}
+
+const accountSelect = `
+	SELECT id, number, customer_id, kind, product, currency, balance, status, status_reason, created_at
+	FROM accounts`
+
+func accountGet(ctx context.Context, e *env, args []string) error {
+	args, err := parseFlags(flag.NewFlagSet("account get", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	db, err := e.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := db.Query(ctx, accountSelect+` WHERE id::text = $1 OR number = $1`, args[0])
+	a, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[accountView])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("account not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	customer := "-"
+	if a.CustomerID != nil {
+		customer = a.CustomerID.String()
+	}
+	return e.print(a, fields(
+		"ID", a.ID.String(),
+		"Number", a.Number,
+		"Customer", customer,
+		"Kind", string(a.Kind),
+		"Product", string(a.Product),
+		"Balance", domain.FormatAmount(a.Balance)+" "+a.Currency,
+		"Status", string(a.Status),
+		"Status reason", stringCell(a.StatusReason),
+		"Created", a.CreatedAt.Format(time.RFC3339),
+	))
+}
+
+func accountFreeze(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("account freeze", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why, shown in the frozen accounts queue")
+	args, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	api, err := e.adminAPI()
+	if err != nil {
+		return err
+	}
+
+	if err := api.do(ctx, "POST", "/accounts/"+args[0]+"/freeze", map[string]string{"reason": *reason}, nil); err != nil {
+		return err
+	}
+	return e.print(map[string]string{"number": args[0], "status": string(domain.AccountStatusFrozen)},
+		fields("Account", args[0], "Status", string(domain.AccountStatusFrozen)))
+}
+
+func accountUnfreeze(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("account unfreeze", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why, for the checker")
+	args, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	api, err := e.adminAPI()
+	if err != nil {
+		return err
+	}
+
+	var a approval
+	if err := api.do(ctx, "POST", "/accounts/"+args[0]+"/unfreeze", map[string]string{"reason": *reason}, &a); err != nil {
+		return err
+	}
+	return e.print(a, a.fields())
+}
+
+// accountReconcile runs the reconciliation checks of a single account, like
+// cmd/reconcile -account but without storing the report or alerting.
+func accountReconcile(ctx context.Context, e *env, args []string) error {
+	args, err := parseFlags(flag.NewFlagSet("account reconcile", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	db, err := e.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	var id uuid.UUID
+	err = db.QueryRow(ctx, `SELECT id FROM accounts WHERE id::text = $1 OR number = $1`, args[0]).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("account not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	// All checks see the same snapshot, money keeps moving meanwhile.
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	report, err := reconcile.Run(ctx, tx, reconcile.Options{AccountID: &id})
+	tx.Rollback(ctx)
+	if err != nil {
+		return err
+	}
+
+	discrepancies := table{header: []string{"KIND", "TRANSACTION", "ACCOUNT", "EXPECTED", "ACTUAL"}}
+	for _, d := range report.Discrepancies {
+		tx, account := "-", "-"
+		if d.TransactionID != nil {
+			tx = d.TransactionID.String()
+		}
+		if d.AccountNumber != "" {
+			account = d.AccountNumber
+		}
+		discrepancies.rows = append(discrepancies.rows, []string{string(d.Kind), tx, account, strconv.FormatInt(d.Expected, 10), strconv.FormatInt(d.Actual, 10)})
+	}
+	err = e.print(report, fields(
+		"Account", id.String(),
+		"Transactions checked", strconv.Itoa(report.TransactionsChecked),
+		"Holds checked", strconv.Itoa(report.HoldsChecked),
+		"Discrepancies", strconv.Itoa(len(report.Discrepancies)),
+	), discrepancies)
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return fmt.Errorf("%d discrepancies found", len(report.Discrepancies))
+	}
+	return nil
+}
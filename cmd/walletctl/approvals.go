@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// approval is an approval request as returned by the admin API.
+type approval struct {
+	ID           uuid.UUID       `json:"id"`
+	Kind         string          `json:"kind"`
+	TargetID     string          `json:"target_id"`
+	Payload      json.RawMessage `json:"payload"`
+	Reason       string          `json:"reason"`
+	Status       string          `json:"status"`
+	MakerID      uuid.UUID       `json:"maker_id"`
+	CheckerID    *uuid.UUID      `json:"checker_id,omitempty"`
+	DecisionNote *string         `json:"decision_note,omitempty"`
+	DecidedAt    *time.Time      `json:"decided_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func (a approval) fields() table {
+	checker := "-"
+	if a.CheckerID != nil {
+		checker = a.CheckerID.String()
+	}
+	return fields(
+		"Approval", a.ID.String(),
+		"Kind", a.Kind,
+		"Target", a.TargetID,
+		"Payload", string(a.Payload),
+		"Reason", a.Reason,
+		"Status", a.Status,
+		"Maker", a.MakerID.String(),
+		"Checker", checker,
+		"Decision note", stringCell(a.DecisionNote),
+		"Decided", timeCell(a.DecidedAt),
+		"Created", a.CreatedAt.Format(time.RFC3339),
+	)
+}
+
+func approvalList(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("approval list", flag.ContinueOnError)
+	status := fs.String("status", "pending", "pending, approved or rejected")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	api, err := e.adminAPI()
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Approvals []approval `json:"approvals"`
+	}
+	if err := api.do(ctx, "GET", "/approvals?status="+url.QueryEscape(*status), nil, &res); err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "KIND", "TARGET", "REASON", "MAKER", "CREATED"}}
+	for _, a := range res.Approvals {
+		t.rows = append(t.rows, []string{a.ID.String(), a.Kind, a.TargetID, a.Reason, a.MakerID.String(), a.CreatedAt.Format(time.RFC3339)})
+	}
+	return e.print(res.Approvals, t)
+}
+
+func approvalApprove(ctx context.Context, e *env, args []string) error {
+	return approvalDecide(ctx, e, args, "approve")
+}
+
+func approvalReject(ctx context.Context, e *env, args []string) error {
+	return approvalDecide(ctx, e, args, "reject")
+}
+
+func approvalDecide(ctx context.Context, e *env, args []string, decision string) error {
+	fs := flag.NewFlagSet("approval "+decision, flag.ContinueOnError)
+	note := fs.String("note", "", "note on the decision")
+	args, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	api, err := e.adminAPI()
+	if err != nil {
+		return err
+	}
+
+	var a approval
+	if err := api.do(ctx, "POST", "/approvals/"+args[0]+"/"+decision, map[string]string{"note": *note}, &a); err != nil {
+		return err
+	}
+	return e.print(a, a.fields())
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+type customerView struct {
+	ID               uuid.UUID        `json:"id"`
+	FirstName        string           `json:"first_name"`
+	LastName         string           `json:"last_name"`
+	Email            string           `json:"email"`
+	ResidenceAddress string           `json:"residence_address"`
+	BirthDate        time.Time        `json:"birth_date"`
+	Phone            string           `json:"phone"`
+	Locale           string           `json:"locale"`
+	KYCStatus        domain.KYCStatus `json:"kyc_status"`
+	KYCTier          domain.KYCTier   `json:"kyc_tier"`
+	CreatedAt        time.Time        `json:"created_at"`
+	Accounts         []accountView    `json:"accounts"`
+}
+
+func customerGet(ctx context.Context, e *env, args []string) error {
+	args, err := parseFlags(flag.NewFlagSet("customer get", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	db, err := e.db(ctx)
+	if err != nil {
+		return err
+	}
+	cipher, err := e.cipher()
+	if err != nil {
+		return err
+	}
+
+	// Emails are found by their blind index, or in plaintext on rows that
+	// aren't encrypted yet.
+	sql := `
+		SELECT id, phone, locale, kyc_status, kyc_tier, created_at, ` + pii.Columns("") + `
+		FROM customers WHERE id = $1`
+	queryArgs := []any{args[0]}
+	if strings.Contains(args[0], "@") {
+		sql = `
+			SELECT id, phone, locale, kyc_status, kyc_tier, created_at, ` + pii.Columns("") + `
+			FROM customers WHERE email_bidx = $1 OR (pii_kek_id IS NULL AND lower(email) = lower($2))`
+		queryArgs = []any{cipher.EmailIndex(args[0]), strings.TrimSpace(args[0])}
+	} else if _, err := uuid.Parse(args[0]); err != nil {
+		return errors.New("expected a customer id or an email")
+	}
+
+	var c customerView
+	var row pii.Row
+	targets := append([]any{&c.ID, &c.Phone, &c.Locale, &c.KYCStatus, &c.KYCTier, &c.CreatedAt}, row.ScanTargets()...)
+	err = db.QueryRow(ctx, sql, queryArgs...).Scan(targets...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("customer not found")
+	}
+	if err != nil {
+		return err
+	}
+	p, err := cipher.OpenRow(ctx, c.ID, row)
+	if err != nil {
+		return err
+	}
+	c.FirstName, c.LastName, c.Email, c.ResidenceAddress, c.BirthDate = p.FirstName, p.LastName, p.Email, p.ResidenceAddress, p.BirthDate
+
+	rows, _ := db.Query(ctx, accountSelect+` WHERE customer_id = $1 ORDER BY created_at`, c.ID)
+	if c.Accounts, err = pgx.CollectRows(rows, pgx.RowToStructByName[accountView]); err != nil {
+		return err
+	}
+
+	accounts := table{header: []string{"NUMBER", "PRODUCT", "CURRENCY", "BALANCE", "STATUS"}}
+	for _, a := range c.Accounts {
+		accounts.rows = append(accounts.rows, []string{a.Number, string(a.Product), a.Currency, domain.FormatAmount(a.Balance), string(a.Status)})
+	}
+	return e.print(c, fields(
+		"ID", c.ID.String(),
+		"Name", c.FirstName+" "+c.LastName,
+		"Email", c.Email,
+		"Phone", c.Phone,
+		"Address", c.ResidenceAddress,
+		"Birth date", c.BirthDate.Format(time.DateOnly),
+		"Locale", c.Locale,
+		"KYC", string(c.KYCStatus)+" ("+string(c.KYCTier)+")",
+		"Created", c.CreatedAt.Format(time.RFC3339),
+	), accounts)
+}
+
+func kycApprove(ctx context.Context, e *env, args []string) error {
+	return kycDecide(ctx, e, args, "approve")
+}
+
+func kycReject(ctx context.Context, e *env, args []string) error {
+	return kycDecide(ctx, e, args, "reject")
+}
+
+// kycDecide approves (through an approval request) or rejects a customer
+// under review.
+func kycDecide(ctx context.Context, e *env, args []string, decision string) error {
+	fs := flag.NewFlagSet("kyc "+decision, flag.ContinueOnError)
+	reason := fs.String("reason", "", "why, recorded in the KYC timeline")
+	args, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	api, err := e.adminAPI()
+	if err != nil {
+		return err
+	}
+
+	path := "/customers/" + args[0] + "/kyc/" + decision
+	body := map[string]string{"reason": *reason}
+	if decision == "reject" {
+		if err := api.do(ctx, "POST", path, body, nil); err != nil {
+			return err
+		}
+		return e.print(map[string]string{"customer_id": args[0], "kyc_status": string(domain.KYCStatusRejected)},
+			fields("Customer", args[0], "KYC", string(domain.KYCStatusRejected)))
+	}
+
+	var a approval
+	if err := api.do(ctx, "POST", path, body, &a); err != nil {
+		return err
+	}
+	return e.print(a, a.fields())
+}
+
+// kycTier requests moving an approved customer to another KYC tier, which
+// changes their limits and fees once approved.
+func kycTier(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("kyc tier", flag.ContinueOnError)
+	tier := fs.String("tier", "", "basic, standard or enhanced")
+	reason := fs.String("reason", "", "why, for the checker")
+	args, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	api, err := e.adminAPI()
+	if err != nil {
+		return err
+	}
+
+	var a approval
+	if err := api.do(ctx, "POST", "/customers/"+args[0]+"/kyc/tier", map[string]string{"tier": *tier, "reason": *reason}, &a); err != nil {
+		return err
+	}
+	return e.print(a, a.fields())
+}
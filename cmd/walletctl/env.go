@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+// actor of the changes walletctl makes to the DB directly.
+var actor = audit.System("cmd:walletctl")
+
+// env connects to what the commands need, when they need it.
+type env struct {
+	format string
+
+	pool  *pgxpool.Pool
+	rdb   *redis.Client
+	admin *adminAPI
+}
+
+func (e *env) db(ctx context.Context) (*pgxpool.Pool, error) {
+	if e.pool != nil {
+		return e.pool, nil
+	}
+
+	pgxConf, err := pgxpool.ParseConfig(os.Getenv("POSTGRES_CONN_STRING"))
+	if err != nil {
+		return nil, fmt.Errorf("can't parse pgx config: %w", err)
+	}
+	pgxConf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxgoogleuuid.Register(conn.TypeMap()) // So we can use google/uuid type with pgx.
+		return nil
+	}
+	if e.pool, err = pgxpool.NewWithConfig(ctx, pgxConf); err != nil {
+		return nil, fmt.Errorf("can't create pgx pool: %w", err)
+	}
+	return e.pool, nil
+}
+
+func (e *env) redis(ctx context.Context) (*redis.Client, error) {
+	if e.rdb != nil {
+		return e.rdb, nil
+	}
+
+	e.rdb = redis.NewClient(&redis.Options{Addr: os.Getenv("REDIS_ADDR")})
+	if err := e.rdb.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("can't talk to redis: %w", err)
+	}
+	return e.rdb, nil
+}
+
+// eventStream is the stream the outbox relay publishes to.
+func (e *env) eventStream() string {
+	if s := os.Getenv("EVENT_STREAM"); s != "" {
+		return s
+	}
+	return "bestwallet:events"
+}
+
+// cipher decrypts customer PII, it's configured like the server.
+func (e *env) cipher() (*pii.Cipher, error) {
+	keys := os.Getenv("PII_KEYS")
+	if path := os.Getenv("PII_KEYS_FILE"); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't read PII_KEYS_FILE: %w", err)
+		}
+		keys = strings.TrimSpace(string(raw))
+	}
+	keks, kekID, err := blob.ParseKeys(keys)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse PII keys: %w", err)
+	}
+	kms, err := pii.NewLocalKMS(keks, kekID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PII keys: %w", err)
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(os.Getenv("PII_BLIND_INDEX_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("can't parse PII_BLIND_INDEX_KEY: %w", err)
+	}
+	return pii.NewCipher(kms, indexKey)
+}
+
+func (e *env) adminAPI() (*adminAPI, error) {
+	if e.admin != nil {
+		return e.admin, nil
+	}
+
+	baseURL, token := os.Getenv("WALLETCTL_ADMIN_URL"), os.Getenv("WALLETCTL_ADMIN_TOKEN")
+	if baseURL == "" || token == "" {
+		return nil, errors.New("WALLETCTL_ADMIN_URL and WALLETCTL_ADMIN_TOKEN must be set")
+	}
+	e.admin = newAdminAPI(baseURL, token)
+	return e.admin, nil
+}
+
+// beginAudited starts a transaction whose changes are audited under the
+// walletctl actor.
+func (e *env) beginAudited(ctx context.Context, action string) (pgx.Tx, error) {
+	db, err := e.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := audit.Set(ctx, tx, actor, "walletctl "+action, ""); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (e *env) close() {
+	if e.pool != nil {
+		e.pool.Close()
+	}
+	if e.rdb != nil {
+		e.rdb.Close()
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"time"
+
+	"github.com/detod/best-wallet/internal/apikey"
+)
+
+func keyIssue(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("key issue", flag.ContinueOnError)
+	name := fs.String("name", "", "the client app the key is for")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	tx, err := e.beginAudited(ctx, "key issue")
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	key, secret, err := apikey.Issue(ctx, tx, *name)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// The client decodes the secret and signs with the raw bytes.
+	encoded := base64.StdEncoding.EncodeToString(secret)
+	return e.print(struct {
+		apikey.Key
+		Secret string `json:"secret"`
+	}{key, encoded}, fields(
+		"Key ID", key.ID,
+		"Name", key.Name,
+		"Secret", encoded+" (base64, shown only once)",
+	))
+}
+
+func keyRevoke(ctx context.Context, e *env, args []string) error {
+	args, err := parseFlags(flag.NewFlagSet("key revoke", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	tx, err := e.beginAudited(ctx, "key revoke")
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := apikey.Revoke(ctx, tx, args[0]); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	return e.print(map[string]string{"id": args[0], "status": "revoked"}, fields("Key ID", args[0], "Status", "revoked"))
+}
+
+func keyList(ctx context.Context, e *env, args []string) error {
+	if _, err := parseFlags(flag.NewFlagSet("key list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+	db, err := e.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := apikey.List(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "NAME", "CREATED", "REVOKED"}}
+	for _, k := range keys {
+		t.rows = append(t.rows, []string{k.ID, k.Name, k.CreatedAt.Format(time.RFC3339), timeCell(k.RevokedAt)})
+	}
+	return e.print(keys, t)
+}
@@ -0,0 +1,107 @@
+// Command walletctl is the operators' command line. It reads the DB directly
+// (customers, accounts, API keys, the outbox) and goes through the admin API
+// for actions that need a back office user, so maker-checker still applies.
+//
+//	walletctl [-o table|json] <command> [flags] [args]
+//
+// Run walletctl without arguments for the list of commands. Flags go before
+// the arguments e.g. walletctl account freeze -reason "card fraud" <number>.
+//
+// DB commands read POSTGRES_CONN_STRING, and the server's REDIS_ADDR,
+// EVENT_STREAM, PII_KEYS (or PII_KEYS_FILE) and PII_BLIND_INDEX_KEY when
+// they need them. Admin API commands read WALLETCTL_ADMIN_URL (e.g.
+// http://localhost:8080) and WALLETCTL_ADMIN_TOKEN.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name  string // One or two words e.g. "account freeze".
+	usage string // Flags and arguments.
+	help  string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = []command{
+	{"customer get", "<id|email>", "show a customer, their KYC status and accounts", customerGet},
+	{"account get", "<number|id>", "show an account", accountGet},
+	{"account freeze", "-reason <reason> <number>", "stop money going out of an account (admin API)", accountFreeze},
+	{"account unfreeze", "-reason <reason> <number>", "request lifting a freeze, needs approval (admin API)", accountUnfreeze},
+	{"account reconcile", "<number|id>", "check an account's ledger for discrepancies", accountReconcile},
+	{"kyc approve", "-reason <reason> <customer-id>", "request approving a customer under review, needs approval (admin API)", kycApprove},
+	{"kyc reject", "-reason <reason> <customer-id>", "reject a customer under review (admin API)", kycReject},
+	{"kyc tier", "-tier basic|standard|enhanced -reason <reason> <customer-id>", "request moving an approved customer to another tier, needs approval (admin API)", kycTier},
+	{"approval list", "[-status pending|approved|rejected]", "list approval requests (admin API)", approvalList},
+	{"approval approve", "[-note <note>] <approval-id>", "approve a request made by another operator (admin API)", approvalApprove},
+	{"approval reject", "[-note <note>] <approval-id>", "reject a request made by another operator (admin API)", approvalReject},
+	{"key issue", "-name <client app>", "issue an HMAC key to a client app, the secret is shown only once", keyIssue},
+	{"key revoke", "<key-id>", "revoke an HMAC key, requests signed with it are rejected", keyRevoke},
+	{"key list", "", "list HMAC keys", keyList},
+	{"outbox replay", "[-group <consumer group>] [-aggregate <id>] [event-id...]", "publish outbox events again, -group makes that group process them again", outboxReplay},
+	{"sign", "[-key-id <id>] [-key <base64>] [-customer <id>] [-body <json|@file>] [-send] <method> <url>", "sign a request to the public API, print or send it", sign},
+}
+
+func main() {
+	var ctx = context.Background()
+
+	flag.Usage = usage
+	format := flag.String("o", "table", "output format, table or json")
+	flag.Parse()
+	if *format != "table" && *format != "json" {
+		fail(errors.New("-o must be table or json"))
+	}
+
+	cmd, args, ok := findCommand(flag.Args())
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	e := &env{format: *format}
+	defer e.close()
+	if err := cmd.run(ctx, e, args); err != nil {
+		fail(err)
+	}
+}
+
+// findCommand matches the arguments against the command names, the rest are
+// the command's arguments.
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: walletctl [-o table|json] <command> [flags] [args]")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\n  %s %s\n      %s\n", cmd.name, cmd.usage, cmd.help)
+	}
+}
+
+// parseFlags parses the command's flags and checks it got n arguments.
+func parseFlags(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != n {
+		return nil, fmt.Errorf("%s: expected %d argument(s), got %d", fs.Name(), n, fs.NArg())
+	}
+	return fs.Args(), nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "walletctl:", err)
+	os.Exit(1)
+}
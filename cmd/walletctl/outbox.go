@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/consumer"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+)
+
+// outboxReplay publishes outbox events to the event stream again. Consumer
+// groups skip events they already processed, unless -group names them: the
+// group then processes the events again (e.g. after fixing a bug in its
+// handler, or for events that ended up in its dead letter stream).
+func outboxReplay(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("outbox replay", flag.ContinueOnError)
+	group := fs.String("group", "", "consumer group to process the events again e.g. kyc, notifications, webhooks, clearing")
+	aggregate := fs.String("aggregate", "", "replay every event of this customer, account... instead of listing event IDs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var ids []uuid.UUID
+	for _, arg := range fs.Args() {
+		id, err := uuid.Parse(arg)
+		if err != nil {
+			return errors.New("malformed event id " + arg)
+		}
+		ids = append(ids, id)
+	}
+	var aggregateID *uuid.UUID
+	if *aggregate != "" {
+		id, err := uuid.Parse(*aggregate)
+		if err != nil {
+			return errors.New("malformed aggregate id")
+		}
+		aggregateID = &id
+	}
+	if (len(ids) == 0) == (aggregateID == nil) {
+		return errors.New("pass either event ids or -aggregate")
+	}
+
+	db, err := e.db(ctx)
+	if err != nil {
+		return err
+	}
+	rdb, err := e.redis(ctx)
+	if err != nil {
+		return err
+	}
+
+	sql := `
+		SELECT event_id, event_type, aggregate_id, payload, occurred_at FROM outbox
+		WHERE event_id = ANY($1) OR aggregate_id = $2
+		ORDER BY seq`
+	rows, _ := db.Query(ctx, sql, ids, aggregateID)
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Event, error) {
+		var ev domain.Event
+		var payload []byte
+		err := row.Scan(&ev.ID, &ev.Type, &ev.AggregateID, &payload, &ev.OccurredAt)
+		ev.Payload = json.RawMessage(payload)
+		return ev, err
+	})
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 || (len(ids) > 0 && len(events) != len(ids)) {
+		return errors.New("event(s) not found in the outbox")
+	}
+
+	stream := e.eventStream()
+	if *group != "" {
+		for _, ev := range events {
+			if err := consumer.Forget(ctx, rdb, stream, *group, ev.ID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := job.PublishEvents(ctx, rdb, stream, events); err != nil {
+		return err
+	}
+
+	t := table{header: []string{"EVENT", "TYPE", "AGGREGATE", "OCCURRED"}}
+	for _, ev := range events {
+		t.rows = append(t.rows, []string{ev.ID.String(), string(ev.Type), ev.AggregateID.String(), ev.OccurredAt.Format(time.RFC3339)})
+	}
+	return e.print(events, t)
+}
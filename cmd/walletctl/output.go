@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// table is the table output of a command, the header is optional.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// fields is a two column table of a single record, from label and value
+// pairs.
+func fields(pairs ...string) table {
+	var t table
+	for i := 0; i+1 < len(pairs); i += 2 {
+		t.rows = append(t.rows, []string{pairs[i] + ":", pairs[i+1]})
+	}
+	return t
+}
+
+// print writes v as JSON, or the tables (separated by an empty line).
+func (e *env) print(v any, tables ...table) error {
+	if e.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Println()
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if len(t.header) > 0 {
+			fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cell formatting.
+
+func timeCell(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func stringCell(s *string) string {
+	if s == nil || *s == "" {
+		return "-"
+	}
+	return *s
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/detod/best-wallet/client"
+)
+
+type signedRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body,omitempty"`
+
+	// Set with -send.
+	StatusCode   int    `json:"status_code,omitempty"`
+	ResponseBody string `json:"response_body,omitempty"`
+}
+
+// sign signs a request to the public API the way client apps do, and prints
+// it as a curl command or sends it. The key defaults to
+// WALLETCTL_HMAC_KEY_ID and WALLETCTL_HMAC_KEY (base64), so the secret
+// doesn't end up in the shell history.
+func sign(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	keyID := fs.String("key-id", os.Getenv("WALLETCTL_HMAC_KEY_ID"), "HMAC key ID")
+	key := fs.String("key", os.Getenv("WALLETCTL_HMAC_KEY"), "HMAC key secret, base64")
+	customerID := fs.String("customer", "", "customer ID, sent as BestWallet-Customer-ID")
+	idempotencyKey := fs.String("idempotency-key", "", "sent as Idempotency-Key")
+	body := fs.String("body", "", "request body, @file reads it from a file and @- from stdin")
+	send := fs.Bool("send", false, "send the request and print the response")
+	args, err := parseFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	if *keyID == "" || *key == "" {
+		return errors.New("missing -key-id or -key")
+	}
+	secret, err := base64.StdEncoding.DecodeString(*key)
+	if err != nil {
+		return errors.New("-key must be base64")
+	}
+
+	raw := []byte(*body)
+	if path, ok := strings.CutPrefix(*body, "@"); ok {
+		if path == "-" {
+			raw, err = io.ReadAll(os.Stdin)
+		} else {
+			raw, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	req := signedRequest{
+		Method: strings.ToUpper(args[0]),
+		URL:    args[1],
+		Headers: map[string]string{
+			"BestWallet-Key-ID":    *keyID,
+			"BestWallet-Signature": client.Sign(raw, secret),
+		},
+		Body: string(raw),
+	}
+	if len(raw) > 0 {
+		req.Headers["Content-Type"] = "application/json"
+	}
+	if *customerID != "" {
+		req.Headers["BestWallet-Customer-ID"] = *customerID
+	}
+	if *idempotencyKey != "" {
+		req.Headers["Idempotency-Key"] = *idempotencyKey
+	}
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !*send {
+		curl := "curl -X " + req.Method
+		headers := table{header: []string{"HEADER", "VALUE"}}
+		for _, name := range names {
+			curl += " -H " + shellQuote(name+": "+req.Headers[name])
+			headers.rows = append(headers.rows, []string{name, req.Headers[name]})
+		}
+		if len(raw) > 0 {
+			curl += " --data-binary " + shellQuote(req.Body)
+		}
+		curl += " " + shellQuote(req.URL)
+		return e.print(req, headers, table{rows: [][]string{{curl}}})
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	req.StatusCode, req.ResponseBody = resp.StatusCode, string(respBody)
+
+	return e.print(req, fields("Status", resp.Status), table{rows: [][]string{{req.ResponseBody}}})
+}
+
+// shellQuote quotes s for sh, so the printed curl command can be pasted.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS screening_hits;
+CREATE TABLE screening_hits (
+    id UUID NOT NULL PRIMARY KEY,
+    customer_id UUID NOT NULL,
+    list_name VARCHAR NOT NULL,
+    entry_id VARCHAR NOT NULL,
+    entry_name VARCHAR NOT NULL,
+    entry_kind VARCHAR NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    strength VARCHAR NOT NULL,
+    review_status VARCHAR NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    UNIQUE (customer_id, list_name, entry_id)
+);
+CREATE INDEX screening_hits_review_status_idx ON screening_hits (review_status);
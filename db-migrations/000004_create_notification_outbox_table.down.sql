@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_outbox;
+
+ALTER TABLE customers DROP COLUMN IF EXISTS locale;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone;
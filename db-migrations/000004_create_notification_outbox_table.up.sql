@@ -0,0 +1,22 @@
+ALTER TABLE customers ADD COLUMN phone VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE customers ADD COLUMN locale VARCHAR NOT NULL DEFAULT 'en';
+
+DROP TABLE IF EXISTS notification_outbox;
+CREATE TABLE notification_outbox (
+    id UUID NOT NULL PRIMARY KEY,
+    customer_id UUID NOT NULL,
+    template VARCHAR NOT NULL,
+    channel VARCHAR NOT NULL,
+    recipient VARCHAR NOT NULL,
+    subject VARCHAR NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX notification_outbox_due_idx ON notification_outbox (next_attempt_at) WHERE status = 'pending';
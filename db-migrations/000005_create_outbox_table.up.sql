@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS outbox;
+CREATE TABLE outbox (
+    seq BIGSERIAL NOT NULL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_type VARCHAR NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX outbox_unpublished_idx ON outbox (seq) WHERE published_at IS NULL;
+CREATE INDEX outbox_aggregate_id_idx ON outbox (aggregate_id);
@@ -0,0 +1,35 @@
+DROP TABLE IF EXISTS webhook_subscriptions;
+CREATE TABLE webhook_subscriptions (
+    id UUID NOT NULL PRIMARY KEY,
+    client_key_id VARCHAR NOT NULL,
+    url VARCHAR NOT NULL,
+    event_types VARCHAR[] NOT NULL,
+    secret VARCHAR NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX webhook_subscriptions_client_key_id_idx ON webhook_subscriptions (client_key_id);
+
+DROP TABLE IF EXISTS webhook_deliveries;
+CREATE TABLE webhook_deliveries (
+    id UUID NOT NULL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id),
+    event_id UUID NOT NULL,
+    event_type VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_status_code INT,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    UNIQUE (subscription_id, event_id)
+);
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_id_idx ON webhook_deliveries (subscription_id, created_at);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS holds;
+DROP TABLE IF EXISTS entries;
+DROP TABLE IF EXISTS transactions;
+
+DROP INDEX IF EXISTS accounts_number_idx;
+ALTER TABLE accounts ALTER COLUMN customer_id SET NOT NULL;
+ALTER TABLE accounts DROP COLUMN IF EXISTS currency;
+ALTER TABLE accounts DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,50 @@
+ALTER TABLE accounts ADD COLUMN kind VARCHAR NOT NULL DEFAULT 'customer';
+ALTER TABLE accounts ADD COLUMN currency VARCHAR NOT NULL DEFAULT 'EUR';
+ALTER TABLE accounts ALTER COLUMN customer_id DROP NOT NULL; -- Internal accounts don't belong to customers.
+CREATE UNIQUE INDEX accounts_number_idx ON accounts (number);
+
+DROP TABLE IF EXISTS transactions;
+CREATE TABLE transactions (
+    id UUID NOT NULL PRIMARY KEY,
+    kind VARCHAR NOT NULL,
+    status VARCHAR NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR NOT NULL,
+    description VARCHAR NOT NULL DEFAULT '',
+    idempotency_key VARCHAR UNIQUE,
+    failure_reason VARCHAR,
+    cleared_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+-- Double bookkeeping: the entries of a transaction always sum up to zero.
+-- Negative amounts are debits (money leaving the account), positive amounts
+-- are credits (money coming into the account). Entries only count towards
+-- the balance once the transaction is cleared.
+DROP TABLE IF EXISTS entries;
+CREATE TABLE entries (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES transactions (id),
+    account_id UUID NOT NULL REFERENCES accounts (id),
+    amount BIGINT NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX entries_transaction_id_idx ON entries (transaction_id);
+CREATE INDEX entries_account_id_idx ON entries (account_id, created_at);
+
+-- Funds reserved by pending debits. Available balance = balance - active holds.
+DROP TABLE IF EXISTS holds;
+CREATE TABLE holds (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES transactions (id),
+    account_id UUID NOT NULL REFERENCES accounts (id),
+    amount BIGINT NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX holds_active_idx ON holds (account_id) WHERE released_at IS NULL;
+CREATE INDEX holds_transaction_id_idx ON holds (transaction_id);
@@ -0,0 +1,48 @@
+DROP TABLE IF EXISTS scheduled_transfers;
+CREATE TABLE scheduled_transfers (
+    id UUID NOT NULL PRIMARY KEY,
+    customer_id UUID NOT NULL,
+    from_account_id UUID NOT NULL REFERENCES accounts (id),
+    to_account_id UUID NOT NULL REFERENCES accounts (id),
+    amount BIGINT NOT NULL,
+    currency VARCHAR NOT NULL,
+    description VARCHAR NOT NULL DEFAULT '',
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recurrence VARCHAR, -- RRULE, NULL for one-off transfers.
+    on_insufficient_funds VARCHAR NOT NULL,
+    status VARCHAR NOT NULL,
+
+    -- The occurrence that runs next (0 based), when it's due and when to
+    -- actually attempt it (later than due when retrying).
+    next_occurrence INT NOT NULL DEFAULT 0,
+    next_occurrence_at TIMESTAMP WITH TIME ZONE,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    retries INT NOT NULL DEFAULT 0,
+
+    -- Lease held by the scheduler instance that's executing the transfer.
+    lease_owner VARCHAR,
+    lease_expires_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX scheduled_transfers_customer_id_idx ON scheduled_transfers (customer_id);
+CREATE INDEX scheduled_transfers_due_idx ON scheduled_transfers (next_attempt_at) WHERE status = 'active';
+
+-- One row per occurrence, the unique constraint guarantees every occurrence
+-- is executed at most once.
+DROP TABLE IF EXISTS scheduled_transfer_runs;
+CREATE TABLE scheduled_transfer_runs (
+    id UUID NOT NULL PRIMARY KEY,
+    scheduled_transfer_id UUID NOT NULL REFERENCES scheduled_transfers (id),
+    occurrence INT NOT NULL,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR NOT NULL,
+    transaction_id UUID REFERENCES transactions (id),
+    attempts INT NOT NULL,
+    error VARCHAR,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    UNIQUE (scheduled_transfer_id, occurrence)
+);
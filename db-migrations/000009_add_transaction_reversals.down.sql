@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS transactions_reversal_of_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversal_of;
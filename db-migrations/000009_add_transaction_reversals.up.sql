@@ -0,0 +1,4 @@
+-- A reversal (or partial refund) is a new transaction with mirrored entries,
+-- linked to the transaction it reverses.
+ALTER TABLE transactions ADD COLUMN reversal_of UUID REFERENCES transactions (id);
+CREATE INDEX transactions_reversal_of_idx ON transactions (reversal_of) WHERE reversal_of IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS accounts_customer_id_idx;
+DROP TABLE IF EXISTS kyc_tier_limits;
+ALTER TABLE customers DROP COLUMN IF EXISTS kyc_tier;
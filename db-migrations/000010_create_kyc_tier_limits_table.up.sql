@@ -0,0 +1,40 @@
+ALTER TABLE customers ADD COLUMN kyc_tier VARCHAR NOT NULL DEFAULT 'basic';
+
+-- Transaction limits per KYC tier, amounts in minor units. A kind of movement
+-- without a limit is unlimited.
+DROP TABLE IF EXISTS kyc_tier_limits;
+CREATE TABLE kyc_tier_limits (
+    tier VARCHAR NOT NULL,
+    kind VARCHAR NOT NULL, -- deposit, withdrawal, transfer_out.
+    period VARCHAR NOT NULL, -- daily, monthly.
+    currency VARCHAR NOT NULL,
+    amount BIGINT NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (tier, kind, period, currency)
+);
+
+INSERT INTO kyc_tier_limits (tier, kind, period, currency, amount) VALUES
+    ('basic', 'deposit', 'daily', 'EUR', 100000),
+    ('basic', 'deposit', 'monthly', 'EUR', 500000),
+    ('basic', 'withdrawal', 'daily', 'EUR', 50000),
+    ('basic', 'withdrawal', 'monthly', 'EUR', 200000),
+    ('basic', 'transfer_out', 'daily', 'EUR', 50000),
+    ('basic', 'transfer_out', 'monthly', 'EUR', 200000),
+    ('standard', 'deposit', 'daily', 'EUR', 1000000),
+    ('standard', 'deposit', 'monthly', 'EUR', 5000000),
+    ('standard', 'withdrawal', 'daily', 'EUR', 500000),
+    ('standard', 'withdrawal', 'monthly', 'EUR', 2000000),
+    ('standard', 'transfer_out', 'daily', 'EUR', 500000),
+    ('standard', 'transfer_out', 'monthly', 'EUR', 2000000),
+    ('enhanced', 'deposit', 'daily', 'EUR', 10000000),
+    ('enhanced', 'deposit', 'monthly', 'EUR', 50000000),
+    ('enhanced', 'withdrawal', 'daily', 'EUR', 5000000),
+    ('enhanced', 'withdrawal', 'monthly', 'EUR', 25000000),
+    ('enhanced', 'transfer_out', 'daily', 'EUR', 5000000),
+    ('enhanced', 'transfer_out', 'monthly', 'EUR', 25000000);
+
+-- Speeds up summing a customer's movements for limit checks.
+CREATE INDEX accounts_customer_id_idx ON accounts (customer_id);
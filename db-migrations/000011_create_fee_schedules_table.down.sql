@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee;
+DROP TABLE IF EXISTS fee_schedules;
@@ -0,0 +1,27 @@
+-- Fees per KYC tier, kind of transaction and currency, amounts in minor units.
+-- A movement without a schedule is free.
+DROP TABLE IF EXISTS fee_schedules;
+CREATE TABLE fee_schedules (
+    tier VARCHAR NOT NULL,
+    kind VARCHAR NOT NULL, -- Transaction kind: withdrawal, transfer.
+    currency VARCHAR NOT NULL,
+    fixed BIGINT NOT NULL DEFAULT 0,
+    percent_bps BIGINT NOT NULL DEFAULT 0, -- Basis points, 100 = 1%.
+    min_fee BIGINT NOT NULL DEFAULT 0,
+    max_fee BIGINT NOT NULL DEFAULT 0, -- 0 means no cap.
+    free_per_month INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (tier, kind, currency)
+);
+
+INSERT INTO fee_schedules (tier, kind, currency, fixed, percent_bps, min_fee, max_fee, free_per_month) VALUES
+    ('basic', 'withdrawal', 'EUR', 100, 0, 0, 0, 1),
+    ('basic', 'transfer', 'EUR', 0, 50, 10, 500, 5),
+    ('standard', 'withdrawal', 'EUR', 50, 0, 0, 0, 3),
+    ('standard', 'transfer', 'EUR', 0, 25, 5, 250, 20);
+
+-- The fee charged on top of the amount, posted to the fee revenue account.
+ALTER TABLE transactions ADD COLUMN fee BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS transactions_cleared_at_idx;
+DROP TABLE IF EXISTS interest_capitalizations;
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS interest_rates;
+ALTER TABLE accounts DROP COLUMN IF EXISTS product;
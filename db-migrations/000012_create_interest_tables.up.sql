@@ -0,0 +1,50 @@
+ALTER TABLE accounts ADD COLUMN product VARCHAR NOT NULL DEFAULT 'current';
+
+-- Yearly interest rates per account product, a rate applies from its
+-- effective date until the next one.
+DROP TABLE IF EXISTS interest_rates;
+CREATE TABLE interest_rates (
+    product VARCHAR NOT NULL,
+    currency VARCHAR NOT NULL,
+    effective_from DATE NOT NULL,
+    rate_bps BIGINT NOT NULL, -- Basis points, 100 = 1%.
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (product, currency, effective_from)
+);
+
+INSERT INTO interest_rates (product, currency, effective_from, rate_bps) VALUES
+    ('savings', 'EUR', '2024-01-01', 200);
+
+-- Interest accrued per account and day, in millionths of a minor unit. The
+-- primary key makes accruing a day again a no-op.
+DROP TABLE IF EXISTS interest_accruals;
+CREATE TABLE interest_accruals (
+    account_id UUID NOT NULL REFERENCES accounts (id),
+    accrual_date DATE NOT NULL,
+    balance BIGINT NOT NULL, -- End of day cleared balance.
+    rate_bps BIGINT NOT NULL,
+    amount_micros BIGINT NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (account_id, accrual_date)
+);
+
+-- Monthly payouts of accrued interest, one per account and month.
+DROP TABLE IF EXISTS interest_capitalizations;
+CREATE TABLE interest_capitalizations (
+    account_id UUID NOT NULL REFERENCES accounts (id),
+    period VARCHAR NOT NULL, -- e.g. 2024-03.
+    accrued_micros BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    transaction_id UUID REFERENCES transactions (id), -- NULL if nothing to pay out.
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (account_id, period)
+);
+
+-- Speeds up end of day balances.
+CREATE INDEX transactions_cleared_at_idx ON transactions (cleared_at) WHERE status = 'cleared';
@@ -0,0 +1,15 @@
+-- Rendered monthly statements. Statements only cover cleared transactions of
+-- past months, so they never change once generated.
+DROP TABLE IF EXISTS account_statements;
+CREATE TABLE account_statements (
+    account_id UUID NOT NULL REFERENCES accounts (id),
+    period VARCHAR NOT NULL, -- e.g. 2024-03.
+    opening_balance BIGINT NOT NULL,
+    closing_balance BIGINT NOT NULL,
+    csv BYTEA NOT NULL,
+    pdf BYTEA NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    PRIMARY KEY (account_id, period)
+);
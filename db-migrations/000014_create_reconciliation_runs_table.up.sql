@@ -0,0 +1,13 @@
+-- Reports of ledger reconciliations, kept for auditing.
+DROP TABLE IF EXISTS reconciliation_runs;
+CREATE TABLE reconciliation_runs (
+    id UUID NOT NULL PRIMARY KEY,
+    account_id UUID, -- Set if only one account was checked.
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    discrepancies INT NOT NULL,
+    report JSONB NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX reconciliation_runs_started_at_idx ON reconciliation_runs (started_at);
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS audit_customers ON customers;
+DROP TRIGGER IF EXISTS audit_accounts ON accounts;
+DROP TRIGGER IF EXISTS audit_transactions ON transactions;
+DROP TRIGGER IF EXISTS audit_holds ON holds;
+DROP TRIGGER IF EXISTS audit_screening_hits ON screening_hits;
+DROP TRIGGER IF EXISTS audit_scheduled_transfers ON scheduled_transfers;
+DROP TRIGGER IF EXISTS audit_webhook_subscriptions ON webhook_subscriptions;
+DROP TRIGGER IF EXISTS audit_kyc_tier_limits ON kyc_tier_limits;
+DROP TRIGGER IF EXISTS audit_fee_schedules ON fee_schedules;
+DROP TRIGGER IF EXISTS audit_interest_rates ON interest_rates;
+DROP FUNCTION IF EXISTS audit_log_row_change();
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_guard();
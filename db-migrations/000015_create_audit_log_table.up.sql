@@ -0,0 +1,106 @@
+-- Append-only log of every change to the audited tables, filled by triggers
+-- so nothing can be missed. The actor, action and request ID are read from
+-- transaction-local settings (see audit.Set). Records are hash-chained by the
+-- audit log sealer after they are committed: seq, prev_hash and hash are NULL
+-- until then.
+DROP TABLE IF EXISTS audit_log;
+CREATE TABLE audit_log (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+    actor_type VARCHAR NOT NULL, -- api_key, admin, system, unknown.
+    actor_id VARCHAR NOT NULL,
+    action VARCHAR NOT NULL, -- e.g. "POST /api/v1/accounts/transfer", "consumer:kyc".
+    request_id VARCHAR NOT NULL,
+    operation VARCHAR NOT NULL, -- insert, update, delete.
+    entity_type VARCHAR NOT NULL, -- Table name.
+    entity_id VARCHAR NOT NULL, -- Primary key, parts joined with ":".
+    before JSONB,
+    after JSONB,
+
+    seq BIGINT UNIQUE,
+    prev_hash BYTEA,
+    hash BYTEA
+);
+CREATE INDEX audit_log_unsealed_idx ON audit_log (id) WHERE seq IS NULL;
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+
+-- The trigger arguments are the primary key columns of the table.
+CREATE OR REPLACE FUNCTION audit_log_row_change() RETURNS TRIGGER AS $$
+DECLARE
+    before_row JSONB;
+    after_row JSONB;
+    entity_id VARCHAR := '';
+    i INT;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        before_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        after_row := to_jsonb(NEW);
+    END IF;
+    IF before_row = after_row THEN
+        RETURN NULL; -- Nothing changed.
+    END IF;
+
+    FOR i IN 0 .. TG_NARGS - 1 LOOP
+        IF i > 0 THEN
+            entity_id := entity_id || ':';
+        END IF;
+        entity_id := entity_id || COALESCE(after_row, before_row) ->> TG_ARGV[i];
+    END LOOP;
+
+    INSERT INTO audit_log (actor_type, actor_id, action, request_id, operation, entity_type, entity_id, before, after)
+    VALUES (
+        COALESCE(NULLIF(current_setting('bestwallet.actor_type', true), ''), 'unknown'),
+        COALESCE(current_setting('bestwallet.actor_id', true), ''),
+        COALESCE(current_setting('bestwallet.action', true), ''),
+        COALESCE(current_setting('bestwallet.request_id', true), ''),
+        lower(TG_OP),
+        TG_TABLE_NAME,
+        entity_id,
+        before_row,
+        after_row
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_customers AFTER INSERT OR UPDATE OR DELETE ON customers
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_accounts AFTER INSERT OR UPDATE OR DELETE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_transactions AFTER INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_holds AFTER INSERT OR UPDATE OR DELETE ON holds
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_screening_hits AFTER INSERT OR UPDATE OR DELETE ON screening_hits
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_scheduled_transfers AFTER INSERT OR UPDATE OR DELETE ON scheduled_transfers
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_webhook_subscriptions AFTER INSERT OR UPDATE OR DELETE ON webhook_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_kyc_tier_limits AFTER INSERT OR UPDATE OR DELETE ON kyc_tier_limits
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('tier', 'kind', 'period', 'currency');
+CREATE TRIGGER audit_fee_schedules AFTER INSERT OR UPDATE OR DELETE ON fee_schedules
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('tier', 'kind', 'currency');
+CREATE TRIGGER audit_interest_rates AFTER INSERT OR UPDATE OR DELETE ON interest_rates
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('product', 'currency', 'effective_from');
+
+-- The log itself is append-only, the only allowed update is sealing. This
+-- stops mistakes, not a superuser, the hash chain is what catches those.
+CREATE OR REPLACE FUNCTION audit_log_guard() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF OLD.seq IS NULL AND NEW.seq IS NOT NULL
+            AND (to_jsonb(OLD) - 'seq' - 'prev_hash' - 'hash') = (to_jsonb(NEW) - 'seq' - 'prev_hash' - 'hash') THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_guard();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_guard();
@@ -0,0 +1,20 @@
+-- Every KYC status transition of a customer, see package kyc.
+DROP TABLE IF EXISTS customer_kyc_events;
+CREATE TABLE customer_kyc_events (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers (id),
+    from_status VARCHAR, -- NULL when the customer was created.
+    to_status VARCHAR NOT NULL,
+    reason VARCHAR NOT NULL,
+    actor_type VARCHAR NOT NULL, -- See audit.ActorType.
+    actor_id VARCHAR NOT NULL,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX customer_kyc_events_customer_id_idx ON customer_kyc_events (customer_id, id);
+
+-- Existing customers start their timeline at their current status, the
+-- steps before are lost.
+INSERT INTO customer_kyc_events (customer_id, from_status, to_status, reason, actor_type, actor_id, created_at)
+SELECT id, NULL, kyc_status, 'history not recorded before this point', 'system', 'migration', updated_at
+FROM customers;
@@ -0,0 +1,22 @@
+-- Documents customers upload for KYC. The files are encrypted in the blob
+-- store, only metadata lives here.
+DROP TABLE IF EXISTS kyc_documents;
+CREATE TABLE kyc_documents (
+    id UUID NOT NULL PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers (id),
+    kind VARCHAR NOT NULL, -- passport, id_card, driving_licence, proof_of_address.
+    file_name VARCHAR NOT NULL, -- As uploaded, for humans only.
+    content_type VARCHAR NOT NULL, -- Sniffed, not what the client claimed.
+    size BIGINT NOT NULL,
+    sha256 BYTEA NOT NULL, -- Of the plaintext.
+    blob_key VARCHAR NOT NULL,
+    status VARCHAR NOT NULL, -- uploaded, accepted, rejected.
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX kyc_documents_customer_id_idx ON kyc_documents (customer_id, created_at);
+
+CREATE TRIGGER audit_kyc_documents AFTER INSERT OR UPDATE OR DELETE ON kyc_documents
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
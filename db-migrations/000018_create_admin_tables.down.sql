@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS kyt_alerts;
+DROP INDEX IF EXISTS accounts_frozen_idx;
+ALTER TABLE accounts DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE accounts DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS approval_requests;
+DROP TABLE IF EXISTS admin_users;
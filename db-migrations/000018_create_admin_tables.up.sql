@@ -0,0 +1,65 @@
+-- Back office users. Tokens are random, only their SHA-256 is stored.
+DROP TABLE IF EXISTS admin_users;
+CREATE TABLE admin_users (
+    id UUID NOT NULL PRIMARY KEY,
+    email VARCHAR NOT NULL UNIQUE,
+    role VARCHAR NOT NULL, -- viewer, operator, admin.
+    token_hash BYTEA NOT NULL UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+-- Sensitive actions wait here for a second operator (maker-checker). The
+-- payload holds the action's parameters, see domain.ApprovalKind.
+DROP TABLE IF EXISTS approval_requests;
+CREATE TABLE approval_requests (
+    id UUID NOT NULL PRIMARY KEY,
+    kind VARCHAR NOT NULL,
+    target_id VARCHAR NOT NULL, -- The customer, account... it's about.
+    payload JSONB NOT NULL,
+    reason VARCHAR NOT NULL,
+    status VARCHAR NOT NULL, -- pending, approved, rejected.
+    maker_id UUID NOT NULL REFERENCES admin_users (id),
+    checker_id UUID REFERENCES admin_users (id),
+    decision_note VARCHAR,
+    decided_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    CHECK (checker_id <> maker_id)
+);
+CREATE INDEX approval_requests_pending_idx ON approval_requests (created_at) WHERE status = 'pending';
+-- One open request per action and target.
+CREATE UNIQUE INDEX approval_requests_pending_target_idx ON approval_requests (kind, target_id) WHERE status = 'pending';
+
+-- Frozen accounts can't be debited.
+ALTER TABLE accounts ADD COLUMN status VARCHAR NOT NULL DEFAULT 'active';
+ALTER TABLE accounts ADD COLUMN status_reason VARCHAR;
+CREATE INDEX accounts_frozen_idx ON accounts (updated_at) WHERE status = 'frozen';
+
+-- Transactions held back by KYT until an operator looks at them.
+DROP TABLE IF EXISTS kyt_alerts;
+CREATE TABLE kyt_alerts (
+    id UUID NOT NULL PRIMARY KEY,
+    transaction_id UUID NOT NULL UNIQUE REFERENCES transactions (id),
+    rule VARCHAR NOT NULL,
+    detail VARCHAR NOT NULL,
+    status VARCHAR NOT NULL, -- open, cleared, blocked.
+    resolved_by UUID REFERENCES admin_users (id),
+    resolution_note VARCHAR,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+CREATE INDEX kyt_alerts_open_idx ON kyt_alerts (created_at) WHERE status = 'open';
+
+CREATE TRIGGER audit_admin_users AFTER INSERT OR UPDATE OR DELETE ON admin_users
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_approval_requests AFTER INSERT OR UPDATE OR DELETE ON approval_requests
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
+CREATE TRIGGER audit_kyt_alerts AFTER INSERT OR UPDATE OR DELETE ON kyt_alerts
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
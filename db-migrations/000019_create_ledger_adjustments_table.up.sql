@@ -0,0 +1,21 @@
+-- Manual adjustments posted by operators, one per ledger transaction. The
+-- transaction moves money between the customer account and the internal
+-- suspense account, this table keeps why and who.
+DROP TABLE IF EXISTS ledger_adjustments;
+CREATE TABLE ledger_adjustments (
+    transaction_id UUID NOT NULL PRIMARY KEY REFERENCES transactions (id),
+    approval_id UUID NOT NULL UNIQUE REFERENCES approval_requests (id),
+    account_id UUID NOT NULL REFERENCES accounts (id),
+    reason_code VARCHAR NOT NULL, -- goodwill, bank_error, fee_refund, correction.
+    attachment_ref VARCHAR NOT NULL, -- Ticket, scanned letter... kept outside the wallet.
+    maker_id UUID NOT NULL REFERENCES admin_users (id),
+    checker_id UUID NOT NULL REFERENCES admin_users (id),
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+
+    CHECK (checker_id <> maker_id)
+);
+CREATE INDEX ledger_adjustments_account_id_idx ON ledger_adjustments (account_id);
+
+CREATE TRIGGER audit_ledger_adjustments AFTER INSERT OR UPDATE OR DELETE ON ledger_adjustments
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('transaction_id');
@@ -0,0 +1,21 @@
+-- Fails if any customer is encrypted, decrypt them first (the key is not in
+-- the DB).
+ALTER TABLE customers
+    ALTER COLUMN first_name SET NOT NULL,
+    ALTER COLUMN last_name SET NOT NULL,
+    ALTER COLUMN email SET NOT NULL,
+    ALTER COLUMN residence_address SET NOT NULL,
+    ALTER COLUMN birth_date SET NOT NULL;
+
+DROP INDEX IF EXISTS customers_pii_kek_id_idx;
+DROP INDEX IF EXISTS customers_email_bidx_key;
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_pii_check;
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS email_bidx,
+    DROP COLUMN IF EXISTS pii_kek_id,
+    DROP COLUMN IF EXISTS pii_data_key,
+    DROP COLUMN IF EXISTS birth_date_enc,
+    DROP COLUMN IF EXISTS residence_address_enc,
+    DROP COLUMN IF EXISTS email_enc,
+    DROP COLUMN IF EXISTS last_name_enc,
+    DROP COLUMN IF EXISTS first_name_enc;
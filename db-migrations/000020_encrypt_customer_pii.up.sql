@@ -0,0 +1,29 @@
+-- Customer PII is encrypted by the app (see package pii), the plaintext
+-- columns are only kept for rows created before, until the re-encryption job
+-- gets to them.
+ALTER TABLE customers
+    ADD COLUMN first_name_enc BYTEA,
+    ADD COLUMN last_name_enc BYTEA,
+    ADD COLUMN email_enc BYTEA,
+    ADD COLUMN residence_address_enc BYTEA,
+    ADD COLUMN birth_date_enc BYTEA,
+    ADD COLUMN pii_data_key BYTEA, -- Wrapped by the KMS.
+    ADD COLUMN pii_kek_id VARCHAR, -- NULL until encrypted.
+    ADD COLUMN email_bidx BYTEA; -- Blind index, HMAC of the normalized email.
+
+ALTER TABLE customers
+    ALTER COLUMN first_name DROP NOT NULL,
+    ALTER COLUMN last_name DROP NOT NULL,
+    ALTER COLUMN email DROP NOT NULL,
+    ALTER COLUMN residence_address DROP NOT NULL,
+    ALTER COLUMN birth_date DROP NOT NULL;
+
+ALTER TABLE customers ADD CONSTRAINT customers_pii_check CHECK (
+    pii_kek_id IS NULL OR (
+        first_name IS NULL AND last_name IS NULL AND email IS NULL AND residence_address IS NULL AND birth_date IS NULL
+        AND pii_data_key IS NOT NULL AND email_bidx IS NOT NULL
+    )
+);
+
+CREATE UNIQUE INDEX customers_email_bidx_key ON customers (email_bidx);
+CREATE INDEX customers_pii_kek_id_idx ON customers (pii_kek_id);
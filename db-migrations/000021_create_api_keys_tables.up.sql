@@ -0,0 +1,22 @@
+-- HMAC keys client apps sign their requests with, see
+-- middleware.HMACVerifier. The secrets are kept in their own table, which
+-- isn't audited, so they don't end up in the audit log. Revoking a key
+-- deletes its secret.
+DROP TABLE IF EXISTS api_keys;
+CREATE TABLE api_keys (
+    id VARCHAR NOT NULL PRIMARY KEY, -- Sent by clients as BestWallet-Key-ID.
+    name VARCHAR NOT NULL, -- The client app.
+    revoked_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT (NOW() AT TIME ZONE 'UTC')
+);
+
+DROP TABLE IF EXISTS api_key_secrets;
+CREATE TABLE api_key_secrets (
+    key_id VARCHAR NOT NULL PRIMARY KEY REFERENCES api_keys (id),
+    secret BYTEA NOT NULL
+);
+
+CREATE TRIGGER audit_api_keys AFTER INSERT OR UPDATE OR DELETE ON api_keys
+    FOR EACH ROW EXECUTE FUNCTION audit_log_row_change('id');
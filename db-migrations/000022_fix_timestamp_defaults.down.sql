@@ -0,0 +1,105 @@
+ALTER TABLE customers
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE accounts
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE screening_hits
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE notification_outbox
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE webhook_subscriptions
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE transactions
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE entries
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE holds
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE scheduled_transfers
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE scheduled_transfer_runs
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE kyc_tier_limits
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE fee_schedules
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE interest_rates
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE interest_accruals
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE interest_capitalizations
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE account_statements
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE reconciliation_runs
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE customer_kyc_events
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE kyc_documents
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE admin_users
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE approval_requests
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE kyt_alerts
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE ledger_adjustments
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE api_keys
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
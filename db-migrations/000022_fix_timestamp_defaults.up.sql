@@ -0,0 +1,182 @@
+-- NOW() AT TIME ZONE 'UTC' is a TIMESTAMP (without time zone) in UTC, which
+-- postgres turns back into a TIMESTAMP WITH TIME ZONE assuming the session's
+-- time zone. Unless the session was in UTC, the stored time is off by the
+-- session's UTC offset. NOW() already is a TIMESTAMP WITH TIME ZONE. Rows
+-- stored before are left alone, see README.md in this directory.
+--
+-- Every row gets its timestamps, the few that were explicitly inserted as
+-- NULL take whichever of the two is known.
+SELECT
+    set_config('bestwallet.actor_type', 'system', true),
+    set_config('bestwallet.actor_id', 'migration', true),
+    set_config('bestwallet.action', 'migration:000022', true);
+
+UPDATE customers SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE customers
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE accounts SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE accounts
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE screening_hits SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE screening_hits
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE notification_outbox SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE notification_outbox
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE webhook_subscriptions SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE webhook_subscriptions
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE webhook_deliveries SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE transactions SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE transactions
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE entries SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE entries
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE holds SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE holds
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE scheduled_transfers SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE scheduled_transfers
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE scheduled_transfer_runs SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE scheduled_transfer_runs
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE kyc_tier_limits SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE kyc_tier_limits
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE fee_schedules SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE fee_schedules
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE interest_rates SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE interest_rates
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE interest_accruals SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE interest_accruals
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE interest_capitalizations SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE interest_capitalizations
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE account_statements SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE account_statements
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE reconciliation_runs SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE reconciliation_runs
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE customer_kyc_events SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE customer_kyc_events
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE kyc_documents SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE kyc_documents
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE admin_users SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE admin_users
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE approval_requests SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE approval_requests
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE kyt_alerts SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE kyt_alerts
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE ledger_adjustments SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE ledger_adjustments
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE api_keys SET created_at = COALESCE(created_at, updated_at, NOW()), updated_at = COALESCE(updated_at, created_at, NOW())
+WHERE created_at IS NULL OR updated_at IS NULL;
+ALTER TABLE api_keys
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW(),
+    ALTER COLUMN updated_at SET NOT NULL;
@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS customers_set_updated_at ON customers;
+DROP TRIGGER IF EXISTS accounts_set_updated_at ON accounts;
+DROP TRIGGER IF EXISTS screening_hits_set_updated_at ON screening_hits;
+DROP TRIGGER IF EXISTS notification_outbox_set_updated_at ON notification_outbox;
+DROP TRIGGER IF EXISTS webhook_subscriptions_set_updated_at ON webhook_subscriptions;
+DROP TRIGGER IF EXISTS webhook_deliveries_set_updated_at ON webhook_deliveries;
+DROP TRIGGER IF EXISTS transactions_set_updated_at ON transactions;
+DROP TRIGGER IF EXISTS scheduled_transfers_set_updated_at ON scheduled_transfers;
+DROP TRIGGER IF EXISTS kyc_tier_limits_set_updated_at ON kyc_tier_limits;
+DROP TRIGGER IF EXISTS fee_schedules_set_updated_at ON fee_schedules;
+DROP TRIGGER IF EXISTS kyc_documents_set_updated_at ON kyc_documents;
+DROP TRIGGER IF EXISTS admin_users_set_updated_at ON admin_users;
+DROP TRIGGER IF EXISTS approval_requests_set_updated_at ON approval_requests;
+DROP TRIGGER IF EXISTS kyt_alerts_set_updated_at ON kyt_alerts;
+DROP TRIGGER IF EXISTS api_keys_set_updated_at ON api_keys;
+
+DROP FUNCTION IF EXISTS set_updated_at();
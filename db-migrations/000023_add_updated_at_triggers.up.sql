@@ -0,0 +1,42 @@
+-- Keeps updated_at current on every update that changes the row, so
+-- callers can't forget to set it. Runs before the audit triggers, which
+-- record the new value.
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW IS DISTINCT FROM OLD THEN
+        NEW.updated_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER customers_set_updated_at BEFORE UPDATE ON customers
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER accounts_set_updated_at BEFORE UPDATE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER screening_hits_set_updated_at BEFORE UPDATE ON screening_hits
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER notification_outbox_set_updated_at BEFORE UPDATE ON notification_outbox
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER webhook_subscriptions_set_updated_at BEFORE UPDATE ON webhook_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER webhook_deliveries_set_updated_at BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER transactions_set_updated_at BEFORE UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER scheduled_transfers_set_updated_at BEFORE UPDATE ON scheduled_transfers
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER kyc_tier_limits_set_updated_at BEFORE UPDATE ON kyc_tier_limits
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER fee_schedules_set_updated_at BEFORE UPDATE ON fee_schedules
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER kyc_documents_set_updated_at BEFORE UPDATE ON kyc_documents
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER admin_users_set_updated_at BEFORE UPDATE ON admin_users
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER approval_requests_set_updated_at BEFORE UPDATE ON approval_requests
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER kyt_alerts_set_updated_at BEFORE UPDATE ON kyt_alerts
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER api_keys_set_updated_at BEFORE UPDATE ON api_keys
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
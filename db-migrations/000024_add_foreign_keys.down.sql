@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS kyt_alerts_resolved_by_idx;
+DROP INDEX IF EXISTS approval_requests_checker_id_idx;
+DROP INDEX IF EXISTS approval_requests_maker_id_idx;
+DROP INDEX IF EXISTS reconciliation_runs_account_id_idx;
+DROP INDEX IF EXISTS interest_capitalizations_transaction_id_idx;
+DROP INDEX IF EXISTS scheduled_transfer_runs_transaction_id_idx;
+DROP INDEX IF EXISTS scheduled_transfers_to_account_id_idx;
+DROP INDEX IF EXISTS scheduled_transfers_from_account_id_idx;
+DROP INDEX IF EXISTS notification_outbox_customer_id_idx;
+
+ALTER TABLE reconciliation_runs DROP CONSTRAINT IF EXISTS reconciliation_runs_account_id_fkey;
+ALTER TABLE scheduled_transfers DROP CONSTRAINT IF EXISTS scheduled_transfers_customer_id_fkey;
+ALTER TABLE notification_outbox DROP CONSTRAINT IF EXISTS notification_outbox_customer_id_fkey;
+ALTER TABLE screening_hits DROP CONSTRAINT IF EXISTS screening_hits_customer_id_fkey;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_customer_id_fkey;
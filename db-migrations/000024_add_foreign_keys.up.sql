@@ -0,0 +1,25 @@
+-- Foreign keys the tables were created without. They're added NOT VALID,
+-- which enforces them for new rows right away without scanning existing ones,
+-- 000026 validates the existing rows. See README.md in this directory.
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES customers (id) NOT VALID;
+ALTER TABLE screening_hits
+    ADD CONSTRAINT screening_hits_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES customers (id) NOT VALID;
+ALTER TABLE notification_outbox
+    ADD CONSTRAINT notification_outbox_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES customers (id) NOT VALID;
+ALTER TABLE scheduled_transfers
+    ADD CONSTRAINT scheduled_transfers_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES customers (id) NOT VALID;
+ALTER TABLE reconciliation_runs
+    ADD CONSTRAINT reconciliation_runs_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts (id) NOT VALID;
+
+-- Indexes on referencing columns that had none, so looking up the rows of a
+-- customer, account or transaction (and checking the keys) doesn't scan.
+CREATE INDEX notification_outbox_customer_id_idx ON notification_outbox (customer_id);
+CREATE INDEX scheduled_transfers_from_account_id_idx ON scheduled_transfers (from_account_id);
+CREATE INDEX scheduled_transfers_to_account_id_idx ON scheduled_transfers (to_account_id);
+CREATE INDEX scheduled_transfer_runs_transaction_id_idx ON scheduled_transfer_runs (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX interest_capitalizations_transaction_id_idx ON interest_capitalizations (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX reconciliation_runs_account_id_idx ON reconciliation_runs (account_id) WHERE account_id IS NOT NULL;
+CREATE INDEX approval_requests_maker_id_idx ON approval_requests (maker_id);
+CREATE INDEX approval_requests_checker_id_idx ON approval_requests (checker_id) WHERE checker_id IS NOT NULL;
+CREATE INDEX kyt_alerts_resolved_by_idx ON kyt_alerts (resolved_by) WHERE resolved_by IS NOT NULL;
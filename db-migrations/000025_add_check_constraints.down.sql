@@ -0,0 +1,87 @@
+ALTER TABLE ledger_adjustments
+    DROP CONSTRAINT IF EXISTS ledger_adjustments_reason_code_check;
+ALTER TABLE kyt_alerts
+    DROP CONSTRAINT IF EXISTS kyt_alerts_status_check;
+ALTER TABLE approval_requests
+    DROP CONSTRAINT IF EXISTS approval_requests_kind_check,
+    DROP CONSTRAINT IF EXISTS approval_requests_status_check;
+ALTER TABLE admin_users
+    DROP CONSTRAINT IF EXISTS admin_users_role_check;
+ALTER TABLE kyc_documents
+    DROP CONSTRAINT IF EXISTS kyc_documents_kind_check,
+    DROP CONSTRAINT IF EXISTS kyc_documents_status_check,
+    DROP CONSTRAINT IF EXISTS kyc_documents_size_check;
+ALTER TABLE customer_kyc_events
+    DROP CONSTRAINT IF EXISTS customer_kyc_events_from_status_check,
+    DROP CONSTRAINT IF EXISTS customer_kyc_events_to_status_check;
+ALTER TABLE account_statements
+    DROP CONSTRAINT IF EXISTS account_statements_period_check;
+ALTER TABLE interest_capitalizations
+    DROP CONSTRAINT IF EXISTS interest_capitalizations_period_check,
+    DROP CONSTRAINT IF EXISTS interest_capitalizations_amount_check;
+ALTER TABLE interest_accruals
+    DROP CONSTRAINT IF EXISTS interest_accruals_rate_bps_check,
+    DROP CONSTRAINT IF EXISTS interest_accruals_amount_micros_check;
+ALTER TABLE interest_rates
+    DROP CONSTRAINT IF EXISTS interest_rates_product_check,
+    DROP CONSTRAINT IF EXISTS interest_rates_currency_check,
+    DROP CONSTRAINT IF EXISTS interest_rates_rate_bps_check;
+ALTER TABLE fee_schedules
+    DROP CONSTRAINT IF EXISTS fee_schedules_tier_check,
+    DROP CONSTRAINT IF EXISTS fee_schedules_kind_check,
+    DROP CONSTRAINT IF EXISTS fee_schedules_currency_check,
+    DROP CONSTRAINT IF EXISTS fee_schedules_amounts_check,
+    DROP CONSTRAINT IF EXISTS fee_schedules_percent_bps_check,
+    DROP CONSTRAINT IF EXISTS fee_schedules_max_fee_check;
+ALTER TABLE kyc_tier_limits
+    DROP CONSTRAINT IF EXISTS kyc_tier_limits_tier_check,
+    DROP CONSTRAINT IF EXISTS kyc_tier_limits_kind_check,
+    DROP CONSTRAINT IF EXISTS kyc_tier_limits_period_check,
+    DROP CONSTRAINT IF EXISTS kyc_tier_limits_currency_check,
+    DROP CONSTRAINT IF EXISTS kyc_tier_limits_amount_check;
+ALTER TABLE scheduled_transfer_runs
+    DROP CONSTRAINT IF EXISTS scheduled_transfer_runs_status_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfer_runs_occurrence_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfer_runs_attempts_check;
+ALTER TABLE scheduled_transfers
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_status_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_on_insufficient_funds_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_amount_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_currency_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_accounts_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_next_occurrence_check,
+    DROP CONSTRAINT IF EXISTS scheduled_transfers_retries_check;
+ALTER TABLE webhook_deliveries
+    DROP CONSTRAINT IF EXISTS webhook_deliveries_status_check,
+    DROP CONSTRAINT IF EXISTS webhook_deliveries_attempts_check;
+ALTER TABLE notification_outbox
+    DROP CONSTRAINT IF EXISTS notification_outbox_channel_check,
+    DROP CONSTRAINT IF EXISTS notification_outbox_status_check,
+    DROP CONSTRAINT IF EXISTS notification_outbox_attempts_check;
+ALTER TABLE screening_hits
+    DROP CONSTRAINT IF EXISTS screening_hits_entry_kind_check,
+    DROP CONSTRAINT IF EXISTS screening_hits_strength_check,
+    DROP CONSTRAINT IF EXISTS screening_hits_review_status_check,
+    DROP CONSTRAINT IF EXISTS screening_hits_score_check;
+ALTER TABLE holds
+    DROP CONSTRAINT IF EXISTS holds_amount_check;
+ALTER TABLE entries
+    DROP CONSTRAINT IF EXISTS entries_amount_check;
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_kind_check,
+    DROP CONSTRAINT IF EXISTS transactions_status_check,
+    DROP CONSTRAINT IF EXISTS transactions_amount_check,
+    DROP CONSTRAINT IF EXISTS transactions_fee_check,
+    DROP CONSTRAINT IF EXISTS transactions_currency_check,
+    DROP CONSTRAINT IF EXISTS transactions_cleared_at_check,
+    DROP CONSTRAINT IF EXISTS transactions_reversal_of_check;
+ALTER TABLE accounts
+    DROP CONSTRAINT IF EXISTS accounts_kind_check,
+    DROP CONSTRAINT IF EXISTS accounts_status_check,
+    DROP CONSTRAINT IF EXISTS accounts_product_check,
+    DROP CONSTRAINT IF EXISTS accounts_currency_check,
+    DROP CONSTRAINT IF EXISTS accounts_customer_id_check,
+    DROP CONSTRAINT IF EXISTS accounts_balance_check;
+ALTER TABLE customers
+    DROP CONSTRAINT IF EXISTS customers_kyc_status_check,
+    DROP CONSTRAINT IF EXISTS customers_kyc_tier_check;
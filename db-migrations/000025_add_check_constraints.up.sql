@@ -0,0 +1,93 @@
+-- Constraints on values the app already restricts: enums (see the types in
+-- package domain), signs of amounts and currency codes. Amounts stay BIGINT
+-- minor units. Customer accounts can't go negative, internal ones can (see
+-- domain.AccountKind). Added NOT VALID like the foreign keys in 000024, 000026
+-- validates the existing rows.
+
+ALTER TABLE customers
+    ADD CONSTRAINT customers_kyc_status_check CHECK (kyc_status IN ('pending', 'in_progress', 'approved', 'rejected')) NOT VALID,
+    ADD CONSTRAINT customers_kyc_tier_check CHECK (kyc_tier IN ('basic', 'standard', 'enhanced')) NOT VALID;
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_kind_check CHECK (kind IN ('customer', 'internal')) NOT VALID,
+    ADD CONSTRAINT accounts_status_check CHECK (status IN ('active', 'frozen')) NOT VALID,
+    ADD CONSTRAINT accounts_product_check CHECK (product IN ('current', 'savings')) NOT VALID,
+    ADD CONSTRAINT accounts_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID,
+    ADD CONSTRAINT accounts_customer_id_check CHECK ((kind = 'customer') = (customer_id IS NOT NULL)) NOT VALID,
+    ADD CONSTRAINT accounts_balance_check CHECK (kind = 'internal' OR balance >= 0) NOT VALID;
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_kind_check CHECK (kind IN ('transfer', 'deposit', 'withdrawal', 'interest', 'reversal', 'adjustment')) NOT VALID,
+    ADD CONSTRAINT transactions_status_check CHECK (status IN ('pending', 'cleared', 'failed')) NOT VALID,
+    ADD CONSTRAINT transactions_amount_check CHECK (amount > 0) NOT VALID,
+    ADD CONSTRAINT transactions_fee_check CHECK (fee >= 0) NOT VALID,
+    ADD CONSTRAINT transactions_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID,
+    ADD CONSTRAINT transactions_cleared_at_check CHECK ((status = 'cleared') = (cleared_at IS NOT NULL)) NOT VALID,
+    ADD CONSTRAINT transactions_reversal_of_check CHECK (reversal_of <> id) NOT VALID;
+ALTER TABLE entries
+    ADD CONSTRAINT entries_amount_check CHECK (amount <> 0) NOT VALID;
+ALTER TABLE holds
+    ADD CONSTRAINT holds_amount_check CHECK (amount > 0) NOT VALID;
+ALTER TABLE screening_hits
+    ADD CONSTRAINT screening_hits_entry_kind_check CHECK (entry_kind IN ('sanction', 'pep')) NOT VALID,
+    ADD CONSTRAINT screening_hits_strength_check CHECK (strength IN ('strong', 'weak')) NOT VALID,
+    ADD CONSTRAINT screening_hits_review_status_check CHECK (review_status IN ('open', 'confirmed', 'dismissed')) NOT VALID,
+    ADD CONSTRAINT screening_hits_score_check CHECK (score BETWEEN 0 AND 1) NOT VALID;
+ALTER TABLE notification_outbox
+    ADD CONSTRAINT notification_outbox_channel_check CHECK (channel IN ('email', 'sms')) NOT VALID,
+    ADD CONSTRAINT notification_outbox_status_check CHECK (status IN ('pending', 'sent', 'failed')) NOT VALID,
+    ADD CONSTRAINT notification_outbox_attempts_check CHECK (attempts >= 0) NOT VALID;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_status_check CHECK (status IN ('pending', 'succeeded', 'dead')) NOT VALID,
+    ADD CONSTRAINT webhook_deliveries_attempts_check CHECK (attempts >= 0) NOT VALID;
+ALTER TABLE scheduled_transfers
+    ADD CONSTRAINT scheduled_transfers_status_check CHECK (status IN ('active', 'paused', 'cancelled', 'completed')) NOT VALID,
+    ADD CONSTRAINT scheduled_transfers_on_insufficient_funds_check CHECK (on_insufficient_funds IN ('skip', 'retry')) NOT VALID,
+    ADD CONSTRAINT scheduled_transfers_amount_check CHECK (amount > 0) NOT VALID,
+    ADD CONSTRAINT scheduled_transfers_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID,
+    ADD CONSTRAINT scheduled_transfers_accounts_check CHECK (from_account_id <> to_account_id) NOT VALID,
+    ADD CONSTRAINT scheduled_transfers_next_occurrence_check CHECK (next_occurrence >= 0) NOT VALID,
+    ADD CONSTRAINT scheduled_transfers_retries_check CHECK (retries >= 0) NOT VALID;
+ALTER TABLE scheduled_transfer_runs
+    ADD CONSTRAINT scheduled_transfer_runs_status_check CHECK (status IN ('executed', 'skipped', 'failed')) NOT VALID,
+    ADD CONSTRAINT scheduled_transfer_runs_occurrence_check CHECK (occurrence >= 0) NOT VALID,
+    ADD CONSTRAINT scheduled_transfer_runs_attempts_check CHECK (attempts >= 0) NOT VALID;
+ALTER TABLE kyc_tier_limits
+    ADD CONSTRAINT kyc_tier_limits_tier_check CHECK (tier IN ('basic', 'standard', 'enhanced')) NOT VALID,
+    ADD CONSTRAINT kyc_tier_limits_kind_check CHECK (kind IN ('deposit', 'withdrawal', 'transfer_out')) NOT VALID,
+    ADD CONSTRAINT kyc_tier_limits_period_check CHECK (period IN ('daily', 'monthly')) NOT VALID,
+    ADD CONSTRAINT kyc_tier_limits_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID,
+    ADD CONSTRAINT kyc_tier_limits_amount_check CHECK (amount >= 0) NOT VALID;
+ALTER TABLE fee_schedules
+    ADD CONSTRAINT fee_schedules_tier_check CHECK (tier IN ('basic', 'standard', 'enhanced')) NOT VALID,
+    ADD CONSTRAINT fee_schedules_kind_check CHECK (kind IN ('transfer', 'deposit', 'withdrawal', 'interest', 'reversal', 'adjustment')) NOT VALID,
+    ADD CONSTRAINT fee_schedules_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID,
+    ADD CONSTRAINT fee_schedules_amounts_check CHECK (fixed >= 0 AND min_fee >= 0 AND max_fee >= 0 AND free_per_month >= 0) NOT VALID,
+    ADD CONSTRAINT fee_schedules_percent_bps_check CHECK (percent_bps BETWEEN 0 AND 10000) NOT VALID,
+    ADD CONSTRAINT fee_schedules_max_fee_check CHECK (max_fee = 0 OR max_fee >= min_fee) NOT VALID;
+ALTER TABLE interest_rates
+    ADD CONSTRAINT interest_rates_product_check CHECK (product IN ('current', 'savings')) NOT VALID,
+    ADD CONSTRAINT interest_rates_currency_check CHECK (currency ~ '^[A-Z]{3}$') NOT VALID,
+    ADD CONSTRAINT interest_rates_rate_bps_check CHECK (rate_bps >= 0) NOT VALID;
+ALTER TABLE interest_accruals
+    ADD CONSTRAINT interest_accruals_rate_bps_check CHECK (rate_bps >= 0) NOT VALID,
+    ADD CONSTRAINT interest_accruals_amount_micros_check CHECK (amount_micros >= 0) NOT VALID;
+ALTER TABLE interest_capitalizations
+    ADD CONSTRAINT interest_capitalizations_period_check CHECK (period ~ '^[0-9]{4}-[0-9]{2}$') NOT VALID,
+    ADD CONSTRAINT interest_capitalizations_amount_check CHECK (amount >= 0 AND accrued_micros >= 0) NOT VALID;
+ALTER TABLE account_statements
+    ADD CONSTRAINT account_statements_period_check CHECK (period ~ '^[0-9]{4}-[0-9]{2}$') NOT VALID;
+ALTER TABLE customer_kyc_events
+    ADD CONSTRAINT customer_kyc_events_from_status_check CHECK (from_status IN ('pending', 'in_progress', 'approved', 'rejected')) NOT VALID,
+    ADD CONSTRAINT customer_kyc_events_to_status_check CHECK (to_status IN ('pending', 'in_progress', 'approved', 'rejected')) NOT VALID;
+ALTER TABLE kyc_documents
+    ADD CONSTRAINT kyc_documents_kind_check CHECK (kind IN ('passport', 'id_card', 'driving_licence', 'proof_of_address')) NOT VALID,
+    ADD CONSTRAINT kyc_documents_status_check CHECK (status IN ('uploaded', 'accepted', 'rejected')) NOT VALID,
+    ADD CONSTRAINT kyc_documents_size_check CHECK (size >= 0) NOT VALID;
+ALTER TABLE admin_users
+    ADD CONSTRAINT admin_users_role_check CHECK (role IN ('viewer', 'operator', 'admin')) NOT VALID;
+ALTER TABLE approval_requests
+    ADD CONSTRAINT approval_requests_kind_check CHECK (kind IN ('kyc_approve', 'account_unfreeze', 'ledger_adjustment')) NOT VALID,
+    ADD CONSTRAINT approval_requests_status_check CHECK (status IN ('pending', 'approved', 'rejected')) NOT VALID;
+ALTER TABLE kyt_alerts
+    ADD CONSTRAINT kyt_alerts_status_check CHECK (status IN ('open', 'cleared', 'blocked')) NOT VALID;
+ALTER TABLE ledger_adjustments
+    ADD CONSTRAINT ledger_adjustments_reason_code_check CHECK (reason_code IN ('goodwill', 'bank_error', 'fee_refund', 'correction')) NOT VALID;
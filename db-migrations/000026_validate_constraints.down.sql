@@ -0,0 +1 @@
+-- Nothing to undo, validated constraints are dropped by 000025 and 000024.
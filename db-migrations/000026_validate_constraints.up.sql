@@ -0,0 +1,18 @@
+-- Validates the foreign keys and checks added NOT VALID by 000024 and 000025
+-- against the existing rows. Validating doesn't block writes. If a row
+-- violates one, this migration fails (leaving the schema at 000025, so the
+-- server refuses to start) and names the constraint: fix the rows as described
+-- in README.md in this directory and migrate up again.
+DO $$
+DECLARE
+    c RECORD;
+BEGIN
+    FOR c IN
+        SELECT conrelid::regclass AS tbl, conname FROM pg_constraint
+        WHERE NOT convalidated AND conrelid <> 0 AND connamespace = current_schema()::regnamespace
+        ORDER BY conrelid::regclass::text, conname
+    LOOP
+        EXECUTE format('ALTER TABLE %s VALIDATE CONSTRAINT %I', c.tbl, c.conname);
+    END LOOP;
+END;
+$$;
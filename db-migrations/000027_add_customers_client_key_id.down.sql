@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS customers_client_key_id_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS client_key_id;
@@ -0,0 +1,19 @@
+-- The client app (API key) that registered the customer. Only its webhook
+-- subscriptions get events about the customer. No foreign key to api_keys,
+-- for the same reason webhook_subscriptions.client_key_id has none.
+--
+-- Existing customers take it from the audit log. Those registered before the
+-- audit log existed keep NULL, their events go to no subscription.
+SELECT
+    set_config('bestwallet.actor_type', 'system', true),
+    set_config('bestwallet.actor_id', 'migration', true),
+    set_config('bestwallet.action', 'migration:000027', true);
+
+ALTER TABLE customers ADD COLUMN client_key_id VARCHAR;
+
+UPDATE customers c SET client_key_id = l.actor_id
+FROM audit_log l
+WHERE l.entity_type = 'customers' AND l.operation = 'insert' AND l.entity_id = c.id::text
+    AND l.actor_type = 'api_key' AND l.actor_id <> '';
+
+CREATE INDEX customers_client_key_id_idx ON customers (client_key_id);
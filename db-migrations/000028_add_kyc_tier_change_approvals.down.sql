@@ -0,0 +1,4 @@
+-- Tier changes requested before stay, unchecked.
+ALTER TABLE approval_requests DROP CONSTRAINT IF EXISTS approval_requests_kind_check;
+ALTER TABLE approval_requests ADD CONSTRAINT approval_requests_kind_check
+    CHECK (kind IN ('kyc_approve', 'account_unfreeze', 'ledger_adjustment')) NOT VALID;
@@ -0,0 +1,4 @@
+-- Operators move approved customers between KYC tiers with maker-checker.
+ALTER TABLE approval_requests DROP CONSTRAINT IF EXISTS approval_requests_kind_check;
+ALTER TABLE approval_requests ADD CONSTRAINT approval_requests_kind_check
+    CHECK (kind IN ('kyc_approve', 'kyc_tier_change', 'account_unfreeze', 'ledger_adjustment'));
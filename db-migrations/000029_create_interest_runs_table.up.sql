@@ -0,0 +1,19 @@
+-- Progress of the interest job per day, so it resumes from the first day
+-- that isn't done, e.g. after a crash half way through the accounts or a
+-- failed month end capitalization. A day is done once it's accrued and, on
+-- the last day of a month, capitalized.
+--
+-- Days accrued before this table existed have no row. The job redoes them,
+-- up to its catch up window, which only fills in what's missing.
+DROP TABLE IF EXISTS interest_runs;
+CREATE TABLE interest_runs (
+    run_date DATE PRIMARY KEY,
+    accrued_at TIMESTAMP WITH TIME ZONE,
+    capitalized_at TIMESTAMP WITH TIME ZONE, -- Only on the last day of a month.
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER interest_runs_set_updated_at BEFORE UPDATE ON interest_runs
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1 @@
+-- Nothing to undo, cleared messages can't be restored.
@@ -0,0 +1,5 @@
+-- The dispatcher clears the recipient and the rendered message once a
+-- notification is sent or failed for good, do the same for the ones
+-- dispatched before.
+UPDATE notification_outbox SET recipient = '', subject = '', body = ''
+WHERE status <> 'pending' AND (recipient <> '' OR subject <> '' OR body <> '');
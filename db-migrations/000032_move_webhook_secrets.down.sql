@@ -0,0 +1,57 @@
+-- The secrets go back into webhook_subscriptions while snapshots still leave
+-- them out, then the function of 000030 is restored.
+ALTER TABLE webhook_subscriptions ADD COLUMN secret VARCHAR;
+
+UPDATE webhook_subscriptions s SET secret = w.secret
+FROM webhook_subscription_secrets w WHERE w.subscription_id = s.id;
+
+ALTER TABLE webhook_subscriptions ALTER COLUMN secret SET NOT NULL;
+
+DROP TABLE IF EXISTS webhook_subscription_secrets;
+
+CREATE OR REPLACE FUNCTION audit_log_row_change() RETURNS TRIGGER AS $$
+DECLARE
+    before_row JSONB;
+    after_row JSONB;
+    entity_id VARCHAR := '';
+    i INT;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        before_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        after_row := to_jsonb(NEW);
+    END IF;
+    IF before_row = after_row THEN
+        RETURN NULL; -- Nothing changed.
+    END IF;
+
+    -- Plaintext PII must not outlive the customer row, the encrypted columns
+    -- are kept.
+    IF TG_TABLE_NAME = 'customers' THEN
+        before_row := before_row - 'first_name' - 'last_name' - 'email' - 'residence_address' - 'birth_date';
+        after_row := after_row - 'first_name' - 'last_name' - 'email' - 'residence_address' - 'birth_date';
+    END IF;
+
+    FOR i IN 0 .. TG_NARGS - 1 LOOP
+        IF i > 0 THEN
+            entity_id := entity_id || ':';
+        END IF;
+        entity_id := entity_id || COALESCE(after_row, before_row) ->> TG_ARGV[i];
+    END LOOP;
+
+    INSERT INTO audit_log (actor_type, actor_id, action, request_id, operation, entity_type, entity_id, before, after)
+    VALUES (
+        COALESCE(NULLIF(current_setting('bestwallet.actor_type', true), ''), 'unknown'),
+        COALESCE(current_setting('bestwallet.actor_id', true), ''),
+        COALESCE(current_setting('bestwallet.action', true), ''),
+        COALESCE(current_setting('bestwallet.request_id', true), ''),
+        lower(TG_OP),
+        TG_TABLE_NAME,
+        entity_id,
+        before_row,
+        after_row
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
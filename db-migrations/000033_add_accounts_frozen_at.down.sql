@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS accounts_frozen_idx;
+CREATE INDEX accounts_frozen_idx ON accounts (updated_at) WHERE status = 'frozen';
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS frozen_at;
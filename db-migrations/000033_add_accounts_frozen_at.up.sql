@@ -0,0 +1,16 @@
+-- When the account was frozen, for the frozen accounts queue. updated_at
+-- can't tell: frozen accounts are still credited, which bumps it.
+--
+-- Accounts frozen before take the time of their freeze from the audit log,
+-- falling back to updated_at for the ones frozen before the log existed.
+ALTER TABLE accounts ADD COLUMN frozen_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE accounts a SET frozen_at = COALESCE((
+    SELECT max(l.occurred_at) FROM audit_log l
+    WHERE l.entity_type = 'accounts' AND l.entity_id = a.id::text
+        AND l.after ->> 'status' = 'frozen' AND l.before ->> 'status' IS DISTINCT FROM 'frozen'
+), a.updated_at)
+WHERE a.status = 'frozen';
+
+DROP INDEX IF EXISTS accounts_frozen_idx;
+CREATE INDEX accounts_frozen_idx ON accounts (frozen_at, id) WHERE status = 'frozen';
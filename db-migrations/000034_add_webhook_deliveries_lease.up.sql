@@ -0,0 +1,4 @@
+-- Lease held by the instance that's sending the delivery, so the HTTP call
+-- is made outside of any DB transaction. A delivery whose lease expired (the
+-- instance died mid batch) is due again.
+ALTER TABLE webhook_deliveries ADD COLUMN lease_expires_at TIMESTAMP WITH TIME ZONE;
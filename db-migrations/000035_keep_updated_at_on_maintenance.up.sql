@@ -0,0 +1,12 @@
+-- Maintenance jobs that rewrite rows without changing anything for their
+-- owner (e.g. re-encrypting customer PII) set bestwallet.keep_updated_at in
+-- their transaction, so updated_at keeps meaning the last real change (the
+-- KYC review queue orders by it).
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW IS DISTINCT FROM OLD AND current_setting('bestwallet.keep_updated_at', true) IS DISTINCT FROM 'on' THEN
+        NEW.updated_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
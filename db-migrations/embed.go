@@ -0,0 +1,8 @@
+// Package dbmigrations embeds the SQL migrations so the server binary can
+// apply them and check the schema it runs against, see internal/migrate.
+package dbmigrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
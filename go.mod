@@ -8,6 +8,7 @@ require (
 	github.com/jackc/pgx/v5 v5.5.1
 	github.com/redis/go-redis/v9 v9.3.1
 	github.com/vgarvardt/pgx-google-uuid/v5 v5.0.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -41,7 +42,6 @@ require (
 	golang.org/x/net v0.19.0 // indirect
 	golang.org/x/sync v0.5.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/protobuf v1.32.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+)
+
+const (
+	maxAttachmentRefLength = 512
+	maxDescriptionLength   = 140
+)
+
+// AdjustmentPayload is the payload of domain.ApprovalKindAdjustment, the
+// target is the account ID (so there's one pending adjustment per account).
+type AdjustmentPayload struct {
+	AccountID     uuid.UUID                  `json:"account_id"`
+	AccountNumber string                     `json:"account_number"`
+	Direction     domain.AdjustmentDirection `json:"direction"`
+	Amount        int64                      `json:"amount"`
+	Currency      string                     `json:"currency"`
+	ReasonCode    domain.AdjustmentReason    `json:"reason_code"`
+	AttachmentRef string                     `json:"attachment_ref"`
+	Description   string                     `json:"description,omitempty"`
+}
+
+// Validate checks everything but the account, which is resolved by the
+// caller.
+func (p AdjustmentPayload) Validate() error {
+	if p.Direction != domain.AdjustmentDirectionCredit && p.Direction != domain.AdjustmentDirectionDebit {
+		return errors.New("direction must be credit or debit")
+	}
+	if p.Amount <= 0 || p.Amount > domain.MaxAmount {
+		return errors.New("invalid amount")
+	}
+	if !p.ReasonCode.Valid() {
+		return errors.New("invalid reason code")
+	}
+	if p.AttachmentRef == "" {
+		return errors.New("missing attachment reference")
+	}
+	if len(p.AttachmentRef) > maxAttachmentRefLength {
+		return fmt.Errorf("attachment reference longer than %d characters", maxAttachmentRefLength)
+	}
+	if len(p.Description) > maxDescriptionLength {
+		return fmt.Errorf("description longer than %d characters", maxDescriptionLength)
+	}
+	return nil
+}
+
+// TransactionDescription labels the adjustment in the customer's history and
+// statements.
+func (p AdjustmentPayload) TransactionDescription() string {
+	label := "Manual adjustment - " + p.ReasonCode.Label()
+	if p.Description == "" {
+		return label
+	}
+	return label + ": " + p.Description
+}
+
+// PostAdjustment posts an approved adjustment between the customer account
+// and the suspense account, cleared right away (operators did the review).
+// The transaction ID is the approval ID, so an approval posts at most once.
+func PostAdjustment(ctx context.Context, tx pgx.Tx, a Approval, p AdjustmentPayload) (ledger.Transaction, error) {
+	if err := CanPostAdjustment(a, p); err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	suspenseID, err := ledger.InternalAccountID(ctx, tx, domain.InternalAccountSuspense, p.Currency)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	t, _, err := ledger.Post(ctx, tx, AdjustmentTransaction(a, p, suspenseID))
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	sql := `
+		INSERT INTO ledger_adjustments (transaction_id, approval_id, account_id, reason_code, attachment_ref, maker_id, checker_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = tx.Exec(ctx, sql, t.ID, a.ID, p.AccountID, p.ReasonCode, p.AttachmentRef, a.MakerID, *a.CheckerID)
+	return t, err
+}
+
+// CanPostAdjustment checks that the adjustment was approved and is valid.
+func CanPostAdjustment(a Approval, p AdjustmentPayload) error {
+	if a.Status != domain.ApprovalStatusApproved || a.CheckerID == nil {
+		return errors.New("adjustment is not approved")
+	}
+	return p.Validate()
+}
+
+// AdjustmentTransaction is the ledger transaction of an approved adjustment,
+// between the customer account and the suspense account.
+func AdjustmentTransaction(a Approval, p AdjustmentPayload, suspenseID uuid.UUID) ledger.Transaction {
+	amount := p.Amount
+	if p.Direction == domain.AdjustmentDirectionDebit {
+		amount = -amount
+	}
+
+	return ledger.Transaction{
+		ID:             a.ID,
+		Kind:           domain.TransactionKindAdjustment,
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Description:    p.TransactionDescription(),
+		IdempotencyKey: "adjustment:" + a.ID.String(),
+		Legs: []ledger.Leg{
+			{AccountID: p.AccountID, Amount: amount},
+			{AccountID: suspenseID, Amount: -amount},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func TestAdjustmentPayload_Validate(t *testing.T) {
+	valid := AdjustmentPayload{
+		AccountID:     uuid.New(),
+		AccountNumber: "1234567890",
+		Direction:     domain.AdjustmentDirectionCredit,
+		Amount:        1500,
+		Currency:      "EUR",
+		ReasonCode:    domain.AdjustmentReasonGoodwill,
+		AttachmentRef: "TICKET-42",
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(p *AdjustmentPayload)
+		wantErr bool
+	}{
+		{"valid credit", func(p *AdjustmentPayload) {}, false},
+		{"valid debit", func(p *AdjustmentPayload) { p.Direction = domain.AdjustmentDirectionDebit }, false},
+		{"unknown direction", func(p *AdjustmentPayload) { p.Direction = "sideways" }, true},
+		{"zero amount", func(p *AdjustmentPayload) { p.Amount = 0 }, true},
+		{"negative amount", func(p *AdjustmentPayload) { p.Amount = -1 }, true},
+		{"amount too large", func(p *AdjustmentPayload) { p.Amount = domain.MaxAmount + 1 }, true},
+		{"unknown reason", func(p *AdjustmentPayload) { p.ReasonCode = "because" }, true},
+		{"missing attachment", func(p *AdjustmentPayload) { p.AttachmentRef = "" }, true},
+		{"attachment too long", func(p *AdjustmentPayload) { p.AttachmentRef = strings.Repeat("a", 513) }, true},
+		{"description too long", func(p *AdjustmentPayload) { p.Description = strings.Repeat("a", 141) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			p := valid
+			tt.edit(&p)
+
+			// Act.
+			err := p.Validate()
+
+			// Assert.
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAdjustmentPayload_TransactionDescription(t *testing.T) {
+	tests := []struct {
+		reason      domain.AdjustmentReason
+		description string
+		want        string
+	}{
+		{domain.AdjustmentReasonGoodwill, "", "Manual adjustment - Goodwill credit"},
+		{domain.AdjustmentReasonBankError, "Duplicate card payment 12 March", "Manual adjustment - Bank error correction: Duplicate card payment 12 March"},
+	}
+
+	for _, tt := range tests {
+		// Act.
+		got := AdjustmentPayload{ReasonCode: tt.reason, Description: tt.description}.TransactionDescription()
+
+		// Assert.
+		if got != tt.want {
+			t.Fatalf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestAdjustmentTransaction(t *testing.T) {
+	accountID, suspenseID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		direction    domain.AdjustmentDirection
+		wantAccount  int64
+		wantSuspense int64
+	}{
+		{domain.AdjustmentDirectionCredit, 1500, -1500},
+		{domain.AdjustmentDirectionDebit, -1500, 1500},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.direction), func(t *testing.T) {
+			// Arrange.
+			a := Approval{ID: uuid.New()}
+			p := AdjustmentPayload{AccountID: accountID, Direction: tt.direction, Amount: 1500, Currency: "EUR", ReasonCode: domain.AdjustmentReasonGoodwill}
+
+			// Act.
+			got := AdjustmentTransaction(a, p, suspenseID)
+
+			// Assert.
+			if got.ID != a.ID || got.Amount != 1500 || got.Kind != domain.TransactionKindAdjustment {
+				t.Fatalf("expected adjustment %s of 1500, got %+v", a.ID, got)
+			}
+			if len(got.Legs) != 2 || got.Legs[0].AccountID != accountID || got.Legs[0].Amount != tt.wantAccount ||
+				got.Legs[1].AccountID != suspenseID || got.Legs[1].Amount != tt.wantSuspense {
+				t.Fatalf("expected legs %d on the account and %d on suspense, got %+v", tt.wantAccount, tt.wantSuspense, got.Legs)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package admin backs the back office: the operators working the review
+// queues and the maker-checker approvals of sensitive actions.
+//
+// Operators authenticate with a bearer token (see CreateUser), only its
+// SHA-256 is stored. A sensitive action is first requested by one operator
+// (the maker) and takes effect only once a different operator (the checker)
+// approves it, see RequestApproval and Decide.
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+var ErrEmailTaken = errors.New("email already taken")
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Actor is the audit actor of a back office user.
+func Actor(u domain.AdminUser) audit.Actor {
+	return audit.Actor{Type: audit.ActorTypeAdmin, ID: u.ID.String()}
+}
+
+// CreateUser adds a back office user and returns its token. The token is
+// shown only once, it can't be recovered from the DB.
+func CreateUser(ctx context.Context, db DB, email string, role domain.AdminRole) (domain.AdminUser, string, error) {
+	u, token, err := NewUser(email, role)
+	if err != nil {
+		return domain.AdminUser{}, "", err
+	}
+	if err := InsertUser(ctx, db, u, token); err != nil {
+		return domain.AdminUser{}, "", err
+	}
+	return u, token, nil
+}
+
+// NewUser prepares a back office user and its token, see InsertUser.
+func NewUser(email string, role domain.AdminRole) (domain.AdminUser, string, error) {
+	if !role.Valid() {
+		return domain.AdminUser{}, "", errors.New("invalid role")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return domain.AdminUser{}, "", err
+	}
+
+	return domain.AdminUser{ID: uuid.New(), Email: email, Role: role}, hex.EncodeToString(raw), nil
+}
+
+// InsertUser stores a user made by NewUser. Returns ErrEmailTaken if another
+// user has the email.
+func InsertUser(ctx context.Context, db DB, u domain.AdminUser, token string) error {
+	sql := `INSERT INTO admin_users (id, email, role, token_hash) VALUES ($1, $2, $3, $4) ON CONFLICT (email) DO NOTHING`
+
+	res, err := db.Exec(ctx, sql, u.ID, u.Email, u.Role, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// FindUserByToken returns the active user the token was issued to.
+func FindUserByToken(ctx context.Context, db DB, token string) (domain.AdminUser, bool, error) {
+	sql := `SELECT id, email, role FROM admin_users WHERE token_hash = $1 AND active`
+
+	rows, _ := db.Query(ctx, sql, hashToken(token))
+	u, err := pgx.CollectOneRow(rows, func(row pgx.CollectableRow) (domain.AdminUser, error) {
+		var u domain.AdminUser
+		err := row.Scan(&u.ID, &u.Email, &u.Role)
+		return u, err
+	})
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return u, false, nil
+	case err != nil:
+		return u, false, err
+	default:
+		return u, true, nil
+	}
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+var (
+	ErrApprovalNotFound = errors.New("approval request not found")
+	ErrAlreadyRequested = errors.New("an approval request for this is already pending")
+	ErrAlreadyDecided   = errors.New("approval request already decided")
+	ErrSameOperator     = errors.New("the checker must be a different operator than the maker")
+)
+
+// Approval is a sensitive action waiting for (or given) a second operator's
+// decision. Payload holds the action's parameters, its shape depends on Kind.
+type Approval struct {
+	ID           uuid.UUID             `json:"id"`
+	Kind         domain.ApprovalKind   `json:"kind"`
+	TargetID     string                `json:"target_id"`
+	Payload      json.RawMessage       `json:"payload"`
+	Reason       string                `json:"reason"`
+	Status       domain.ApprovalStatus `json:"status"`
+	MakerID      uuid.UUID             `json:"maker_id"`
+	CheckerID    *uuid.UUID            `json:"checker_id,omitempty"`
+	DecisionNote *string               `json:"decision_note,omitempty"`
+	DecidedAt    *time.Time            `json:"decided_at,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+// RequestApproval stores a pending approval request. There can be only one
+// pending request per kind and target.
+func RequestApproval(ctx context.Context, db DB, kind domain.ApprovalKind, targetID string, payload any, reason string, makerID uuid.UUID) (Approval, error) {
+	a, err := NewApproval(kind, targetID, payload, reason, makerID)
+	if err != nil {
+		return Approval{}, err
+	}
+	if err := InsertApproval(ctx, db, a); err != nil {
+		return Approval{}, err
+	}
+	return a, nil
+}
+
+// NewApproval prepares a pending approval request, see InsertApproval.
+func NewApproval(kind domain.ApprovalKind, targetID string, payload any, reason string, makerID uuid.UUID) (Approval, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Approval{}, err
+	}
+	return Approval{
+		ID:        uuid.New(),
+		Kind:      kind,
+		TargetID:  targetID,
+		Payload:   raw,
+		Reason:    reason,
+		Status:    domain.ApprovalStatusPending,
+		MakerID:   makerID,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// InsertApproval stores a request made by NewApproval. Returns
+// ErrAlreadyRequested if the same kind and target is pending already.
+func InsertApproval(ctx context.Context, db DB, a Approval) error {
+	sql := `
+		INSERT INTO approval_requests (id, kind, target_id, payload, reason, status, maker_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := db.Exec(ctx, sql, a.ID, a.Kind, a.TargetID, a.Payload, a.Reason, a.Status, a.MakerID, a.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+		return fmt.Errorf("%w: %s %s", ErrAlreadyRequested, a.Kind, a.TargetID)
+	}
+	return err
+}
+
+// LockApproval returns the approval request, locked until the caller's
+// transaction ends so it's decided only once. Pass a transaction.
+func LockApproval(ctx context.Context, db DB, id uuid.UUID) (Approval, error) {
+	rows, _ := db.Query(ctx, approvalSelect+` WHERE id = $1 FOR UPDATE`, id)
+	a, err := pgx.CollectOneRow(rows, scanApproval)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Approval{}, ErrApprovalNotFound
+	}
+	return a, err
+}
+
+// ListApprovals returns the approval requests in a status, oldest first.
+func ListApprovals(ctx context.Context, db DB, status domain.ApprovalStatus, limit int) ([]Approval, error) {
+	rows, _ := db.Query(ctx, approvalSelect+` WHERE status = $1 ORDER BY created_at, id LIMIT $2`, status, limit)
+	return pgx.CollectRows(rows, scanApproval)
+}
+
+// CanDecide checks that the checker may decide the approval request.
+func CanDecide(a Approval, checkerID uuid.UUID) error {
+	if a.Status != domain.ApprovalStatusPending {
+		return fmt.Errorf("%w: %s", ErrAlreadyDecided, a.Status)
+	}
+	if a.MakerID == checkerID {
+		return ErrSameOperator
+	}
+	return nil
+}
+
+// Decide records the checker's decision on a request locked with
+// LockApproval. Approving doesn't execute the action, the caller does that in
+// the same transaction.
+func Decide(ctx context.Context, db DB, a Approval, checkerID uuid.UUID, approve bool, note string) (Approval, error) {
+	a, err := Decided(a, checkerID, approve, note, time.Now())
+	if err != nil {
+		return a, err
+	}
+
+	sql := `
+		UPDATE approval_requests SET status = $1, checker_id = $2, decision_note = $3, decided_at = $4, updated_at = now()
+		WHERE id = $5`
+
+	_, err = db.Exec(ctx, sql, a.Status, checkerID, note, *a.DecidedAt, a.ID)
+	return a, err
+}
+
+// Decided returns the request with the checker's decision, if they may decide
+// it (see CanDecide).
+func Decided(a Approval, checkerID uuid.UUID, approve bool, note string, now time.Time) (Approval, error) {
+	if err := CanDecide(a, checkerID); err != nil {
+		return a, err
+	}
+
+	a.Status = domain.ApprovalStatusRejected
+	if approve {
+		a.Status = domain.ApprovalStatusApproved
+	}
+	a.CheckerID, a.DecisionNote, a.DecidedAt = &checkerID, &note, &now
+	return a, nil
+}
+
+const approvalSelect = `
+	SELECT id, kind, target_id, payload, reason, status, maker_id, checker_id, decision_note, decided_at, created_at
+	FROM approval_requests`
+
+func scanApproval(row pgx.CollectableRow) (Approval, error) {
+	var a Approval
+	err := row.Scan(&a.ID, &a.Kind, &a.TargetID, &a.Payload, &a.Reason, &a.Status, &a.MakerID, &a.CheckerID, &a.DecisionNote, &a.DecidedAt, &a.CreatedAt)
+	return a, err
+}
+
+// KYCApprovePayload is the payload of domain.ApprovalKindKYCApprove, the
+// target is the customer ID.
+type KYCApprovePayload struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+}
+
+// KYCTierChangePayload is the payload of domain.ApprovalKindKYCTierChange,
+// the target is the customer ID.
+type KYCTierChangePayload struct {
+	CustomerID uuid.UUID      `json:"customer_id"`
+	From       domain.KYCTier `json:"from"`
+	To         domain.KYCTier `json:"to"`
+}
+
+// AccountUnfreezePayload is the payload of domain.ApprovalKindAccountUnfreeze,
+// the target is the account ID.
+type AccountUnfreezePayload struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	AccountNumber string    `json:"account_number"`
+}
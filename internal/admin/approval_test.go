@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func TestCanDecide(t *testing.T) {
+	maker, checker := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		status  domain.ApprovalStatus
+		checker uuid.UUID
+		wantErr error
+	}{
+		{"pending, other operator", domain.ApprovalStatusPending, checker, nil},
+		{"pending, same operator", domain.ApprovalStatusPending, maker, ErrSameOperator},
+		{"approved", domain.ApprovalStatusApproved, checker, ErrAlreadyDecided},
+		{"rejected", domain.ApprovalStatusRejected, checker, ErrAlreadyDecided},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			a := Approval{Status: tt.status, MakerID: maker}
+
+			// Act.
+			err := CanDecide(a, tt.checker)
+
+			// Assert.
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package apikey stores the HMAC keys client apps sign their requests with,
+// see middleware.HMACVerifier.
+//
+// A key is issued to a client app once (its secret is shown only then) and
+// stays valid until it's revoked. Revoking deletes the secret, so it can't be
+// undone, issue a new key instead.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/util"
+)
+
+// SecretSize of issued keys, in bytes.
+const SecretSize = 64
+
+var ErrKeyNotFound = errors.New("api key not found or already revoked")
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Issue creates a key for a client app and returns it with its secret. Pass
+// a transaction, the key and its secret are stored in separate tables.
+func Issue(ctx context.Context, db DB, name string) (Key, []byte, error) {
+	if name == "" {
+		return Key{}, nil, errors.New("missing name")
+	}
+	id, err := newKeyID()
+	if err != nil {
+		return Key{}, nil, err
+	}
+	secret, err := util.NewKeyHMAC(SecretSize)
+	if err != nil {
+		return Key{}, nil, err
+	}
+
+	k := Key{ID: id, Name: name, CreatedAt: time.Now()}
+	if _, err := db.Exec(ctx, `INSERT INTO api_keys (id, name, created_at) VALUES ($1, $2, $3)`, k.ID, k.Name, k.CreatedAt); err != nil {
+		return Key{}, nil, err
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO api_key_secrets (key_id, secret) VALUES ($1, $2)`, k.ID, secret); err != nil {
+		return Key{}, nil, err
+	}
+
+	return k, secret, nil
+}
+
+// Revoke stops accepting requests signed with the key. Pass a transaction.
+func Revoke(ctx context.Context, db DB, id string) error {
+	sql := `UPDATE api_keys SET revoked_at = now(), updated_at = now() WHERE id = $1 AND revoked_at IS NULL`
+
+	res, err := db.Exec(ctx, sql, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return ErrKeyNotFound
+	}
+
+	_, err = db.Exec(ctx, `DELETE FROM api_key_secrets WHERE key_id = $1`, id)
+	return err
+}
+
+// List returns every key, revoked ones included, oldest first.
+func List(ctx context.Context, db DB) ([]Key, error) {
+	sql := `SELECT id, name, created_at, revoked_at FROM api_keys ORDER BY created_at, id`
+
+	rows, _ := db.Query(ctx, sql)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Key, error) {
+		var k Key
+		err := row.Scan(&k.ID, &k.Name, &k.CreatedAt, &k.RevokedAt)
+		return k, err
+	})
+}
+
+// FindSecret returns the secret of a key that isn't revoked, it's a
+// middleware.HMACKeyFetcher once bound to a DB.
+func FindSecret(ctx context.Context, db DB, id string) ([]byte, bool, error) {
+	sql := `
+		SELECT s.secret FROM api_keys k
+		JOIN api_key_secrets s ON s.key_id = k.id
+		WHERE k.id = $1 AND k.revoked_at IS NULL`
+
+	rows, _ := db.Query(ctx, sql, id)
+	secret, err := pgx.CollectOneRow(rows, pgx.RowTo[[]byte])
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	default:
+		return secret, true, nil
+	}
+}
+
+// newKeyID returns a random ID like "bwk_1f3a9c0e5d7b2a46", the prefix makes
+// keys easy to spot in logs and config.
+func newKeyID() (string, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "bwk_" + hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,23 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewKeyID(t *testing.T) {
+	// Act.
+	a, errA := newKeyID()
+	b, errB := newKeyID()
+
+	// Assert.
+	if errA != nil || errB != nil {
+		t.Fatalf("unexpected errors: %v, %v", errA, errB)
+	}
+	if !strings.HasPrefix(a, "bwk_") || len(a) != len("bwk_")+16 {
+		t.Fatalf("unexpected key id %q", a)
+	}
+	if a == b {
+		t.Fatalf("expected different key ids, got %q twice", a)
+	}
+}
@@ -0,0 +1,54 @@
+// Package audit attributes changes to whoever made them and keeps them in a
+// tamper-evident log.
+//
+// Changes to the audited tables are written to the audit_log table by DB
+// triggers, with before/after snapshots of the row, so no code path can
+// forget it. The triggers read the actor, action and request ID from
+// transaction-local settings, see Set. Committed records are hash-chained
+// by Seal, Verify walks the chain and reports deleted or edited records.
+package audit
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type ActorType string
+
+const (
+	ActorTypeAPIKey  ActorType = "api_key" // A client app, ID is the HMAC key ID.
+	ActorTypeAdmin   ActorType = "admin"   // A back office user.
+	ActorTypeSystem  ActorType = "system"  // A job or consumer, ID is its name.
+	ActorTypeUnknown ActorType = "unknown" // Set wasn't called.
+)
+
+type Actor struct {
+	Type ActorType `json:"type"`
+	ID   string    `json:"id"`
+}
+
+// System is the actor for background processing e.g. System("job:accrue_interest").
+func System(name string) Actor {
+	return Actor{Type: ActorTypeSystem, ID: name}
+}
+
+// Execer is satisfied by pgx.Tx.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Set attributes the changes made in the transaction to the actor. The
+// settings are local to the transaction, so call it right after begin, and
+// never outside of a transaction (they would be gone with the statement).
+func Set(ctx context.Context, tx Execer, actor Actor, action, requestID string) error {
+	sql := `
+		SELECT
+			set_config('bestwallet.actor_type', $1, true),
+			set_config('bestwallet.actor_id', $2, true),
+			set_config('bestwallet.action', $3, true),
+			set_config('bestwallet.request_id', $4, true)`
+
+	_, err := tx.Exec(ctx, sql, string(actor.Type), actor.ID, action, requestID)
+	return err
+}
@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a row of audit_log.
+type Record struct {
+	ID         int64     `db:"id"`
+	OccurredAt time.Time `db:"occurred_at"`
+	ActorType  ActorType `db:"actor_type"`
+	ActorID    string    `db:"actor_id"`
+	Action     string    `db:"action"`
+	RequestID  string    `db:"request_id"`
+	Operation  string    `db:"operation"`
+	EntityType string    `db:"entity_type"`
+	EntityID   string    `db:"entity_id"`
+	Before     []byte    `db:"before"` // JSON, nil for inserts.
+	After      []byte    `db:"after"`  // JSON, nil for deletes.
+
+	// Set when sealed.
+	Seq      *int64 `db:"seq"`
+	PrevHash []byte `db:"prev_hash"`
+	Hash     []byte `db:"hash"`
+}
+
+// ComputeHash hashes the record contents, its position in the chain and the
+// hash of the previous record. Fields are length prefixed, so moving bytes
+// from one field to another changes the hash.
+func (r Record) ComputeHash(seq int64, prevHash []byte) []byte {
+	h := sha256.New()
+	field := func(b []byte) {
+		binary.Write(h, binary.BigEndian, uint64(len(b)))
+		h.Write(b)
+	}
+
+	field(prevHash)
+	field([]byte(strconv.FormatInt(seq, 10)))
+	field([]byte(strconv.FormatInt(r.ID, 10)))
+	field([]byte(r.OccurredAt.UTC().Format(time.RFC3339Nano)))
+	field([]byte(r.ActorType))
+	field([]byte(r.ActorID))
+	field([]byte(r.Action))
+	field([]byte(r.RequestID))
+	field([]byte(r.Operation))
+	field([]byte(r.EntityType))
+	field([]byte(r.EntityID))
+	field(r.Before)
+	field(r.After)
+
+	return h.Sum(nil)
+}
+
+// Head is the last sealed record of the chain. Keeping heads somewhere else
+// (e.g. the output of every verification) and passing them to Verify as
+// anchors detects records deleted from the end of the chain too.
+type Head struct {
+	Seq  int64  `json:"seq"`
+	Hash []byte `json:"hash"`
+}
+
+// String formats the head as "<seq>:<hex hash>", see ParseHead.
+func (h Head) String() string {
+	return fmt.Sprintf("%d:%s", h.Seq, hex.EncodeToString(h.Hash))
+}
+
+func ParseHead(s string) (Head, error) {
+	seqRaw, hashRaw, ok := strings.Cut(s, ":")
+	if !ok {
+		return Head{}, fmt.Errorf("malformed head %q, want <seq>:<hex hash>", s)
+	}
+	seq, err := strconv.ParseInt(seqRaw, 10, 64)
+	if err != nil || seq < 1 {
+		return Head{}, fmt.Errorf("malformed head seq %q", seqRaw)
+	}
+	hash, err := hex.DecodeString(hashRaw)
+	if err != nil || len(hash) != sha256.Size {
+		return Head{}, fmt.Errorf("malformed head hash %q", hashRaw)
+	}
+	return Head{Seq: seq, Hash: hash}, nil
+}
+
+type ProblemKind string
+
+const (
+	ProblemKindGap            ProblemKind = "gap"             // Records were deleted.
+	ProblemKindBrokenLink     ProblemKind = "broken_link"     // Previous hash doesn't match the previous record.
+	ProblemKindHashMismatch   ProblemKind = "hash_mismatch"   // Record was edited.
+	ProblemKindAnchorMismatch ProblemKind = "anchor_mismatch" // Chain was cut or rewritten after the anchor.
+)
+
+type Problem struct {
+	Kind   ProblemKind `json:"kind"`
+	Seq    int64       `json:"seq"`
+	Detail string      `json:"detail"`
+}
+
+// Verifier checks sealed records, fed in seq order.
+type Verifier struct {
+	anchor   *Head
+	head     Head
+	checked  int64
+	problems []Problem
+}
+
+// NewVerifier verifies a chain from its first record, anchor is optional.
+func NewVerifier(anchor *Head) *Verifier {
+	return &Verifier{anchor: anchor, problems: []Problem{}}
+}
+
+func (v *Verifier) Add(r Record) {
+	if r.Seq == nil {
+		return // Not sealed yet, nothing to check.
+	}
+	seq := *r.Seq
+	v.checked++
+
+	switch {
+	case seq != v.head.Seq+1:
+		v.problems = append(v.problems, Problem{
+			Kind:   ProblemKindGap,
+			Seq:    seq,
+			Detail: fmt.Sprintf("records %d to %d are missing", v.head.Seq+1, seq-1),
+		})
+	case !bytes.Equal(r.PrevHash, v.head.Hash):
+		v.problems = append(v.problems, Problem{
+			Kind:   ProblemKindBrokenLink,
+			Seq:    seq,
+			Detail: "previous hash doesn't match the previous record",
+		})
+	}
+	if !bytes.Equal(r.ComputeHash(seq, r.PrevHash), r.Hash) {
+		v.problems = append(v.problems, Problem{
+			Kind:   ProblemKindHashMismatch,
+			Seq:    seq,
+			Detail: fmt.Sprintf("%s %s %s was edited", r.Operation, r.EntityType, r.EntityID),
+		})
+	}
+	if v.anchor != nil && seq == v.anchor.Seq && !bytes.Equal(r.Hash, v.anchor.Hash) {
+		v.problems = append(v.problems, Problem{
+			Kind:   ProblemKindAnchorMismatch,
+			Seq:    seq,
+			Detail: "hash differs from the anchor, the chain was rewritten",
+		})
+	}
+
+	v.head = Head{Seq: seq, Hash: r.Hash}
+}
+
+// Finish returns the head of the verified chain, the number of records
+// checked and the problems found.
+func (v *Verifier) Finish() (Head, int64, []Problem) {
+	if v.anchor != nil && v.head.Seq < v.anchor.Seq {
+		v.problems = append(v.problems, Problem{
+			Kind:   ProblemKindAnchorMismatch,
+			Seq:    v.anchor.Seq,
+			Detail: fmt.Sprintf("chain ends at %d, before the anchor, records were deleted", v.head.Seq),
+		})
+	}
+	return v.head, v.checked, v.problems
+}
@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+// sealed chains records like Seal does.
+func sealed(n int) []Record {
+	var head Head
+	records := make([]Record, n)
+	for i := range records {
+		r := Record{
+			ID:         int64(i + 1),
+			OccurredAt: time.Date(2024, 3, 1, 10, 0, i, 0, time.UTC),
+			ActorType:  ActorTypeAPIKey,
+			ActorID:    "key-1",
+			Action:     "POST /api/v1/accounts",
+			RequestID:  "req-1",
+			Operation:  "update",
+			EntityType: "customers",
+			EntityID:   "c1",
+			Before:     []byte(`{"kyc_status": "pending"}`),
+			After:      []byte(`{"kyc_status": "approved"}`),
+		}
+		seq := head.Seq + 1
+		r.Seq = &seq
+		r.PrevHash = head.Hash
+		r.Hash = r.ComputeHash(seq, head.Hash)
+		head = Head{Seq: seq, Hash: r.Hash}
+		records[i] = r
+	}
+	return records
+}
+
+func verify(records []Record, anchor *Head) (Head, int64, []Problem) {
+	v := NewVerifier(anchor)
+	for _, r := range records {
+		v.Add(r)
+	}
+	return v.Finish()
+}
+
+func TestVerifier(t *testing.T) {
+	anchorOf := func(r Record) *Head { return &Head{Seq: *r.Seq, Hash: r.Hash} }
+
+	tests := []struct {
+		name       string
+		tamper     func([]Record) []Record
+		anchor     func([]Record) *Head
+		wantKind   ProblemKind
+		wantSeq    int64
+		wantChecks int64
+	}{
+		{
+			name:       "intact",
+			tamper:     func(rs []Record) []Record { return rs },
+			anchor:     func(rs []Record) *Head { return anchorOf(rs[2]) },
+			wantChecks: 5,
+		},
+		{
+			name: "edited",
+			tamper: func(rs []Record) []Record {
+				rs[2].After = []byte(`{"kyc_status": "rejected"}`)
+				return rs
+			},
+			wantKind:   ProblemKindHashMismatch,
+			wantSeq:    3,
+			wantChecks: 5,
+		},
+		{
+			name: "edited and rehashed",
+			tamper: func(rs []Record) []Record {
+				rs[2].ActorID = "key-2"
+				rs[2].Hash = rs[2].ComputeHash(3, rs[2].PrevHash)
+				return rs
+			},
+			wantKind:   ProblemKindBrokenLink,
+			wantSeq:    4,
+			wantChecks: 5,
+		},
+		{
+			name:       "deleted",
+			tamper:     func(rs []Record) []Record { return append(rs[:1], rs[3:]...) },
+			wantKind:   ProblemKindGap,
+			wantSeq:    4,
+			wantChecks: 3,
+		},
+		{
+			name:       "deleted from the end",
+			tamper:     func(rs []Record) []Record { return rs[:3] },
+			anchor:     func(rs []Record) *Head { return anchorOf(rs[4]) },
+			wantKind:   ProblemKindAnchorMismatch,
+			wantSeq:    5,
+			wantChecks: 3,
+		},
+		{
+			name: "whole chain rewritten",
+			tamper: func(rs []Record) []Record {
+				rewritten := sealed(5)
+				rewritten[0].ActorID = "key-2"
+				var head Head
+				for i := range rewritten {
+					rewritten[i].PrevHash = head.Hash
+					rewritten[i].Hash = rewritten[i].ComputeHash(*rewritten[i].Seq, head.Hash)
+					head = Head{Seq: *rewritten[i].Seq, Hash: rewritten[i].Hash}
+				}
+				return rewritten
+			},
+			anchor:     func(rs []Record) *Head { return anchorOf(rs[1]) },
+			wantKind:   ProblemKindAnchorMismatch,
+			wantSeq:    2,
+			wantChecks: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			records := sealed(5)
+			var anchor *Head
+			if tt.anchor != nil {
+				anchor = tt.anchor(records)
+			}
+			records = tt.tamper(records)
+
+			// Act.
+			head, checked, problems := verify(records, anchor)
+
+			// Assert.
+			if checked != tt.wantChecks {
+				t.Fatalf("expected %d records checked, got %d", tt.wantChecks, checked)
+			}
+			if tt.wantKind == "" {
+				if len(problems) != 0 {
+					t.Fatalf("expected no problems, got %+v", problems)
+				}
+				if head.Seq != 5 || string(head.Hash) != string(records[4].Hash) {
+					t.Fatalf("expected head at the last record, got %s", head)
+				}
+				return
+			}
+			if len(problems) != 1 {
+				t.Fatalf("expected 1 problem, got %+v", problems)
+			}
+			if problems[0].Kind != tt.wantKind || problems[0].Seq != tt.wantSeq {
+				t.Fatalf("expected %s at %d, got %+v", tt.wantKind, tt.wantSeq, problems[0])
+			}
+		})
+	}
+}
+
+func TestParseHead(t *testing.T) {
+	// Arrange.
+	want := Head{Seq: 42, Hash: sealed(1)[0].Hash}
+
+	// Act.
+	got, err := ParseHead(want.String())
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.Seq != want.Seq || string(got.Hash) != string(want.Hash) {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	for _, s := range []string{"", "42", "0:" + want.String()[3:], "42:abc", "x:" + want.String()[3:]} {
+		if _, err := ParseHead(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+const recordColumns = `
+	id, occurred_at, actor_type, actor_id, action, request_id, operation,
+	entity_type, entity_id, before, after, seq, prev_hash, hash`
+
+// Seal chains up to limit committed records that aren't sealed yet, and
+// returns how many it sealed. Only one sealer may run at a time, callers
+// have to serialize it (e.g. with an advisory lock).
+//
+// Records are chained in the order the sealer finds them, not by ID: IDs are
+// taken at insert, and a transaction that started earlier can commit later.
+func Seal(ctx context.Context, db DB, limit int) (int, error) {
+	head, err := dbGetHead(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, _ := db.Query(ctx, `SELECT `+recordColumns+` FROM audit_log WHERE seq IS NULL ORDER BY id LIMIT $1`, limit)
+	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[Record])
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, r := range records {
+		seq := head.Seq + 1
+		hash := r.ComputeHash(seq, head.Hash)
+		batch.Queue(`UPDATE audit_log SET seq = $1, prev_hash = $2, hash = $3 WHERE id = $4 AND seq IS NULL`,
+			seq, head.Hash, hash, r.ID)
+		head = Head{Seq: seq, Hash: hash}
+	}
+	if err := db.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
+
+func dbGetHead(ctx context.Context, db DB) (Head, error) {
+	var head Head
+	err := db.QueryRow(ctx, `SELECT seq, hash FROM audit_log WHERE seq IS NOT NULL ORDER BY seq DESC LIMIT 1`).
+		Scan(&head.Seq, &head.Hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Head{}, nil // Empty chain.
+	}
+	return head, err
+}
+
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Checked    int64     `json:"checked"`
+	Head       Head      `json:"head"`
+	Anchor     *Head     `json:"anchor,omitempty"`
+	Problems   []Problem `json:"problems"`
+
+	// Records waiting for the sealer. Old ones mean the sealer isn't
+	// running, or someone is hiding a change from it.
+	Unsealed         int64      `json:"unsealed"`
+	OldestUnsealedAt *time.Time `json:"oldest_unsealed_at,omitempty"`
+}
+
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify walks the whole chain, see Verifier. Run it in a repeatable read
+// transaction, so the unsealed records match the chain.
+func Verify(ctx context.Context, db DB, anchor *Head) (Report, error) {
+	report := Report{StartedAt: time.Now().UTC(), Anchor: anchor}
+
+	v := NewVerifier(anchor)
+	rows, _ := db.Query(ctx, `SELECT `+recordColumns+` FROM audit_log WHERE seq IS NOT NULL ORDER BY seq`)
+	for rows.Next() {
+		r, err := pgx.RowToStructByName[Record](rows)
+		if err != nil {
+			rows.Close()
+			return Report{}, err
+		}
+		v.Add(r)
+	}
+	if err := rows.Err(); err != nil {
+		return Report{}, err
+	}
+	report.Head, report.Checked, report.Problems = v.Finish()
+
+	if err := db.QueryRow(ctx, `SELECT count(*), min(occurred_at) FROM audit_log WHERE seq IS NULL`).
+		Scan(&report.Unsealed, &report.OldestUnsealedAt); err != nil {
+		return Report{}, err
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
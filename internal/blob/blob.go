@@ -0,0 +1,41 @@
+// Package blob stores opaque binary objects (e.g. KYC documents) by key.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps whole objects in memory on the way in and out, it's meant
+// for objects of a few MB at most.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error) // ErrNotFound if missing.
+	Delete(ctx context.Context, key string) error        // Deleting a missing blob is not an error.
+}
+
+// ValidateKey accepts slash separated paths of letters, digits, '-', '_' and
+// '.', e.g. "kyc/<customer id>/<document id>". Keys end up in file paths and
+// URLs, so anything that could escape the store is rejected.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 512 {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+		for _, r := range part {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			default:
+				return fmt.Errorf("invalid blob key %q", key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// testStore checks the BlobStore contract, every implementation runs it.
+func testStore(t *testing.T, s BlobStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	// Missing.
+	if _, err := s.Get(ctx, "kyc/c1/missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get missing: expected ErrNotFound, got %v", err)
+	}
+
+	// Put and get.
+	want := []byte("%PDF-1.4 some document")
+	if err := s.Put(ctx, "kyc/c1/d1", want); err != nil {
+		t.Fatalf("put: unexpected error: %s", err)
+	}
+	got, err := s.Get(ctx, "kyc/c1/d1")
+	if err != nil {
+		t.Fatalf("get: unexpected error: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("get: expected %q, got %q", want, got)
+	}
+
+	// Overwrite.
+	want = []byte("replaced")
+	if err := s.Put(ctx, "kyc/c1/d1", want); err != nil {
+		t.Fatalf("overwrite: unexpected error: %s", err)
+	}
+	if got, _ := s.Get(ctx, "kyc/c1/d1"); !bytes.Equal(got, want) {
+		t.Fatalf("overwrite: expected %q, got %q", want, got)
+	}
+
+	// Delete, twice.
+	for i := 0; i < 2; i++ {
+		if err := s.Delete(ctx, "kyc/c1/d1"); err != nil {
+			t.Fatalf("delete %d: unexpected error: %s", i, err)
+		}
+	}
+	if _, err := s.Get(ctx, "kyc/c1/d1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get deleted: expected ErrNotFound, got %v", err)
+	}
+
+	// Invalid key.
+	if err := s.Put(ctx, "../etc/passwd", want); err == nil {
+		t.Fatalf("put invalid key: expected error")
+	}
+}
+
+func TestFSStore(t *testing.T) {
+	// Arrange.
+	s, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Act & Assert.
+	testStore(t, s)
+}
+
+func TestValidateKey(t *testing.T) {
+	for key, valid := range map[string]bool{
+		"kyc/0c1f7a1e-5b7e-4c1a-9f0e-8d2b3c4d5e6f/passport.pdf": true,
+		"a":          true,
+		"":           false,
+		"/abs":       false,
+		"a//b":       false,
+		"a/../b":     false,
+		"./a":        false,
+		"a/b/":       false,
+		"a b":        false,
+		"a\\b":       false,
+		"a?x=1":      false,
+		"résumé.pdf": false,
+	} {
+		// Act.
+		err := ValidateKey(key)
+
+		// Assert.
+		if (err == nil) != valid {
+			t.Fatalf("%q: expected valid=%t, got error %v", key, valid, err)
+		}
+	}
+}
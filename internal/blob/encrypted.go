@@ -0,0 +1,123 @@
+package blob
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedVersion is the first byte of every encrypted blob, so the format
+// can change without guessing.
+const encryptedVersion = 1
+
+// EncryptedStore encrypts blobs with AES-256-GCM before handing them to the
+// underlying store. Every blob records the ID of the key it was encrypted
+// with, so keys can be rotated: new blobs use the current key, old keys are
+// kept around for reading.
+//
+// Format: version (1 byte) | key ID length (1 byte) | key ID | nonce | ciphertext.
+// The blob key is authenticated too, a blob copied under another key fails
+// to decrypt.
+type EncryptedStore struct {
+	inner     BlobStore
+	keys      map[string]cipher.AEAD
+	currentID string
+}
+
+// NewEncryptedStore takes 32 byte keys by ID, currentID encrypts new blobs.
+func NewEncryptedStore(inner BlobStore, keys map[string][]byte, currentID string) (*EncryptedStore, error) {
+	s := &EncryptedStore{inner: inner, keys: make(map[string]cipher.AEAD, len(keys)), currentID: currentID}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q: expected 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if s.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := s.keys[currentID]; !ok {
+		return nil, fmt.Errorf("current key %q not found", currentID)
+	}
+	return s, nil
+}
+
+// ParseKeys parses "<id>:<base64 key>,<id>:<base64 key>...", the first key
+// is the current one.
+func ParseKeys(s string) (keys map[string][]byte, currentID string, err error) {
+	keys = make(map[string][]byte)
+	for i, part := range strings.Split(s, ",") {
+		id, raw, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, "", fmt.Errorf("malformed key %d, want <id>:<base64 key>", i)
+		}
+		if keys[id], err = base64.StdEncoding.DecodeString(raw); err != nil {
+			return nil, "", fmt.Errorf("malformed key %q: %w", id, err)
+		}
+		if i == 0 {
+			currentID = id
+		}
+	}
+	return keys, currentID, nil
+}
+
+func (s *EncryptedStore) Put(ctx context.Context, key string, data []byte) error {
+	aead := s.keys[s.currentID]
+
+	out := make([]byte, 0, 2+len(s.currentID)+aead.NonceSize()+len(data)+aead.Overhead())
+	out = append(out, encryptedVersion, byte(len(s.currentID)))
+	out = append(out, s.currentID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	out = append(out, nonce...)
+	out = aead.Seal(out, nonce, data, []byte(key))
+
+	return s.inner.Put(ctx, key, out)
+}
+
+func (s *EncryptedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 2 || data[0] != encryptedVersion {
+		return nil, errors.New("blob isn't encrypted or has an unknown format")
+	}
+	idLen := int(data[1])
+	if len(data) < 2+idLen {
+		return nil, errors.New("truncated blob")
+	}
+	id := string(data[2 : 2+idLen])
+	aead, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("blob encrypted with unknown key %q", id)
+	}
+	data = data[2+idLen:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("truncated blob")
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt blob: %w", err)
+	}
+	return plain, nil
+}
+
+func (s *EncryptedStore) Delete(ctx context.Context, key string) error {
+	return s.inner.Delete(ctx, key)
+}
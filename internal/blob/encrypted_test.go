@@ -0,0 +1,129 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func newTestEncryptedStore(t *testing.T, inner BlobStore, keys map[string][]byte, current string) *EncryptedStore {
+	t.Helper()
+	s, err := NewEncryptedStore(inner, keys, current)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return s
+}
+
+func TestEncryptedStore(t *testing.T) {
+	// Arrange.
+	inner, _ := NewFSStore(t.TempDir())
+	s := newTestEncryptedStore(t, inner, map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+
+	// Act & Assert.
+	testStore(t, s)
+}
+
+func TestEncryptedStore_AtRest(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	inner, _ := NewFSStore(t.TempDir())
+	s := newTestEncryptedStore(t, inner, map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+	plain := []byte("passport number 123456789")
+
+	// Act.
+	if err := s.Put(ctx, "kyc/c1/d1", plain); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Assert.
+	stored, _ := inner.Get(ctx, "kyc/c1/d1")
+	if bytes.Contains(stored, []byte("123456789")) {
+		t.Fatalf("expected the stored blob to be encrypted, got %q", stored)
+	}
+}
+
+func TestEncryptedStore_Rotation(t *testing.T) {
+	// Arrange: a blob written with the old key.
+	ctx := context.Background()
+	inner, _ := NewFSStore(t.TempDir())
+	oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	old := newTestEncryptedStore(t, inner, map[string][]byte{"k1": oldKey}, "k1")
+	if err := old.Put(ctx, "kyc/c1/d1", []byte("old")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Act.
+	rotated := newTestEncryptedStore(t, inner, map[string][]byte{"k1": oldKey, "k2": newKey}, "k2")
+	if err := rotated.Put(ctx, "kyc/c1/d2", []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Assert: both readable, the new one isn't readable with the old key only.
+	for key, want := range map[string]string{"kyc/c1/d1": "old", "kyc/c1/d2": "new"} {
+		got, err := rotated.Get(ctx, key)
+		if err != nil || string(got) != want {
+			t.Fatalf("%s: expected %q, got %q (%v)", key, want, got, err)
+		}
+	}
+	if _, err := old.Get(ctx, "kyc/c1/d2"); err == nil {
+		t.Fatalf("expected error reading with an unknown key")
+	}
+}
+
+func TestEncryptedStore_Tampered(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]func(inner BlobStore){
+		"flipped bit": func(inner BlobStore) {
+			data, _ := inner.Get(ctx, "kyc/c1/d1")
+			data[len(data)-1] ^= 1
+			inner.Put(ctx, "kyc/c1/d1", data)
+		},
+		"moved to another key": func(inner BlobStore) {
+			data, _ := inner.Get(ctx, "kyc/c1/d2")
+			inner.Put(ctx, "kyc/c1/d1", data)
+		},
+		"truncated": func(inner BlobStore) {
+			data, _ := inner.Get(ctx, "kyc/c1/d1")
+			inner.Put(ctx, "kyc/c1/d1", data[:5])
+		},
+		"plaintext": func(inner BlobStore) {
+			inner.Put(ctx, "kyc/c1/d1", []byte("plain"))
+		},
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange.
+			inner, _ := NewFSStore(t.TempDir())
+			s := newTestEncryptedStore(t, inner, map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+			s.Put(ctx, "kyc/c1/d1", []byte("one"))
+			s.Put(ctx, "kyc/c1/d2", []byte("two"))
+			tamper(inner)
+
+			// Act.
+			_, err := s.Get(ctx, "kyc/c1/d1")
+
+			// Assert.
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	// Act.
+	keys, current, err := ParseKeys("k2:" + "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI=" + ", k1:AQ==")
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if current != "k2" || len(keys["k2"]) != 32 || !bytes.Equal(keys["k1"], []byte{1}) {
+		t.Fatalf("unexpected keys %v, current %q", keys, current)
+	}
+	if _, _, err := ParseKeys("k1"); err == nil {
+		t.Fatalf("expected error for a key without ID")
+	}
+}
@@ -0,0 +1,28 @@
+package consumer
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// beginAudited begins a transaction whose changes are attributed to the
+// consumer group, see package audit. The event ID stands in for the request
+// ID, it ties the changes to the event that caused them.
+func beginAudited(ctx context.Context, db *pgxpool.Pool, group string, ev domain.Event) (pgx.Tx, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := audit.Set(ctx, tx, audit.System("consumer:"+group), string(ev.Type), ev.ID.String()); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,117 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+)
+
+func NewClearTransactions(
+	db *pgxpool.Pool,
+	reviewThreshold int64,
+) *ClearTransactions {
+	return &ClearTransactions{
+		db:              db,
+		reviewThreshold: reviewThreshold,
+	}
+}
+
+// ClearTransactions takes pending transactions through KYT and clears them.
+//
+// KYT is a single rule for now: money the customer sends out (transfers,
+// withdrawals) at or above the review threshold raises an alert and stays
+// pending until an operator clears or blocks it in the back office.
+type ClearTransactions struct {
+	db              *pgxpool.Pool
+	reviewThreshold int64
+}
+
+// kytKinds are the transactions initiated by customers, the rest are moved
+// by the wallet itself.
+var kytKinds = map[domain.TransactionKind]bool{
+	domain.TransactionKindTransfer:   true,
+	domain.TransactionKindWithdrawal: true,
+}
+
+func (h *ClearTransactions) Handle(ctx context.Context, ev domain.Event) error {
+	if ev.Type != domain.EventTypeTransactionInitiated {
+		return nil
+	}
+	var payload domain.TransactionInitiatedPayload
+	if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+		return err
+	}
+
+	tx, err := beginAudited(ctx, h.db, "clearing", ev)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	t, err := h.dbLockTransaction(ctx, tx, payload.TransactionID)
+	if err != nil {
+		return err
+	}
+	if t.Status != domain.TransactionStatusPending || t.Alerted {
+		return nil // Already processed, or waiting for an operator.
+	}
+
+	if kytKinds[t.Kind] && t.Amount >= h.reviewThreshold {
+		detail := fmt.Sprintf("%s of %s %s is at or above the review threshold of %s",
+			t.Kind, domain.FormatAmount(t.Amount), t.Currency, domain.FormatAmount(h.reviewThreshold))
+		if err := h.dbInsertAlert(ctx, tx, payload.TransactionID, "large_amount", detail); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	err = ledger.Clear(ctx, tx, payload.TransactionID)
+	if errors.Is(err, ledger.ErrNotPending) {
+		return nil // Already processed.
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type dbClearingTransaction struct {
+	Kind     domain.TransactionKind
+	Status   domain.TransactionStatus
+	Amount   int64
+	Currency string
+	Alerted  bool
+}
+
+// dbLockTransaction locks the transaction so an operator resolving its alert
+// waits for us (and the other way around).
+func (h *ClearTransactions) dbLockTransaction(ctx context.Context, tx pgx.Tx, id uuid.UUID) (dbClearingTransaction, error) {
+	sql := `
+		SELECT t.kind, t.status, t.amount, t.currency, EXISTS (SELECT 1 FROM kyt_alerts WHERE transaction_id = t.id)
+		FROM transactions t WHERE t.id = $1 FOR UPDATE OF t`
+	var res dbClearingTransaction
+
+	err := tx.QueryRow(ctx, sql, id).Scan(&res.Kind, &res.Status, &res.Amount, &res.Currency, &res.Alerted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, fmt.Errorf("%w: %s", ledger.ErrTransactionNotFound, id)
+	}
+	return res, err
+}
+
+func (h *ClearTransactions) dbInsertAlert(ctx context.Context, tx pgx.Tx, transactionID uuid.UUID, rule, detail string) error {
+	sql := `
+		INSERT INTO kyt_alerts (id, transaction_id, rule, detail, status)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.Exec(ctx, sql, uuid.New(), transactionID, rule, detail, domain.KYTAlertStatusOpen)
+	return err
+}
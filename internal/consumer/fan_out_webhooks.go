@@ -0,0 +1,96 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func NewFanOutWebhooks(
+	db *pgxpool.Pool,
+) *FanOutWebhooks {
+	return &FanOutWebhooks{
+		db: db,
+	}
+}
+
+// FanOutWebhooks creates a webhook delivery for every active subscription
+// interested in the event. Deliveries are sent by a background job.
+//
+// Client apps only get events about their own customers, the ones they
+// registered. A transfer between customers of two apps goes to both.
+type FanOutWebhooks struct {
+	db *pgxpool.Pool
+}
+
+func (h *FanOutWebhooks) Handle(ctx context.Context, ev domain.Event) error {
+	customerIDs, err := eventCustomerIDs(ev)
+	if err != nil {
+		return err
+	}
+	if len(customerIDs) == 0 {
+		return nil // Nobody's business.
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	return h.dbInsertDeliveries(ctx, ev, payload, customerIDs)
+}
+
+// eventCustomerIDs returns the customers the event is about.
+func eventCustomerIDs(ev domain.Event) ([]uuid.UUID, error) {
+	switch ev.Type {
+	case domain.EventTypeCustomerCreated:
+		var p domain.CustomerCreatedPayload
+		err := json.Unmarshal(ev.Payload, &p)
+		return []uuid.UUID{p.CustomerID}, err
+	case domain.EventTypeKYCStatusChanged:
+		var p domain.KYCStatusChangedPayload
+		err := json.Unmarshal(ev.Payload, &p)
+		return []uuid.UUID{p.CustomerID}, err
+	case domain.EventTypeKYCDocumentUploaded:
+		var p domain.KYCDocumentUploadedPayload
+		err := json.Unmarshal(ev.Payload, &p)
+		return []uuid.UUID{p.CustomerID}, err
+	case domain.EventTypeAccountOpened:
+		var p domain.AccountOpenedPayload
+		err := json.Unmarshal(ev.Payload, &p)
+		return []uuid.UUID{p.CustomerID}, err
+	case domain.EventTypeTransactionPosted:
+		var p domain.TransactionPostedPayload
+		if err := json.Unmarshal(ev.Payload, &p); err != nil {
+			return nil, err
+		}
+		var ids []uuid.UUID
+		for _, e := range p.Entries {
+			if e.CustomerID != nil && !slices.Contains(ids, *e.CustomerID) {
+				ids = append(ids, *e.CustomerID)
+			}
+		}
+		return ids, nil
+	default:
+		return nil, nil // Not offered to subscribers.
+	}
+}
+
+// dbInsertDeliveries is a single statement, so it's all or nothing and safe to
+// repeat for the same event.
+func (h *FanOutWebhooks) dbInsertDeliveries(ctx context.Context, ev domain.Event, payload []byte, customerIDs []uuid.UUID) error {
+	sql := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status)
+		SELECT gen_random_uuid(), s.id, $1, $2, $3, $4 FROM webhook_subscriptions s
+		WHERE s.active AND $2 = ANY(s.event_types)
+			AND s.client_key_id IN (SELECT client_key_id FROM customers WHERE id = ANY($5))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	_, err := h.db.Exec(ctx, sql, ev.ID, ev.Type, payload, domain.WebhookDeliveryStatusPending, customerIDs)
+	return err
+}
@@ -0,0 +1,78 @@
+package consumer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/outbox"
+)
+
+func TestEventCustomerIDs(t *testing.T) {
+	jane, john := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		typ     domain.EventType
+		payload any
+		want    []uuid.UUID
+	}{
+		{
+			name:    "customer created",
+			typ:     domain.EventTypeCustomerCreated,
+			payload: domain.CustomerCreatedPayload{CustomerID: jane},
+			want:    []uuid.UUID{jane},
+		},
+		{
+			name:    "kyc status changed",
+			typ:     domain.EventTypeKYCStatusChanged,
+			payload: domain.KYCStatusChangedPayload{CustomerID: jane},
+			want:    []uuid.UUID{jane},
+		},
+		{
+			name:    "account opened",
+			typ:     domain.EventTypeAccountOpened,
+			payload: domain.AccountOpenedPayload{AccountID: uuid.New(), CustomerID: jane},
+			want:    []uuid.UUID{jane},
+		},
+		{
+			name: "transfer between customers",
+			typ:  domain.EventTypeTransactionPosted,
+			payload: domain.TransactionPostedPayload{Entries: []domain.PostedEntry{
+				{AccountID: uuid.New(), CustomerID: &jane, Amount: -100},
+				{AccountID: uuid.New(), CustomerID: &jane, Amount: -1}, // Fee.
+				{AccountID: uuid.New(), CustomerID: &john, Amount: 100},
+				{AccountID: uuid.New(), Amount: 1},
+			}},
+			want: []uuid.UUID{jane, john},
+		},
+		{
+			name:    "not offered to subscribers",
+			typ:     domain.EventTypeTransactionInitiated,
+			payload: domain.TransactionInitiatedPayload{TransactionID: uuid.New()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			ev, err := outbox.NewEvent(tt.typ, uuid.New(), tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act.
+			got, err := eventCustomerIDs(ev)
+
+			// Assert.
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
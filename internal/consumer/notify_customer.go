@@ -0,0 +1,76 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/notify"
+)
+
+func NewNotifyCustomer(
+	db *pgxpool.Pool,
+	notifications *notify.Outbox,
+) *NotifyCustomer {
+	return &NotifyCustomer{
+		db:            db,
+		notifications: notifications,
+	}
+}
+
+// NotifyCustomer turns domain events into customer notifications.
+type NotifyCustomer struct {
+	db            *pgxpool.Pool
+	notifications *notify.Outbox
+}
+
+func (h *NotifyCustomer) Handle(ctx context.Context, ev domain.Event) error {
+	switch ev.Type {
+	case domain.EventTypeKYCStatusChanged:
+		var payload domain.KYCStatusChangedPayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return err
+		}
+		switch payload.NewStatus {
+		case domain.KYCStatusApproved:
+			return h.notifications.EnqueueForCustomer(ctx, h.db, payload.CustomerID, notify.TemplateKYCApproved, nil)
+		case domain.KYCStatusRejected:
+			return h.notifications.EnqueueForCustomer(ctx, h.db, payload.CustomerID, notify.TemplateKYCRejected, nil)
+		}
+
+	case domain.EventTypeAccountOpened:
+		var payload domain.AccountOpenedPayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return err
+		}
+		return h.notifications.EnqueueForCustomer(ctx, h.db, payload.CustomerID, notify.TemplateAccountOpened, map[string]any{
+			"AccountNumber": payload.Number,
+		})
+
+	case domain.EventTypeTransactionPosted:
+		var payload domain.TransactionPostedPayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return err
+		}
+		for _, e := range payload.Entries {
+			if e.CustomerID == nil {
+				continue // Internal account.
+			}
+			tmpl, amount := notify.TemplateMoneyReceived, e.Amount
+			if e.Amount < 0 {
+				tmpl, amount = notify.TemplateMoneySent, -e.Amount
+			}
+			if err := h.notifications.EnqueueForCustomer(ctx, h.db, *e.CustomerID, tmpl, map[string]any{
+				"AccountNumber": e.AccountNumber,
+				"Amount":        domain.FormatAmount(amount),
+				"Currency":      payload.Currency,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
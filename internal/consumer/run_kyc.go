@@ -0,0 +1,200 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+func NewRunKYC(
+	db *pgxpool.Pool,
+	screener *screening.Screener,
+	cipher *pii.Cipher,
+	reviewDelay time.Duration,
+) *RunKYC {
+	return &RunKYC{
+		db:          db,
+		screener:    screener,
+		cipher:      cipher,
+		reviewDelay: reviewDelay,
+	}
+}
+
+// RunKYC takes newly created customers through the KYC process. It runs
+// again on every uploaded document, approval waits for the required ones.
+//
+// Every step checks the current KYC status first, so a redelivered event
+// resumes where the previous attempt left off.
+type RunKYC struct {
+	db          *pgxpool.Pool
+	screener    *screening.Screener
+	cipher      *pii.Cipher
+	reviewDelay time.Duration // How long reviewing the documents takes.
+}
+
+func (h *RunKYC) Handle(ctx context.Context, ev domain.Event) error {
+	var id uuid.UUID
+	switch ev.Type {
+	case domain.EventTypeCustomerCreated:
+		var payload domain.CustomerCreatedPayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return err
+		}
+		id = payload.CustomerID
+	case domain.EventTypeKYCDocumentUploaded:
+		var payload domain.KYCDocumentUploadedPayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return err
+		}
+		id = payload.CustomerID
+	default:
+		return nil
+	}
+
+	customer, found, err := h.dbGetCustomer(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to read customer: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("customer %s not found", id)
+	}
+
+	if customer.KYCStatus == domain.KYCStatusPending {
+		if err := h.updateKYCStatus(ctx, ev, id, domain.KYCStatusPending, domain.KYCStatusInProgress, "screening started"); err != nil {
+			return fmt.Errorf("failed to set KYC status in_progress: %w", err)
+		}
+		customer.KYCStatus = domain.KYCStatusInProgress
+	}
+	if customer.KYCStatus != domain.KYCStatusInProgress {
+		return nil // Already decided.
+	}
+
+	// Sanctions and PEP screening.
+	if !h.screener.Loaded() {
+		log.Println("WARNING: no watchlists loaded, screening customer", id, "against nothing")
+	}
+	hits := h.screener.Screen(screening.Subject{
+		Name:      customer.FirstName + " " + customer.LastName,
+		BirthDate: customer.BirthDate,
+	})
+	if err := h.saveHits(ctx, ev, id, hits); err != nil {
+		return fmt.Errorf("failed to save screening hits: %w", err)
+	}
+	if screening.HasStrongHit(hits) {
+		reason := "strong screening hit on " + strongHitLists(hits)
+		if err := h.updateKYCStatus(ctx, ev, id, domain.KYCStatusInProgress, domain.KYCStatusRejected, reason); err != nil {
+			return fmt.Errorf("failed to set KYC status rejected: %w", err)
+		}
+		return nil
+	}
+	if len(hits) > 0 {
+		// Weak hits stay in_progress until a human reviews them.
+		log.Println("customer", id, "has screening hits, waiting for manual review")
+		return nil
+	}
+
+	missing, err := kyc.MissingDocuments(ctx, h.db, id)
+	if err != nil {
+		return fmt.Errorf("failed to read documents: %w", err)
+	}
+	if len(missing) > 0 {
+		// Runs again when the next document is uploaded.
+		log.Println("customer", id, "is missing KYC documents:", strings.Join(missing, ", "))
+		return nil
+	}
+
+	// Simulate reviewing the documents.
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(h.reviewDelay):
+	}
+
+	if err := h.updateKYCStatus(ctx, ev, id, domain.KYCStatusInProgress, domain.KYCStatusApproved, "screening and documents passed"); err != nil {
+		return fmt.Errorf("failed to set KYC status approved: %w", err)
+	}
+
+	return nil
+}
+
+// updateKYCStatus transitions the status, see kyc.Transition.
+func (h *RunKYC) updateKYCStatus(ctx context.Context, ev domain.Event, id uuid.UUID, oldStatus, newStatus domain.KYCStatus, reason string) error {
+	tx, err := beginAudited(ctx, h.db, "kyc", ev)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := kyc.Transition(ctx, tx, id, oldStatus, newStatus, reason, audit.System("consumer:kyc")); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func strongHitLists(hits []screening.Hit) string {
+	var lists []string
+	for _, h := range hits {
+		if h.Strength == screening.HitStrengthStrong && !slices.Contains(lists, h.ListName) {
+			lists = append(lists, h.ListName)
+		}
+	}
+	return strings.Join(lists, ", ")
+}
+
+func (h *RunKYC) saveHits(ctx context.Context, ev domain.Event, id uuid.UUID, hits []screening.Hit) error {
+	tx, err := beginAudited(ctx, h.db, "kyc", ev)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := screening.SaveHits(ctx, tx, id, hits); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type dbGetCustomerRow struct {
+	FirstName string
+	LastName  string
+	BirthDate time.Time
+	KYCStatus domain.KYCStatus
+}
+
+func (h *RunKYC) dbGetCustomer(ctx context.Context, id uuid.UUID) (dbGetCustomerRow, bool, error) {
+	sql := `SELECT kyc_status, ` + pii.Columns("") + ` FROM customers WHERE id = $1`
+	var res dbGetCustomerRow
+	var row pii.Row
+
+	err := h.db.QueryRow(ctx, sql, id).Scan(append([]any{&res.KYCStatus}, row.ScanTargets()...)...)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return res, false, nil
+	case err != nil:
+		return res, false, err
+	}
+
+	p, err := h.cipher.OpenRow(ctx, id, row)
+	if err != nil {
+		return res, false, err
+	}
+	res.FirstName, res.LastName, res.BirthDate = p.FirstName, p.LastName, p.BirthDate
+	return res, true, nil
+}
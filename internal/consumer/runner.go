@@ -0,0 +1,216 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+// Handler processes a single event. Handlers receive every event on the
+// stream and ignore the types they're not interested in. Returning an error
+// leaves the event unacknowledged, so it's redelivered later.
+type Handler interface {
+	Handle(ctx context.Context, ev domain.Event) error
+}
+
+type RunnerConfig struct {
+	Stream      string
+	Group       string // Every group gets every event once.
+	Consumer    string // Unique per process e.g. hostname.
+	Concurrency int    // Events processed in parallel by this process.
+
+	// ClaimIdle is how long an event can stay unacknowledged before another
+	// consumer takes it over. Must be longer than the slowest handler run.
+	ClaimIdle time.Duration
+	// MaxAttempts before the event is moved to the group's dead letter stream.
+	MaxAttempts int
+}
+
+const (
+	readCount   = 10
+	readBlock   = 5 * time.Second
+	dedupeTTL   = 7 * 24 * time.Hour
+	attemptsTTL = 7 * 24 * time.Hour
+)
+
+func NewRunner(
+	redis *redis.Client,
+	conf RunnerConfig,
+	handler Handler,
+) *Runner {
+	return &Runner{
+		redis:   redis,
+		conf:    conf,
+		handler: handler,
+	}
+}
+
+// Runner feeds events from a redis stream consumer group to a handler, with
+// at-least-once delivery:
+//   - Events are acknowledged only after the handler succeeds.
+//   - Unacknowledged events (failed, or the consumer crashed) are claimed
+//     again after ClaimIdle and retried, up to MaxAttempts.
+//   - Events are deduped by event ID, since the outbox relay can publish the
+//     same event more than once.
+type Runner struct {
+	redis   *redis.Client
+	conf    RunnerConfig
+	handler Handler
+}
+
+func (r *Runner) Run(ctx context.Context) error {
+	err := r.redis.XGroupCreateMkStream(ctx, r.conf.Stream, r.conf.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", r.conf.Group, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(1, r.conf.Concurrency); i++ {
+		consumer := fmt.Sprintf("%s-%d", r.conf.Consumer, i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				// Keep the worker alive if the handler panics, the event
+				// stays unacknowledged and gets retried.
+				if util.Recover(func() { r.work(ctx, consumer) }) {
+					time.Sleep(time.Second)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (r *Runner) work(ctx context.Context, consumer string) {
+	for ctx.Err() == nil {
+		// Take over events other consumers gave up on (or crashed with).
+		claimed, _, err := r.redis.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   r.conf.Stream,
+			Group:    r.conf.Group,
+			Consumer: consumer,
+			MinIdle:  r.conf.ClaimIdle,
+			Start:    "0-0",
+			Count:    readCount,
+		}).Result()
+		if err != nil && ctx.Err() == nil {
+			log.Printf("consumer %s: failed to claim events: %s", r.conf.Group, err)
+		}
+		for _, msg := range claimed {
+			r.process(ctx, msg)
+		}
+
+		streams, err := r.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.conf.Group,
+			Consumer: consumer,
+			Streams:  []string{r.conf.Stream, ">"},
+			Count:    readCount,
+			Block:    readBlock,
+		}).Result()
+		switch {
+		case errors.Is(err, redis.Nil): // Nothing new.
+			continue
+		case err != nil:
+			if ctx.Err() == nil {
+				log.Printf("consumer %s: failed to read events: %s", r.conf.Group, err)
+				time.Sleep(readBlock)
+			}
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				r.process(ctx, msg)
+			}
+		}
+	}
+}
+
+func (r *Runner) process(ctx context.Context, msg redis.XMessage) {
+	raw, _ := msg.Values["event"].(string)
+	var ev domain.Event
+	if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+		r.deadLetter(ctx, msg, fmt.Errorf("malformed event: %w", err))
+		return
+	}
+
+	doneKey := doneKey(r.conf.Stream, r.conf.Group, ev.ID)
+	done, err := r.redis.Exists(ctx, doneKey).Result()
+	if err != nil {
+		log.Printf("consumer %s: failed to check event %s: %s", r.conf.Group, ev.ID, err)
+		return
+	}
+	if done > 0 {
+		r.ack(ctx, msg)
+		return
+	}
+
+	attemptsKey := fmt.Sprintf("%s:%s:attempts:%s", r.conf.Stream, r.conf.Group, ev.ID)
+	attempts, err := r.redis.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		log.Printf("consumer %s: failed to count attempts for event %s: %s", r.conf.Group, ev.ID, err)
+		return
+	}
+	r.redis.Expire(ctx, attemptsKey, attemptsTTL)
+
+	if err := r.handler.Handle(ctx, ev); err != nil {
+		log.Printf("consumer %s: failed to handle event %s (attempt %d): %s", r.conf.Group, ev.ID, attempts, err)
+		if r.conf.MaxAttempts > 0 && attempts >= int64(r.conf.MaxAttempts) {
+			r.deadLetter(ctx, msg, err)
+		}
+		return
+	}
+
+	if err := r.redis.Set(ctx, doneKey, 1, dedupeTTL).Err(); err != nil {
+		log.Printf("consumer %s: failed to mark event %s done: %s", r.conf.Group, ev.ID, err)
+	}
+	r.redis.Del(ctx, attemptsKey)
+	r.ack(ctx, msg)
+}
+
+// deadLetter moves the event to <stream>:dead:<group> for a human to look at
+// and replay, so it doesn't block or get retried forever.
+func (r *Runner) deadLetter(ctx context.Context, msg redis.XMessage, reason error) {
+	log.Printf("consumer %s: dead lettering message %s: %s", r.conf.Group, msg.ID, reason)
+
+	values := map[string]any{"message_id": msg.ID, "error": reason.Error()}
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	if err := r.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: fmt.Sprintf("%s:dead:%s", r.conf.Stream, r.conf.Group),
+		Values: values,
+	}).Err(); err != nil {
+		log.Printf("consumer %s: failed to dead letter message %s: %s", r.conf.Group, msg.ID, err)
+		return
+	}
+	r.ack(ctx, msg)
+}
+
+func (r *Runner) ack(ctx context.Context, msg redis.XMessage) {
+	if err := r.redis.XAck(ctx, r.conf.Stream, r.conf.Group, msg.ID).Err(); err != nil {
+		log.Printf("consumer %s: failed to ack message %s: %s", r.conf.Group, msg.ID, err)
+	}
+}
+
+// Forget makes the group process the event again the next time it reads it,
+// instead of skipping it as a duplicate. Used to replay events.
+func Forget(ctx context.Context, rdb *redis.Client, stream, group string, eventID uuid.UUID) error {
+	return rdb.Del(ctx, doneKey(stream, group, eventID)).Err()
+}
+
+func doneKey(stream, group string, eventID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s:done:%s", stream, group, eventID)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+)
+
+// AdminRole is what a back office user can do. Roles are ordered, every role
+// can do what the ones before it can.
+type AdminRole string
+
+const (
+	AdminRoleViewer   AdminRole = "viewer"   // Read the queues.
+	AdminRoleOperator AdminRole = "operator" // Work the queues, make and check approvals.
+	AdminRoleAdmin    AdminRole = "admin"    // Manage back office users.
+)
+
+var adminRoleRanks = map[AdminRole]int{
+	AdminRoleViewer:   1,
+	AdminRoleOperator: 2,
+	AdminRoleAdmin:    3,
+}
+
+func (r AdminRole) Valid() bool {
+	return adminRoleRanks[r] > 0
+}
+
+// Includes reports whether the role can do what other can.
+func (r AdminRole) Includes(other AdminRole) bool {
+	return r.Valid() && adminRoleRanks[r] >= adminRoleRanks[other]
+}
+
+type AdminUser struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Role  AdminRole `json:"role"`
+}
+
+// ApprovalKind is a sensitive action that needs a second operator (maker-
+// checker) before it takes effect.
+type ApprovalKind string
+
+const (
+	ApprovalKindKYCApprove      ApprovalKind = "kyc_approve"
+	ApprovalKindKYCTierChange   ApprovalKind = "kyc_tier_change"
+	ApprovalKindAccountUnfreeze ApprovalKind = "account_unfreeze"
+	ApprovalKindAdjustment      ApprovalKind = "ledger_adjustment"
+)
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved" // And executed, in the same DB transaction.
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+)
+
+type KYTAlertStatus string
+
+const (
+	KYTAlertStatusOpen    KYTAlertStatus = "open"    // The transaction waits in pending.
+	KYTAlertStatusCleared KYTAlertStatus = "cleared" // False positive, the transaction was cleared.
+	KYTAlertStatusBlocked KYTAlertStatus = "blocked" // The transaction was failed.
+)
+
+// AdjustmentReason is why an operator corrects a balance by hand.
+type AdjustmentReason string
+
+const (
+	AdjustmentReasonGoodwill   AdjustmentReason = "goodwill"   // Compensation, e.g. for an outage.
+	AdjustmentReasonBankError  AdjustmentReason = "bank_error" // The bank booked something wrong.
+	AdjustmentReasonFeeRefund  AdjustmentReason = "fee_refund" // A fee that shouldn't have been charged.
+	AdjustmentReasonCorrection AdjustmentReason = "correction" // Anything else, explained in the description.
+)
+
+var adjustmentReasonLabels = map[AdjustmentReason]string{
+	AdjustmentReasonGoodwill:   "Goodwill credit",
+	AdjustmentReasonBankError:  "Bank error correction",
+	AdjustmentReasonFeeRefund:  "Fee refund",
+	AdjustmentReasonCorrection: "Balance correction",
+}
+
+func (r AdjustmentReason) Valid() bool {
+	_, ok := adjustmentReasonLabels[r]
+	return ok
+}
+
+// Label is what the customer sees in their history and statements.
+func (r AdjustmentReason) Label() string {
+	return adjustmentReasonLabels[r]
+}
+
+type AdjustmentDirection string
+
+const (
+	AdjustmentDirectionCredit AdjustmentDirection = "credit" // From suspense to the customer.
+	AdjustmentDirectionDebit  AdjustmentDirection = "debit"  // From the customer to suspense.
+)
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestAdminRole_Includes(t *testing.T) {
+	tests := []struct {
+		role, other AdminRole
+		want        bool
+	}{
+		{AdminRoleAdmin, AdminRoleOperator, true},
+		{AdminRoleAdmin, AdminRoleViewer, true},
+		{AdminRoleOperator, AdminRoleOperator, true},
+		{AdminRoleOperator, AdminRoleAdmin, false},
+		{AdminRoleViewer, AdminRoleOperator, false},
+		{"", AdminRoleViewer, false},
+		{"root", AdminRoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		// Act.
+		got := tt.role.Includes(tt.other)
+
+		// Assert.
+		if got != tt.want {
+			t.Fatalf("%q includes %q: expected %t, got %t", tt.role, tt.other, tt.want, got)
+		}
+	}
+}
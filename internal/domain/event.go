@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventTypeCustomerCreated      EventType = "customer_created"
+	EventTypeKYCStatusChanged     EventType = "kyc_status_changed"
+	EventTypeKYCDocumentUploaded  EventType = "kyc_document_uploaded"
+	EventTypeAccountOpened        EventType = "account_opened"
+	EventTypeTransactionInitiated EventType = "transaction_initiated"
+	EventTypeTransactionPosted    EventType = "transaction_posted"
+)
+
+// Event is a fact about a state change that already happened. Events are
+// written to the outbox in the same DB transaction as the state change and
+// published to subscribers afterwards.
+type Event struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        EventType       `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"` // The customer, account... the event is about.
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Payload     json.RawMessage `json:"payload"` // One of the *Payload types below, depending on Type.
+}
+
+type CustomerCreatedPayload struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+}
+
+type KYCStatusChangedPayload struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+	OldStatus  KYCStatus `json:"old_status"`
+	NewStatus  KYCStatus `json:"new_status"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type KYCDocumentUploadedPayload struct {
+	CustomerID uuid.UUID       `json:"customer_id"`
+	DocumentID uuid.UUID       `json:"document_id"`
+	Kind       KYCDocumentKind `json:"kind"`
+}
+
+type AccountOpenedPayload struct {
+	AccountID  uuid.UUID `json:"account_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	Number     string    `json:"number"`
+}
+
+type TransactionInitiatedPayload struct {
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	ReversalOf    *uuid.UUID `json:"reversal_of,omitempty"` // Set for reversals and refunds.
+}
+
+type TransactionPostedPayload struct {
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	Kind          TransactionKind `json:"kind"`
+	Amount        int64           `json:"amount"`
+	Currency      string          `json:"currency"`
+	ReversalOf    *uuid.UUID      `json:"reversal_of,omitempty"` // Set for reversals and refunds.
+	Entries       []PostedEntry   `json:"entries"`
+}
+
+type PostedEntry struct {
+	AccountID     uuid.UUID  `json:"account_id"`
+	AccountNumber string     `json:"account_number"`
+	CustomerID    *uuid.UUID `json:"customer_id"` // Nil for internal accounts.
+	Amount        int64      `json:"amount"`      // Negative for debits.
+}
@@ -8,3 +8,66 @@ const (
 	KYCStatusApproved   KYCStatus = "approved"
 	KYCStatusRejected   KYCStatus = "rejected"
 )
+
+// KYCTier is the level of verification of an approved customer. Higher tiers
+// have higher transaction limits. Operators move customers between tiers,
+// with maker-checker.
+type KYCTier string
+
+const (
+	KYCTierBasic    KYCTier = "basic" // Every approved customer starts here.
+	KYCTierStandard KYCTier = "standard"
+	KYCTierEnhanced KYCTier = "enhanced"
+)
+
+func (t KYCTier) Valid() bool {
+	return t == KYCTierBasic || t == KYCTierStandard || t == KYCTierEnhanced
+}
+
+// kycTransitions are the legal moves of the KYC state machine. A rejected
+// customer has to re-submit (back to pending) before being reviewed again.
+var kycTransitions = map[KYCStatus][]KYCStatus{
+	KYCStatusPending:    {KYCStatusInProgress},
+	KYCStatusInProgress: {KYCStatusApproved, KYCStatusRejected},
+	KYCStatusRejected:   {KYCStatusPending},
+}
+
+func (s KYCStatus) CanTransitionTo(to KYCStatus) bool {
+	for _, next := range kycTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type KYCDocumentKind string
+
+const (
+	KYCDocumentKindPassport       KYCDocumentKind = "passport"
+	KYCDocumentKindIDCard         KYCDocumentKind = "id_card"
+	KYCDocumentKindDrivingLicence KYCDocumentKind = "driving_licence"
+	KYCDocumentKindProofOfAddress KYCDocumentKind = "proof_of_address"
+)
+
+// IsIdentity reports whether the document proves the customer's identity.
+func (k KYCDocumentKind) IsIdentity() bool {
+	switch k {
+	case KYCDocumentKindPassport, KYCDocumentKindIDCard, KYCDocumentKindDrivingLicence:
+		return true
+	}
+	return false
+}
+
+func (k KYCDocumentKind) Valid() bool {
+	return k.IsIdentity() || k == KYCDocumentKindProofOfAddress
+}
+
+// KYCDocumentStatus follows the KYC decision the document was reviewed in.
+type KYCDocumentStatus string
+
+const (
+	KYCDocumentStatusUploaded KYCDocumentStatus = "uploaded" // Waiting for review.
+	KYCDocumentStatusAccepted KYCDocumentStatus = "accepted"
+	KYCDocumentStatusRejected KYCDocumentStatus = "rejected"
+)
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestKYCStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to KYCStatus
+		want     bool
+	}{
+		{KYCStatusPending, KYCStatusInProgress, true},
+		{KYCStatusInProgress, KYCStatusApproved, true},
+		{KYCStatusInProgress, KYCStatusRejected, true},
+		{KYCStatusRejected, KYCStatusPending, true},
+		{KYCStatusPending, KYCStatusApproved, false},
+		{KYCStatusRejected, KYCStatusApproved, false},
+		{KYCStatusRejected, KYCStatusInProgress, false},
+		{KYCStatusApproved, KYCStatusRejected, false},
+		{KYCStatusApproved, KYCStatusApproved, false},
+		{"unknown", KYCStatusPending, false},
+	}
+
+	for _, tt := range tests {
+		// Act.
+		got := tt.from.CanTransitionTo(tt.to)
+
+		// Assert.
+		if got != tt.want {
+			t.Fatalf("%s -> %s: expected %t, got %t", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+func TestKYCTier_Valid(t *testing.T) {
+	for tier, want := range map[KYCTier]bool{
+		KYCTierBasic:    true,
+		KYCTierStandard: true,
+		KYCTierEnhanced: true,
+		"":              false,
+		"gold":          false,
+	} {
+		// Act.
+		got := tier.Valid()
+
+		// Assert.
+		if got != want {
+			t.Fatalf("%q: expected %t, got %t", tier, want, got)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+// LimitKind is the kind of money movement a transaction limit applies to.
+type LimitKind string
+
+const (
+	LimitKindDeposit     LimitKind = "deposit"
+	LimitKindWithdrawal  LimitKind = "withdrawal"
+	LimitKindTransferOut LimitKind = "transfer_out" // Transfers from the customer's accounts.
+)
+
+type LimitPeriod string
+
+const (
+	LimitPeriodDaily   LimitPeriod = "daily"   // Calendar day, UTC.
+	LimitPeriodMonthly LimitPeriod = "monthly" // Calendar month, UTC.
+)
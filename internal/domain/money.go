@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCurrency of new accounts. All supported currencies have 2 decimal
+// places, amounts are stored as integers in minor units (cents).
+const DefaultCurrency = "EUR"
+
+// MaxAmount keeps amounts (and sums of them) far away from int64 overflow.
+const MaxAmount = 1_000_000_000_000_00 // 1 trillion, in minor units.
+
+// ParseAmount parses a positive decimal amount with up to 2 decimal places
+// e.g. "12", "12.3" or "12.34", and returns it in minor units.
+func ParseAmount(s string) (int64, error) {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || (hasFrac && (frac == "" || len(frac) > 2)) {
+		return 0, fmt.Errorf("malformed amount %q", s)
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, fmt.Errorf("malformed amount %q", s)
+			}
+		}
+	}
+	if len(whole) > 15 {
+		return 0, errors.New("amount too large")
+	}
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed amount %q", s)
+	}
+	var minor int64
+	if frac != "" {
+		if minor, err = strconv.ParseInt(frac, 10, 64); err != nil {
+			return 0, fmt.Errorf("malformed amount %q", s)
+		}
+		if len(frac) == 1 {
+			minor *= 10
+		}
+	}
+
+	amount := major*100 + minor
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+	if amount > MaxAmount {
+		return 0, errors.New("amount too large")
+	}
+
+	return amount, nil
+}
+
+// FormatAmount formats an amount in minor units as a decimal e.g. "12.34".
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// ValidateAccountNumber checks the format of an account number.
+func ValidateAccountNumber(number string) error {
+	if _, err := uuid.Parse(number); err != nil || len(number) != 36 {
+		return fmt.Errorf("malformed account number %q", number)
+	}
+	return nil
+}
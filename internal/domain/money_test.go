@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseAmount_OK(t *testing.T) {
+	for in, want := range map[string]int64{
+		"12":      1200,
+		"12.3":    1230,
+		"12.34":   1234,
+		"0.01":    1,
+		"0012.00": 1200,
+	} {
+		// Act.
+		got, err := ParseAmount(in)
+
+		// Assert.
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", in, err)
+		}
+		if got != want {
+			t.Fatalf("%q: expected %d, got %d", in, want, got)
+		}
+	}
+}
+
+func TestParseAmount_Invalid(t *testing.T) {
+	for _, in := range []string{"", "0", "0.00", "-1", "+1", "1.", ".5", "1.234", "1,00", "1e3", " 1", "9999999999999999"} {
+		// Act.
+		_, err := ParseAmount(in)
+
+		// Assert.
+		if err == nil {
+			t.Fatalf("%q: expected an error, got nil", in)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for in, want := range map[int64]string{
+		1234: "12.34",
+		5:    "0.05",
+		-150: "-1.50",
+		0:    "0.00",
+	} {
+		// Act.
+		got := FormatAmount(in)
+
+		// Assert.
+		if got != want {
+			t.Fatalf("%d: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestValidateAccountNumber(t *testing.T) {
+	for in, wantOK := range map[string]bool{
+		"2b8a9c3e-8f4b-4a55-9d0e-6f1c2a7b9e10": true,
+		"2b8a9c3e8f4b4a559d0e6f1c2a7b9e10":     false, // Must be the canonical form.
+		"not-an-account":                       false,
+		"":                                     false,
+	} {
+		// Act.
+		err := ValidateAccountNumber(in)
+
+		// Assert.
+		if (err == nil) != wantOK {
+			t.Fatalf("%q: expected ok=%t, got err %v", in, wantOK, err)
+		}
+	}
+}
+
+// FuzzParseAmount checks that parsed amounts are in range and survive a
+// round trip through FormatAmount.
+func FuzzParseAmount(f *testing.F) {
+	for _, seed := range []string{"12", "12.3", "12.34", "0.01", "0012.00", "0.00", "-1", "1.", ".5", "1.234", "9999999999999999", "999999999999999.99"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		// Act.
+		got, err := ParseAmount(in)
+
+		// Assert.
+		if err != nil {
+			return
+		}
+		if got <= 0 || got > MaxAmount {
+			t.Fatalf("%q: expected an amount between 1 and %d, got %d", in, MaxAmount, got)
+		}
+		again, err := ParseAmount(FormatAmount(got))
+		if err != nil || again != got {
+			t.Fatalf("%q: expected %q to parse back to %d, got %d, %v", in, FormatAmount(got), got, again, err)
+		}
+	})
+}
+
+// FuzzValidateAccountNumber checks that only UUIDs in the canonical form
+// (the way account numbers are stored) are accepted.
+func FuzzValidateAccountNumber(f *testing.F) {
+	for _, seed := range []string{"2b8a9c3e-8f4b-4a55-9d0e-6f1c2a7b9e10", "2b8a9c3e8f4b4a559d0e6f1c2a7b9e10", "{2b8a9c3e-8f4b-4a55-9d0e-6f1c2a7b9e10}", "urn:uuid:2b8a9c3e-8f4b-4a55-9d0e-6f1c2a7b9e10", "not-an-account", ""} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, in string) {
+		// Act.
+		err := ValidateAccountNumber(in)
+
+		// Assert.
+		if err != nil {
+			return
+		}
+		id, err := uuid.Parse(in)
+		if err != nil {
+			t.Fatalf("%q: accepted but not a UUID: %s", in, err)
+		}
+		if !strings.EqualFold(in, id.String()) {
+			t.Fatalf("%q: accepted but not in the canonical form %q", in, id)
+		}
+	})
+}
@@ -0,0 +1,9 @@
+package domain
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending" // Not sent yet, or waiting for a retry.
+	NotificationStatusSent    NotificationStatus = "sent"
+	NotificationStatusFailed  NotificationStatus = "failed" // Gave up, needs a human.
+)
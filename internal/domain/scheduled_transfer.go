@@ -0,0 +1,27 @@
+package domain
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduledTransferStatusActive    ScheduledTransferStatus = "active"
+	ScheduledTransferStatusPaused    ScheduledTransferStatus = "paused"
+	ScheduledTransferStatusCancelled ScheduledTransferStatus = "cancelled"
+	ScheduledTransferStatusCompleted ScheduledTransferStatus = "completed" // No more occurrences.
+)
+
+// InsufficientFundsPolicy decides what happens to an occurrence of a
+// scheduled transfer when the source account can't cover it.
+type InsufficientFundsPolicy string
+
+const (
+	InsufficientFundsPolicySkip  InsufficientFundsPolicy = "skip"  // Move on to the next occurrence.
+	InsufficientFundsPolicyRetry InsufficientFundsPolicy = "retry" // Retry a few times, then skip.
+)
+
+type ScheduledTransferRunStatus string
+
+const (
+	ScheduledTransferRunStatusExecuted ScheduledTransferRunStatus = "executed"
+	ScheduledTransferRunStatusSkipped  ScheduledTransferRunStatus = "skipped"
+	ScheduledTransferRunStatusFailed   ScheduledTransferRunStatus = "failed"
+)
@@ -0,0 +1,9 @@
+package domain
+
+type ScreeningReviewStatus string
+
+const (
+	ScreeningReviewStatusOpen      ScreeningReviewStatus = "open"      // Waiting for a human.
+	ScreeningReviewStatusConfirmed ScreeningReviewStatus = "confirmed" // True match.
+	ScreeningReviewStatusDismissed ScreeningReviewStatus = "dismissed" // False positive.
+)
@@ -0,0 +1,61 @@
+package domain
+
+type AccountKind string
+
+const (
+	AccountKindCustomer AccountKind = "customer" // Personal account of a customer, can't go negative.
+	AccountKindInternal AccountKind = "internal" // Owned by the wallet (settlement, revenue...), can go negative.
+)
+
+// AccountStatus is set by operators, see the admin API.
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "active"
+	AccountStatusFrozen AccountStatus = "frozen" // Money can come in, but not go out.
+)
+
+// AccountProduct is chosen by the customer when opening an account.
+type AccountProduct string
+
+const (
+	AccountProductCurrent AccountProduct = "current" // For everyday payments, no interest.
+	AccountProductSavings AccountProduct = "savings" // Earns interest.
+)
+
+// InternalAccount names an internal account. There's one per currency, see
+// ledger.InternalAccountID.
+type InternalAccount string
+
+const (
+	// InternalAccountSettlement mirrors money held outside the wallet (e.g.
+	// at the bank), deposits and withdrawals move money between it and
+	// customer accounts.
+	InternalAccountSettlement InternalAccount = "settlement"
+	// InternalAccountFeeRevenue collects the fees charged to customers.
+	InternalAccountFeeRevenue InternalAccount = "fee_revenue"
+	// InternalAccountInterestExpense pays the interest of savings accounts.
+	InternalAccountInterestExpense InternalAccount = "interest_expense"
+	// InternalAccountSuspense is the other side of manual adjustments made by
+	// operators, until the money is accounted for elsewhere.
+	InternalAccountSuspense InternalAccount = "suspense"
+)
+
+type TransactionStatus string
+
+const (
+	TransactionStatusPending TransactionStatus = "pending" // Funds reserved, going through (or waiting for) KYT.
+	TransactionStatusCleared TransactionStatus = "cleared" // Posted to account balances.
+	TransactionStatusFailed  TransactionStatus = "failed"  // Reservation released, nothing posted.
+)
+
+type TransactionKind string
+
+const (
+	TransactionKindTransfer   TransactionKind = "transfer"   // Between accounts in the wallet.
+	TransactionKindDeposit    TransactionKind = "deposit"    // Money coming into the wallet.
+	TransactionKindWithdrawal TransactionKind = "withdrawal" // Money leaving the wallet.
+	TransactionKindInterest   TransactionKind = "interest"   // Interest paid out on a savings account.
+	TransactionKindReversal   TransactionKind = "reversal"   // Full or partial reversal (refund) of another transaction.
+	TransactionKindAdjustment TransactionKind = "adjustment" // Manual correction by operators, see AdjustmentReason.
+)
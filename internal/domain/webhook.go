@@ -0,0 +1,9 @@
+package domain
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending" // Not delivered yet, or waiting for a retry.
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "dead" // Gave up, can be redelivered manually.
+)
@@ -0,0 +1,122 @@
+// Package fees calculates the fees of money movements. Fees depend on the kind
+// of movement, its currency and the customer's KYC tier, and are configured in
+// the fee_schedules table. Movements without a schedule are free.
+package fees
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Schedule is the fee of a kind of movement. Amounts are in minor units.
+type Schedule struct {
+	Fixed        int64 `db:"fixed"`
+	PercentBPS   int64 `db:"percent_bps"`    // Basis points of the amount, 100 = 1%.
+	Min          int64 `db:"min_fee"`        // Applied after adding up fixed and percentage.
+	Max          int64 `db:"max_fee"`        // Zero means no cap.
+	FreePerMonth int   `db:"free_per_month"` // Movements per calendar month (UTC) without a fee.
+}
+
+// Quote is the fee of a movement, if it was made now.
+type Quote struct {
+	Fee int64
+	// FreeRemaining is how many more movements of the kind are free this
+	// month, not counting the quoted one.
+	FreeRemaining int
+}
+
+// Calculate returns the fee for amount, given how many movements of the kind
+// the customer already made this month.
+func (s Schedule) Calculate(amount int64, usedThisMonth int) int64 {
+	if usedThisMonth < s.FreePerMonth {
+		return 0
+	}
+
+	// Round the percentage half up. Splitting the amount keeps amount*bps
+	// from overflowing for big amounts.
+	fee := s.Fixed + amount/10000*s.PercentBPS + (amount%10000*s.PercentBPS+5000)/10000
+	if fee < s.Min {
+		fee = s.Min
+	}
+	if s.Max > 0 && fee > s.Max {
+		fee = s.Max
+	}
+	return fee
+}
+
+// Get quotes the fee for the customer moving amount. Call it in the DB
+// transaction that initiates the movement, after checking limits (which locks
+// the customer), so concurrent movements can't use the same free allowance.
+func Get(ctx context.Context, db DB, customerID uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (Quote, error) {
+	var tier domain.KYCTier
+	err := db.QueryRow(ctx, `SELECT kyc_tier FROM customers WHERE id = $1`, customerID).Scan(&tier)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Quote{}, ErrCustomerNotFound
+	}
+	if err != nil {
+		return Quote{}, err
+	}
+
+	s, found, err := dbGetSchedule(ctx, db, tier, kind, currency)
+	if err != nil || !found {
+		return Quote{}, err
+	}
+
+	monthStart := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	used, err := dbCountMovements(ctx, db, customerID, kind, monthStart)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	return Quote{
+		Fee:           s.Calculate(amount, used),
+		FreeRemaining: max(s.FreePerMonth-used-1, 0),
+	}, nil
+}
+
+func dbGetSchedule(ctx context.Context, db DB, tier domain.KYCTier, kind domain.TransactionKind, currency string) (Schedule, bool, error) {
+	sql := `
+		SELECT fixed, percent_bps, min_fee, max_fee, free_per_month FROM fee_schedules
+		WHERE tier = $1 AND kind = $2 AND currency = $3`
+
+	var s Schedule
+	err := db.QueryRow(ctx, sql, tier, kind, currency).Scan(&s.Fixed, &s.PercentBPS, &s.Min, &s.Max, &s.FreePerMonth)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return s, false, nil
+	case err != nil:
+		return s, false, err
+	default:
+		return s, true, nil
+	}
+}
+
+// dbCountMovements counts the movements of a kind debiting the customer's
+// accounts since a point in time. Failed ones don't count.
+func dbCountMovements(ctx context.Context, db DB, customerID uuid.UUID, kind domain.TransactionKind, since time.Time) (int, error) {
+	sql := `
+		SELECT count(*)
+		FROM transactions t
+		WHERE t.kind = $1 AND t.status <> $2 AND t.created_at >= $3
+			AND EXISTS (
+				SELECT 1 FROM entries e JOIN accounts a ON a.id = e.account_id
+				WHERE e.transaction_id = t.id AND a.customer_id = $4 AND e.amount < 0
+			)`
+
+	var res int
+	err := db.QueryRow(ctx, sql, kind, domain.TransactionStatusFailed, since, customerID).Scan(&res)
+	return res, err
+}
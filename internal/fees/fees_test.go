@@ -0,0 +1,31 @@
+package fees
+
+import "testing"
+
+func TestScheduleCalculate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule Schedule
+		amount   int64
+		used     int
+		want     int64
+	}{
+		{"no fee", Schedule{}, 10000, 0, 0},
+		{"fixed", Schedule{Fixed: 50}, 10000, 0, 50},
+		{"percentage", Schedule{PercentBPS: 150}, 10000, 0, 150},
+		{"percentage rounds half up", Schedule{PercentBPS: 150}, 1001, 0, 15},
+		{"fixed plus percentage", Schedule{Fixed: 25, PercentBPS: 100}, 10000, 0, 125},
+		{"min", Schedule{PercentBPS: 10, Min: 50}, 10000, 0, 50},
+		{"max", Schedule{PercentBPS: 1000, Max: 500}, 10000, 0, 500},
+		{"zero max means no cap", Schedule{PercentBPS: 1000}, 10000, 0, 1000},
+		{"free allowance", Schedule{Fixed: 50, FreePerMonth: 3}, 10000, 2, 0},
+		{"free allowance used up", Schedule{Fixed: 50, FreePerMonth: 3}, 10000, 3, 50},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.schedule.Calculate(tc.amount, tc.used); got != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/middleware"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+// beginAdminWork is beginAuditedWork for the back office, the changes are
+// attributed to the operator authenticated by middleware.AdminAuth.
+func beginAdminWork(c *gin.Context, store repo.Store) (repo.Tx, error) {
+	return store.Begin(c, repo.Audit{
+		Actor:     adminActor(c),
+		Action:    c.Request.Method + " " + c.FullPath(),
+		RequestID: c.GetHeader(middleware.RequestIDHeader),
+	})
+}
+
+// adminActor is the operator that made the request.
+func adminActor(c *gin.Context) audit.Actor {
+	return audit.Actor{Type: audit.ActorTypeAdmin, ID: c.GetHeader(middleware.AdminIDHeader)}
+}
+
+// adminID is the operator that made the request. AdminAuth sets it, so it's
+// always valid behind it.
+func adminID(c *gin.Context) uuid.UUID {
+	id, _ := uuid.Parse(c.GetHeader(middleware.AdminIDHeader))
+	return id
+}
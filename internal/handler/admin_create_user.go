@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminCreateUser(
+	store repo.Store,
+) *AdminCreateUser {
+	return &AdminCreateUser{
+		store: store,
+	}
+}
+
+// AdminCreateUser adds a back office user. The response carries the user's
+// bearer token, it's never shown again.
+type AdminCreateUser struct {
+	store repo.Store
+}
+
+type AdminCreateUserRequest struct {
+	Email string           `json:"email"`
+	Role  domain.AdminRole `json:"role"`
+}
+
+type AdminCreateUserResponse struct {
+	User  domain.AdminUser `json:"user"`
+	Token string           `json:"token"`
+}
+
+func (h *AdminCreateUser) Handle(c *gin.Context) {
+	// Validate request.
+	var req AdminCreateUserRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if req.Email == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing email")
+		return
+	}
+	if !req.Role.Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "invalid role")
+		return
+	}
+
+	user, token, err := admin.NewUser(req.Email, req.Role)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	err = tx.BackOffice().CreateUser(c, user, token)
+	switch {
+	case errors.Is(err, admin.ErrEmailTaken):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, AdminCreateUserResponse{User: user, Token: token})
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminCreateUser(t *testing.T) {
+	body := fmt.Sprintf(`{"email": "ops@example.com", "role": %q}`, domain.AdminRoleOperator)
+
+	// Arrange.
+	m := repo.NewMemory()
+	h := NewAdminCreateUser(m)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "missing email", body: fmt.Sprintf(`{"role": %q}`, domain.AdminRoleViewer), wantCode: http.StatusBadRequest},
+		{name: "invalid role", body: `{"email": "ops@example.com", "role": "root"}`, wantCode: http.StatusBadRequest},
+		{name: "created", body: body, wantCode: http.StatusCreated},
+		{name: "email taken", body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/users", h.Handle, newAdminRequest(http.MethodPost, "/admin/v1/users", tt.body, uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			resp := decode[AdminCreateUserResponse](t, w)
+			user, found, err := m.BackOffice().FindUserByToken(context.Background(), resp.Token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found || user.ID != resp.User.ID || user.Email != "ops@example.com" || user.Role != domain.AdminRoleOperator {
+				t.Fatalf("expected the token to sign in %+v, got %+v (found %t)", resp.User, user, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminDecideApproval(
+	store repo.Store,
+	approve bool,
+) *AdminDecideApproval {
+	return &AdminDecideApproval{
+		store:   store,
+		approve: approve,
+	}
+}
+
+// AdminDecideApproval is the checker's side of maker-checker. The checker
+// must be a different operator than the maker. Approving executes the action
+// in the same DB transaction, if it can't be executed anymore (e.g. the
+// customer isn't under review now) nothing changes and the request stays
+// pending, to be rejected.
+type AdminDecideApproval struct {
+	store   repo.Store
+	approve bool
+}
+
+type AdminDecideApprovalRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// errNotExecutable means the approved action conflicts with the current state.
+var errNotExecutable = errors.New("can't execute")
+
+func (h *AdminDecideApproval) Handle(c *gin.Context) {
+	// Validate request.
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed approval id")
+		return
+	}
+	var req AdminDecideApprovalRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	approval, err := tx.BackOffice().LockApproval(c, id)
+	if errors.Is(err, admin.ErrApprovalNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	approval, err = tx.BackOffice().Decide(c, approval, adminID(c), h.approve, req.Note)
+	switch {
+	case errors.Is(err, admin.ErrSameOperator):
+		c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, admin.ErrAlreadyDecided):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if h.approve {
+		err := h.execute(c, tx, approval)
+		if errors.Is(err, errNotExecutable) {
+			c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
+
+// execute carries out an approved action.
+func (h *AdminDecideApproval) execute(c *gin.Context, tx repo.Tx, a admin.Approval) error {
+	switch a.Kind {
+	case domain.ApprovalKindKYCApprove:
+		var p admin.KYCApprovePayload
+		if err := json.Unmarshal(a.Payload, &p); err != nil {
+			return err
+		}
+		if err := tx.BackOffice().ReviewOpenHits(c, p.CustomerID, domain.ScreeningReviewStatusDismissed); err != nil {
+			return err
+		}
+		err := tx.Customers().TransitionKYC(c, p.CustomerID, domain.KYCStatusInProgress, domain.KYCStatusApproved, a.Reason, adminActor(c))
+		if errors.Is(err, kyc.ErrIllegalTransition) || errors.Is(err, kyc.ErrStatusChanged) || errors.Is(err, kyc.ErrMissingDocuments) {
+			return fmt.Errorf("%w: %w", errNotExecutable, err)
+		}
+		return err
+
+	case domain.ApprovalKindKYCTierChange:
+		var p admin.KYCTierChangePayload
+		if err := json.Unmarshal(a.Payload, &p); err != nil {
+			return err
+		}
+		err := tx.Customers().ChangeTier(c, p.CustomerID, p.From, p.To)
+		if errors.Is(err, kyc.ErrTierChanged) {
+			return fmt.Errorf("%w: %w", errNotExecutable, err)
+		}
+		return err
+
+	case domain.ApprovalKindAccountUnfreeze:
+		var p admin.AccountUnfreezePayload
+		if err := json.Unmarshal(a.Payload, &p); err != nil {
+			return err
+		}
+		unfrozen, err := tx.Accounts().Unfreeze(c, p.AccountID)
+		if err != nil {
+			return err
+		}
+		if !unfrozen {
+			return fmt.Errorf("%w: account %s is not frozen", errNotExecutable, p.AccountNumber)
+		}
+		return nil
+
+	case domain.ApprovalKindAdjustment:
+		var p admin.AdjustmentPayload
+		if err := json.Unmarshal(a.Payload, &p); err != nil {
+			return err
+		}
+		_, err := tx.Ledger().PostAdjustment(c, a, p)
+		if errors.Is(err, ledger.ErrInsufficientFunds) || errors.Is(err, ledger.ErrAccountFrozen) {
+			return fmt.Errorf("%w: %w", errNotExecutable, err)
+		}
+		return err
+
+	default:
+		return fmt.Errorf("unknown approval kind %q", a.Kind)
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+func TestAdminDecideApproval(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := repo.NewMemory()
+	maker, checker := uuid.New(), uuid.New()
+
+	inReview, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	addDocument(t, m, inReview, domain.KYCDocumentKindPassport)
+	addDocument(t, m, inReview, domain.KYCDocumentKindProofOfAddress)
+	m.AddScreeningHits(inReview, screening.Hit{ListName: "ofac", EntryID: "1", EntryName: "Someone Else", Score: 0.8, Strength: screening.HitStrengthWeak})
+	kycApprove := addApproval(t, m, domain.ApprovalKindKYCApprove, inReview.String(), admin.KYCApprovePayload{CustomerID: inReview}, maker)
+
+	approved, _ := addCustomer(m, domain.KYCStatusApproved, 0)
+	tierChange := addApproval(t, m, domain.ApprovalKindKYCTierChange, approved.String(), admin.KYCTierChangePayload{
+		CustomerID: approved, From: domain.KYCTierBasic, To: domain.KYCTierEnhanced,
+	}, maker)
+
+	_, frozen := addCustomer(m, domain.KYCStatusApproved, 0)
+	if err := m.Accounts().Freeze(ctx, frozen.ID, "card reported stolen"); err != nil {
+		t.Fatal(err)
+	}
+	unfreeze := addApproval(t, m, domain.ApprovalKindAccountUnfreeze, frozen.ID.String(), admin.AccountUnfreezePayload{
+		AccountID: frozen.ID, AccountNumber: frozen.Number,
+	}, maker)
+
+	_, active := addCustomer(m, domain.KYCStatusApproved, 0)
+	staleUnfreeze := addApproval(t, m, domain.ApprovalKindAccountUnfreeze, active.ID.String(), admin.AccountUnfreezePayload{
+		AccountID: active.ID, AccountNumber: active.Number,
+	}, maker)
+
+	_, credited := addCustomer(m, domain.KYCStatusApproved, 0)
+	adjustment := addApproval(t, m, domain.ApprovalKindAdjustment, credited.ID.String(), admin.AdjustmentPayload{
+		AccountID:     credited.ID,
+		AccountNumber: credited.Number,
+		Direction:     domain.AdjustmentDirectionCredit,
+		Amount:        1234,
+		Currency:      credited.Currency,
+		ReasonCode:    domain.AdjustmentReasonGoodwill,
+		AttachmentRef: "TICKET-1",
+	}, maker)
+
+	toReject := addApproval(t, m, domain.ApprovalKindKYCApprove, uuid.NewString(), admin.KYCApprovePayload{}, maker)
+
+	approve := NewAdminDecideApproval(m, true)
+	reject := NewAdminDecideApproval(m, false)
+
+	tests := []struct {
+		name       string
+		handler    *AdminDecideApproval
+		action     string
+		approvalID string
+		adminID    uuid.UUID
+		wantCode   int
+		wantStatus domain.ApprovalStatus
+	}{
+		{name: "malformed approval id", handler: approve, action: "approve", approvalID: "nope", adminID: checker, wantCode: http.StatusBadRequest},
+		{name: "not found", handler: approve, action: "approve", approvalID: uuid.NewString(), adminID: checker, wantCode: http.StatusNotFound},
+		{name: "maker can't check", handler: approve, action: "approve", approvalID: kycApprove.ID.String(), adminID: maker, wantCode: http.StatusForbidden},
+		{name: "kyc approved", handler: approve, action: "approve", approvalID: kycApprove.ID.String(), adminID: checker, wantCode: http.StatusOK, wantStatus: domain.ApprovalStatusApproved},
+		{name: "tier changed", handler: approve, action: "approve", approvalID: tierChange.ID.String(), adminID: checker, wantCode: http.StatusOK, wantStatus: domain.ApprovalStatusApproved},
+		{name: "unfrozen", handler: approve, action: "approve", approvalID: unfreeze.ID.String(), adminID: checker, wantCode: http.StatusOK, wantStatus: domain.ApprovalStatusApproved},
+		{name: "account not frozen anymore", handler: approve, action: "approve", approvalID: staleUnfreeze.ID.String(), adminID: checker, wantCode: http.StatusConflict},
+		{name: "adjustment posted", handler: approve, action: "approve", approvalID: adjustment.ID.String(), adminID: checker, wantCode: http.StatusOK, wantStatus: domain.ApprovalStatusApproved},
+		{name: "rejected", handler: reject, action: "reject", approvalID: toReject.ID.String(), adminID: checker, wantCode: http.StatusOK, wantStatus: domain.ApprovalStatusRejected},
+		{name: "already decided", handler: approve, action: "approve", approvalID: toReject.ID.String(), adminID: checker, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := "/admin/v1/approvals/:id/" + tt.action
+			path := "/admin/v1/approvals/" + tt.approvalID + "/" + tt.action
+
+			// Act.
+			w := serve(http.MethodPost, route, tt.handler.Handle, newAdminRequest(http.MethodPost, path, `{"note": "looks right"}`, tt.adminID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			a := decode[admin.Approval](t, w)
+			if a.Status != tt.wantStatus || a.CheckerID == nil || *a.CheckerID != checker {
+				t.Fatalf("expected %s by %s, got %+v", tt.wantStatus, checker, a)
+			}
+		})
+	}
+
+	customer, err := m.Customers().Get(ctx, inReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		t.Fatalf("expected KYC %s, got %s", domain.KYCStatusApproved, customer.KYCStatus)
+	}
+	if hits := m.ScreeningHits(inReview); hits[0].ReviewStatus != domain.ScreeningReviewStatusDismissed {
+		t.Fatalf("expected the hit dismissed, got %+v", hits)
+	}
+	customer, err = m.Customers().Get(ctx, approved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customer.KYCTier != domain.KYCTierEnhanced {
+		t.Fatalf("expected tier %s, got %s", domain.KYCTierEnhanced, customer.KYCTier)
+	}
+	account, err := m.Accounts().GetByNumber(ctx, frozen.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Status != domain.AccountStatusActive {
+		t.Fatalf("expected account %s, got %s", domain.AccountStatusActive, account.Status)
+	}
+	account, err = m.Accounts().GetByNumber(ctx, credited.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Balance != 1234 {
+		t.Fatalf("expected balance 1234, got %d", account.Balance)
+	}
+	pending, err := m.BackOffice().Approvals(ctx, domain.ApprovalStatusPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != staleUnfreeze.ID {
+		t.Fatalf("expected only the approval that couldn't be executed left pending, got %+v", pending)
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminFreezeAccount(
+	store repo.Store,
+) *AdminFreezeAccount {
+	return &AdminFreezeAccount{
+		store: store,
+	}
+}
+
+// AdminFreezeAccount stops money going out of a customer account. Freezing
+// protects the customer and the wallet, so it takes effect right away, while
+// unfreezing needs a second operator (see AdminRequestUnfreeze).
+type AdminFreezeAccount struct {
+	store repo.Store
+}
+
+type AdminFreezeAccountRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AdminFreezeAccount) Handle(c *gin.Context) {
+	// Validate request.
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var req AdminFreezeAccountRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if req.Reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing reason")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	account, found, err := lockCustomerAccount(c, tx, number)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+	if account.Status == domain.AccountStatusFrozen {
+		c.AbortWithStatusJSON(http.StatusConflict, "account already frozen")
+		return
+	}
+
+	if err := tx.Accounts().Freeze(c, account.ID, req.Reason); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// lockCustomerAccount locks a customer account (internal accounts are never
+// frozen) until the unit of work ends.
+func lockCustomerAccount(c *gin.Context, tx repo.Tx, number string) (repo.Account, bool, error) {
+	account, err := tx.Accounts().LockByNumber(c, number)
+	switch {
+	case errors.Is(err, repo.ErrAccountNotFound):
+		return repo.Account{}, false, nil
+	case err != nil:
+		return repo.Account{}, false, err
+	default:
+		return account, account.Kind == domain.AccountKindCustomer, nil
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminFreezeAccount(t *testing.T) {
+	const body = `{"reason": "card reported stolen"}`
+
+	// Arrange.
+	m := repo.NewMemory()
+	operator := uuid.New()
+	_, account := addCustomer(m, domain.KYCStatusApproved, 0)
+	internal := m.AddAccount(repo.Account{})
+	h := NewAdminFreezeAccount(m)
+
+	tests := []struct {
+		name     string
+		number   string
+		body     string
+		wantCode int
+	}{
+		{name: "malformed number", number: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "missing reason", number: account.Number, body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "not found", number: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "internal account", number: internal.Number, body: body, wantCode: http.StatusNotFound},
+		{name: "frozen", number: account.Number, body: body, wantCode: http.StatusNoContent},
+		{name: "already frozen", number: account.Number, body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/accounts/" + tt.number + "/freeze"
+
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/accounts/:number/freeze", h.Handle, newAdminRequest(http.MethodPost, path, tt.body, operator))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+		})
+	}
+
+	frozen, err := m.Accounts().ListFrozen(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frozen) != 1 || frozen[0].ID != account.ID || *frozen[0].Reason != "card reported stolen" {
+		t.Fatalf("expected only %s frozen, got %+v", account.Number, frozen)
+	}
+	audits := m.Audits()
+	if len(audits) != 1 || audits[0].Actor.Type != audit.ActorTypeAdmin || audits[0].Actor.ID != operator.String() || audits[0].Action != "POST /admin/v1/accounts/:number/freeze" {
+		t.Fatalf("expected the freeze attributed to operator %s, got %+v", operator, audits)
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminListApprovals(
+	store repo.Store,
+) *AdminListApprovals {
+	return &AdminListApprovals{
+		store: store,
+	}
+}
+
+// AdminListApprovals lists maker-checker approval requests, pending ones by
+// default (use the "status" query param for others). Oldest first.
+type AdminListApprovals struct {
+	store repo.Store
+}
+
+type AdminListApprovalsResponse struct {
+	Approvals []admin.Approval `json:"approvals"`
+}
+
+func (h *AdminListApprovals) Handle(c *gin.Context) {
+	status := domain.ApprovalStatus(c.DefaultQuery("status", string(domain.ApprovalStatusPending)))
+	switch status {
+	case domain.ApprovalStatusPending, domain.ApprovalStatusApproved, domain.ApprovalStatusRejected:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	approvals, err := h.store.BackOffice().Approvals(c, status, maxPageSize)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AdminListApprovalsResponse{Approvals: approvals})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminListApprovals(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := repo.NewMemory()
+	maker := uuid.New()
+	pending := addApproval(t, m, domain.ApprovalKindKYCApprove, uuid.NewString(), admin.KYCApprovePayload{}, maker)
+	rejected := addApproval(t, m, domain.ApprovalKindKYCApprove, uuid.NewString(), admin.KYCApprovePayload{}, maker)
+	if _, err := m.BackOffice().Decide(ctx, rejected, uuid.New(), false, "no evidence"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminListApprovals(m)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantIDs  []uuid.UUID
+	}{
+		{name: "unknown status", query: "?status=nope", wantCode: http.StatusBadRequest},
+		{name: "pending by default", query: "", wantCode: http.StatusOK, wantIDs: []uuid.UUID{pending.ID}},
+		{name: "rejected", query: "?status=rejected", wantCode: http.StatusOK, wantIDs: []uuid.UUID{rejected.ID}},
+		{name: "none approved", query: "?status=approved", wantCode: http.StatusOK, wantIDs: []uuid.UUID{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/approvals" + tt.query
+
+			// Act.
+			w := serve(http.MethodGet, "/admin/v1/approvals", h.Handle, newAdminRequest(http.MethodGet, path, "", uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[AdminListApprovalsResponse](t, w)
+			if len(resp.Approvals) != len(tt.wantIDs) {
+				t.Fatalf("expected approvals %v, got %+v", tt.wantIDs, resp.Approvals)
+			}
+			for i, a := range resp.Approvals {
+				if a.ID != tt.wantIDs[i] {
+					t.Fatalf("expected approvals %v, got %+v", tt.wantIDs, resp.Approvals)
+				}
+			}
+		})
+	}
+}
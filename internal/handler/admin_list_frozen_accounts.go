@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminListFrozenAccounts(
+	store repo.Store,
+) *AdminListFrozenAccounts {
+	return &AdminListFrozenAccounts{
+		store: store,
+	}
+}
+
+// AdminListFrozenAccounts is the queue of frozen accounts, longest frozen
+// first.
+type AdminListFrozenAccounts struct {
+	store repo.Store
+}
+
+type AdminFrozenAccount struct {
+	ID         uuid.UUID  `json:"id"`
+	Number     string     `json:"number"`
+	CustomerID *uuid.UUID `json:"customer_id"`
+	Currency   string     `json:"currency"`
+	Balance    int64      `json:"balance"`
+	Reason     *string    `json:"reason"`
+	FrozenAt   time.Time  `json:"frozen_at"`
+}
+
+type AdminListFrozenAccountsResponse struct {
+	Accounts []AdminFrozenAccount `json:"accounts"`
+}
+
+func (h *AdminListFrozenAccounts) Handle(c *gin.Context) {
+	accounts, err := h.store.Accounts().ListFrozen(c, maxPageSize)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := AdminListFrozenAccountsResponse{Accounts: make([]AdminFrozenAccount, 0, len(accounts))}
+	for _, a := range accounts {
+		resp.Accounts = append(resp.Accounts, AdminFrozenAccount{
+			ID:         a.ID,
+			Number:     a.Number,
+			CustomerID: a.CustomerID,
+			Currency:   a.Currency,
+			Balance:    a.Balance,
+			Reason:     a.Reason,
+			FrozenAt:   a.FrozenAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
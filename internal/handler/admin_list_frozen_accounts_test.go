@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminListFrozenAccounts(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, frozen := addCustomer(m, domain.KYCStatusApproved, 1234)
+	addCustomer(m, domain.KYCStatusApproved, 0)
+	if err := m.Accounts().Freeze(context.Background(), frozen.ID, "card reported stolen"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminListFrozenAccounts(m)
+
+	// Act.
+	w := serve(http.MethodGet, "/admin/v1/queues/frozen-accounts", h.Handle, newAdminRequest(http.MethodGet, "/admin/v1/queues/frozen-accounts", "", uuid.New()))
+
+	// Assert.
+	assertCode(t, w, http.StatusOK)
+	resp := decode[AdminListFrozenAccountsResponse](t, w)
+	if len(resp.Accounts) != 1 {
+		t.Fatalf("expected only the frozen account, got %+v", resp.Accounts)
+	}
+	account := resp.Accounts[0]
+	if account.Number != frozen.Number || *account.CustomerID != customerID || account.Balance != 1234 {
+		t.Fatalf("expected account %s of %s with 1234, got %+v", frozen.Number, customerID, account)
+	}
+	if account.Reason == nil || *account.Reason != "card reported stolen" {
+		t.Fatalf("expected the freeze reason, got %v", account.Reason)
+	}
+}
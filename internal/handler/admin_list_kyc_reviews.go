@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminListKYCReviews(
+	store repo.Store,
+	cipher *pii.Cipher,
+) *AdminListKYCReviews {
+	return &AdminListKYCReviews{
+		store:  store,
+		cipher: cipher,
+	}
+}
+
+// AdminListKYCReviews is the queue of customers whose KYC waits for a human:
+// in progress with open screening hits. Oldest first.
+type AdminListKYCReviews struct {
+	store  repo.Store
+	cipher *pii.Cipher
+}
+
+type AdminKYCReview struct {
+	CustomerID uuid.UUID           `json:"customer_id"`
+	FirstName  string              `json:"first_name"`
+	LastName   string              `json:"last_name"`
+	Since      time.Time           `json:"since"`
+	Hits       []AdminKYCReviewHit `json:"hits"`
+}
+
+type AdminKYCReviewHit struct {
+	ID        uuid.UUID `json:"id"`
+	ListName  string    `json:"list_name"`
+	EntryName string    `json:"entry_name"`
+	EntryKind string    `json:"entry_kind"`
+	Score     float64   `json:"score"`
+	Strength  string    `json:"strength"`
+}
+
+type AdminListKYCReviewsResponse struct {
+	Reviews []AdminKYCReview `json:"reviews"`
+}
+
+func (h *AdminListKYCReviews) Handle(c *gin.Context) {
+	reviews, err := h.store.BackOffice().KYCReviews(c, maxPageSize)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := AdminListKYCReviewsResponse{Reviews: make([]AdminKYCReview, 0, len(reviews))}
+	for _, r := range reviews {
+		p, err := h.cipher.OpenRow(c, r.CustomerID, r.PII)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		review := AdminKYCReview{
+			CustomerID: r.CustomerID,
+			FirstName:  p.FirstName,
+			LastName:   p.LastName,
+			Since:      r.Since,
+			Hits:       make([]AdminKYCReviewHit, 0, len(r.Hits)),
+		}
+		for _, hit := range r.Hits {
+			review.Hits = append(review.Hits, AdminKYCReviewHit{
+				ID:        hit.ID,
+				ListName:  hit.ListName,
+				EntryName: hit.EntryName,
+				EntryKind: hit.EntryKind,
+				Score:     hit.Score,
+				Strength:  hit.Strength,
+			})
+		}
+		resp.Reviews = append(resp.Reviews, review)
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+func TestAdminListKYCReviews(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := repo.NewMemory()
+	cipher := newTestCipher(t)
+	inReview := uuid.New()
+	sealed, err := cipher.Seal(ctx, inReview, pii.PII{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Customers().Create(ctx, repo.NewCustomer{ID: inReview, PII: sealed}, audit.Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Customers().TransitionKYC(ctx, inReview, domain.KYCStatusPending, domain.KYCStatusInProgress, "screening hits", audit.Actor{}); err != nil {
+		t.Fatal(err)
+	}
+	m.AddScreeningHits(inReview,
+		screening.Hit{ListName: "ofac", EntryID: "1", EntryName: "Ada Lovelace", Kind: screening.EntryKindPEP, Score: 0.8, Strength: screening.HitStrengthWeak},
+		screening.Hit{ListName: "un", EntryID: "2", EntryName: "Ada Lovelace", Kind: screening.EntryKindSanction, Score: 0.95, Strength: screening.HitStrengthStrong},
+	)
+	noHits, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	pending, _ := addCustomer(m, domain.KYCStatusPending, 0)
+	m.AddScreeningHits(pending, screening.Hit{ListName: "ofac", EntryID: "3", EntryName: "Someone", Score: 0.9})
+	h := NewAdminListKYCReviews(m, cipher)
+
+	// Act.
+	w := serve(http.MethodGet, "/admin/v1/queues/kyc-reviews", h.Handle, newAdminRequest(http.MethodGet, "/admin/v1/queues/kyc-reviews", "", uuid.New()))
+
+	// Assert.
+	assertCode(t, w, http.StatusOK)
+	resp := decode[AdminListKYCReviewsResponse](t, w)
+	if len(resp.Reviews) != 1 {
+		t.Fatalf("expected only the customer in review with hits (not %s or %s), got %+v", noHits, pending, resp.Reviews)
+	}
+	review := resp.Reviews[0]
+	if review.CustomerID != inReview || review.FirstName != "Ada" || review.LastName != "Lovelace" {
+		t.Fatalf("expected %s Ada Lovelace, got %+v", inReview, review)
+	}
+	if len(review.Hits) != 2 || review.Hits[0].ListName != "un" || review.Hits[1].ListName != "ofac" {
+		t.Fatalf("expected the hits strongest first, got %+v", review.Hits)
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminListKYTAlerts(
+	store repo.Store,
+) *AdminListKYTAlerts {
+	return &AdminListKYTAlerts{
+		store: store,
+	}
+}
+
+// AdminListKYTAlerts is the queue of transactions held back by KYT, see
+// consumer.ClearTransactions. Oldest first.
+type AdminListKYTAlerts struct {
+	store repo.Store
+}
+
+type AdminKYTAlert struct {
+	ID            uuid.UUID              `json:"id"`
+	TransactionID uuid.UUID              `json:"transaction_id"`
+	Kind          domain.TransactionKind `json:"kind"`
+	Amount        int64                  `json:"amount"`
+	Currency      string                 `json:"currency"`
+	Rule          string                 `json:"rule"`
+	Detail        string                 `json:"detail"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+type AdminListKYTAlertsResponse struct {
+	Alerts []AdminKYTAlert `json:"alerts"`
+}
+
+func (h *AdminListKYTAlerts) Handle(c *gin.Context) {
+	alerts, err := h.store.BackOffice().KYTAlerts(c, maxPageSize)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := AdminListKYTAlertsResponse{Alerts: make([]AdminKYTAlert, 0, len(alerts))}
+	for _, a := range alerts {
+		resp.Alerts = append(resp.Alerts, AdminKYTAlert{
+			ID:            a.ID,
+			TransactionID: a.TransactionID,
+			Kind:          a.Kind,
+			Amount:        a.Amount,
+			Currency:      a.Currency,
+			Rule:          a.Rule,
+			Detail:        a.Detail,
+			CreatedAt:     a.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
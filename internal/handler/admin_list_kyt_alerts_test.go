@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminListKYTAlerts(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	_, from := addCustomer(m, domain.KYCStatusApproved, 100000)
+	_, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	held := pendingTransfer(t, m, from, to, 60000)
+	open := m.AddKYTAlert(held.ID, "large_amount", "over 500.00")
+	resolved := m.AddKYTAlert(pendingTransfer(t, m, from, to, 100).ID, "velocity", "3 transfers in a minute")
+	if err := m.BackOffice().ResolveKYTAlert(context.Background(), resolved, domain.KYTAlertStatusCleared, uuid.New(), "known payee"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminListKYTAlerts(m)
+
+	// Act.
+	w := serve(http.MethodGet, "/admin/v1/queues/kyt-alerts", h.Handle, newAdminRequest(http.MethodGet, "/admin/v1/queues/kyt-alerts", "", uuid.New()))
+
+	// Assert.
+	assertCode(t, w, http.StatusOK)
+	resp := decode[AdminListKYTAlertsResponse](t, w)
+	if len(resp.Alerts) != 1 {
+		t.Fatalf("expected only the open alert, got %+v", resp.Alerts)
+	}
+	alert := resp.Alerts[0]
+	if alert.ID != open || alert.TransactionID != held.ID || alert.Amount != 60000 || alert.Kind != domain.TransactionKindTransfer || alert.Rule != "large_amount" {
+		t.Fatalf("expected alert %s on transfer %s of 60000, got %+v", open, held.ID, alert)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminRejectKYC(
+	store repo.Store,
+) *AdminRejectKYC {
+	return &AdminRejectKYC{
+		store: store,
+	}
+}
+
+// AdminRejectKYC rejects a customer under review, confirming their open
+// screening hits as true matches. Unlike approving, it takes effect right
+// away, the customer can re-submit documents.
+type AdminRejectKYC struct {
+	store repo.Store
+}
+
+type AdminRejectKYCRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AdminRejectKYC) Handle(c *gin.Context) {
+	// Validate request.
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	var req AdminRejectKYCRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if req.Reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing reason")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	customer, err := tx.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.BackOffice().ReviewOpenHits(c, customerID, domain.ScreeningReviewStatusConfirmed); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	err = tx.Customers().TransitionKYC(c, customerID, customer.KYCStatus, domain.KYCStatusRejected, req.Reason, adminActor(c))
+	switch {
+	case errors.Is(err, kyc.ErrIllegalTransition), errors.Is(err, kyc.ErrStatusChanged):
+		c.AbortWithStatusJSON(http.StatusConflict, "customer is not under review")
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+func TestAdminRejectKYC(t *testing.T) {
+	const body = `{"reason": "sanctioned"}`
+
+	// Arrange.
+	m := repo.NewMemory()
+	approved, _ := addCustomer(m, domain.KYCStatusApproved, 0)
+	inReview, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	m.AddScreeningHits(inReview, screening.Hit{ListName: "un", EntryID: "1", EntryName: "Someone", Score: 0.97, Strength: screening.HitStrengthStrong})
+	h := NewAdminRejectKYC(m)
+
+	tests := []struct {
+		name       string
+		customerID string
+		body       string
+		wantCode   int
+	}{
+		{name: "malformed customer id", customerID: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "missing reason", customerID: inReview.String(), body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "not found", customerID: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "not under review", customerID: approved.String(), body: body, wantCode: http.StatusConflict},
+		{name: "rejected", customerID: inReview.String(), body: body, wantCode: http.StatusNoContent},
+		{name: "already rejected", customerID: inReview.String(), body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/customers/" + tt.customerID + "/kyc/reject"
+
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/customers/:id/kyc/reject", h.Handle, newAdminRequest(http.MethodPost, path, tt.body, uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+		})
+	}
+
+	customer, err := m.Customers().Get(context.Background(), inReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customer.KYCStatus != domain.KYCStatusRejected {
+		t.Fatalf("expected KYC %s, got %s", domain.KYCStatusRejected, customer.KYCStatus)
+	}
+	if hits := m.ScreeningHits(inReview); hits[0].ReviewStatus != domain.ScreeningReviewStatusConfirmed {
+		t.Fatalf("expected the hit confirmed, got %+v", hits)
+	}
+}
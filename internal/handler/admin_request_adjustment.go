@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminRequestAdjustment(
+	store repo.Store,
+) *AdminRequestAdjustment {
+	return &AdminRequestAdjustment{
+		store: store,
+	}
+}
+
+// AdminRequestAdjustment asks for a manual correction of a customer account's
+// balance, credited from or debited to the internal suspense account. It's
+// posted once another operator approves it, see AdminDecideApproval. The
+// attachment reference points to the evidence (ticket, bank letter...).
+type AdminRequestAdjustment struct {
+	store repo.Store
+}
+
+type AdminRequestAdjustmentRequest struct {
+	Direction     domain.AdjustmentDirection `json:"direction"`
+	Amount        string                     `json:"amount"` // Decimal e.g. "12.34".
+	ReasonCode    domain.AdjustmentReason    `json:"reason_code"`
+	AttachmentRef string                     `json:"attachment_ref"`
+	Description   string                     `json:"description,omitempty"` // Shown to the customer.
+	Reason        string                     `json:"reason"`                // For the checker.
+}
+
+func (h *AdminRequestAdjustment) Handle(c *gin.Context) {
+	// Validate request.
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var req AdminRequestAdjustmentRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	amount, err := domain.ParseAmount(req.Amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing reason")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	account, found, err := lockCustomerAccount(c, tx, number)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+
+	payload := admin.AdjustmentPayload{
+		AccountID:     account.ID,
+		AccountNumber: account.Number,
+		Direction:     req.Direction,
+		Amount:        amount,
+		Currency:      account.Currency,
+		ReasonCode:    req.ReasonCode,
+		AttachmentRef: req.AttachmentRef,
+		Description:   req.Description,
+	}
+	if err := payload.Validate(); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	approval, err := tx.BackOffice().RequestApproval(c, domain.ApprovalKindAdjustment, account.ID.String(), payload, req.Reason, adminID(c))
+	switch {
+	case errors.Is(err, admin.ErrAlreadyRequested):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, approval)
+}
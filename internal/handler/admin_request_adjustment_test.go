@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminRequestAdjustment(t *testing.T) {
+	body := fmt.Sprintf(`{"direction": %q, "amount": "12.34", "reason_code": %q, "attachment_ref": "TICKET-1", "reason": "outage on May 1st"}`,
+		domain.AdjustmentDirectionCredit, domain.AdjustmentReasonGoodwill)
+
+	// Arrange.
+	m := repo.NewMemory()
+	_, account := addCustomer(m, domain.KYCStatusApproved, 0)
+	h := NewAdminRequestAdjustment(m)
+
+	tests := []struct {
+		name     string
+		number   string
+		body     string
+		wantCode int
+	}{
+		{name: "malformed number", number: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "malformed amount", number: account.Number, body: `{"amount": "x", "reason": "typo"}`, wantCode: http.StatusBadRequest},
+		{name: "missing reason", number: account.Number, body: `{"amount": "1.00"}`, wantCode: http.StatusBadRequest},
+		{name: "not found", number: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "invalid direction", number: account.Number, body: `{"direction": "up", "amount": "1.00", "reason_code": "goodwill", "attachment_ref": "TICKET-1", "reason": "typo"}`, wantCode: http.StatusBadRequest},
+		{name: "requested", number: account.Number, body: body, wantCode: http.StatusAccepted},
+		{name: "already requested", number: account.Number, body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/accounts/" + tt.number + "/adjustments"
+
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/accounts/:number/adjustments", h.Handle, newAdminRequest(http.MethodPost, path, tt.body, uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			a := decode[admin.Approval](t, w)
+			var p admin.AdjustmentPayload
+			if err := json.Unmarshal(a.Payload, &p); err != nil {
+				t.Fatal(err)
+			}
+			if a.Kind != domain.ApprovalKindAdjustment || p.AccountID != account.ID || p.Amount != 1234 || p.Currency != account.Currency {
+				t.Fatalf("expected an adjustment of 1234 %s on %s, got %+v", account.Currency, account.Number, p)
+			}
+		})
+	}
+
+	if txs := m.Transactions(); len(txs) != 0 {
+		t.Fatalf("expected nothing posted until the checker approves, got %+v", txs)
+	}
+}
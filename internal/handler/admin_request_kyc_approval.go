@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminRequestKYCApproval(
+	store repo.Store,
+) *AdminRequestKYCApproval {
+	return &AdminRequestKYCApproval{
+		store: store,
+	}
+}
+
+// AdminRequestKYCApproval asks for a customer under review to be approved,
+// i.e. their open screening hits to be dismissed as false positives. It takes
+// effect once another operator approves it, see AdminDecideApproval.
+type AdminRequestKYCApproval struct {
+	store repo.Store
+}
+
+type AdminRequestKYCApprovalRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AdminRequestKYCApproval) Handle(c *gin.Context) {
+	// Validate request.
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	var req AdminRequestKYCApprovalRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if req.Reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing reason")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	customer, err := tx.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus != domain.KYCStatusInProgress {
+		c.AbortWithStatusJSON(http.StatusConflict, "customer is not under review")
+		return
+	}
+	// Fail early, the checker couldn't approve it either.
+	docs, err := tx.Customers().Documents(c, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if missing := kyc.Missing(docs); len(missing) > 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, "missing documents: "+strings.Join(missing, ", "))
+		return
+	}
+
+	approval, err := tx.BackOffice().RequestApproval(c, domain.ApprovalKindKYCApprove, customerID.String(), admin.KYCApprovePayload{
+		CustomerID: customerID,
+	}, req.Reason, adminID(c))
+	switch {
+	case errors.Is(err, admin.ErrAlreadyRequested):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, approval)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminRequestKYCApproval(t *testing.T) {
+	const body = `{"reason": "false positive, different birth date"}`
+
+	// Arrange.
+	m := repo.NewMemory()
+	maker := uuid.New()
+	pending, _ := addCustomer(m, domain.KYCStatusPending, 0)
+	noDocuments, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	inReview, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	addDocument(t, m, inReview, domain.KYCDocumentKindPassport)
+	addDocument(t, m, inReview, domain.KYCDocumentKindProofOfAddress)
+	h := NewAdminRequestKYCApproval(m)
+
+	tests := []struct {
+		name       string
+		customerID string
+		body       string
+		wantCode   int
+	}{
+		{name: "malformed customer id", customerID: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "missing reason", customerID: inReview.String(), body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "not found", customerID: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "not under review", customerID: pending.String(), body: body, wantCode: http.StatusConflict},
+		{name: "missing documents", customerID: noDocuments.String(), body: body, wantCode: http.StatusConflict},
+		{name: "requested", customerID: inReview.String(), body: body, wantCode: http.StatusAccepted},
+		{name: "already requested", customerID: inReview.String(), body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/customers/" + tt.customerID + "/kyc/approve"
+
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/customers/:id/kyc/approve", h.Handle, newAdminRequest(http.MethodPost, path, tt.body, maker))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			a := decode[admin.Approval](t, w)
+			if a.Kind != domain.ApprovalKindKYCApprove || a.TargetID != inReview.String() || a.MakerID != maker || a.Status != domain.ApprovalStatusPending {
+				t.Fatalf("expected a pending KYC approval of %s made by %s, got %+v", inReview, maker, a)
+			}
+		})
+	}
+
+	customer, err := m.Customers().Get(context.Background(), inReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customer.KYCStatus != domain.KYCStatusInProgress {
+		t.Fatalf("expected the customer to stay %s until the checker approves, got %s", domain.KYCStatusInProgress, customer.KYCStatus)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminRequestKYCTierChange(
+	store repo.Store,
+) *AdminRequestKYCTierChange {
+	return &AdminRequestKYCTierChange{
+		store: store,
+	}
+}
+
+// AdminRequestKYCTierChange asks for an approved customer to move to another
+// KYC tier, e.g. after enhanced due diligence, which changes their limits and
+// fees. It takes effect once another operator approves it, see
+// AdminDecideApproval.
+type AdminRequestKYCTierChange struct {
+	store repo.Store
+}
+
+type AdminRequestKYCTierChangeRequest struct {
+	Tier   domain.KYCTier `json:"tier"`
+	Reason string         `json:"reason"`
+}
+
+func (h *AdminRequestKYCTierChange) Handle(c *gin.Context) {
+	// Validate request.
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	var req AdminRequestKYCTierChangeRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if !req.Tier.Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "invalid tier")
+		return
+	}
+	if req.Reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing reason")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	customer, err := tx.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusConflict, "customer is not approved")
+		return
+	}
+	if customer.KYCTier == req.Tier {
+		c.AbortWithStatusJSON(http.StatusConflict, "customer is already in tier "+string(customer.KYCTier))
+		return
+	}
+
+	approval, err := tx.BackOffice().RequestApproval(c, domain.ApprovalKindKYCTierChange, customerID.String(), admin.KYCTierChangePayload{
+		CustomerID: customerID,
+		From:       customer.KYCTier,
+		To:         req.Tier,
+	}, req.Reason, adminID(c))
+	switch {
+	case errors.Is(err, admin.ErrAlreadyRequested):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, approval)
+}
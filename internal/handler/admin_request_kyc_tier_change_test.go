@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminRequestKYCTierChange(t *testing.T) {
+	body := fmt.Sprintf(`{"tier": %q, "reason": "proof of income"}`, domain.KYCTierEnhanced)
+
+	// Arrange.
+	m := repo.NewMemory()
+	inReview, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	approved, _ := addCustomer(m, domain.KYCStatusApproved, 0)
+	h := NewAdminRequestKYCTierChange(m)
+
+	tests := []struct {
+		name       string
+		customerID string
+		body       string
+		wantCode   int
+	}{
+		{name: "malformed customer id", customerID: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "invalid tier", customerID: approved.String(), body: `{"tier": "gold", "reason": "vip"}`, wantCode: http.StatusBadRequest},
+		{name: "missing reason", customerID: approved.String(), body: fmt.Sprintf(`{"tier": %q}`, domain.KYCTierEnhanced), wantCode: http.StatusBadRequest},
+		{name: "not found", customerID: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "not approved", customerID: inReview.String(), body: body, wantCode: http.StatusConflict},
+		{name: "same tier", customerID: approved.String(), body: fmt.Sprintf(`{"tier": %q, "reason": "no change"}`, domain.KYCTierBasic), wantCode: http.StatusConflict},
+		{name: "requested", customerID: approved.String(), body: body, wantCode: http.StatusAccepted},
+		{name: "already requested", customerID: approved.String(), body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/customers/" + tt.customerID + "/kyc/tier"
+
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/customers/:id/kyc/tier", h.Handle, newAdminRequest(http.MethodPost, path, tt.body, uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			a := decode[admin.Approval](t, w)
+			want := fmt.Sprintf(`{"customer_id":%q,"from":%q,"to":%q}`, approved, domain.KYCTierBasic, domain.KYCTierEnhanced)
+			if a.Kind != domain.ApprovalKindKYCTierChange || string(a.Payload) != want {
+				t.Fatalf("expected a tier change with payload %s, got %+v", want, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminRequestUnfreeze(
+	store repo.Store,
+) *AdminRequestUnfreeze {
+	return &AdminRequestUnfreeze{
+		store: store,
+	}
+}
+
+// AdminRequestUnfreeze asks for a frozen account to be unfrozen. It takes
+// effect once another operator approves it, see AdminDecideApproval.
+type AdminRequestUnfreeze struct {
+	store repo.Store
+}
+
+type AdminRequestUnfreezeRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AdminRequestUnfreeze) Handle(c *gin.Context) {
+	// Validate request.
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var req AdminRequestUnfreezeRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if req.Reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing reason")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	account, found, err := lockCustomerAccount(c, tx, number)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+	if account.Status != domain.AccountStatusFrozen {
+		c.AbortWithStatusJSON(http.StatusConflict, "account is not frozen")
+		return
+	}
+
+	approval, err := tx.BackOffice().RequestApproval(c, domain.ApprovalKindAccountUnfreeze, account.ID.String(), admin.AccountUnfreezePayload{
+		AccountID:     account.ID,
+		AccountNumber: account.Number,
+	}, req.Reason, adminID(c))
+	switch {
+	case errors.Is(err, admin.ErrAlreadyRequested):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, approval)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminRequestUnfreeze(t *testing.T) {
+	const body = `{"reason": "card found"}`
+
+	// Arrange.
+	m := repo.NewMemory()
+	_, active := addCustomer(m, domain.KYCStatusApproved, 0)
+	_, frozen := addCustomer(m, domain.KYCStatusApproved, 0)
+	if err := m.Accounts().Freeze(context.Background(), frozen.ID, "card reported stolen"); err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminRequestUnfreeze(m)
+
+	tests := []struct {
+		name     string
+		number   string
+		body     string
+		wantCode int
+	}{
+		{name: "malformed number", number: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "missing reason", number: frozen.Number, body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "not found", number: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "not frozen", number: active.Number, body: body, wantCode: http.StatusConflict},
+		{name: "requested", number: frozen.Number, body: body, wantCode: http.StatusAccepted},
+		{name: "already requested", number: frozen.Number, body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/admin/v1/accounts/" + tt.number + "/unfreeze"
+
+			// Act.
+			w := serve(http.MethodPost, "/admin/v1/accounts/:number/unfreeze", h.Handle, newAdminRequest(http.MethodPost, path, tt.body, uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			a := decode[admin.Approval](t, w)
+			if a.Kind != domain.ApprovalKindAccountUnfreeze || a.TargetID != frozen.ID.String() {
+				t.Fatalf("expected an unfreeze of %s, got %+v", frozen.ID, a)
+			}
+		})
+	}
+
+	stillFrozen, err := m.Accounts().ListFrozen(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stillFrozen) != 1 {
+		t.Fatalf("expected the account frozen until the checker approves, got %+v", stillFrozen)
+	}
+}
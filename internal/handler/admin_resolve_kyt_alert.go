@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewAdminResolveKYTAlert(
+	store repo.Store,
+	resolution domain.KYTAlertStatus,
+) *AdminResolveKYTAlert {
+	return &AdminResolveKYTAlert{
+		store:      store,
+		resolution: resolution,
+	}
+}
+
+// AdminResolveKYTAlert works an open KYT alert: a cleared alert clears the
+// transaction it held back, a blocked alert fails it (releasing the reserved
+// funds).
+type AdminResolveKYTAlert struct {
+	store      repo.Store
+	resolution domain.KYTAlertStatus
+}
+
+type AdminResolveKYTAlertRequest struct {
+	Note string `json:"note"`
+}
+
+func (h *AdminResolveKYTAlert) Handle(c *gin.Context) {
+	// Validate request.
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed alert id")
+		return
+	}
+	var req AdminResolveKYTAlertRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if req.Note == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing note")
+		return
+	}
+
+	tx, err := beginAdminWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	alert, err := tx.BackOffice().LockKYTAlert(c, id)
+	if errors.Is(err, repo.ErrKYTAlertNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "alert not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if alert.Status != domain.KYTAlertStatusOpen {
+		c.AbortWithStatusJSON(http.StatusConflict, "alert already "+string(alert.Status))
+		return
+	}
+
+	if h.resolution == domain.KYTAlertStatusCleared {
+		err = tx.Ledger().Clear(c, alert.TransactionID)
+	} else {
+		err = tx.Ledger().Fail(c, alert.TransactionID, "blocked by KYT review: "+req.Note)
+	}
+	switch {
+	case errors.Is(err, ledger.ErrNotPending):
+		c.AbortWithStatusJSON(http.StatusConflict, "transaction is no longer pending")
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := tx.BackOffice().ResolveKYTAlert(c, id, h.resolution, adminID(c), req.Note); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
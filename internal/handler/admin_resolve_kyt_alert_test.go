@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestAdminResolveKYTAlert(t *testing.T) {
+	const body = `{"note": "checked with the customer"}`
+
+	// Arrange.
+	m := repo.NewMemory()
+	_, from := addCustomer(m, domain.KYCStatusApproved, 100000)
+	_, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	toClear := pendingTransfer(t, m, from, to, 60000)
+	toBlock := pendingTransfer(t, m, from, to, 20000)
+	alreadyCleared := clearedTransfer(t, m, from, to, 100)
+	clearAlert := m.AddKYTAlert(toClear.ID, "large_amount", "over 500.00")
+	blockAlert := m.AddKYTAlert(toBlock.ID, "velocity", "3 transfers in a minute")
+	staleAlert := m.AddKYTAlert(alreadyCleared.ID, "velocity", "3 transfers in a minute")
+	clear := NewAdminResolveKYTAlert(m, domain.KYTAlertStatusCleared)
+	block := NewAdminResolveKYTAlert(m, domain.KYTAlertStatusBlocked)
+
+	tests := []struct {
+		name     string
+		handler  *AdminResolveKYTAlert
+		action   string
+		alertID  string
+		body     string
+		wantCode int
+	}{
+		{name: "malformed alert id", handler: clear, action: "clear", alertID: "nope", body: body, wantCode: http.StatusBadRequest},
+		{name: "missing note", handler: clear, action: "clear", alertID: clearAlert.String(), body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "not found", handler: clear, action: "clear", alertID: uuid.NewString(), body: body, wantCode: http.StatusNotFound},
+		{name: "transaction no longer pending", handler: clear, action: "clear", alertID: staleAlert.String(), body: body, wantCode: http.StatusConflict},
+		{name: "cleared", handler: clear, action: "clear", alertID: clearAlert.String(), body: body, wantCode: http.StatusNoContent},
+		{name: "blocked", handler: block, action: "block", alertID: blockAlert.String(), body: body, wantCode: http.StatusNoContent},
+		{name: "already resolved", handler: block, action: "block", alertID: clearAlert.String(), body: body, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := "/admin/v1/kyt-alerts/:id/" + tt.action
+			path := "/admin/v1/kyt-alerts/" + tt.alertID + "/" + tt.action
+
+			// Act.
+			w := serve(http.MethodPost, route, tt.handler.Handle, newAdminRequest(http.MethodPost, path, tt.body, uuid.New()))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+		})
+	}
+
+	statuses := map[uuid.UUID]domain.TransactionStatus{}
+	for _, tr := range m.Transactions() {
+		statuses[tr.ID] = tr.Status
+	}
+	if statuses[toClear.ID] != domain.TransactionStatusCleared {
+		t.Fatalf("expected the cleared alert's transfer %s, got %s", domain.TransactionStatusCleared, statuses[toClear.ID])
+	}
+	if statuses[toBlock.ID] != domain.TransactionStatusFailed {
+		t.Fatalf("expected the blocked alert's transfer %s, got %s", domain.TransactionStatusFailed, statuses[toBlock.ID])
+	}
+	if held := m.Held(from.ID); held != 0 {
+		t.Fatalf("expected no funds held, got %d", held)
+	}
+	stale, err := m.BackOffice().LockKYTAlert(context.Background(), staleAlert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale.Status != domain.KYTAlertStatusOpen {
+		t.Fatalf("expected the stale alert to stay %s, got %s", domain.KYTAlertStatusOpen, stale.Status)
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+// beginAuditedWork begins a unit of work whose changes are attributed to the
+// client app that signed the request, see package audit. Handlers that change
+// state always go through it, even for a single statement.
+func beginAuditedWork(c *gin.Context, store repo.Store) (repo.Tx, error) {
+	return store.Begin(c, repo.Audit{
+		Actor:     apiActor(c),
+		Action:    apiAction(c),
+		RequestID: c.GetHeader("X-Request-ID"),
+	})
+}
+
+// apiAction is the route the request was made to.
+func apiAction(c *gin.Context) string {
+	return c.Request.Method + " " + c.FullPath()
+}
+
+// apiActor is the client app that signed the request.
+func apiActor(c *gin.Context) audit.Actor {
+	return audit.Actor{Type: audit.ActorTypeAPIKey, ID: c.GetHeader("BestWallet-Key-ID")}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewCancelScheduledTransfer(
+	store repo.Store,
+) *CancelScheduledTransfer {
+	return &CancelScheduledTransfer{
+		store: store,
+	}
+}
+
+// CancelScheduledTransfer stops a scheduled transfer for good. It's kept
+// around (with its runs) for the customer's history.
+type CancelScheduledTransfer struct {
+	store repo.Store
+}
+
+func (h *CancelScheduledTransfer) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed scheduled transfer id")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Waits for the scheduler if it's executing the transfer right now.
+	found, err := tx.ScheduledTransfers().Cancel(c, id, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "active or paused scheduled transfer not found")
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
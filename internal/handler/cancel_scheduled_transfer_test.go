@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	otherID, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	active := addScheduledTransfer(t, m, from, to, tomorrow, "", domain.ScheduledTransferStatusActive)
+	paused := addScheduledTransfer(t, m, from, to, tomorrow, "FREQ=DAILY;INTERVAL=1", domain.ScheduledTransferStatusPaused)
+	h := NewCancelScheduledTransfer(m)
+	path := func(id uuid.UUID) string { return "/api/v1/scheduled-transfers/" + id.String() + "/cancel" }
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		id         uuid.UUID
+		path       string
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(active), wantCode: http.StatusBadRequest},
+		{name: "malformed id", customerID: customerID, path: "/api/v1/scheduled-transfers/nope/cancel", wantCode: http.StatusBadRequest},
+		{name: "not found", customerID: customerID, path: path(uuid.New()), wantCode: http.StatusNotFound},
+		{name: "someone else's", customerID: otherID, path: path(active), wantCode: http.StatusNotFound},
+		{name: "active", customerID: customerID, id: active, path: path(active), wantCode: http.StatusNoContent},
+		{name: "paused", customerID: customerID, id: paused, path: path(paused), wantCode: http.StatusNoContent},
+		{name: "cancelled already", customerID: customerID, path: path(active), wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/scheduled-transfers/:id/cancel", h.Handle, newRequest(http.MethodPost, tt.path, "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusNoContent {
+				return
+			}
+			s := scheduledTransfer(t, m, tt.id, customerID)
+			if s.Status != domain.ScheduledTransferStatusCancelled || s.NextRunAt != nil {
+				t.Fatalf("expected a cancelled transfer without a next run, got %+v", s)
+			}
+		})
+	}
+}
@@ -1,34 +1,37 @@
 package handler
 
 import (
-	"context"
 	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/detod/best-wallet/internal/domain"
-	"github.com/detod/best-wallet/internal/util"
+	"github.com/detod/best-wallet/internal/repo"
 )
 
 func NewCreateAccount(
-	db *pgxpool.Pool,
+	store repo.Store,
 ) *CreateAccount {
 	return &CreateAccount{
-		db: db,
+		store: store,
 	}
 }
 
 type CreateAccount struct {
-	db *pgxpool.Pool
+	store repo.Store
+}
+
+type CreateAccountRequest struct {
+	// Product is "current" (default) or "savings".
+	Product domain.AccountProduct `json:"product,omitempty"`
 }
 
 type CreateAccountResponse struct {
-	ID     uuid.UUID `json:"id"`
-	Number string    `json:"number"`
+	ID      uuid.UUID             `json:"id"`
+	Number  string                `json:"number"`
+	Product domain.AccountProduct `json:"product"`
 }
 
 func (h *CreateAccount) Handle(c *gin.Context) {
@@ -44,79 +47,75 @@ func (h *CreateAccount) Handle(c *gin.Context) {
 		return
 	}
 
+	// Validate request, the body is optional.
+	var req CreateAccountRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&req); err != nil {
+			return
+		}
+	}
+	switch req.Product {
+	case "":
+		req.Product = domain.AccountProductCurrent
+	case domain.AccountProductCurrent, domain.AccountProductSavings:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, "product must be current or savings")
+		return
+	}
+
 	// Only customers with approved KYC can open accounts.
-	kycStatus, exists, err := h.dbGetCustomerKYCStatus(c, customerID)
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	}
 	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
-	if !exists {
-		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not verified, try again later")
 		return
 	}
-	if kycStatus != domain.KYCStatusApproved {
-		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not verified, try again later")
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	defer tx.Rollback(c)
 
 	// Create new account.
-	id := uuid.New()
-	number := uuid.New().String()
-	if err = h.dbInsertAccount(c, dbInsertAccountArgs{
-		id:         id,
-		customerID: customerID,
-		number:     number,
-		balance:    0,
-	}); err != nil {
+	account, err := tx.Accounts().Create(c, repo.Account{
+		ID:         uuid.New(),
+		CustomerID: &customerID,
+		Number:     uuid.New().String(),
+		Product:    req.Product,
+	})
+	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
 
 	// Kickstart background notification.
-	go util.Recover(func() {
-		// TODO notify customer.
-	})
+	if err := tx.Outbox().Write(c, domain.EventTypeAccountOpened, account.ID, domain.AccountOpenedPayload{
+		AccountID:  account.ID,
+		CustomerID: customerID,
+		Number:     account.Number,
+	}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
 
 	// Return new account identifiers.
 	c.JSON(http.StatusCreated, CreateAccountResponse{
-		ID:     id,
-		Number: number,
+		ID:      account.ID,
+		Number:  account.Number,
+		Product: account.Product,
 	})
 }
-
-func (h *CreateAccount) dbGetCustomerKYCStatus(ctx context.Context, customerID uuid.UUID) (domain.KYCStatus, bool, error) {
-	sql := `SELECT kyc_status FROM customers WHERE id = $1`
-	var res domain.KYCStatus
-
-	err := h.db.QueryRow(ctx, sql, customerID).Scan(&res)
-	switch {
-	case errors.Is(err, pgx.ErrNoRows):
-		return "", false, nil
-	case err != nil:
-		return "", false, err
-	default:
-		return res, true, nil
-	}
-}
-
-type dbInsertAccountArgs struct {
-	id         uuid.UUID
-	customerID uuid.UUID
-	number     string
-	balance    int
-}
-
-func (h *CreateAccount) dbInsertAccount(ctx context.Context, args dbInsertAccountArgs) error {
-	sql := `
-		INSERT INTO accounts (id, customer_id, number, balance)
-		VALUES ($1, $2, $3, $4)`
-
-	_, err := h.db.Exec(ctx, sql,
-		args.id,
-		args.customerID,
-		args.number,
-		args.balance,
-	)
-
-	return err
-}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestCreateAccount(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	approvedID, _ := addCustomer(m, domain.KYCStatusApproved, 0)
+	pendingID, _ := addCustomer(m, domain.KYCStatusPending, 0)
+	h := NewCreateAccount(m)
+
+	tests := []struct {
+		name        string
+		customerID  uuid.UUID
+		body        string
+		wantCode    int
+		wantProduct domain.AccountProduct
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, wantCode: http.StatusBadRequest},
+		{name: "unknown product", customerID: approvedID, body: `{"product": "crypto"}`, wantCode: http.StatusBadRequest},
+		{name: "customer not found", customerID: uuid.New(), wantCode: http.StatusBadRequest},
+		{name: "customer not verified", customerID: pendingID, wantCode: http.StatusBadRequest},
+		{name: "current by default", customerID: approvedID, wantCode: http.StatusCreated, wantProduct: domain.AccountProductCurrent},
+		{name: "savings", customerID: approvedID, body: `{"product": "savings"}`, wantCode: http.StatusCreated, wantProduct: domain.AccountProductSavings},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/accounts", h.Handle, newRequest(http.MethodPost, "/api/v1/accounts", tt.body, tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			resp := decode[CreateAccountResponse](t, w)
+			account, err := m.Accounts().GetByNumber(context.Background(), resp.Number)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if account.ID != resp.ID || !account.BelongsTo(tt.customerID) || account.Product != tt.wantProduct {
+				t.Fatalf("unexpected account %+v", account)
+			}
+			if account.Balance != 0 || account.Currency != domain.DefaultCurrency {
+				t.Fatalf("expected an empty %s account, got %+v", domain.DefaultCurrency, account)
+			}
+			assertEvent(t, m, domain.EventTypeAccountOpened, account.ID)
+		})
+	}
+}
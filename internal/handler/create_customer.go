@@ -1,30 +1,36 @@
 package handler
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/detod/best-wallet/internal/domain"
-	"github.com/detod/best-wallet/internal/util"
+	"github.com/detod/best-wallet/internal/notify"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/repo"
 )
 
 func NewCreateCustomer(
-	db *pgxpool.Pool,
+	store repo.Store,
+	cipher *pii.Cipher,
 ) *CreateCustomer {
 	return &CreateCustomer{
-		db: db,
+		store:  store,
+		cipher: cipher,
 	}
 }
 
+// CreateCustomer registers a customer and starts their KYC. Personal data is
+// encrypted before it's stored, see package pii, and an email can only be
+// registered once.
 type CreateCustomer struct {
-	db *pgxpool.Pool
+	store  repo.Store
+	cipher *pii.Cipher
 }
 
 type CreateCustomerRequest struct {
@@ -33,6 +39,8 @@ type CreateCustomerRequest struct {
 	Email            string    `json:"email"`
 	ResidenceAddress string    `json:"residence_address"`
 	BirthDate        time.Time `json:"birth_date"`
+	Phone            string    `json:"phone,omitempty"`  // Optional, enables SMS notifications.
+	Locale           string    `json:"locale,omitempty"` // Optional, defaults to "en".
 }
 
 type CreateCustomerResponse struct {
@@ -46,95 +54,57 @@ func (h *CreateCustomer) Handle(c *gin.Context) {
 	}
 
 	// TODO validate request.
+	if req.Locale == "" {
+		req.Locale = notify.DefaultLocale
+	}
 
-	id := uuid.New()
-	if err := h.dbInsertCustomer(c, dbInsertCustomerArgs{
-		id:               id,
-		firstName:        req.FirstName,
-		lastName:         req.LastName,
-		email:            req.Email,
-		residenceAddress: req.ResidenceAddress,
-		birthDate:        req.BirthDate,
-		kycStatus:        domain.KYCStatusPending,
-	}); err != nil {
-		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("failed to insert customer in db: %w", err))
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	defer tx.Rollback(c)
 
-	go util.Recover(func() {
-		if err := h.dbUpdateKYCStatus(c, dbUpdateKYCStatusArgs{
-			id:        id,
-			oldStatus: domain.KYCStatusPending,
-			newStatus: domain.KYCStatusInProgress,
-		}); err != nil {
-			log.Println("failed to set KYC status in_progress", err)
-			return
-		}
-
-		// Simulate KYC process.
-		time.Sleep(time.Minute)
-
-		if err := h.dbUpdateKYCStatus(c, dbUpdateKYCStatusArgs{
-			id:        id,
-			oldStatus: domain.KYCStatusInProgress,
-			newStatus: domain.KYCStatusApproved,
-		}); err != nil {
-			log.Println("failed to set KYC status approved", err)
-			return
-		}
-
-		// TODO notify customer.
+	id := uuid.New()
+	sealed, err := h.cipher.Seal(c, id, pii.PII{
+		FirstName:        req.FirstName,
+		LastName:         req.LastName,
+		Email:            req.Email,
+		ResidenceAddress: req.ResidenceAddress,
+		BirthDate:        req.BirthDate,
 	})
-
-	c.JSON(http.StatusCreated, CreateCustomerResponse{ID: id})
-}
-
-type dbInsertCustomerArgs struct {
-	id               uuid.UUID
-	firstName        string
-	lastName         string
-	email            string
-	residenceAddress string
-	birthDate        time.Time
-	kycStatus        domain.KYCStatus
-}
-
-func (h *CreateCustomer) dbInsertCustomer(ctx context.Context, args dbInsertCustomerArgs) error {
-	sql := `
-		INSERT INTO customers (id, first_name, last_name, email, residence_address, birth_date, kyc_status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
-
-	_, err := h.db.Exec(ctx, sql,
-		args.id,
-		args.firstName,
-		args.lastName,
-		args.email,
-		args.residenceAddress,
-		args.birthDate,
-		args.kycStatus,
-	)
-
-	return err
-}
-
-type dbUpdateKYCStatusArgs struct {
-	id        uuid.UUID
-	oldStatus domain.KYCStatus
-	newStatus domain.KYCStatus
-}
-
-func (h *CreateCustomer) dbUpdateKYCStatus(ctx context.Context, args dbUpdateKYCStatusArgs) error {
-	sql := `
-		UPDATE customers SET kyc_status = $1, updated_at = now()
-		WHERE id = $2 AND kyc_status = $3`
-
-	res, err := h.db.Exec(ctx, sql, args.newStatus, args.id, args.oldStatus)
 	if err != nil {
-		return err
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	err = tx.Customers().Create(c, repo.NewCustomer{
+		ID:          id,
+		PII:         sealed,
+		Phone:       req.Phone,
+		Locale:      req.Locale,
+		ClientKeyID: apiActor(c).ID, // Only this app can act for the customer and gets its events.
+	}, apiActor(c))
+	if errors.Is(err, repo.ErrEmailTaken) {
+		c.AbortWithStatusJSON(http.StatusConflict, "email already registered")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("failed to insert customer in db: %w", err))
+		return
 	}
-	if ra := res.RowsAffected(); ra != 1 {
-		return fmt.Errorf("expected rows affected to be 1, got %d", ra)
+
+	// Kickstart the KYC process in the background (see consumer.RunKYC).
+	if err := tx.Outbox().Write(c, domain.EventTypeCustomerCreated, id, domain.CustomerCreatedPayload{
+		CustomerID: id,
+	}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
 	}
 
-	return nil
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateCustomerResponse{ID: id})
 }
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func newTestCipher(t *testing.T) *pii.Cipher {
+	t.Helper()
+	kms, err := pii.NewLocalKMS(map[string][]byte{"kek-1": bytes.Repeat([]byte{1}, 32)}, "kek-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := pii.NewCipher(kms, bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func TestCreateCustomer(t *testing.T) {
+	const body = `{"first_name": "Ada", "last_name": "Lovelace", "email": "ada@example.com",
+		"residence_address": "London", "birth_date": "1815-12-10T00:00:00Z"}`
+
+	// Arrange.
+	m := repo.NewMemory()
+	h := NewCreateCustomer(m, newTestCipher(t))
+	req := newRequest(http.MethodPost, "/api/v1/customers", body, uuid.Nil)
+	req.Header.Set("X-Request-ID", "req-1")
+
+	// Act.
+	w := serve(http.MethodPost, "/api/v1/customers", h.Handle, req)
+
+	// Assert.
+	assertCode(t, w, http.StatusCreated)
+	resp := decode[CreateCustomerResponse](t, w)
+	customer, err := m.Customers().Get(context.Background(), resp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if customer.KYCStatus != domain.KYCStatusPending {
+		t.Fatalf("expected KYC %s, got %s", domain.KYCStatusPending, customer.KYCStatus)
+	}
+	if customer.ClientKeyID != testKeyID {
+		t.Fatalf("expected the customer to belong to %s, got %q", testKeyID, customer.ClientKeyID)
+	}
+	assertEvent(t, m, domain.EventTypeCustomerCreated, resp.ID)
+	audits := m.Audits()
+	if len(audits) != 1 || audits[0].Actor.ID != testKeyID || audits[0].Action != "POST /api/v1/customers" || audits[0].RequestID != "req-1" {
+		t.Fatalf("expected the creation to be attributed to the client app, got %+v", audits)
+	}
+
+	// Act.
+	w = serve(http.MethodPost, "/api/v1/customers", h.Handle, newRequest(http.MethodPost, "/api/v1/customers", body, uuid.Nil))
+
+	// Assert.
+	assertCode(t, w, http.StatusConflict)
+}
+
+func TestCreateCustomer_MalformedBody(t *testing.T) {
+	// Arrange.
+	h := NewCreateCustomer(repo.NewMemory(), newTestCipher(t))
+	req := newRequest(http.MethodPost, "/api/v1/customers", `{"birth_date": "yesterday"}`, uuid.Nil)
+
+	// Act.
+	w := serve(http.MethodPost, "/api/v1/customers", h.Handle, req)
+
+	// Assert.
+	assertCode(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+func NewCreateScheduledTransfer(
+	store repo.Store,
+) *CreateScheduledTransfer {
+	return &CreateScheduledTransfer{
+		store: store,
+	}
+}
+
+// CreateScheduledTransfer schedules a one-off transfer in the future, or a
+// recurring one (standing order) when a recurrence rule is given. Funds are
+// only checked when an occurrence is executed.
+type CreateScheduledTransfer struct {
+	store repo.Store
+}
+
+type CreateScheduledTransferRequest struct {
+	FromAccount string    `json:"from_account"`
+	ToAccount   string    `json:"to_account"`
+	Amount      string    `json:"amount"` // Decimal e.g. "12.34".
+	Description string    `json:"description,omitempty"`
+	StartAt     time.Time `json:"start_at"` // The first (or only) occurrence.
+	// Recurrence is an RRULE e.g. "FREQ=MONTHLY;COUNT=12", see
+	// util.ParseRecurrence. Leave empty for a one-off transfer.
+	Recurrence string `json:"recurrence,omitempty"`
+	// OnInsufficientFunds is "skip" (default) or "retry".
+	OnInsufficientFunds domain.InsufficientFundsPolicy `json:"on_insufficient_funds,omitempty"`
+}
+
+type CreateScheduledTransferResponse struct {
+	ID        uuid.UUID `json:"id"`
+	NextRunAt time.Time `json:"next_run_at"`
+}
+
+func (h *CreateScheduledTransfer) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Validate request.
+	var req CreateScheduledTransferRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if err := domain.ValidateAccountNumber(req.FromAccount); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := domain.ValidateAccountNumber(req.ToAccount); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.FromAccount == req.ToAccount {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "can't transfer to the same account")
+		return
+	}
+	amount, err := domain.ParseAmount(req.Amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.StartAt.Before(time.Now().Add(-time.Minute)) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "start_at must be in the future")
+		return
+	}
+	var recurrence *string
+	nextRunAt := req.StartAt
+	if req.Recurrence != "" {
+		r, err := util.ParseRecurrence(req.Recurrence)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "invalid recurrence: "+err.Error())
+			return
+		}
+		// An UNTIL before start_at leaves nothing to run.
+		first, ok := r.Occurrence(req.StartAt, 0)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "recurrence ends before start_at")
+			return
+		}
+		nextRunAt = first
+		canonical := r.String()
+		recurrence = &canonical
+	}
+	switch req.OnInsufficientFunds {
+	case "":
+		req.OnInsufficientFunds = domain.InsufficientFundsPolicySkip
+	case domain.InsufficientFundsPolicySkip, domain.InsufficientFundsPolicyRetry:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, "on_insufficient_funds must be skip or retry")
+		return
+	}
+
+	// Only customers with approved KYC can move money.
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not verified")
+		return
+	}
+
+	// Resolve accounts, the source account must belong to the customer.
+	from, err := h.store.Accounts().GetByNumber(c, req.FromAccount)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !from.BelongsTo(customerID) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "account not found")
+		return
+	}
+	to, err := h.store.Accounts().GetByNumber(c, req.ToAccount)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || to.Kind != domain.AccountKindCustomer {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "destination account not found")
+		return
+	}
+	if from.Currency != to.Currency {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "accounts are in different currencies")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	id := uuid.New()
+	if err := tx.ScheduledTransfers().Create(c, repo.NewScheduledTransfer{
+		ID:                  id,
+		CustomerID:          customerID,
+		FromAccountID:       from.ID,
+		ToAccountID:         to.ID,
+		Amount:              amount,
+		Currency:            from.Currency,
+		Description:         req.Description,
+		StartAt:             req.StartAt,
+		Recurrence:          recurrence,
+		OnInsufficientFunds: req.OnInsufficientFunds,
+	}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateScheduledTransferResponse{
+		ID:        id,
+		NextRunAt: nextRunAt,
+	})
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	_, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	pendingID, pendingAccount := addCustomer(m, domain.KYCStatusPending, 1000)
+	h := NewCreateScheduledTransfer(m)
+	tomorrow := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	body := func(from, to string, startAt time.Time, extra string) string {
+		return fmt.Sprintf(`{"from_account": %q, "to_account": %q, "amount": "1.00", "start_at": %q%s}`, from, to, startAt.Format(time.RFC3339), extra)
+	}
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		body       string
+		wantCode   int
+		wantRule   string // Canonical, empty for one-off transfers.
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, body: body(from.Number, to.Number, tomorrow, ""), wantCode: http.StatusBadRequest},
+		{name: "same account", customerID: customerID, body: body(from.Number, from.Number, tomorrow, ""), wantCode: http.StatusBadRequest},
+		{name: "in the past", customerID: customerID, body: body(from.Number, to.Number, tomorrow.Add(-48*time.Hour), ""), wantCode: http.StatusBadRequest},
+		{name: "invalid recurrence", customerID: customerID, body: body(from.Number, to.Number, tomorrow, `, "recurrence": "FREQ=HOURLY"`), wantCode: http.StatusBadRequest},
+		{name: "recurrence ends before start", customerID: customerID, body: body(from.Number, to.Number, tomorrow, `, "recurrence": "FREQ=DAILY;UNTIL=20200101"`), wantCode: http.StatusBadRequest},
+		{name: "unknown policy", customerID: customerID, body: body(from.Number, to.Number, tomorrow, `, "on_insufficient_funds": "panic"`), wantCode: http.StatusBadRequest},
+		{name: "customer not found", customerID: uuid.New(), body: body(from.Number, to.Number, tomorrow, ""), wantCode: http.StatusBadRequest},
+		{name: "customer not verified", customerID: pendingID, body: body(pendingAccount.Number, to.Number, tomorrow, ""), wantCode: http.StatusBadRequest},
+		{name: "someone else's account", customerID: customerID, body: body(to.Number, from.Number, tomorrow, ""), wantCode: http.StatusBadRequest},
+		{name: "destination not found", customerID: customerID, body: body(from.Number, uuid.NewString(), tomorrow, ""), wantCode: http.StatusBadRequest},
+		{name: "one-off", customerID: customerID, body: body(from.Number, to.Number, tomorrow, ""), wantCode: http.StatusCreated},
+		{
+			name:       "recurring",
+			customerID: customerID,
+			body:       body(from.Number, to.Number, tomorrow, `, "recurrence": "freq=monthly;count=3"`),
+			wantCode:   http.StatusCreated,
+			wantRule:   "FREQ=MONTHLY;INTERVAL=1;COUNT=3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/scheduled-transfers", h.Handle, newRequest(http.MethodPost, "/api/v1/scheduled-transfers", tt.body, tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			resp := decode[CreateScheduledTransferResponse](t, w)
+			if !resp.NextRunAt.Equal(tomorrow) {
+				t.Fatalf("expected the first run at %s, got %s", tomorrow, resp.NextRunAt)
+			}
+			s := scheduledTransfer(t, m, resp.ID, customerID)
+			if s.Status != domain.ScheduledTransferStatusActive || s.FromAccount != from.Number || s.ToAccount != to.Number || s.Amount != 100 {
+				t.Fatalf("expected an active transfer of 1.00 from %s to %s, got %+v", from.Number, to.Number, s)
+			}
+			if s.OnInsufficientFunds != domain.InsufficientFundsPolicySkip {
+				t.Fatalf("expected policy %s, got %s", domain.InsufficientFundsPolicySkip, s.OnInsufficientFunds)
+			}
+			var rule string
+			if s.Recurrence != nil {
+				rule = *s.Recurrence
+			}
+			if rule != tt.wantRule {
+				t.Fatalf("expected recurrence %q, got %q", tt.wantRule, rule)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/util"
+	"github.com/detod/best-wallet/internal/webhook"
+)
+
+func NewCreateWebhook(
+	store repo.Store,
+) *CreateWebhook {
+	return &CreateWebhook{
+		store: store,
+	}
+}
+
+type CreateWebhook struct {
+	store repo.Store
+}
+
+type CreateWebhookRequest struct {
+	URL        string             `json:"url"`
+	EventTypes []domain.EventType `json:"event_types"`
+}
+
+type CreateWebhookResponse struct {
+	ID uuid.UUID `json:"id"`
+	// Secret is used to sign deliveries once base64 decoded, see
+	// middleware.HMACVerifier for the scheme. It's only returned once, store
+	// it safely.
+	Secret string `json:"secret"`
+}
+
+var webhookEventTypes = map[domain.EventType]bool{
+	domain.EventTypeCustomerCreated:     true,
+	domain.EventTypeKYCStatusChanged:    true,
+	domain.EventTypeKYCDocumentUploaded: true,
+	domain.EventTypeAccountOpened:       true,
+	domain.EventTypeTransactionPosted:   true,
+}
+
+func (h *CreateWebhook) Handle(c *gin.Context) {
+	// Subscriptions belong to the client app that signed the request.
+	clientKeyID := c.GetHeader("BestWallet-Key-ID")
+	if clientKeyID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing key id")
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "invalid url: "+err.Error())
+		return
+	}
+	if len(req.EventTypes) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing event types")
+		return
+	}
+	for _, t := range req.EventTypes {
+		if !webhookEventTypes[t] {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "unknown event type "+string(t))
+			return
+		}
+	}
+
+	key, err := util.NewKeyHMAC(32)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	secret := base64.StdEncoding.EncodeToString(key)
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	id := uuid.New()
+	if err := tx.Webhooks().Create(c, repo.NewWebhookSubscription{
+		ID:          id,
+		ClientKeyID: clientKeyID,
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      secret,
+	}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{ID: id, Secret: secret})
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "invalid url", body: `{"url": "ftp://example.com", "event_types": ["account.opened"]}`, wantCode: http.StatusBadRequest},
+		{name: "missing event types", body: `{"url": "https://example.com", "event_types": []}`, wantCode: http.StatusBadRequest},
+		{name: "unknown event type", body: `{"url": "https://example.com", "event_types": ["nope"]}`, wantCode: http.StatusBadRequest},
+		{name: "created", body: fmt.Sprintf(`{"url": "https://example.com", "event_types": [%q]}`, domain.EventTypeAccountOpened), wantCode: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			m := repo.NewMemory()
+			h := NewCreateWebhook(m)
+
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/webhooks", h.Handle, newRequest(http.MethodPost, "/api/v1/webhooks", tt.body, uuid.Nil))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			webhooks, err := m.Webhooks().ListByClient(context.Background(), testKeyID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantCode != http.StatusCreated {
+				if len(webhooks) != 0 {
+					t.Fatalf("expected no webhooks, got %+v", webhooks)
+				}
+				return
+			}
+			res := decode[CreateWebhookResponse](t, w)
+			if len(webhooks) != 1 || webhooks[0].ID != res.ID || !webhooks[0].Active || webhooks[0].URL != "https://example.com" {
+				t.Fatalf("expected active webhook %s, got %+v", res.ID, webhooks)
+			}
+			if res.Secret == "" || m.WebhookSecret(res.ID) != res.Secret {
+				t.Fatalf("expected the stored secret %q, got %q", m.WebhookSecret(res.ID), res.Secret)
+			}
+		})
+	}
+}
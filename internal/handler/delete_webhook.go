@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewDeleteWebhook(
+	store repo.Store,
+) *DeleteWebhook {
+	return &DeleteWebhook{
+		store: store,
+	}
+}
+
+// DeleteWebhook deactivates a subscription. It's kept around (with its
+// deliveries) for auditing.
+type DeleteWebhook struct {
+	store repo.Store
+}
+
+func (h *DeleteWebhook) Handle(c *gin.Context) {
+	clientKeyID := c.GetHeader("BestWallet-Key-ID")
+	if clientKeyID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing key id")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed webhook id")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	found, err := tx.Webhooks().Deactivate(c, id, clientKeyID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
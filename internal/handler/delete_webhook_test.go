@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestDeleteWebhook(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	id := addWebhook(t, m, testKeyID)
+	someoneElses := addWebhook(t, m, "other-key")
+	h := NewDeleteWebhook(m)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{name: "malformed id", path: "/api/v1/webhooks/nope", wantCode: http.StatusBadRequest},
+		{name: "not found", path: "/api/v1/webhooks/" + uuid.NewString(), wantCode: http.StatusNotFound},
+		{name: "someone else's", path: "/api/v1/webhooks/" + someoneElses.String(), wantCode: http.StatusNotFound},
+		{name: "deleted", path: "/api/v1/webhooks/" + id.String(), wantCode: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodDelete, "/api/v1/webhooks/:id", h.Handle, newRequest(http.MethodDelete, tt.path, "", uuid.Nil))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+		})
+	}
+
+	others, err := m.Webhooks().ListByClient(context.Background(), "other-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !others[0].Active {
+		t.Fatal("expected someone else's webhook to stay active")
+	}
+	ours, err := m.Webhooks().ListByClient(context.Background(), testKeyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ours) != 1 || ours[0].Active {
+		t.Fatalf("expected the webhook kept but inactive, got %+v", ours)
+	}
+}
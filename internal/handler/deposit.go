@@ -1,15 +1,156 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
+	"time"
 
-func NewDeposit() *Deposit {
-	return &Deposit{}
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewDeposit(
+	store repo.Store,
+) *Deposit {
+	return &Deposit{
+		store: store,
+	}
 }
 
+// Deposit credits money that came into the wallet from outside (e.g. a bank
+// transfer the client app received) to a customer account. The money comes
+// from the settlement account, the deposit is cleared in the background
+// (after KYT).
+//
+// Clients should send an Idempotency-Key header, retrying a request with the
+// same key returns the original deposit instead of creating a new one.
 type Deposit struct {
-	// deps
+	store repo.Store
+}
+
+type DepositRequest struct {
+	Amount      string `json:"amount"` // Decimal e.g. "12.34".
+	Description string `json:"description,omitempty"`
+}
+
+type DepositResponse struct {
+	TransactionID uuid.UUID                `json:"transaction_id"`
+	Status        domain.TransactionStatus `json:"status"`
 }
 
 func (h *Deposit) Handle(c *gin.Context) {
-	panic("not implemented")
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Validate request.
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var req DepositRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	amount, err := domain.ParseAmount(req.Amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var idempotencyKey string
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		idempotencyKey = "deposit:" + customerID.String() + ":" + key
+	}
+
+	// Only customers with approved KYC can move money.
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not verified")
+		return
+	}
+
+	account, err := h.store.Accounts().GetByNumber(c, number)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !account.BelongsTo(customerID) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Retries are answered with the original deposit, even if the limits
+	// are used up by now.
+	t, found, err := findRetried(c, tx.Ledger(), idempotencyKey)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		if err := tx.Ledger().CheckLimit(c, customerID, domain.LimitKindDeposit, account.Currency, amount, time.Now()); err != nil {
+			if !abortLimitExceeded(c, err) {
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		settlementID, err := tx.Ledger().InternalAccountID(c, domain.InternalAccountSettlement, account.Currency)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		t, _, err = tx.Ledger().Initiate(c, ledger.Transaction{
+			Kind:           domain.TransactionKindDeposit,
+			Amount:         amount,
+			Currency:       account.Currency,
+			Description:    req.Description,
+			IdempotencyKey: idempotencyKey,
+			Legs: []ledger.Leg{
+				{AccountID: settlementID, Amount: -amount},
+				{AccountID: account.ID, Amount: amount},
+			},
+		})
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, DepositResponse{
+		TransactionID: t.ID,
+		Status:        t.Status,
+	})
 }
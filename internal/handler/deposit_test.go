@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestDeposit(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 0)
+	pendingID, pendingAccount := addCustomer(m, domain.KYCStatusPending, 0)
+	m.SetLimit(domain.KYCTierBasic, limits.Limit{
+		Kind:     domain.LimitKindDeposit,
+		Period:   domain.LimitPeriodDaily,
+		Currency: domain.DefaultCurrency,
+		Amount:   100000,
+	})
+	h := NewDeposit(m)
+	path := func(a repo.Account) string { return "/api/v1/accounts/" + a.Number + "/deposit" }
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		body       string
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(account), body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "malformed number", customerID: customerID, path: "/api/v1/accounts/nope/deposit", body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "malformed amount", customerID: customerID, path: path(account), body: `{"amount": "ten"}`, wantCode: http.StatusBadRequest},
+		{name: "customer not found", customerID: uuid.New(), path: path(account), body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "customer not verified", customerID: pendingID, path: path(pendingAccount), body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "someone else's account", customerID: customerID, path: path(pendingAccount), body: `{"amount": "10.00"}`, wantCode: http.StatusNotFound},
+		{name: "limit exceeded", customerID: customerID, path: path(account), body: `{"amount": "1000.01"}`, wantCode: http.StatusBadRequest},
+		{name: "accepted", customerID: customerID, path: path(account), body: `{"amount": "10.00"}`, wantCode: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/accounts/:number/deposit", h.Handle, newRequest(http.MethodPost, tt.path, tt.body, tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			resp := decode[DepositResponse](t, w)
+			if resp.Status != domain.TransactionStatusPending {
+				t.Fatalf("expected a pending deposit, got %s", resp.Status)
+			}
+			assertEvent(t, m, domain.EventTypeTransactionInitiated, resp.TransactionID)
+		})
+	}
+}
+
+func TestDeposit_Retried(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 0)
+	h := NewDeposit(m)
+	deposit := func() DepositResponse {
+		req := newRequest(http.MethodPost, "/api/v1/accounts/"+account.Number+"/deposit", `{"amount": "10.00"}`, customerID)
+		req.Header.Set("Idempotency-Key", "deposit-1")
+		w := serve(http.MethodPost, "/api/v1/accounts/:number/deposit", h.Handle, req)
+		assertCode(t, w, http.StatusAccepted)
+		return decode[DepositResponse](t, w)
+	}
+	first := deposit()
+
+	// Act.
+	second := deposit()
+
+	// Assert.
+	if second.TransactionID != first.TransactionID {
+		t.Fatalf("expected the retry to return %s, got %s", first.TransactionID, second.TransactionID)
+	}
+	if got := len(m.Transactions()); got != 1 {
+		t.Fatalf("expected 1 transaction, got %d", got)
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewGetCustomerKYC(
+	store repo.Store,
+) *GetCustomerKYC {
+	return &GetCustomerKYC{
+		store: store,
+	}
+}
+
+// GetCustomerKYC shows the customer's KYC status and how it got there.
+type GetCustomerKYC struct {
+	store repo.Store
+}
+
+type GetCustomerKYCResponse struct {
+	CustomerID uuid.UUID        `json:"customer_id"`
+	Status     domain.KYCStatus `json:"status"`
+	Tier       domain.KYCTier   `json:"tier"`
+	Timeline   []kyc.Event      `json:"timeline"` // Oldest first.
+}
+
+func (h *GetCustomerKYC) Handle(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	timeline, err := h.store.Customers().KYCTimeline(c, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetCustomerKYCResponse{
+		CustomerID: customerID,
+		Status:     customer.KYCStatus,
+		Tier:       customer.KYCTier,
+		Timeline:   timeline,
+	})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestGetCustomerKYC(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	approvedID, _ := addCustomer(m, domain.KYCStatusApproved, 0)
+	h := NewGetCustomerKYC(m)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{name: "malformed id", path: "/api/v1/customers/nope/kyc", wantCode: http.StatusBadRequest},
+		{name: "not found", path: "/api/v1/customers/" + uuid.NewString() + "/kyc", wantCode: http.StatusNotFound},
+		{name: "found", path: "/api/v1/customers/" + approvedID.String() + "/kyc", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/customers/:id/kyc", h.Handle, newRequest(http.MethodGet, tt.path, "", uuid.Nil))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[GetCustomerKYCResponse](t, w)
+			if resp.CustomerID != approvedID || resp.Status != domain.KYCStatusApproved || resp.Tier != domain.KYCTierBasic {
+				t.Fatalf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewGetLimits(
+	store repo.Store,
+) *GetLimits {
+	return &GetLimits{
+		store: store,
+	}
+}
+
+// GetLimits shows the customer's transaction limits and how much of them is
+// used in the current periods.
+type GetLimits struct {
+	store repo.Store
+}
+
+type GetLimitsResponse struct {
+	Tier   domain.KYCTier `json:"tier"`
+	Limits []LimitUsage   `json:"limits"`
+}
+
+type LimitUsage struct {
+	Kind      domain.LimitKind   `json:"kind"`
+	Period    domain.LimitPeriod `json:"period"`
+	Currency  string             `json:"currency"`
+	Limit     string             `json:"limit"`
+	Used      string             `json:"used"`
+	Remaining string             `json:"remaining"`
+	ResetsAt  time.Time          `json:"resets_at"`
+}
+
+// LimitExceededResponse is returned by the money handlers when a movement
+// doesn't fit in the customer's limits.
+type LimitExceededResponse struct {
+	Error     string `json:"error"` // Always "limit_exceeded".
+	Message   string `json:"message"`
+	Requested string `json:"requested"`
+	LimitUsage
+}
+
+func (h *GetLimits) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	now := time.Now()
+	tier, usage, err := h.store.Customers().Limits(c, customerID, now)
+	switch {
+	case errors.Is(err, repo.ErrCustomerNotFound):
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := GetLimitsResponse{Tier: tier, Limits: make([]LimitUsage, 0, len(usage))}
+	for _, u := range usage {
+		resp.Limits = append(resp.Limits, newLimitUsage(u, now))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func newLimitUsage(u limits.Usage, now time.Time) LimitUsage {
+	start := limits.PeriodStart(u.Period, now)
+	resetsAt := start.AddDate(0, 0, 1)
+	if u.Period == domain.LimitPeriodMonthly {
+		resetsAt = start.AddDate(0, 1, 0)
+	}
+
+	return LimitUsage{
+		Kind:      u.Kind,
+		Period:    u.Period,
+		Currency:  u.Currency,
+		Limit:     domain.FormatAmount(u.Amount),
+		Used:      domain.FormatAmount(u.Used),
+		Remaining: domain.FormatAmount(u.Remaining),
+		ResetsAt:  resetsAt,
+	}
+}
+
+// abortLimitExceeded responds with a limit_exceeded error if err is one, and
+// reports whether it did.
+func abortLimitExceeded(c *gin.Context, err error) bool {
+	var exceeded *limits.ExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+
+	c.AbortWithStatusJSON(http.StatusBadRequest, LimitExceededResponse{
+		Error:      "limit_exceeded",
+		Message:    exceeded.Error(),
+		Requested:  domain.FormatAmount(exceeded.Requested),
+		LimitUsage: newLimitUsage(exceeded.Usage, time.Now()),
+	})
+	return true
+}
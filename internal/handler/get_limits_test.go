@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestGetLimits(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	_, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	m.SetLimit(domain.KYCTierBasic, limits.Limit{Kind: domain.LimitKindWithdrawal, Period: domain.LimitPeriodMonthly, Currency: domain.DefaultCurrency, Amount: 5000})
+	m.SetLimit(domain.KYCTierBasic, limits.Limit{Kind: domain.LimitKindTransferOut, Period: domain.LimitPeriodDaily, Currency: domain.DefaultCurrency, Amount: 500})
+	m.SetLimit(domain.KYCTierStandard, limits.Limit{Kind: domain.LimitKindTransferOut, Period: domain.LimitPeriodDaily, Currency: domain.DefaultCurrency, Amount: 5000})
+	clearedTransfer(t, m, from, to, 200)
+	clearedTransfer(t, m, to, from, 50) // Received, doesn't count.
+	h := NewGetLimits(m)
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, wantCode: http.StatusBadRequest},
+		{name: "customer not found", customerID: uuid.New(), wantCode: http.StatusBadRequest},
+		{name: "listed", customerID: customerID, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/limits", h.Handle, newRequest(http.MethodGet, "/api/v1/limits", "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[GetLimitsResponse](t, w)
+			if resp.Tier != domain.KYCTierBasic {
+				t.Fatalf("expected tier %s, got %s", domain.KYCTierBasic, resp.Tier)
+			}
+			type usage struct {
+				kind                   domain.LimitKind
+				limit, used, remaining string
+			}
+			want := []usage{
+				{kind: domain.LimitKindTransferOut, limit: "5.00", used: "2.00", remaining: "3.00"},
+				{kind: domain.LimitKindWithdrawal, limit: "50.00", used: "0.00", remaining: "50.00"},
+			}
+			var got []usage
+			for _, l := range resp.Limits {
+				got = append(got, usage{kind: l.Kind, limit: l.Limit, used: l.Used, remaining: l.Remaining})
+			}
+			if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
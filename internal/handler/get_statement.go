@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/statement"
+)
+
+func NewGetStatement(
+	store repo.Store,
+) *GetStatement {
+	return &GetStatement{
+		store: store,
+	}
+}
+
+// GetStatement downloads the monthly statement of an account, as PDF
+// (default) or CSV ("format" query param). Statements are generated by the
+// month-end job (see job.GenerateStatements), until then there's none. It's
+// never generated here, that could freeze it before the month's last
+// postings have cleared.
+type GetStatement struct {
+	store repo.Store
+}
+
+func (h *GetStatement) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Validate request.
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	period := c.Param("period")
+	if _, _, err := statement.ParsePeriod(period); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	format := statement.Format(c.DefaultQuery("format", string(statement.FormatPDF)))
+	if format != statement.FormatPDF && format != statement.FormatCSV {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "format must be pdf or csv")
+		return
+	}
+
+	account, err := h.store.Accounts().GetByNumber(c, number)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !account.BelongsTo(customerID) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+
+	body, found, err := h.store.Accounts().Statement(c, account.ID, period, format)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "statement not available yet")
+		return
+	}
+
+	contentType := "application/pdf"
+	if format == statement.FormatCSV {
+		contentType = "text/csv"
+	}
+	c.Header("Content-Disposition", `attachment; filename="statement-`+period+`.`+string(format)+`"`)
+	c.Data(http.StatusOK, contentType, body)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestGetStatement(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 100)
+	_, other := addCustomer(m, domain.KYCStatusApproved, 100)
+	m.AddStatement(account.ID, "2024-01", []byte("csv"), []byte("pdf"))
+	m.AddStatement(other.ID, "2024-02", []byte("csv"), []byte("pdf"))
+	h := NewGetStatement(m)
+	path := func(a repo.Account, period string) string {
+		return "/api/v1/accounts/" + a.Number + "/statements/" + period
+	}
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		wantCode   int
+		wantBody   string
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(account, "2024-01"), wantCode: http.StatusBadRequest},
+		{name: "malformed number", customerID: customerID, path: "/api/v1/accounts/nope/statements/2024-01", wantCode: http.StatusBadRequest},
+		{name: "malformed period", customerID: customerID, path: path(account, "january"), wantCode: http.StatusBadRequest},
+		{name: "unknown format", customerID: customerID, path: path(account, "2024-01") + "?format=xls", wantCode: http.StatusBadRequest},
+		{name: "account not found", customerID: customerID, path: "/api/v1/accounts/" + uuid.NewString() + "/statements/2024-01", wantCode: http.StatusNotFound},
+		{name: "someone else's account", customerID: customerID, path: path(other, "2024-02"), wantCode: http.StatusNotFound},
+		{name: "not generated yet", customerID: customerID, path: path(account, "2024-02"), wantCode: http.StatusNotFound},
+		{name: "pdf", customerID: customerID, path: path(account, "2024-01"), wantCode: http.StatusOK, wantBody: "pdf"},
+		{name: "csv", customerID: customerID, path: path(account, "2024-01") + "?format=csv", wantCode: http.StatusOK, wantBody: "csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/accounts/:number/statements/:period", h.Handle, newRequest(http.MethodGet, tt.path, "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, got)
+			}
+		})
+	}
+}
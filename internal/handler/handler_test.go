@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/middleware"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+const testKeyID = "test-key"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve routes req to handle registered at route, like the router does.
+func serve(method, route string, handle gin.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, route, handle)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// newRequest is a request of the client app, made for customerID unless it's
+// uuid.Nil.
+func newRequest(method, path, body string, customerID uuid.UUID) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("BestWallet-Key-ID", testKeyID)
+	if customerID != uuid.Nil {
+		req.Header.Set("BestWallet-Customer-ID", customerID.String())
+	}
+	return req
+}
+
+// newAdminRequest is a request of the back office operator adminID, as
+// middleware.AdminAuth passes it on.
+func newAdminRequest(method, path, body string, adminID uuid.UUID) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(middleware.AdminIDHeader, adminID.String())
+	return req
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var res T
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("can't decode the response %q: %s", w.Body, err)
+	}
+	return res
+}
+
+// addCustomer adds a customer with the KYC status and an account with balance.
+func addCustomer(m *repo.Memory, status domain.KYCStatus, balance int64) (uuid.UUID, repo.Account) {
+	id := uuid.New()
+	m.AddCustomer(repo.Customer{ID: id, KYCStatus: status})
+	return id, m.AddAccount(repo.Account{CustomerID: &id, Balance: balance})
+}
+
+// clearedTransfer moves amount from one account to another and clears it.
+func clearedTransfer(t *testing.T, m *repo.Memory, from, to repo.Account, amount int64) ledger.Transaction {
+	t.Helper()
+	res := pendingTransfer(t, m, from, to, amount)
+	if err := m.Clear(res.ID); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// pendingTransfer moves amount from one account to another without clearing
+// it.
+func pendingTransfer(t *testing.T, m *repo.Memory, from, to repo.Account, amount int64) ledger.Transaction {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := m.Begin(ctx, repo.Audit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	res, _, err := tx.Ledger().Initiate(ctx, ledger.Transaction{
+		Kind:     domain.TransactionKindTransfer,
+		Amount:   amount,
+		Currency: from.Currency,
+		Legs: []ledger.Leg{
+			{AccountID: from.ID, Amount: -amount},
+			{AccountID: to.ID, Amount: amount},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// addDocument stores a KYC document of the customer waiting for review.
+func addDocument(t *testing.T, m *repo.Memory, customerID uuid.UUID, kind domain.KYCDocumentKind) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := m.Begin(ctx, repo.Audit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	doc := kyc.NewDocument(customerID, kind, "document.pdf", "application/pdf", []byte("%PDF-1.4"))
+	if err := tx.Customers().AddDocument(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return doc.ID
+}
+
+// addScheduledTransfer schedules a transfer of 1.00 between the accounts and
+// leaves it in status (active, paused or cancelled).
+func addScheduledTransfer(t *testing.T, m *repo.Memory, from, to repo.Account, startAt time.Time, recurrence string, status domain.ScheduledTransferStatus) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := m.Begin(ctx, repo.Audit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	s := repo.NewScheduledTransfer{
+		ID:                  uuid.New(),
+		CustomerID:          *from.CustomerID,
+		FromAccountID:       from.ID,
+		ToAccountID:         to.ID,
+		Amount:              100,
+		Currency:            from.Currency,
+		StartAt:             startAt,
+		OnInsufficientFunds: domain.InsufficientFundsPolicySkip,
+	}
+	if recurrence != "" {
+		s.Recurrence = &recurrence
+	}
+	if err := tx.ScheduledTransfers().Create(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	switch status {
+	case domain.ScheduledTransferStatusPaused:
+		_, err = tx.ScheduledTransfers().Pause(ctx, s.ID, s.CustomerID)
+	case domain.ScheduledTransferStatusCancelled:
+		_, err = tx.ScheduledTransfers().Cancel(ctx, s.ID, s.CustomerID)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return s.ID
+}
+
+// scheduledTransfer returns the customer's scheduled transfer.
+func scheduledTransfer(t *testing.T, m *repo.Memory, id, customerID uuid.UUID) repo.ScheduledTransfer {
+	t.Helper()
+	all, err := m.ScheduledTransfers().ListByCustomer(context.Background(), customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range all {
+		if s.ID == id {
+			return s
+		}
+	}
+	t.Fatalf("expected scheduled transfer %s, got %+v", id, all)
+	return repo.ScheduledTransfer{}
+}
+
+// addWebhook subscribes the client app to accounts being opened.
+func addWebhook(t *testing.T, m *repo.Memory, clientKeyID string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	if err := m.Webhooks().Create(context.Background(), repo.NewWebhookSubscription{
+		ID:          id,
+		ClientKeyID: clientKeyID,
+		URL:         "https://example.com/hooks",
+		EventTypes:  []domain.EventType{domain.EventTypeAccountOpened},
+		Secret:      "c2VjcmV0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// addApproval requests an approval on behalf of the maker, like the
+// handlers of the back office.
+func addApproval(t *testing.T, m *repo.Memory, kind domain.ApprovalKind, targetID string, payload any, makerID uuid.UUID) admin.Approval {
+	t.Helper()
+	a, err := m.BackOffice().RequestApproval(context.Background(), kind, targetID, payload, "checked with the customer", makerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func assertCode(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("expected response code %d, got %d: %s", want, w.Code, w.Body)
+	}
+}
+
+func assertEvent(t *testing.T, m *repo.Memory, typ domain.EventType, aggregateID uuid.UUID) {
+	t.Helper()
+	for _, ev := range m.Events() {
+		if ev.Type == typ && ev.AggregateID == aggregateID {
+			return
+		}
+	}
+	t.Fatalf("expected a %s event for %s, got %v", typ, aggregateID, m.Events())
+}
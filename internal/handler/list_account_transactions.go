@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewListAccountTransactions(
+	store repo.Store,
+) *ListAccountTransactions {
+	return &ListAccountTransactions{
+		store: store,
+	}
+}
+
+// ListAccountTransactions is the transaction history of an account, newest
+// first. Reversals link to the transaction they reverse, and reversed
+// transactions show how much of them was reversed so far. Use the "after"
+// query param with the previous page's next_cursor to page.
+type ListAccountTransactions struct {
+	store repo.Store
+}
+
+type ListAccountTransactionsResponse struct {
+	Transactions []ListAccountTransactionsItem `json:"transactions"`
+	NextCursor   *int64                        `json:"next_cursor,omitempty"`
+}
+
+type ListAccountTransactionsItem struct {
+	TransactionID  uuid.UUID                `json:"transaction_id"`
+	Kind           domain.TransactionKind   `json:"kind"`
+	Status         domain.TransactionStatus `json:"status"`
+	Amount         int64                    `json:"amount"` // Negative for debits, includes the fee.
+	Fee            int64                    `json:"fee,omitempty"`
+	Currency       string                   `json:"currency"`
+	Description    string                   `json:"description"`
+	ReversalOf     *uuid.UUID               `json:"reversal_of,omitempty"`
+	ReversedAmount int64                    `json:"reversed_amount,omitempty"`
+	// AdjustmentReason is set on manual adjustments made by operators.
+	AdjustmentReason *domain.AdjustmentReason `json:"adjustment_reason,omitempty"`
+	CreatedAt        time.Time                `json:"created_at"`
+}
+
+func (h *ListAccountTransactions) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := defaultPageSize
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 || limit > maxPageSize {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxPageSize))
+			return
+		}
+	}
+	var after *int64
+	if raw := c.Query("after"); raw != "" {
+		cursor, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "malformed cursor")
+			return
+		}
+		after = &cursor
+	}
+
+	account, err := h.store.Accounts().GetByNumber(c, number)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !account.BelongsTo(customerID) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+
+	entries, err := h.store.Accounts().History(c, account.ID, after, limit)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := ListAccountTransactionsResponse{Transactions: make([]ListAccountTransactionsItem, 0, len(entries))}
+	for _, e := range entries {
+		resp.Transactions = append(resp.Transactions, ListAccountTransactionsItem{
+			TransactionID:    e.TransactionID,
+			Kind:             e.Kind,
+			Status:           e.Status,
+			Amount:           e.Amount,
+			Fee:              e.Fee,
+			Currency:         e.Currency,
+			Description:      e.Description,
+			ReversalOf:       e.ReversalOf,
+			ReversedAmount:   e.ReversedAmount,
+			AdjustmentReason: e.AdjustmentReason,
+			CreatedAt:        e.CreatedAt,
+		})
+	}
+	if len(entries) == limit {
+		resp.NextCursor = &entries[len(entries)-1].EntryID
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListAccountTransactions(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 1000)
+	otherID, other := addCustomer(m, domain.KYCStatusApproved, 0)
+	first := clearedTransfer(t, m, account, other, 100)
+	second := clearedTransfer(t, m, account, other, 200)
+	third := clearedTransfer(t, m, account, other, 300)
+	h := NewListAccountTransactions(m)
+	path := "/api/v1/accounts/" + account.Number + "/transactions"
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path, wantCode: http.StatusBadRequest},
+		{name: "malformed number", customerID: customerID, path: "/api/v1/accounts/nope/transactions", wantCode: http.StatusBadRequest},
+		{name: "limit too small", customerID: customerID, path: path + "?limit=0", wantCode: http.StatusBadRequest},
+		{name: "limit too large", customerID: customerID, path: path + "?limit=" + strconv.Itoa(maxPageSize+1), wantCode: http.StatusBadRequest},
+		{name: "malformed cursor", customerID: customerID, path: path + "?after=x", wantCode: http.StatusBadRequest},
+		{name: "someone else's account", customerID: otherID, path: path, wantCode: http.StatusNotFound},
+		{name: "unknown account", customerID: customerID, path: "/api/v1/accounts/" + uuid.NewString() + "/transactions", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/accounts/:number/transactions", h.Handle, newRequest(http.MethodGet, tt.path, "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+		})
+	}
+
+	t.Run("pages newest first", func(t *testing.T) {
+		// Act.
+		w := serve(http.MethodGet, "/api/v1/accounts/:number/transactions", h.Handle, newRequest(http.MethodGet, path+"?limit=2", "", customerID))
+
+		// Assert.
+		assertCode(t, w, http.StatusOK)
+		page := decode[ListAccountTransactionsResponse](t, w)
+		if len(page.Transactions) != 2 || page.Transactions[0].TransactionID != third.ID || page.Transactions[1].TransactionID != second.ID {
+			t.Fatalf("expected the 2 newest transactions, got %+v", page.Transactions)
+		}
+		if page.Transactions[0].Amount != -300 || page.Transactions[0].Status != domain.TransactionStatusCleared {
+			t.Fatalf("expected a cleared debit of 300, got %+v", page.Transactions[0])
+		}
+		if page.NextCursor == nil {
+			t.Fatal("expected a cursor")
+		}
+
+		// Act.
+		next := path + "?limit=2&after=" + strconv.FormatInt(*page.NextCursor, 10)
+		w = serve(http.MethodGet, "/api/v1/accounts/:number/transactions", h.Handle, newRequest(http.MethodGet, next, "", customerID))
+
+		// Assert.
+		assertCode(t, w, http.StatusOK)
+		page = decode[ListAccountTransactionsResponse](t, w)
+		if len(page.Transactions) != 1 || page.Transactions[0].TransactionID != first.ID || page.NextCursor != nil {
+			t.Fatalf("expected the last transaction without a cursor, got %+v", page)
+		}
+	})
+}
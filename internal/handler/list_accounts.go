@@ -1,30 +1,37 @@
 package handler
 
 import (
-	"context"
 	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
 )
 
 func NewListAccounts(
-	db *pgxpool.Pool,
+	store repo.Store,
 ) *ListAccounts {
 	return &ListAccounts{
-		db: db,
+		store: store,
 	}
 }
 
 type ListAccounts struct {
-	db *pgxpool.Pool
+	store repo.Store
 }
 
 type ListAccountsResponse struct {
-	Accounts []dbGetAccountForCustomer `json:"accounts"`
+	Accounts []ListAccountsItem `json:"accounts"` // Newest first.
+}
+
+type ListAccountsItem struct {
+	Number   string                `json:"number"`
+	Product  domain.AccountProduct `json:"product"`
+	Currency string                `json:"currency"`
+	Balance  int64                 `json:"balance"`
 }
 
 func (h *ListAccounts) Handle(c *gin.Context) {
@@ -40,56 +47,32 @@ func (h *ListAccounts) Handle(c *gin.Context) {
 		return
 	}
 
-	// Check if customer exists in db.
-	exists, err := h.dbCustomerExists(c, customerID)
-	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, err)
-		return
-	}
-	if !exists {
+	// Check if customer exists.
+	_, err = h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
 		return
 	}
-
-	// Read customer accounts from db.
-	accounts, err := h.dbGetAccountsForCustomer(c, customerID)
 	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, ListAccountsResponse{Accounts: accounts})
-}
-
-func (h *ListAccounts) dbCustomerExists(ctx context.Context, customerID uuid.UUID) (ok bool, err error) {
-	sql := `SELECT true FROM customers WHERE id = $1`
-
-	err = h.db.QueryRow(ctx, sql, customerID).Scan(&ok)
-	switch {
-	case errors.Is(err, pgx.ErrNoRows):
-		return false, nil
-	case err != nil:
-		return false, err
-	default:
-		return ok, nil
+	// Read customer accounts.
+	accounts, err := h.store.Accounts().ListByCustomer(c, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
 	}
-}
-
-type dbGetAccountForCustomer struct {
-	Number  string `json:"number"`
-	Balance int    `json:"balance"`
-}
-
-func (h *ListAccounts) dbGetAccountsForCustomer(ctx context.Context, customerID uuid.UUID) ([]dbGetAccountForCustomer, error) {
-	sql := `SELECT number, balance FROM accounts WHERE customer_id = $1 ORDER BY created_at desc`
-	// TODO index on customer_id.
 
-	rows, _ := h.db.Query(ctx, sql, customerID)
-	res, err := pgx.CollectRows[dbGetAccountForCustomer](rows, pgx.RowToStructByName[dbGetAccountForCustomer])
-	switch {
-	case err != nil:
-		return nil, err
-	default:
-		return res, nil
+	resp := ListAccountsResponse{Accounts: make([]ListAccountsItem, 0, len(accounts))}
+	for _, a := range accounts {
+		resp.Accounts = append(resp.Accounts, ListAccountsItem{
+			Number:   a.Number,
+			Product:  a.Product,
+			Currency: a.Currency,
+			Balance:  a.Balance,
+		})
 	}
+	c.JSON(http.StatusOK, resp)
 }
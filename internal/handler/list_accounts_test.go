@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListAccounts(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, older := addCustomer(m, domain.KYCStatusApproved, 100)
+	newer := m.AddAccount(repo.Account{CustomerID: &customerID, Product: domain.AccountProductSavings})
+	addCustomer(m, domain.KYCStatusApproved, 500) // Someone else's account.
+	h := NewListAccounts(m)
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, wantCode: http.StatusBadRequest},
+		{name: "customer not found", customerID: uuid.New(), wantCode: http.StatusBadRequest},
+		{name: "listed", customerID: customerID, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/accounts", h.Handle, newRequest(http.MethodGet, "/api/v1/accounts", "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[ListAccountsResponse](t, w)
+			want := []ListAccountsItem{
+				{Number: newer.Number, Product: domain.AccountProductSavings, Currency: domain.DefaultCurrency, Balance: 0},
+				{Number: older.Number, Product: domain.AccountProductCurrent, Currency: domain.DefaultCurrency, Balance: 100},
+			}
+			if len(resp.Accounts) != len(want) {
+				t.Fatalf("expected %v, got %v", want, resp.Accounts)
+			}
+			for i := range want {
+				if resp.Accounts[i] != want[i] {
+					t.Fatalf("expected %v, got %v", want, resp.Accounts)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewListKYCDocuments(
+	store repo.Store,
+) *ListKYCDocuments {
+	return &ListKYCDocuments{
+		store: store,
+	}
+}
+
+// ListKYCDocuments shows the customer's uploaded documents and their review
+// status, without the files themselves.
+type ListKYCDocuments struct {
+	store repo.Store
+}
+
+type ListKYCDocumentsResponse struct {
+	Documents []kyc.Document `json:"documents"`
+	Missing   []string       `json:"missing"` // What's still needed for approval.
+}
+
+func (h *ListKYCDocuments) Handle(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	docs, err := h.store.Customers().Documents(c, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListKYCDocumentsResponse{Documents: docs, Missing: kyc.Missing(docs)})
+}
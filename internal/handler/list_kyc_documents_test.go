@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListKYCDocuments(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	older := addDocument(t, m, customerID, domain.KYCDocumentKindPassport)
+	newer := addDocument(t, m, customerID, domain.KYCDocumentKindIDCard)
+	otherID, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	addDocument(t, m, otherID, domain.KYCDocumentKindProofOfAddress)
+	h := NewListKYCDocuments(m)
+
+	tests := []struct {
+		name        string
+		path        string
+		wantCode    int
+		wantIDs     []uuid.UUID
+		wantMissing []string
+	}{
+		{name: "malformed customer id", path: "/api/v1/customers/nope/documents", wantCode: http.StatusBadRequest},
+		{
+			name:        "none",
+			path:        "/api/v1/customers/" + uuid.NewString() + "/documents",
+			wantCode:    http.StatusOK,
+			wantIDs:     []uuid.UUID{},
+			wantMissing: []string{"identity document", "proof of address"},
+		},
+		{
+			name:        "listed",
+			path:        "/api/v1/customers/" + customerID.String() + "/documents",
+			wantCode:    http.StatusOK,
+			wantIDs:     []uuid.UUID{newer, older},
+			wantMissing: []string{"proof of address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/customers/:id/documents", h.Handle, newRequest(http.MethodGet, tt.path, "", uuid.Nil))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[ListKYCDocumentsResponse](t, w)
+			ids := []uuid.UUID{}
+			for _, d := range resp.Documents {
+				ids = append(ids, d.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("expected documents %v, got %v", tt.wantIDs, ids)
+			}
+			if !slices.Equal(resp.Missing, tt.wantMissing) {
+				t.Fatalf("expected missing %v, got %v", tt.wantMissing, resp.Missing)
+			}
+		})
+	}
+}
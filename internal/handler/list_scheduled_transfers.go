@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewListScheduledTransfers(
+	store repo.Store,
+) *ListScheduledTransfers {
+	return &ListScheduledTransfers{
+		store: store,
+	}
+}
+
+type ListScheduledTransfers struct {
+	store repo.Store
+}
+
+type ListScheduledTransfersResponse struct {
+	ScheduledTransfers []ListScheduledTransfersItem `json:"scheduled_transfers"` // Newest first.
+}
+
+type ListScheduledTransfersItem struct {
+	ID                  uuid.UUID                      `json:"id"`
+	FromAccount         string                         `json:"from_account"`
+	ToAccount           string                         `json:"to_account"`
+	Amount              int64                          `json:"amount"`
+	Currency            string                         `json:"currency"`
+	Description         string                         `json:"description"`
+	StartAt             time.Time                      `json:"start_at"`
+	Recurrence          *string                        `json:"recurrence"`
+	OnInsufficientFunds domain.InsufficientFundsPolicy `json:"on_insufficient_funds"`
+	Status              domain.ScheduledTransferStatus `json:"status"`
+	NextRunAt           *time.Time                     `json:"next_run_at"`
+	CreatedAt           time.Time                      `json:"created_at"`
+}
+
+func (h *ListScheduledTransfers) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	transfers, err := h.store.ScheduledTransfers().ListByCustomer(c, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := ListScheduledTransfersResponse{ScheduledTransfers: make([]ListScheduledTransfersItem, 0, len(transfers))}
+	for _, s := range transfers {
+		resp.ScheduledTransfers = append(resp.ScheduledTransfers, ListScheduledTransfersItem{
+			ID:                  s.ID,
+			FromAccount:         s.FromAccount,
+			ToAccount:           s.ToAccount,
+			Amount:              s.Amount,
+			Currency:            s.Currency,
+			Description:         s.Description,
+			StartAt:             s.StartAt,
+			Recurrence:          s.Recurrence,
+			OnInsufficientFunds: s.OnInsufficientFunds,
+			Status:              s.Status,
+			NextRunAt:           s.NextRunAt,
+			CreatedAt:           s.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListScheduledTransfers(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	_, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	older := addScheduledTransfer(t, m, from, to, tomorrow, "", domain.ScheduledTransferStatusActive)
+	newer := addScheduledTransfer(t, m, from, to, tomorrow, "FREQ=WEEKLY;INTERVAL=1", domain.ScheduledTransferStatusPaused)
+	addScheduledTransfer(t, m, to, from, tomorrow, "", domain.ScheduledTransferStatusActive) // Someone else's.
+	h := NewListScheduledTransfers(m)
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		wantCode   int
+		wantIDs    []uuid.UUID
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, wantCode: http.StatusBadRequest},
+		{name: "none", customerID: uuid.New(), wantCode: http.StatusOK, wantIDs: []uuid.UUID{}},
+		{name: "listed", customerID: customerID, wantCode: http.StatusOK, wantIDs: []uuid.UUID{newer, older}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/scheduled-transfers", h.Handle, newRequest(http.MethodGet, "/api/v1/scheduled-transfers", "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[ListScheduledTransfersResponse](t, w)
+			ids := []uuid.UUID{}
+			for _, s := range resp.ScheduledTransfers {
+				ids = append(ids, s.ID)
+				if s.FromAccount != from.Number || s.ToAccount != to.Number || s.Amount != 100 {
+					t.Fatalf("expected transfers of 1.00 from %s to %s, got %+v", from.Number, to.Number, s)
+				}
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("expected scheduled transfers %v, got %v", tt.wantIDs, ids)
+			}
+			if len(ids) > 0 && (resp.ScheduledTransfers[0].Status != domain.ScheduledTransferStatusPaused || resp.ScheduledTransfers[1].Status != domain.ScheduledTransferStatusActive) {
+				t.Fatalf("expected a paused and an active transfer, got %+v", resp.ScheduledTransfers)
+			}
+		})
+	}
+}
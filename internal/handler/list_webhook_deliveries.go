@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+func NewListWebhookDeliveries(
+	store repo.Store,
+) *ListWebhookDeliveries {
+	return &ListWebhookDeliveries{
+		store: store,
+	}
+}
+
+// ListWebhookDeliveries is the delivery log of a subscription, newest first.
+// Use the "after" query param with the previous page's next_cursor to page,
+// and "status" to filter e.g. only dead deliveries.
+type ListWebhookDeliveries struct {
+	store repo.Store
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []ListWebhookDeliveriesItem `json:"deliveries"`
+	NextCursor *uuid.UUID                  `json:"next_cursor,omitempty"`
+}
+
+type ListWebhookDeliveriesItem struct {
+	ID             uuid.UUID                    `json:"id"`
+	EventID        uuid.UUID                    `json:"event_id"`
+	EventType      domain.EventType             `json:"event_type"`
+	Payload        json.RawMessage              `json:"payload"`
+	Status         domain.WebhookDeliveryStatus `json:"status"`
+	Attempts       int                          `json:"attempts"`
+	LastStatusCode *int                         `json:"last_status_code"`
+	LastError      *string                      `json:"last_error"`
+	NextAttemptAt  time.Time                    `json:"next_attempt_at"`
+	DeliveredAt    *time.Time                   `json:"delivered_at"`
+	CreatedAt      time.Time                    `json:"created_at"`
+}
+
+func (h *ListWebhookDeliveries) Handle(c *gin.Context) {
+	clientKeyID := c.GetHeader("BestWallet-Key-ID")
+	if clientKeyID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing key id")
+		return
+	}
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed webhook id")
+		return
+	}
+	status := domain.WebhookDeliveryStatus(c.Query("status"))
+	limit := defaultPageSize
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 || limit > maxPageSize {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxPageSize))
+			return
+		}
+	}
+	var after *uuid.UUID
+	if raw := c.Query("after"); raw != "" {
+		cursor, err := uuid.Parse(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, "malformed cursor")
+			return
+		}
+		after = &cursor
+	}
+
+	owned, err := h.store.Webhooks().OwnedBy(c, subscriptionID, clientKeyID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !owned {
+		c.AbortWithStatusJSON(http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	deliveries, err := h.store.Webhooks().Deliveries(c, subscriptionID, status, after, limit)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := ListWebhookDeliveriesResponse{Deliveries: make([]ListWebhookDeliveriesItem, 0, len(deliveries))}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, ListWebhookDeliveriesItem{
+			ID:             d.ID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			NextAttemptAt:  d.NextAttemptAt,
+			DeliveredAt:    d.DeliveredAt,
+			CreatedAt:      d.CreatedAt,
+		})
+	}
+	if len(deliveries) == limit {
+		resp.NextCursor = &deliveries[len(deliveries)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListWebhookDeliveries(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	id := addWebhook(t, m, testKeyID)
+	someoneElses := addWebhook(t, m, "other-key")
+	now := time.Now()
+	delivery := func(subscriptionID uuid.UUID, status domain.WebhookDeliveryStatus, age time.Duration) uuid.UUID {
+		return m.AddWebhookDelivery(repo.WebhookDelivery{
+			SubscriptionID: subscriptionID,
+			EventID:        uuid.New(),
+			EventType:      domain.EventTypeAccountOpened,
+			Payload:        []byte(`{}`),
+			Status:         status,
+			CreatedAt:      now.Add(-age),
+		}).ID
+	}
+	oldest := delivery(id, domain.WebhookDeliveryStatusDead, 3*time.Minute)
+	middle := delivery(id, domain.WebhookDeliveryStatusSucceeded, 2*time.Minute)
+	newest := delivery(id, domain.WebhookDeliveryStatusDead, time.Minute)
+	delivery(someoneElses, domain.WebhookDeliveryStatusDead, 0)
+	h := NewListWebhookDeliveries(m)
+	path := "/api/v1/webhooks/" + id.String() + "/deliveries"
+
+	tests := []struct {
+		name           string
+		path           string
+		wantCode       int
+		wantDeliveries []uuid.UUID
+		wantCursor     *uuid.UUID
+	}{
+		{name: "malformed id", path: "/api/v1/webhooks/nope/deliveries", wantCode: http.StatusBadRequest},
+		{name: "malformed cursor", path: path + "?after=x", wantCode: http.StatusBadRequest},
+		{name: "limit out of range", path: path + "?limit=0", wantCode: http.StatusBadRequest},
+		{name: "not found", path: "/api/v1/webhooks/" + uuid.NewString() + "/deliveries", wantCode: http.StatusNotFound},
+		{name: "someone else's", path: "/api/v1/webhooks/" + someoneElses.String() + "/deliveries", wantCode: http.StatusNotFound},
+		{name: "all", path: path, wantCode: http.StatusOK, wantDeliveries: []uuid.UUID{newest, middle, oldest}},
+		{name: "first page", path: path + "?limit=2", wantCode: http.StatusOK, wantDeliveries: []uuid.UUID{newest, middle}, wantCursor: &middle},
+		{name: "second page", path: path + "?limit=2&after=" + middle.String(), wantCode: http.StatusOK, wantDeliveries: []uuid.UUID{oldest}},
+		{name: "dead only", path: path + "?status=dead", wantCode: http.StatusOK, wantDeliveries: []uuid.UUID{newest, oldest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/webhooks/:id/deliveries", h.Handle, newRequest(http.MethodGet, tt.path, "", uuid.Nil))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			res := decode[ListWebhookDeliveriesResponse](t, w)
+			if len(res.Deliveries) != len(tt.wantDeliveries) {
+				t.Fatalf("expected %d deliveries, got %+v", len(tt.wantDeliveries), res.Deliveries)
+			}
+			for i, d := range res.Deliveries {
+				if d.ID != tt.wantDeliveries[i] {
+					t.Fatalf("expected delivery %d to be %s, got %s", i, tt.wantDeliveries[i], d.ID)
+				}
+			}
+			if (res.NextCursor == nil) != (tt.wantCursor == nil) || (res.NextCursor != nil && *res.NextCursor != *tt.wantCursor) {
+				t.Fatalf("expected next cursor %v, got %v", tt.wantCursor, res.NextCursor)
+			}
+		})
+	}
+}
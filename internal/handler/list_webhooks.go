@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewListWebhooks(
+	store repo.Store,
+) *ListWebhooks {
+	return &ListWebhooks{
+		store: store,
+	}
+}
+
+type ListWebhooks struct {
+	store repo.Store
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []ListWebhooksItem `json:"webhooks"`
+}
+
+type ListWebhooksItem struct {
+	ID         uuid.UUID          `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []domain.EventType `json:"event_types"`
+	Active     bool               `json:"active"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+func (h *ListWebhooks) Handle(c *gin.Context) {
+	clientKeyID := c.GetHeader("BestWallet-Key-ID")
+	if clientKeyID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing key id")
+		return
+	}
+
+	webhooks, err := h.store.Webhooks().ListByClient(c, clientKeyID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := ListWebhooksResponse{Webhooks: make([]ListWebhooksItem, 0, len(webhooks))}
+	for _, w := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, ListWebhooksItem{
+			ID:         w.ID,
+			URL:        w.URL,
+			EventTypes: w.EventTypes,
+			Active:     w.Active,
+			CreatedAt:  w.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListWebhooks(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	older := addWebhook(t, m, testKeyID)
+	newer := addWebhook(t, m, testKeyID)
+	addWebhook(t, m, "other-key")
+	if _, err := m.Webhooks().Deactivate(context.Background(), older, testKeyID); err != nil {
+		t.Fatal(err)
+	}
+	h := NewListWebhooks(m)
+
+	// Act.
+	w := serve(http.MethodGet, "/api/v1/webhooks", h.Handle, newRequest(http.MethodGet, "/api/v1/webhooks", "", uuid.Nil))
+
+	// Assert.
+	assertCode(t, w, http.StatusOK)
+	res := decode[ListWebhooksResponse](t, w)
+	if len(res.Webhooks) != 2 || res.Webhooks[0].ID != newer || res.Webhooks[1].ID != older {
+		t.Fatalf("expected webhooks %s, %s, got %+v", newer, older, res.Webhooks)
+	}
+	if !res.Webhooks[0].Active || res.Webhooks[1].Active {
+		t.Fatalf("expected only %s active, got %+v", newer, res.Webhooks)
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestPauseScheduledTransfer(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	otherID, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	active := addScheduledTransfer(t, m, from, to, tomorrow, "", domain.ScheduledTransferStatusActive)
+	cancelled := addScheduledTransfer(t, m, from, to, tomorrow, "", domain.ScheduledTransferStatusCancelled)
+	h := NewPauseScheduledTransfer(m)
+	path := func(id uuid.UUID) string { return "/api/v1/scheduled-transfers/" + id.String() + "/pause" }
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(active), wantCode: http.StatusBadRequest},
+		{name: "malformed id", customerID: customerID, path: "/api/v1/scheduled-transfers/nope/pause", wantCode: http.StatusBadRequest},
+		{name: "not found", customerID: customerID, path: path(uuid.New()), wantCode: http.StatusNotFound},
+		{name: "someone else's", customerID: otherID, path: path(active), wantCode: http.StatusNotFound},
+		{name: "cancelled", customerID: customerID, path: path(cancelled), wantCode: http.StatusNotFound},
+		{name: "paused", customerID: customerID, path: path(active), wantCode: http.StatusNoContent},
+		{name: "paused already", customerID: customerID, path: path(active), wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/scheduled-transfers/:id/pause", h.Handle, newRequest(http.MethodPost, tt.path, "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusNoContent {
+				return
+			}
+			if s := scheduledTransfer(t, m, active, customerID); s.Status != domain.ScheduledTransferStatusPaused {
+				t.Fatalf("expected status %s, got %s", domain.ScheduledTransferStatusPaused, s.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewQuoteFee(
+	store repo.Store,
+) *QuoteFee {
+	return &QuoteFee{
+		store: store,
+	}
+}
+
+// QuoteFee tells the customer what a transfer or withdrawal would cost if it
+// was made now, e.g. to show it before they confirm.
+type QuoteFee struct {
+	store repo.Store
+}
+
+// feeKinds are the transaction kinds that can have a fee.
+var feeKinds = map[domain.TransactionKind]bool{
+	domain.TransactionKindTransfer:   true,
+	domain.TransactionKindWithdrawal: true,
+}
+
+type QuoteFeeResponse struct {
+	Kind          domain.TransactionKind `json:"kind"`
+	Currency      string                 `json:"currency"`
+	Amount        string                 `json:"amount"`
+	Fee           string                 `json:"fee"`
+	Total         string                 `json:"total"`          // Debited from the account.
+	FreeRemaining int                    `json:"free_remaining"` // Free movements left this month after this one.
+}
+
+func (h *QuoteFee) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Validate request.
+	kind := domain.TransactionKind(c.Query("kind"))
+	if !feeKinds[kind] {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "kind must be transfer or withdrawal")
+		return
+	}
+	amount, err := domain.ParseAmount(c.Query("amount"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	currency := c.DefaultQuery("currency", domain.DefaultCurrency)
+
+	quote, err := h.store.Customers().QuoteFee(c, customerID, kind, currency, amount, time.Now())
+	switch {
+	case errors.Is(err, repo.ErrCustomerNotFound):
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, QuoteFeeResponse{
+		Kind:          kind,
+		Currency:      currency,
+		Amount:        domain.FormatAmount(amount),
+		Fee:           domain.FormatAmount(quote.Fee),
+		Total:         domain.FormatAmount(amount + quote.Fee),
+		FreeRemaining: quote.FreeRemaining,
+	})
+}
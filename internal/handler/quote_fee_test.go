@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestQuoteFee(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	otherID, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	m.SetFeeSchedule(domain.KYCTierBasic, domain.TransactionKindTransfer, domain.DefaultCurrency, fees.Schedule{Fixed: 25, FreePerMonth: 1})
+	clearedTransfer(t, m, from, to, 100) // Uses up the free one.
+	h := NewQuoteFee(m)
+	path := func(kind, amount string) string { return "/api/v1/fees/quote?kind=" + kind + "&amount=" + amount }
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		wantCode   int
+		want       QuoteFeeResponse
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path("transfer", "1.00"), wantCode: http.StatusBadRequest},
+		{name: "unknown kind", customerID: customerID, path: path("deposit", "1.00"), wantCode: http.StatusBadRequest},
+		{name: "malformed amount", customerID: customerID, path: path("transfer", "x"), wantCode: http.StatusBadRequest},
+		{name: "customer not found", customerID: uuid.New(), path: path("transfer", "1.00"), wantCode: http.StatusBadRequest},
+		{
+			name:       "fee",
+			customerID: customerID,
+			path:       path("transfer", "1.00"),
+			wantCode:   http.StatusOK,
+			want:       QuoteFeeResponse{Kind: domain.TransactionKindTransfer, Currency: domain.DefaultCurrency, Amount: "1.00", Fee: "0.25", Total: "1.25"},
+		},
+		{
+			name:       "free",
+			customerID: otherID,
+			path:       path("transfer", "1.00"),
+			wantCode:   http.StatusOK,
+			want:       QuoteFeeResponse{Kind: domain.TransactionKindTransfer, Currency: domain.DefaultCurrency, Amount: "1.00", Fee: "0.00", Total: "1.00"},
+		},
+		{
+			name:       "no schedule",
+			customerID: customerID,
+			path:       path("withdrawal", "1.00"),
+			wantCode:   http.StatusOK,
+			want:       QuoteFeeResponse{Kind: domain.TransactionKindWithdrawal, Currency: domain.DefaultCurrency, Amount: "1.00", Fee: "0.00", Total: "1.00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodGet, "/api/v1/fees/quote", h.Handle, newRequest(http.MethodGet, tt.path, "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if resp := decode[QuoteFeeResponse](t, w); resp != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, resp)
+			}
+		})
+	}
+}
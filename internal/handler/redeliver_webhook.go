@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewRedeliverWebhook(
+	store repo.Store,
+) *RedeliverWebhook {
+	return &RedeliverWebhook{
+		store: store,
+	}
+}
+
+// RedeliverWebhook schedules a delivery (typically a dead one) to be sent
+// again right away, with a fresh set of retry attempts.
+type RedeliverWebhook struct {
+	store repo.Store
+}
+
+func (h *RedeliverWebhook) Handle(c *gin.Context) {
+	clientKeyID := c.GetHeader("BestWallet-Key-ID")
+	if clientKeyID == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing key id")
+		return
+	}
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed webhook id")
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed delivery id")
+		return
+	}
+
+	found, err := h.store.Webhooks().Redeliver(c, deliveryID, subscriptionID, clientKeyID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "delivery not found")
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
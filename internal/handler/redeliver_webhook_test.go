@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestRedeliverWebhook(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	id := addWebhook(t, m, testKeyID)
+	someoneElses := addWebhook(t, m, "other-key")
+	dead := func(subscriptionID uuid.UUID) uuid.UUID {
+		return m.AddWebhookDelivery(repo.WebhookDelivery{
+			SubscriptionID: subscriptionID,
+			EventID:        uuid.New(),
+			EventType:      domain.EventTypeAccountOpened,
+			Payload:        []byte(`{}`),
+			Status:         domain.WebhookDeliveryStatusDead,
+			Attempts:       8,
+			NextAttemptAt:  time.Now().Add(-time.Hour),
+		}).ID
+	}
+	ours := dead(id)
+	theirs := dead(someoneElses)
+	h := NewRedeliverWebhook(m)
+	path := func(subscriptionID, deliveryID uuid.UUID) string {
+		return "/api/v1/webhooks/" + subscriptionID.String() + "/deliveries/" + deliveryID.String() + "/redeliver"
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+	}{
+		{name: "malformed webhook id", path: "/api/v1/webhooks/nope/deliveries/" + ours.String() + "/redeliver", wantCode: http.StatusBadRequest},
+		{name: "malformed delivery id", path: "/api/v1/webhooks/" + id.String() + "/deliveries/nope/redeliver", wantCode: http.StatusBadRequest},
+		{name: "not found", path: path(id, uuid.New()), wantCode: http.StatusNotFound},
+		{name: "another webhook's", path: path(id, theirs), wantCode: http.StatusNotFound},
+		{name: "someone else's", path: path(someoneElses, theirs), wantCode: http.StatusNotFound},
+		{name: "redelivered", path: path(id, ours), wantCode: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/webhooks/:id/deliveries/:delivery_id/redeliver", h.Handle, newRequest(http.MethodPost, tt.path, "", uuid.Nil))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+		})
+	}
+
+	ctx := context.Background()
+	got, err := m.Webhooks().Deliveries(ctx, id, "", nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got[0]; d.Status != domain.WebhookDeliveryStatusPending || d.Attempts != 0 || d.NextAttemptAt.After(time.Now()) || d.NextAttemptAt.Before(time.Now().Add(-time.Minute)) {
+		t.Fatalf("expected a pending delivery due now with no attempts, got %+v", d)
+	}
+	got, err = m.Webhooks().Deliveries(ctx, someoneElses, "", nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Status != domain.WebhookDeliveryStatusDead {
+		t.Fatalf("expected someone else's delivery to stay %s, got %s", domain.WebhookDeliveryStatusDead, got[0].Status)
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+func NewResumeScheduledTransfer(
+	store repo.Store,
+) *ResumeScheduledTransfer {
+	return &ResumeScheduledTransfer{
+		store: store,
+	}
+}
+
+// ResumeScheduledTransfer reactivates a paused scheduled transfer. Occurrences
+// that fell due while it was paused are not executed, it continues with the
+// first one that's still in the future.
+type ResumeScheduledTransfer struct {
+	store repo.Store
+}
+
+type ResumeScheduledTransferResponse struct {
+	Status    domain.ScheduledTransferStatus `json:"status"`
+	NextRunAt *time.Time                     `json:"next_run_at"`
+}
+
+func (h *ResumeScheduledTransfer) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed scheduled transfer id")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	s, found, err := tx.ScheduledTransfers().LockPaused(c, id, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(http.StatusNotFound, "paused scheduled transfer not found")
+		return
+	}
+
+	// Find the first occurrence that's not in the past.
+	now := time.Now()
+	res := ResumeScheduledTransferResponse{Status: domain.ScheduledTransferStatusCompleted}
+	next := s.NextOccurrence
+	if s.Recurrence == nil {
+		if !s.StartAt.Before(now) {
+			res.Status, res.NextRunAt = domain.ScheduledTransferStatusActive, &s.StartAt
+		}
+	} else {
+		r, err := util.ParseRecurrence(*s.Recurrence)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		for ; ; next++ {
+			t, ok := r.Occurrence(s.StartAt, next)
+			if !ok {
+				break
+			}
+			if !t.Before(now) {
+				res.Status, res.NextRunAt = domain.ScheduledTransferStatusActive, &t
+				break
+			}
+		}
+	}
+
+	if err := tx.ScheduledTransfers().Resume(c, id, res.Status, next, res.NextRunAt); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestResumeScheduledTransfer(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	otherID, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	now := time.Now().UTC().Truncate(time.Second)
+	tomorrow := now.Add(24 * time.Hour)
+	active := addScheduledTransfer(t, m, from, to, tomorrow, "", domain.ScheduledTransferStatusActive)
+	future := addScheduledTransfer(t, m, from, to, tomorrow, "", domain.ScheduledTransferStatusPaused)
+	missed := addScheduledTransfer(t, m, from, to, now.Add(-time.Hour), "", domain.ScheduledTransferStatusPaused)
+	recurring := addScheduledTransfer(t, m, from, to, now.Add(-36*time.Hour), "FREQ=DAILY;INTERVAL=1", domain.ScheduledTransferStatusPaused)
+	ended := addScheduledTransfer(t, m, from, to, now.Add(-72*time.Hour), "FREQ=DAILY;INTERVAL=1;COUNT=2", domain.ScheduledTransferStatusPaused)
+	h := NewResumeScheduledTransfer(m)
+	path := func(id uuid.UUID) string { return "/api/v1/scheduled-transfers/" + id.String() + "/resume" }
+
+	tests := []struct {
+		name           string
+		customerID     uuid.UUID
+		id             uuid.UUID
+		path           string
+		wantCode       int
+		wantStatus     domain.ScheduledTransferStatus
+		wantNext       time.Time // Zero for none.
+		wantOccurrence int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(future), wantCode: http.StatusBadRequest},
+		{name: "malformed id", customerID: customerID, path: "/api/v1/scheduled-transfers/nope/resume", wantCode: http.StatusBadRequest},
+		{name: "not found", customerID: customerID, path: path(uuid.New()), wantCode: http.StatusNotFound},
+		{name: "someone else's", customerID: otherID, path: path(future), wantCode: http.StatusNotFound},
+		{name: "not paused", customerID: customerID, path: path(active), wantCode: http.StatusNotFound},
+		{
+			name:       "one-off still ahead",
+			customerID: customerID,
+			id:         future,
+			path:       path(future),
+			wantCode:   http.StatusOK,
+			wantStatus: domain.ScheduledTransferStatusActive,
+			wantNext:   tomorrow,
+		},
+		{
+			name:       "one-off missed",
+			customerID: customerID,
+			id:         missed,
+			path:       path(missed),
+			wantCode:   http.StatusOK,
+			wantStatus: domain.ScheduledTransferStatusCompleted,
+		},
+		{
+			name:           "recurring skips missed occurrences",
+			customerID:     customerID,
+			id:             recurring,
+			path:           path(recurring),
+			wantCode:       http.StatusOK,
+			wantStatus:     domain.ScheduledTransferStatusActive,
+			wantNext:       now.Add(12 * time.Hour),
+			wantOccurrence: 2,
+		},
+		{
+			name:           "recurring ended",
+			customerID:     customerID,
+			id:             ended,
+			path:           path(ended),
+			wantCode:       http.StatusOK,
+			wantStatus:     domain.ScheduledTransferStatusCompleted,
+			wantOccurrence: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/scheduled-transfers/:id/resume", h.Handle, newRequest(http.MethodPost, tt.path, "", tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			resp := decode[ResumeScheduledTransferResponse](t, w)
+			if resp.Status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %s", tt.wantStatus, resp.Status)
+			}
+			if (resp.NextRunAt == nil) != tt.wantNext.IsZero() || (resp.NextRunAt != nil && !resp.NextRunAt.Equal(tt.wantNext)) {
+				t.Fatalf("expected the next run at %s, got %v", tt.wantNext, resp.NextRunAt)
+			}
+			s := scheduledTransfer(t, m, tt.id, customerID)
+			if s.Status != tt.wantStatus || s.NextOccurrence != tt.wantOccurrence {
+				t.Fatalf("expected status %s at occurrence %d, got %+v", tt.wantStatus, tt.wantOccurrence, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewReverseTransaction(
+	store repo.Store,
+) *ReverseTransaction {
+	return &ReverseTransaction{
+		store: store,
+	}
+}
+
+// ReverseTransaction refunds a cleared transaction, fully or partially. Only
+// the customer that received the money can give it back, so the refund is
+// debited from their account. Like transfers, reversals are cleared in the
+// background.
+//
+// Clients should send an Idempotency-Key header, retrying a request with the
+// same key returns the original reversal instead of creating a new one.
+type ReverseTransaction struct {
+	store repo.Store
+}
+
+type ReverseTransactionRequest struct {
+	Amount      string `json:"amount,omitempty"` // Decimal e.g. "12.34", empty reverses everything that's left.
+	Description string `json:"description,omitempty"`
+}
+
+type ReverseTransactionResponse struct {
+	TransactionID uuid.UUID                `json:"transaction_id"`
+	Status        domain.TransactionStatus `json:"status"`
+	ReversalOf    uuid.UUID                `json:"reversal_of"`
+	Amount        string                   `json:"amount"`
+}
+
+func (h *ReverseTransaction) Handle(c *gin.Context) {
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed transaction id")
+		return
+	}
+
+	// Validate request.
+	var req ReverseTransactionRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	var amount int64
+	if req.Amount != "" {
+		if amount, err = domain.ParseAmount(req.Amount); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	var idempotencyKey string
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		idempotencyKey = "reversal:" + customerID.String() + ":" + key
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	credited, err := tx.Ledger().CreditedCustomer(c, id, customerID)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !credited {
+		c.AbortWithStatusJSON(http.StatusNotFound, "transaction not found")
+		return
+	}
+
+	t, _, err := tx.Ledger().Reverse(c, ledger.Reversal{
+		TransactionID:  id,
+		Amount:         amount,
+		Description:    req.Description,
+		IdempotencyKey: idempotencyKey,
+	})
+	switch {
+	case errors.Is(err, ledger.ErrNotReversible), errors.Is(err, ledger.ErrReversalTooLarge):
+		c.AbortWithStatusJSON(http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, ledger.ErrInsufficientFunds):
+		c.AbortWithStatusJSON(http.StatusBadRequest, "insufficient funds")
+		return
+	case errors.Is(err, ledger.ErrAccountFrozen):
+		c.AbortWithStatusJSON(http.StatusConflict, "account is frozen")
+		return
+	case err != nil:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if t.ReversalOf == nil || *t.ReversalOf != id { // Idempotency key reused for something else.
+		c.AbortWithStatusJSON(http.StatusConflict, "idempotency key already used")
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, ReverseTransactionResponse{
+		TransactionID: t.ID,
+		Status:        t.Status,
+		ReversalOf:    id,
+		Amount:        domain.FormatAmount(t.Amount),
+	})
+}
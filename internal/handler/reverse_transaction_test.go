@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestReverseTransaction(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	payerID, payer := addCustomer(m, domain.KYCStatusApproved, 1000)
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 0)
+	cleared := clearedTransfer(t, m, payer, account, 500)
+	pending := pendingTransfer(t, m, payer, account, 100)
+	h := NewReverseTransaction(m)
+	path := func(id uuid.UUID) string { return "/api/v1/transactions/" + id.String() + "/reverse" }
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		body       string
+		wantCode   int
+		wantAmount string
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(cleared.ID), body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "malformed transaction id", customerID: customerID, path: "/api/v1/transactions/nope/reverse", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "malformed amount", customerID: customerID, path: path(cleared.ID), body: `{"amount": "x"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown transaction", customerID: customerID, path: path(uuid.New()), body: `{}`, wantCode: http.StatusNotFound},
+		{name: "payer can't reverse", customerID: payerID, path: path(cleared.ID), body: `{}`, wantCode: http.StatusNotFound},
+		{name: "not cleared", customerID: customerID, path: path(pending.ID), body: `{}`, wantCode: http.StatusConflict},
+		{name: "too large", customerID: customerID, path: path(cleared.ID), body: `{"amount": "5.01"}`, wantCode: http.StatusConflict},
+		{name: "partially", customerID: customerID, path: path(cleared.ID), body: `{"amount": "1.50"}`, wantCode: http.StatusAccepted, wantAmount: "1.50"},
+		{name: "what's left", customerID: customerID, path: path(cleared.ID), body: `{}`, wantCode: http.StatusAccepted, wantAmount: "3.50"},
+		{name: "nothing left", customerID: customerID, path: path(cleared.ID), body: `{}`, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/transactions/:id/reverse", h.Handle, newRequest(http.MethodPost, tt.path, tt.body, tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			resp := decode[ReverseTransactionResponse](t, w)
+			if resp.ReversalOf != cleared.ID || resp.Amount != tt.wantAmount || resp.Status != domain.TransactionStatusPending {
+				t.Fatalf("expected a pending reversal of %s for %s, got %+v", cleared.ID, tt.wantAmount, resp)
+			}
+		})
+	}
+}
+
+func TestReverseTransaction_KeyReused(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	_, payer := addCustomer(m, domain.KYCStatusApproved, 1000)
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 0)
+	first := clearedTransfer(t, m, payer, account, 100)
+	second := clearedTransfer(t, m, payer, account, 200)
+	h := NewReverseTransaction(m)
+	reverse := func(id uuid.UUID) *http.Request {
+		req := newRequest(http.MethodPost, "/api/v1/transactions/"+id.String()+"/reverse", `{}`, customerID)
+		req.Header.Set("Idempotency-Key", "refund-1")
+		return req
+	}
+	assertCode(t, serve(http.MethodPost, "/api/v1/transactions/:id/reverse", h.Handle, reverse(first.ID)), http.StatusAccepted)
+
+	// Act.
+	w := serve(http.MethodPost, "/api/v1/transactions/:id/reverse", h.Handle, reverse(second.ID))
+
+	// Assert.
+	assertCode(t, w, http.StatusConflict)
+}
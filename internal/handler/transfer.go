@@ -1,15 +1,202 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
 
-func NewTransfer() *Transfer {
-	return &Transfer{}
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewTransfer(
+	store repo.Store,
+) *Transfer {
+	return &Transfer{
+		store: store,
+	}
 }
 
+// Transfer moves money between 2 accounts in the wallet. The funds (and the
+// fee, if any) are reserved right away, the transfer is cleared in the
+// background (after KYT).
+//
+// Clients should send an Idempotency-Key header, retrying a request with the
+// same key returns the original transfer instead of creating a new one.
 type Transfer struct {
-	// deps
+	store repo.Store
+}
+
+type TransferRequest struct {
+	FromAccount string `json:"from_account"`
+	ToAccount   string `json:"to_account"`
+	Amount      string `json:"amount"` // Decimal e.g. "12.34".
+	Description string `json:"description,omitempty"`
+}
+
+type TransferResponse struct {
+	TransactionID uuid.UUID                `json:"transaction_id"`
+	Status        domain.TransactionStatus `json:"status"`
+	Fee           string                   `json:"fee"`
 }
 
 func (h *Transfer) Handle(c *gin.Context) {
-	panic("not implemented")
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Validate request.
+	var req TransferRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	if err := domain.ValidateAccountNumber(req.FromAccount); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := domain.ValidateAccountNumber(req.ToAccount); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.FromAccount == req.ToAccount {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "can't transfer to the same account")
+		return
+	}
+	amount, err := domain.ParseAmount(req.Amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var idempotencyKey string
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		idempotencyKey = "transfer:" + customerID.String() + ":" + key
+	}
+
+	// Only customers with approved KYC can move money.
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not verified")
+		return
+	}
+
+	// Resolve accounts, the source account must belong to the customer.
+	from, err := h.store.Accounts().GetByNumber(c, req.FromAccount)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !from.BelongsTo(customerID) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "account not found")
+		return
+	}
+	to, err := h.store.Accounts().GetByNumber(c, req.ToAccount)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || to.Kind != domain.AccountKindCustomer {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "destination account not found")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Retries are answered with the original transfer, even if the limits
+	// are used up by now.
+	t, found, err := findRetried(c, tx.Ledger(), idempotencyKey)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		if err := tx.Ledger().CheckLimit(c, customerID, domain.LimitKindTransferOut, from.Currency, amount, time.Now()); err != nil {
+			if !abortLimitExceeded(c, err) {
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		quote, err := tx.Ledger().QuoteFee(c, customerID, domain.TransactionKindTransfer, from.Currency, amount, time.Now())
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		t = ledger.Transaction{
+			Kind:           domain.TransactionKindTransfer,
+			Amount:         amount,
+			Currency:       from.Currency,
+			Description:    req.Description,
+			IdempotencyKey: idempotencyKey,
+			Legs: []ledger.Leg{
+				{AccountID: from.ID, Amount: -amount},
+				{AccountID: to.ID, Amount: amount},
+			},
+		}
+		if err := tx.Ledger().ChargeFee(c, &t, from.ID, quote.Fee); err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		t, _, err = tx.Ledger().Initiate(c, t)
+		switch {
+		case errors.Is(err, ledger.ErrInsufficientFunds):
+			c.AbortWithStatusJSON(http.StatusBadRequest, "insufficient funds")
+			return
+		case errors.Is(err, ledger.ErrAccountFrozen):
+			c.AbortWithStatusJSON(http.StatusConflict, "account is frozen")
+			return
+		case errors.Is(err, ledger.ErrCurrencyMismatch):
+			c.AbortWithStatusJSON(http.StatusBadRequest, "accounts are in different currencies")
+			return
+		case err != nil:
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, TransferResponse{
+		TransactionID: t.ID,
+		Status:        t.Status,
+		Fee:           domain.FormatAmount(t.Fee),
+	})
+}
+
+// findRetried returns the transaction initiated by an earlier request with the
+// same idempotency key, if any.
+func findRetried(ctx context.Context, l repo.Ledger, idempotencyKey string) (ledger.Transaction, bool, error) {
+	if idempotencyKey == "" {
+		return ledger.Transaction{}, false, nil
+	}
+	return l.FindByIdempotencyKey(ctx, idempotencyKey)
 }
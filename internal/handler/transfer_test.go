@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestTransfer(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, from := addCustomer(m, domain.KYCStatusApproved, 1000)
+	_, to := addCustomer(m, domain.KYCStatusApproved, 0)
+	pendingID, pendingAccount := addCustomer(m, domain.KYCStatusPending, 1000)
+	usd := m.AddAccount(repo.Account{CustomerID: &customerID, Currency: "USD"})
+	internal := m.AddAccount(repo.Account{Number: uuid.NewString()})
+	h := NewTransfer(m)
+	body := func(from, to repo.Account, amount string) string {
+		return fmt.Sprintf(`{"from_account": %q, "to_account": %q, "amount": %q}`, from.Number, to.Number, amount)
+	}
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		body       string
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, body: body(from, to, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "malformed number", customerID: customerID, body: body(from, repo.Account{Number: "nope"}, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "same account", customerID: customerID, body: body(from, from, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "zero amount", customerID: customerID, body: body(from, to, "0"), wantCode: http.StatusBadRequest},
+		{name: "customer not verified", customerID: pendingID, body: body(pendingAccount, to, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "someone else's account", customerID: customerID, body: body(to, from, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "unknown destination", customerID: customerID, body: body(from, repo.Account{Number: uuid.NewString()}, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "internal destination", customerID: customerID, body: body(from, internal, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "different currencies", customerID: customerID, body: body(from, usd, "1.00"), wantCode: http.StatusBadRequest},
+		{name: "insufficient funds", customerID: customerID, body: body(from, to, "10.01"), wantCode: http.StatusBadRequest},
+		{name: "accepted", customerID: customerID, body: body(from, to, "10.00"), wantCode: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/accounts/transfer", h.Handle, newRequest(http.MethodPost, "/api/v1/accounts/transfer", tt.body, tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			resp := decode[TransferResponse](t, w)
+			if resp.Status != domain.TransactionStatusPending || resp.Fee != "0.00" {
+				t.Fatalf("expected a pending transfer without a fee, got %+v", resp)
+			}
+			want := []ledger.Leg{{AccountID: from.ID, Amount: -1000}, {AccountID: to.ID, Amount: 1000}}
+			transactions := m.Transactions()
+			got := transactions[len(transactions)-1].Legs
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("expected legs %v, got %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewUploadKYCDocument(
+	store repo.Store,
+	documents blob.BlobStore,
+) *UploadKYCDocument {
+	return &UploadKYCDocument{
+		store:     store,
+		documents: documents,
+	}
+}
+
+// UploadKYCDocument takes an identity document or proof of address as a
+// multipart form with a "kind" and a "file" field. Uploading after a
+// rejection re-submits the customer for KYC.
+type UploadKYCDocument struct {
+	store     repo.Store
+	documents blob.BlobStore
+}
+
+func (h *UploadKYCDocument) Handle(c *gin.Context) {
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Leave some room for the rest of the form.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, kyc.MaxDocumentSize+64<<10)
+	if err := c.Request.ParseMultipartForm(kyc.MaxDocumentSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, "document too large, the limit is 10MB")
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed multipart form")
+		return
+	}
+
+	kind := domain.KYCDocumentKind(c.PostForm("kind"))
+	if !kind.Valid() {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "kind must be passport, id_card, driving_licence or proof_of_address")
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing file")
+		return
+	}
+	if header.Size > kyc.MaxDocumentSize {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, "document too large, the limit is 10MB")
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, kyc.MaxDocumentSize))
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	contentType, err := kyc.SniffContentType(data)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	// Approved customers are done with KYC.
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus == domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusConflict, "customer already verified")
+		return
+	}
+
+	doc := kyc.NewDocument(customerID, kind, header.Filename, contentType, data)
+	if err := kyc.SaveFile(c, h.documents, doc, data); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	if err := tx.Customers().AddDocument(c, doc); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus == domain.KYCStatusRejected {
+		err := tx.Customers().TransitionKYC(c, customerID, customer.KYCStatus, domain.KYCStatusPending, "documents re-submitted", apiActor(c))
+		switch {
+		case errors.Is(err, kyc.ErrStatusChanged):
+			c.AbortWithStatusJSON(http.StatusConflict, "KYC status changed, try again")
+			return
+		case err != nil:
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Continue the KYC process in the background (see consumer.RunKYC).
+	if err := tx.Outbox().Write(c, domain.EventTypeKYCDocumentUploaded, customerID, domain.KYCDocumentUploadedPayload{
+		CustomerID: customerID,
+		DocumentID: doc.ID,
+		Kind:       kind,
+	}); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, doc)
+}
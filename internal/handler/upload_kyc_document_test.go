@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestUploadKYCDocument(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	pendingID, _ := addCustomer(m, domain.KYCStatusInProgress, 0)
+	rejectedID, _ := addCustomer(m, domain.KYCStatusRejected, 0)
+	approvedID, _ := addCustomer(m, domain.KYCStatusApproved, 0)
+	documents, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewUploadKYCDocument(m, documents)
+	path := func(id uuid.UUID) string { return "/api/v1/customers/" + id.String() + "/documents" }
+	pdf := []byte("%PDF-1.4")
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantCode   int
+		customerID uuid.UUID
+		wantStatus domain.KYCStatus
+	}{
+		{name: "malformed customer id", req: uploadRequest(t, "/api/v1/customers/nope/documents", "passport", pdf), wantCode: http.StatusBadRequest},
+		{name: "malformed form", req: newRequest(http.MethodPost, path(pendingID), `{}`, uuid.Nil), wantCode: http.StatusBadRequest},
+		{name: "unknown kind", req: uploadRequest(t, path(pendingID), "selfie", pdf), wantCode: http.StatusBadRequest},
+		{name: "unsupported content type", req: uploadRequest(t, path(pendingID), "passport", []byte("hello")), wantCode: http.StatusUnsupportedMediaType},
+		{name: "customer not found", req: uploadRequest(t, path(uuid.New()), "passport", pdf), wantCode: http.StatusNotFound},
+		{name: "already verified", req: uploadRequest(t, path(approvedID), "passport", pdf), wantCode: http.StatusConflict},
+		{
+			name:       "uploaded",
+			req:        uploadRequest(t, path(pendingID), "passport", pdf),
+			wantCode:   http.StatusCreated,
+			customerID: pendingID,
+			wantStatus: domain.KYCStatusInProgress,
+		},
+		{
+			name:       "re-submitted after a rejection",
+			req:        uploadRequest(t, path(rejectedID), "proof_of_address", pdf),
+			wantCode:   http.StatusCreated,
+			customerID: rejectedID,
+			wantStatus: domain.KYCStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/customers/:id/documents", h.Handle, tt.req)
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			doc := decode[kyc.Document](t, w)
+			if doc.Status != domain.KYCDocumentStatusUploaded || doc.ContentType != "application/pdf" {
+				t.Fatalf("expected an uploaded PDF, got %+v", doc)
+			}
+			stored, err := m.Customers().Documents(context.Background(), tt.customerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored) != 1 || stored[0].ID != doc.ID {
+				t.Fatalf("expected document %s to be stored, got %+v", doc.ID, stored)
+			}
+			if file, err := documents.Get(context.Background(), stored[0].BlobKey); err != nil || !bytes.Equal(file, pdf) {
+				t.Fatalf("expected the file in the blob store, got %q, %v", file, err)
+			}
+			customer, err := m.Customers().Get(context.Background(), tt.customerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if customer.KYCStatus != tt.wantStatus {
+				t.Fatalf("expected KYC status %s, got %s", tt.wantStatus, customer.KYCStatus)
+			}
+			assertEvent(t, m, domain.EventTypeKYCDocumentUploaded, tt.customerID)
+		})
+	}
+}
+
+// uploadRequest is a multipart KYC document upload.
+func uploadRequest(t *testing.T, path, kind string, file []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("kind", kind); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := mw.CreateFormFile("file", "passport.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := newRequest(http.MethodPost, path, body.String(), uuid.Nil)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
@@ -1,15 +1,176 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net/http"
+	"time"
 
-func NewWithdraw() *Withdraw {
-	return &Withdraw{}
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func NewWithdraw(
+	store repo.Store,
+) *Withdraw {
+	return &Withdraw{
+		store: store,
+	}
 }
 
+// Withdraw debits money leaving the wallet (e.g. a payout the client app
+// makes to the customer's bank) from a customer account to the settlement
+// account. The funds (and the fee, if any) are reserved right away, the
+// withdrawal is cleared in the background (after KYT).
+//
+// Clients should send an Idempotency-Key header, retrying a request with the
+// same key returns the original withdrawal instead of creating a new one.
 type Withdraw struct {
-	// deps
+	store repo.Store
+}
+
+type WithdrawRequest struct {
+	Amount      string `json:"amount"` // Decimal e.g. "12.34".
+	Description string `json:"description,omitempty"`
+}
+
+type WithdrawResponse struct {
+	TransactionID uuid.UUID                `json:"transaction_id"`
+	Status        domain.TransactionStatus `json:"status"`
+	Fee           string                   `json:"fee"`
 }
 
 func (h *Withdraw) Handle(c *gin.Context) {
-	panic("not implemented")
+	// Read customer id.
+	customerIDRaw := c.GetHeader("BestWallet-Customer-ID")
+	if customerIDRaw == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "missing customer id")
+		return
+	}
+	customerID, err := uuid.Parse(customerIDRaw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "malformed customer id")
+		return
+	}
+
+	// Validate request.
+	number := c.Param("number")
+	if err := domain.ValidateAccountNumber(number); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var req WithdrawRequest
+	if err := c.BindJSON(&req); err != nil {
+		return
+	}
+	amount, err := domain.ParseAmount(req.Amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	var idempotencyKey string
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		idempotencyKey = "withdrawal:" + customerID.String() + ":" + key
+	}
+
+	// Only customers with approved KYC can move money.
+	customer, err := h.store.Customers().Get(c, customerID)
+	if errors.Is(err, repo.ErrCustomerNotFound) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not found")
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if customer.KYCStatus != domain.KYCStatusApproved {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "customer not verified")
+		return
+	}
+
+	account, err := h.store.Accounts().GetByNumber(c, number)
+	if err != nil && !errors.Is(err, repo.ErrAccountNotFound) {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !account.BelongsTo(customerID) {
+		c.AbortWithStatusJSON(http.StatusNotFound, "account not found")
+		return
+	}
+
+	tx, err := beginAuditedWork(c, h.store)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer tx.Rollback(c)
+
+	// Retries are answered with the original withdrawal, even if the limits
+	// are used up by now.
+	t, found, err := findRetried(c, tx.Ledger(), idempotencyKey)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		if err := tx.Ledger().CheckLimit(c, customerID, domain.LimitKindWithdrawal, account.Currency, amount, time.Now()); err != nil {
+			if !abortLimitExceeded(c, err) {
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		settlementID, err := tx.Ledger().InternalAccountID(c, domain.InternalAccountSettlement, account.Currency)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		quote, err := tx.Ledger().QuoteFee(c, customerID, domain.TransactionKindWithdrawal, account.Currency, amount, time.Now())
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		t = ledger.Transaction{
+			Kind:           domain.TransactionKindWithdrawal,
+			Amount:         amount,
+			Currency:       account.Currency,
+			Description:    req.Description,
+			IdempotencyKey: idempotencyKey,
+			Legs: []ledger.Leg{
+				{AccountID: account.ID, Amount: -amount},
+				{AccountID: settlementID, Amount: amount},
+			},
+		}
+		if err := tx.Ledger().ChargeFee(c, &t, account.ID, quote.Fee); err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		t, _, err = tx.Ledger().Initiate(c, t)
+		switch {
+		case errors.Is(err, ledger.ErrInsufficientFunds):
+			c.AbortWithStatusJSON(http.StatusBadRequest, "insufficient funds")
+			return
+		case errors.Is(err, ledger.ErrAccountFrozen):
+			c.AbortWithStatusJSON(http.StatusConflict, "account is frozen")
+			return
+		case err != nil:
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(c); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, WithdrawResponse{
+		TransactionID: t.ID,
+		Status:        t.Status,
+		Fee:           domain.FormatAmount(t.Fee),
+	})
 }
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestWithdraw(t *testing.T) {
+	// Arrange.
+	m := repo.NewMemory()
+	customerID, account := addCustomer(m, domain.KYCStatusApproved, 10000)
+	frozen := m.AddAccount(repo.Account{CustomerID: &customerID, Balance: 10000, Status: domain.AccountStatusFrozen})
+	pendingID, pendingAccount := addCustomer(m, domain.KYCStatusPending, 10000)
+	m.SetFeeSchedule(domain.KYCTierBasic, domain.TransactionKindWithdrawal, domain.DefaultCurrency, fees.Schedule{Fixed: 25})
+	h := NewWithdraw(m)
+	path := func(a repo.Account) string { return "/api/v1/accounts/" + a.Number + "/withdraw" }
+
+	tests := []struct {
+		name       string
+		customerID uuid.UUID
+		path       string
+		body       string
+		wantCode   int
+	}{
+		{name: "missing customer id", customerID: uuid.Nil, path: path(account), body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "malformed number", customerID: customerID, path: "/api/v1/accounts/nope/withdraw", body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "negative amount", customerID: customerID, path: path(account), body: `{"amount": "-10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "customer not verified", customerID: pendingID, path: path(pendingAccount), body: `{"amount": "10.00"}`, wantCode: http.StatusBadRequest},
+		{name: "someone else's account", customerID: customerID, path: path(pendingAccount), body: `{"amount": "10.00"}`, wantCode: http.StatusNotFound},
+		{name: "fee doesn't fit", customerID: customerID, path: path(account), body: `{"amount": "100.00"}`, wantCode: http.StatusBadRequest},
+		{name: "frozen", customerID: customerID, path: path(frozen), body: `{"amount": "10.00"}`, wantCode: http.StatusConflict},
+		{name: "accepted", customerID: customerID, path: path(account), body: `{"amount": "99.75"}`, wantCode: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			w := serve(http.MethodPost, "/api/v1/accounts/:number/withdraw", h.Handle, newRequest(http.MethodPost, tt.path, tt.body, tt.customerID))
+
+			// Assert.
+			assertCode(t, w, tt.wantCode)
+			if tt.wantCode != http.StatusAccepted {
+				return
+			}
+			resp := decode[WithdrawResponse](t, w)
+			if resp.Status != domain.TransactionStatusPending || resp.Fee != "0.25" {
+				t.Fatalf("expected a pending withdrawal with a 0.25 fee, got %+v", resp)
+			}
+			if got := m.Held(account.ID); got != 10000 {
+				t.Fatalf("expected the amount and the fee to be held, got %d", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+func TestListFrozen_LongestFrozenFirst(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	store := repo.NewPgx(db)
+	var ids []uuid.UUID
+	for range [2]struct{}{} {
+		customerID, accountID := uuid.New(), uuid.New()
+		insertCustomer(t, db, customerID, "")
+		sql := `INSERT INTO accounts (id, customer_id, number, balance) VALUES ($1, $2, $3, 0)`
+		if _, err := db.Exec(ctx, sql, accountID, customerID, uuid.NewString()); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Accounts().Freeze(ctx, accountID, "card reported stolen"); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, accountID)
+	}
+	// Frozen accounts are still credited.
+	if _, err := db.Exec(ctx, `UPDATE accounts SET balance = balance + 100 WHERE id = $1`, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act.
+	frozen, err := store.Accounts().ListFrozen(ctx, 10)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frozen) != 2 || frozen[0].ID != ids[0] || frozen[1].ID != ids[1] {
+		t.Fatalf("expected %v in the order they were frozen, got %+v", ids, frozen)
+	}
+	if !frozen[0].FrozenAt.Before(frozen[1].FrozenAt) {
+		t.Fatalf("expected the credit to leave the freeze time alone, got %+v", frozen)
+	}
+	unfrozen, err := store.Accounts().Unfreeze(ctx, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unfrozen || exists(t, db, `SELECT true FROM accounts WHERE id = $1 AND frozen_at IS NOT NULL`, ids[0]) {
+		t.Fatal("expected the freeze time cleared on unfreeze")
+	}
+}
@@ -0,0 +1,390 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/detod/best-wallet/client"
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/apikey"
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/consumer"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/router"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+const (
+	eventStream = "bestwallet:events"
+	// waitFor bounds how long the background work of a flow (relaying
+	// events, KYC, clearing) can take.
+	waitFor = 15 * time.Second
+)
+
+// env is a running wallet: the API with the outbox relay and the consumers
+// behind it, on a fresh copy of the migrated database and an empty Redis.
+type env struct {
+	db     *pgxpool.Pool
+	url    string
+	keyID  string
+	key    []byte
+	client *client.Client // Signs with keyID.
+}
+
+// newEnv starts an env that's torn down when t ends.
+func newEnv(t *testing.T) *env {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// State, reset for every test.
+	db := newDatabase(t)
+	rdb := newRedis(t)
+
+	// Dependencies, configured like the server's.
+	keks := map[string][]byte{"test": randomBytes(t, 32)}
+	kms, err := pii.NewLocalKMS(keks, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := pii.NewCipher(kms, randomBytes(t, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	documents, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	screener := screening.NewScreener(screening.DefaultThresholds) // Without watchlists nobody is a hit.
+	kytThreshold, err := domain.ParseAmount("10000.00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Background work.
+	var wg sync.WaitGroup
+	relayOutbox := job.NewRelayOutbox(db, rdb, eventStream, 50*time.Millisecond)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relayOutbox.Run(ctx)
+	}()
+	consumers := []struct {
+		group   string
+		handler consumer.Handler
+	}{
+		{"kyc", consumer.NewRunKYC(db, screener, cipher, 0)},
+		{"clearing", consumer.NewClearTransactions(db, kytThreshold)},
+	}
+	for _, cons := range consumers {
+		runner := consumer.NewRunner(rdb, consumer.RunnerConfig{
+			Stream:      eventStream,
+			Group:       cons.group,
+			Consumer:    "integration",
+			Concurrency: 2,
+			ClaimIdle:   time.Minute,
+			MaxAttempts: 3,
+		}, cons.handler)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runner.Run(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// API.
+	srv := httptest.NewServer(router.New(router.Config{
+		DB:        db,
+		PII:       cipher,
+		Documents: documents,
+		HMACKeys: func(ctx context.Context, keyID string) ([]byte, bool, error) {
+			return apikey.FindSecret(ctx, db, keyID)
+		},
+	}))
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+		rdb.Close() // Unblocks the consumers' reads.
+		wg.Wait()
+	})
+
+	e := &env{db: db, url: srv.URL}
+	e.keyID, e.key = e.issueKey(t)
+	e.client = client.New(client.Config{BaseURL: srv.URL, KeyID: e.keyID, Key: e.key})
+	return e
+}
+
+// newDatabase creates a fresh copy of the migrated database, dropped when t
+// ends.
+func newDatabase(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+
+	name := "bestwallet_test_" + randomHex(t, 8)
+	admin, err := connect(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(admin.Close)
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name+" TEMPLATE "+templateDB); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), "DROP DATABASE "+name+" WITH (FORCE)"); err != nil {
+			t.Error(err)
+		}
+	})
+	db, err := connect(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+// newRedis returns a client of the test database, flushed, closed when t
+// ends.
+func newRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr, DB: redisDB})
+	t.Cleanup(func() { rdb.Close() })
+	if err := rdb.FlushDB(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+	return rdb
+}
+
+// issueKey issues an HMAC key to a client app, like walletctl does.
+func (e *env) issueKey(t *testing.T) (string, []byte) {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	if err := audit.Set(ctx, tx, audit.System("integration"), "issue key", ""); err != nil {
+		t.Fatal(err)
+	}
+	key, secret, err := apikey.Issue(ctx, tx, "integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return key.ID, secret
+}
+
+// adminToken creates a back office user, like cmd/create-admin does, and
+// returns their bearer token.
+func (e *env) adminToken(t *testing.T, email string, role domain.AdminRole) string {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	if err := audit.Set(ctx, tx, audit.System("integration"), "create-admin", ""); err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := admin.CreateUser(ctx, tx, email, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// adminDo calls the admin API as the token's user, failing t unless it
+// responds wantCode. The response is decoded into out, if not nil.
+func (e *env) adminDo(t *testing.T, token, method, path string, body, out any, wantCode int) {
+	t.Helper()
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(method, e.url+"/admin/v1"+path, bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantCode {
+		t.Fatalf("expected response code %d for %s %s, got %d", wantCode, method, path, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// onboard creates a customer, uploads their documents and waits for KYC to
+// approve them.
+func (e *env) onboard(t *testing.T, email string) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	id, err := e.client.CreateCustomer(ctx, client.CreateCustomerRequest{
+		FirstName:        "Jane",
+		LastName:         "Doe",
+		Email:            email,
+		ResidenceAddress: "1 Main Street, Springfield",
+		BirthDate:        time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.uploadDocument(t, id, domain.KYCDocumentKindPassport)
+	e.uploadDocument(t, id, domain.KYCDocumentKindProofOfAddress)
+	e.waitForKYC(t, id, string(domain.KYCStatusApproved))
+	return id
+}
+
+// uploadDocument uploads a PDF, signed like the client does. The SDK
+// doesn't cover uploads.
+func (e *env) uploadDocument(t *testing.T, customerID uuid.UUID, kind domain.KYCDocumentKind) {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("kind", string(kind)); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := mw.CreateFormFile("file", string(kind)+".pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url+"/api/v1/customers/"+customerID.String()+"/documents", bytes.NewReader(body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("BestWallet-Key-ID", e.keyID)
+	req.Header.Set("BestWallet-Signature", client.Sign(body.Bytes(), e.key))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected response code %d uploading %s, got %d", http.StatusCreated, kind, resp.StatusCode)
+	}
+}
+
+// get sends a signed GET on behalf of the customer, returning the response
+// code and body.
+func (e *env) get(t *testing.T, customerID uuid.UUID, path string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, e.url+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("BestWallet-Key-ID", e.keyID)
+	req.Header.Set("BestWallet-Signature", client.Sign(nil, e.key))
+	req.Header.Set("BestWallet-Customer-ID", customerID.String())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+func (e *env) waitForKYC(t *testing.T, customerID uuid.UUID, status string) {
+	t.Helper()
+	eventually(t, "KYC status "+status, func() (bool, error) {
+		kyc, err := e.client.GetCustomerKYC(context.Background(), customerID)
+		return kyc.Status == status, err
+	})
+}
+
+// waitForBalance waits for the account's posted balance to be want.
+func (e *env) waitForBalance(t *testing.T, customerID uuid.UUID, number string, want int64) {
+	t.Helper()
+	eventually(t, "balance "+domain.FormatAmount(want), func() (bool, error) {
+		accounts, err := e.client.ListAccounts(context.Background(), customerID)
+		if err != nil {
+			return false, err
+		}
+		for _, a := range accounts {
+			if a.Number == number {
+				return a.Balance == want, nil
+			}
+		}
+		return false, errors.New("account not listed")
+	})
+}
+
+// eventually polls cond until it's true, failing t after waitFor.
+func eventually(t *testing.T, what string, cond func() (bool, error)) {
+	t.Helper()
+	deadline := time.Now().Add(waitFor)
+	for {
+		ok, err := cond()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s within %s, last error: %v", what, waitFor, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func randomHex(t *testing.T, n int) string {
+	return hex.EncodeToString(randomBytes(t, n))
+}
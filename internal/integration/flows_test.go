@@ -0,0 +1,284 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/client"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+)
+
+func TestSignature(t *testing.T) {
+	// Arrange.
+	e := newEnv(t)
+	body := []byte(`{"first_name": "Jane"}`)
+
+	tests := []struct {
+		name      string
+		keyID     string
+		signature string
+	}{
+		{name: "unsigned", keyID: e.keyID},
+		{name: "unknown key", keyID: "nope", signature: client.Sign(body, e.key)},
+		{name: "wrong key", keyID: e.keyID, signature: client.Sign(body, []byte("wrong"))},
+		{name: "tampered body", keyID: e.keyID, signature: client.Sign([]byte(`{"first_name": "John"}`), e.key)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, e.url+"/api/v1/customers", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("BestWallet-Key-ID", tt.keyID)
+			if tt.signature != "" {
+				req.Header.Set("BestWallet-Signature", tt.signature)
+			}
+
+			// Act.
+			resp, err := http.DefaultClient.Do(req)
+
+			// Assert.
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("expected response code %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestOnboarding(t *testing.T) {
+	// Arrange.
+	e := newEnv(t)
+	ctx := context.Background()
+	req := client.CreateCustomerRequest{
+		FirstName:        "Jane",
+		LastName:         "Doe",
+		Email:            "jane@example.com",
+		ResidenceAddress: "1 Main Street, Springfield",
+		BirthDate:        time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Act.
+	id, err := e.client.CreateCustomer(ctx, req)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.client.CreateCustomer(ctx, req); !errors.Is(err, client.ErrConflict) {
+		t.Fatalf("expected the email to be taken, got %v", err)
+	}
+	e.waitForKYC(t, id, string(domain.KYCStatusInProgress)) // Screened, waiting for documents.
+	if _, err := e.client.CreateAccount(ctx, id, string(domain.AccountProductCurrent)); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("expected no accounts before KYC is done, got %v", err)
+	}
+
+	// Act.
+	e.uploadDocument(t, id, domain.KYCDocumentKindPassport)
+	e.uploadDocument(t, id, domain.KYCDocumentKindProofOfAddress)
+
+	// Assert.
+	e.waitForKYC(t, id, string(domain.KYCStatusApproved))
+	if _, err := e.client.CreateAccount(ctx, id, string(domain.AccountProductCurrent)); err != nil {
+		t.Fatal(err)
+	}
+	kyc, err := e.client.GetCustomerKYC(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, ev := range kyc.Timeline {
+		statuses = append(statuses, ev.To)
+	}
+	want := []string{string(domain.KYCStatusPending), string(domain.KYCStatusInProgress), string(domain.KYCStatusApproved)}
+	if len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] || statuses[2] != want[2] {
+		t.Fatalf("expected timeline %v, got %v", want, statuses)
+	}
+}
+
+func TestMoneyMovement(t *testing.T) {
+	// Arrange.
+	e := newEnv(t)
+	ctx := context.Background()
+	jane := e.onboard(t, "jane@example.com")
+	john := e.onboard(t, "john@example.com")
+	janes, err := e.client.CreateAccount(ctx, jane, string(domain.AccountProductCurrent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	johns, err := e.client.CreateAccount(ctx, john, string(domain.AccountProductCurrent))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act.
+	deposit, err := e.client.Deposit(ctx, jane, janes.Number, client.DepositRequest{Amount: "100.00", IdempotencyKey: "deposit-1"})
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	retried, err := e.client.Deposit(ctx, jane, janes.Number, client.DepositRequest{Amount: "100.00", IdempotencyKey: "deposit-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried.TransactionID != deposit.TransactionID {
+		t.Fatalf("expected the retry to return %s, got %s", deposit.TransactionID, retried.TransactionID)
+	}
+	e.waitForBalance(t, jane, janes.Number, 10000)
+
+	// Act.
+	transferFee := quoteFee(t, e, jane, domain.TransactionKindTransfer, "30.00")
+	_, err = e.client.Transfer(ctx, jane, client.TransferRequest{FromAccount: janes.Number, ToAccount: johns.Number, Amount: "30.00"})
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.waitForBalance(t, jane, janes.Number, 10000-3000-transferFee)
+	e.waitForBalance(t, john, johns.Number, 3000)
+
+	// Act.
+	withdrawalFee := quoteFee(t, e, john, domain.TransactionKindWithdrawal, "20.00")
+	_, err = e.client.Withdraw(ctx, john, johns.Number, client.WithdrawRequest{Amount: "20.00"})
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.waitForBalance(t, john, johns.Number, 3000-2000-withdrawalFee)
+	if _, err := e.client.Withdraw(ctx, john, johns.Number, client.WithdrawRequest{Amount: "10.01"}); !errors.Is(err, client.ErrBadRequest) {
+		t.Fatalf("expected insufficient funds, got %v", err)
+	}
+
+	var history []client.Transaction
+	it := e.client.ListAccountTransactions(ctx, john, johns.Number, 10)
+	for it.Next() {
+		history = append(history, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Kind != string(domain.TransactionKindWithdrawal) || history[1].Kind != string(domain.TransactionKindTransfer) {
+		t.Fatalf("expected a withdrawal after a transfer, got %+v", history)
+	}
+	for _, tx := range history {
+		if tx.Status != string(domain.TransactionStatusCleared) {
+			t.Fatalf("expected cleared transactions, got %+v", history)
+		}
+	}
+}
+
+func TestKYCTierChange(t *testing.T) {
+	// Arrange.
+	e := newEnv(t)
+	ctx := context.Background()
+	jane := e.onboard(t, "jane@example.com")
+	janes, err := e.client.CreateAccount(ctx, jane, string(domain.AccountProductCurrent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	maker := e.adminToken(t, "maker@example.com", domain.AdminRoleOperator)
+	checker := e.adminToken(t, "checker@example.com", domain.AdminRoleOperator)
+	deposit := client.DepositRequest{Amount: "2000.00"} // Over the basic daily limit.
+	if _, err := e.client.Deposit(ctx, jane, janes.Number, deposit); !errors.Is(err, client.ErrLimitExceeded) {
+		t.Fatalf("expected the basic tier limit, got %v", err)
+	}
+
+	// Act.
+	var approval struct {
+		ID uuid.UUID `json:"id"`
+	}
+	path := "/customers/" + jane.String() + "/kyc/tier"
+	e.adminDo(t, maker, http.MethodPost, path, map[string]string{"tier": "standard", "reason": "source of funds checked"}, &approval, http.StatusAccepted)
+	e.adminDo(t, maker, http.MethodPost, "/approvals/"+approval.ID.String()+"/approve", map[string]string{}, nil, http.StatusForbidden)
+	e.adminDo(t, checker, http.MethodPost, "/approvals/"+approval.ID.String()+"/approve", map[string]string{}, nil, http.StatusOK)
+
+	// Assert.
+	limits, err := e.client.GetLimits(ctx, jane)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits.Tier != string(domain.KYCTierStandard) {
+		t.Fatalf("expected tier %s, got %s", domain.KYCTierStandard, limits.Tier)
+	}
+	if _, err := e.client.Deposit(ctx, jane, janes.Number, deposit); err != nil {
+		t.Fatalf("expected the standard tier limit to fit the deposit, got %v", err)
+	}
+	e.adminDo(t, maker, http.MethodPost, path, map[string]string{"tier": "standard", "reason": "again"}, nil, http.StatusConflict)
+}
+
+func TestStatements(t *testing.T) {
+	// Arrange.
+	e := newEnv(t)
+	ctx := context.Background()
+	jane := e.onboard(t, "jane@example.com")
+	janes, err := e.client.CreateAccount(ctx, jane, string(domain.AccountProductCurrent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Opened before last month, so it gets last month's statement.
+	if _, err := e.db.Exec(ctx, `UPDATE accounts SET created_at = now() - interval '2 months' WHERE number = $1`, janes.Number); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+	path := "/api/v1/accounts/" + janes.Number + "/statements/" + period + "?format=csv"
+
+	// Act.
+	code, _ := e.get(t, jane, path)
+
+	// Assert.
+	if code != http.StatusNotFound {
+		t.Fatalf("expected response code %d before the job ran, got %d", http.StatusNotFound, code)
+	}
+	if n := count(t, e.db, `SELECT count(*) FROM account_statements`); n != 0 {
+		t.Fatalf("expected no statement generated on read, got %d", n)
+	}
+
+	// Act.
+	if err := job.NewGenerateStatements(e.db, 0).RunForPeriod(ctx, period); err != nil {
+		t.Fatal(err)
+	}
+	code, body := e.get(t, jane, path)
+
+	// Assert.
+	if code != http.StatusOK {
+		t.Fatalf("expected response code %d after the job ran, got %d", http.StatusOK, code)
+	}
+	if len(body) == 0 {
+		t.Fatal("expected a statement, got an empty body")
+	}
+}
+
+// quoteFee returns what the customer would pay for a movement of amount, in
+// minor units.
+func quoteFee(t *testing.T, e *env, customerID uuid.UUID, kind domain.TransactionKind, amount string) int64 {
+	t.Helper()
+	quote, err := e.client.QuoteFee(context.Background(), customerID, string(kind), amount, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Fee == "0.00" {
+		return 0 // ParseAmount only takes positive amounts.
+	}
+	fee, err := domain.ParseAmount(quote.Fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fee
+}
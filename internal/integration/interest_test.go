@@ -0,0 +1,163 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/job"
+)
+
+// interestToday is when the tests run the job, the day after February ends.
+var interestToday = time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+
+func TestAccrueInterest_ResumesCrashedDay(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	jane, john := insertSavingsAccount(t, db), insertSavingsAccount(t, db)
+	// Everything up to February 27 is done. February 28 crashed after jane.
+	for d := 1; d <= 27; d++ {
+		markInterestRun(t, db, time.Date(2024, 2, d, 0, 0, 0, 0, time.UTC), false)
+	}
+	insertAccrual(t, db, jane, "2024-02-28")
+	j := job.NewAccrueInterest(db, 0)
+
+	// Act.
+	err := j.CatchUp(ctx, interestToday)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, date := range []string{"2024-02-28", "2024-02-29", "2024-03-01"} {
+		for _, id := range []uuid.UUID{jane, john} {
+			if !exists(t, db, `SELECT true FROM interest_accruals WHERE account_id = $1 AND accrual_date = $2`, id, date) {
+				t.Fatalf("expected %s accrued on %s", id, date)
+			}
+		}
+	}
+	if got := count(t, db, `SELECT count(*) FROM interest_accruals WHERE accrual_date < '2024-02-28'`); got != 0 {
+		t.Fatalf("expected done days to be left alone, got %d accruals", got)
+	}
+	for _, id := range []uuid.UUID{jane, john} {
+		if !exists(t, db, `SELECT true FROM interest_capitalizations WHERE account_id = $1 AND period = '2024-02'`, id) {
+			t.Fatalf("expected %s capitalized", id)
+		}
+	}
+	if got := count(t, db, `SELECT count(*) FROM interest_runs WHERE accrued_at IS NULL`); got != 0 {
+		t.Fatalf("expected every day accrued, got %d not", got)
+	}
+}
+
+func TestAccrueInterest_RedoesFailedCapitalization(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	jane := insertSavingsAccount(t, db)
+	// February 29 was accrued but capitalizing failed, March 1 never ran.
+	for d := 1; d <= 29; d++ {
+		markInterestRun(t, db, time.Date(2024, 2, d, 0, 0, 0, 0, time.UTC), false)
+	}
+	insertAccrual(t, db, jane, "2024-02-29")
+	j := job.NewAccrueInterest(db, 0)
+
+	// Act.
+	err := j.CatchUp(ctx, interestToday)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists(t, db, `SELECT true FROM interest_capitalizations WHERE account_id = $1 AND period = '2024-02'`, jane) {
+		t.Fatal("expected february capitalized")
+	}
+	if !exists(t, db, `SELECT true FROM interest_runs WHERE run_date = '2024-02-29' AND capitalized_at IS NOT NULL`) {
+		t.Fatal("expected february 29 done")
+	}
+	if !exists(t, db, `SELECT true FROM interest_accruals WHERE account_id = $1 AND accrual_date = '2024-03-01'`, jane) {
+		t.Fatal("expected march 1 accrued")
+	}
+}
+
+func TestAccrueInterest_NothingToDo(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	insertSavingsAccount(t, db)
+	for d := 1; d <= 29; d++ {
+		markInterestRun(t, db, time.Date(2024, 2, d, 0, 0, 0, 0, time.UTC), d == 29)
+	}
+	markInterestRun(t, db, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false)
+	j := job.NewAccrueInterest(db, 0)
+
+	// Act.
+	err := j.CatchUp(ctx, interestToday)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := count(t, db, `SELECT count(*) FROM interest_accruals`); got != 0 {
+		t.Fatalf("expected no accruals, got %d", got)
+	}
+}
+
+// insertSavingsAccount inserts an empty EUR savings account, opened long
+// before the days the tests accrue.
+func insertSavingsAccount(t *testing.T, db *pgxpool.Pool) uuid.UUID {
+	t.Helper()
+	customerID, id := uuid.New(), uuid.New()
+	insertCustomer(t, db, customerID, "")
+	sql := `
+		INSERT INTO accounts (id, customer_id, number, balance, product, created_at)
+		VALUES ($1, $2, $3, 0, 'savings', '2024-01-01')`
+	if _, err := db.Exec(context.Background(), sql, id, customerID, randomHex(t, 8)); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func insertAccrual(t *testing.T, db *pgxpool.Pool, accountID uuid.UUID, date string) {
+	t.Helper()
+	sql := `
+		INSERT INTO interest_accruals (account_id, accrual_date, balance, rate_bps, amount_micros)
+		VALUES ($1, $2, 0, 200, 0)`
+	if _, err := db.Exec(context.Background(), sql, accountID, date); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// markInterestRun records date as accrued, and capitalized if asked.
+func markInterestRun(t *testing.T, db *pgxpool.Pool, date time.Time, capitalized bool) {
+	t.Helper()
+	sql := `
+		INSERT INTO interest_runs (run_date, accrued_at, capitalized_at)
+		VALUES ($1, now(), CASE WHEN $2 THEN now() END)`
+	if _, err := db.Exec(context.Background(), sql, date, capitalized); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(t *testing.T, db *pgxpool.Pool, sql string, args ...any) bool {
+	t.Helper()
+	var ok bool
+	if err := db.QueryRow(context.Background(), `SELECT EXISTS (`+sql+`)`, args...).Scan(&ok); err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func count(t *testing.T, db *pgxpool.Pool, sql string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(context.Background(), sql, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
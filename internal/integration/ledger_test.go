@@ -0,0 +1,109 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/repo/repotest"
+)
+
+// TestPgxLedger_Invariants runs the simulation repo.Memory is checked with
+// against Postgres, fewer seeds since each gets its own database.
+func TestPgxLedger_Invariants(t *testing.T) {
+	repotest.TestLedgerInvariants(t, func(t *testing.T) repotest.LedgerStore {
+		db := newDatabase(t)
+		return &pgxLedgerStore{Pgx: repo.NewPgx(db), t: t, db: db}
+	}, 5, 200)
+}
+
+// pgxLedgerStore is a repo.Pgx the ledger simulation can seed and read back.
+type pgxLedgerStore struct {
+	*repo.Pgx
+	t  *testing.T
+	db *pgxpool.Pool
+}
+
+func (s *pgxLedgerStore) AddCustomer(c repo.Customer) {
+	sql := `INSERT INTO customers (id, kyc_status) VALUES ($1, $2)`
+	if _, err := s.db.Exec(context.Background(), sql, c.ID, c.KYCStatus); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+func (s *pgxLedgerStore) AddAccount(a repo.Account) repo.Account {
+	a.ID, a.Number, a.Kind, a.Currency = uuid.New(), uuid.NewString(), domain.AccountKindCustomer, domain.DefaultCurrency
+	if a.Status == "" {
+		a.Status = domain.AccountStatusActive
+	}
+	sql := `INSERT INTO accounts (id, customer_id, number, balance, status) VALUES ($1, $2, $3, 0, $4)`
+	if _, err := s.db.Exec(context.Background(), sql, a.ID, a.CustomerID, a.Number, a.Status); err != nil {
+		s.t.Fatal(err)
+	}
+	return a
+}
+
+// Clear clears like the clearing consumer, without KYT.
+func (s *pgxLedgerStore) Clear(id uuid.UUID) error {
+	return pgx.BeginFunc(context.Background(), s.db, func(tx pgx.Tx) error {
+		return ledger.Clear(context.Background(), tx, id)
+	})
+}
+
+func (s *pgxLedgerStore) Fail(id uuid.UUID) error {
+	return pgx.BeginFunc(context.Background(), s.db, func(tx pgx.Tx) error {
+		return ledger.Fail(context.Background(), tx, id, "simulated")
+	})
+}
+
+func (s *pgxLedgerStore) LedgerState() repo.LedgerState {
+	ctx := context.Background()
+	var res repo.LedgerState
+	var err error
+
+	rows, _ := s.db.Query(ctx, `SELECT id, kind, balance FROM accounts`)
+	var a repo.Account
+	if _, err = pgx.ForEachRow(rows, []any{&a.ID, &a.Kind, &a.Balance}, func() error {
+		res.Accounts = append(res.Accounts, a)
+		return nil
+	}); err != nil {
+		s.t.Fatal(err)
+	}
+
+	rows, _ = s.db.Query(ctx, `SELECT id, status, amount, reversal_of FROM transactions ORDER BY created_at, id`)
+	if res.Transactions, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (ledger.Transaction, error) {
+		var t ledger.Transaction
+		err := row.Scan(&t.ID, &t.Status, &t.Amount, &t.ReversalOf)
+		return t, err
+	}); err != nil {
+		s.t.Fatal(err)
+	}
+
+	rows, _ = s.db.Query(ctx, `SELECT transaction_id, account_id, amount FROM entries`)
+	var e repo.LedgerEntry
+	if _, err = pgx.ForEachRow(rows, []any{&e.TransactionID, &e.AccountID, &e.Amount}, func() error {
+		res.Entries = append(res.Entries, e)
+		return nil
+	}); err != nil {
+		s.t.Fatal(err)
+	}
+
+	rows, _ = s.db.Query(ctx, `SELECT transaction_id, account_id, amount, released_at IS NOT NULL FROM holds`)
+	var h repo.LedgerHold
+	if _, err = pgx.ForEachRow(rows, []any{&h.TransactionID, &h.AccountID, &h.Amount, &h.Released}, func() error {
+		res.Holds = append(res.Holds, h)
+		return nil
+	}); err != nil {
+		s.t.Fatal(err)
+	}
+
+	return res
+}
@@ -0,0 +1,212 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	pgxgoogleuuid "github.com/vgarvardt/pgx-google-uuid/v5"
+
+	dbmigrations "github.com/detod/best-wallet/db-migrations"
+	"github.com/detod/best-wallet/internal/migrate"
+)
+
+// templateDB has the migrations applied, tests copy it.
+const templateDB = "bestwallet_template"
+
+var (
+	postgresConnString string // Of the maintenance database, tests create theirs next to it.
+	redisAddr          string
+	redisDB            = 15
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(run(m))
+}
+
+// run sets up the environment for m, returning instead of exiting so the
+// servers it started are stopped.
+func run(m *testing.M) int {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "bestwallet-integration")
+	if err != nil {
+		log.Print("Can't create temp dir: ", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	// Postgres.
+	postgresConnString = os.Getenv("INTEGRATION_POSTGRES_CONN_STRING")
+	if postgresConnString == "" {
+		stop, err := startPostgres(filepath.Join(dir, "postgres"))
+		if err != nil {
+			log.Print("Can't start postgres: ", err)
+			return 1
+		}
+		defer stop()
+	}
+	if err := createTemplate(ctx); err != nil {
+		log.Print("Can't create the template database: ", err)
+		return 1
+	}
+
+	// Redis.
+	redisAddr = os.Getenv("INTEGRATION_REDIS_ADDR")
+	if v := os.Getenv("INTEGRATION_REDIS_DB"); v != "" {
+		if redisDB, err = strconv.Atoi(v); err != nil {
+			log.Print("Can't parse INTEGRATION_REDIS_DB: ", err)
+			return 1
+		}
+	}
+	if redisAddr == "" {
+		stop, err := startRedis(ctx, filepath.Join(dir, "redis"))
+		if err != nil {
+			log.Print("Can't start redis: ", err)
+			return 1
+		}
+		defer stop()
+	}
+
+	return m.Run()
+}
+
+// startPostgres runs a throwaway cluster in dir, tuned for speed rather than
+// durability.
+func startPostgres(dir string) (func(), error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	data := filepath.Join(dir, "data")
+	if out, err := exec.Command("initdb", "-D", data, "-U", "postgres", "-A", "trust", "--no-sync").CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("initdb: %w: %s", err, out)
+	}
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off -c synchronous_commit=off -c full_page_writes=off", port, dir)
+	if out, err := exec.Command("pg_ctl", "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-o", opts, "-w", "start").CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("pg_ctl start: %w: %s", err, out)
+	}
+	stop := func() {
+		if out, err := exec.Command("pg_ctl", "-D", data, "-m", "immediate", "stop").CombinedOutput(); err != nil {
+			log.Printf("pg_ctl stop: %s: %s", err, out)
+		}
+	}
+
+	postgresConnString = fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port)
+	return stop, nil
+}
+
+// startRedis runs a throwaway redis-server without persistence.
+func startRedis(ctx context.Context, dir string) (func(), error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("redis-server", "--port", strconv.Itoa(port), "--bind", "127.0.0.1", "--dir", dir, "--save", "", "--appendonly", "no")
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("redis-server: %w", err)
+	}
+	stop := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+
+	redisAddr = fmt.Sprintf("127.0.0.1:%d", port)
+	rdb := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer rdb.Close()
+	for attempt := 0; ; attempt++ {
+		err := rdb.Ping(ctx).Err()
+		if err == nil {
+			return stop, nil
+		}
+		if attempt == 50 {
+			stop()
+			return nil, fmt.Errorf("redis-server isn't answering: %w", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// createTemplate migrates a new templateDB.
+func createTemplate(ctx context.Context) error {
+	admin, err := connect(ctx, "")
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+	if _, err := admin.Exec(ctx, "DROP DATABASE IF EXISTS "+templateDB+" WITH (FORCE)"); err != nil {
+		return err
+	}
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+templateDB); err != nil {
+		return err
+	}
+
+	db, err := connect(ctx, templateDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close() // Copying needs the template without connections.
+	migrator, err := migrate.New(db, dbmigrations.FS)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		return errors.New("no migrations applied")
+	}
+	return nil
+}
+
+// connect opens a pool to database on the test server, the maintenance
+// database if empty. It's configured like the server's.
+func connect(ctx context.Context, database string) (*pgxpool.Pool, error) {
+	conf, err := pgxpool.ParseConfig(postgresConnString)
+	if err != nil {
+		return nil, err
+	}
+	if database != "" {
+		conf.ConnConfig.Database = database
+	}
+	conf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxgoogleuuid.Register(conn.TypeMap())
+		return nil
+	}
+	db, err := pgxpool.NewWithConfig(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,300 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/detod/best-wallet/internal/consumer"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+	"github.com/detod/best-wallet/internal/outbox"
+)
+
+const testGroup = "test"
+
+func TestRelayOutbox(t *testing.T) {
+	// Arrange.
+	db, rdb := newDatabase(t), newRedis(t)
+	ctx := context.Background()
+	var committed []uuid.UUID
+	for i := 0; i < 3; i++ {
+		committed = append(committed, writeEvent(t, db, true))
+	}
+	writeEvent(t, db, false)
+	relay := job.NewRelayOutbox(db, rdb, eventStream, time.Hour)
+
+	// Act.
+	n, err := relay.RunOnce(ctx)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(committed) {
+		t.Fatalf("expected %d events relayed, got %d", len(committed), n)
+	}
+	if published := streamEventIDs(t, rdb, eventStream); !slices.Equal(published, committed) {
+		t.Fatalf("expected events %v published, got %v", committed, published)
+	}
+	if n, err := relay.RunOnce(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to relay, got %d, %v", n, err)
+	}
+}
+
+func TestRelayOutbox_PublishFails(t *testing.T) {
+	// Arrange.
+	db, rdb := newDatabase(t), newRedis(t)
+	ctx := context.Background()
+	id := writeEvent(t, db, true)
+	down := redis.NewClient(&redis.Options{Addr: redisAddr, DB: redisDB})
+	down.Close()
+
+	// Act.
+	_, err := job.NewRelayOutbox(db, down, eventStream, time.Hour).RunOnce(ctx)
+
+	// Assert.
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var unpublished int
+	if err := db.QueryRow(ctx, `SELECT count(*) FROM outbox WHERE published_at IS NULL`).Scan(&unpublished); err != nil {
+		t.Fatal(err)
+	}
+	if unpublished != 1 {
+		t.Fatalf("expected the event to stay unpublished, got %d unpublished", unpublished)
+	}
+
+	// Act.
+	n, err := job.NewRelayOutbox(db, rdb, eventStream, time.Hour).RunOnce(ctx)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected the event relayed on the next run, got %d events", n)
+	}
+	if published := streamEventIDs(t, rdb, eventStream); !slices.Equal(published, []uuid.UUID{id}) {
+		t.Fatalf("expected event %s published, got %v", id, published)
+	}
+}
+
+func TestRunner_Dedupe(t *testing.T) {
+	// Arrange.
+	rdb := newRedis(t)
+	dup, last := newEvent(t), newEvent(t)
+	publish(t, rdb, dup, dup, last) // The relay published dup twice.
+	h := &recordingHandler{}
+
+	// Act.
+	runRunner(t, rdb, 1, time.Minute, h)
+
+	// Assert.
+	eventually(t, "the last event handled", func() (bool, error) {
+		return h.count(last.ID) == 1, nil
+	})
+	if n := h.count(dup.ID); n != 1 {
+		t.Fatalf("expected the duplicated event handled once, got %d times", n)
+	}
+	waitForNothingPending(t, rdb)
+}
+
+func TestRunner_ClaimsStuckEvents(t *testing.T) {
+	// Arrange.
+	rdb := newRedis(t)
+	ctx := context.Background()
+	ev := newEvent(t)
+	publish(t, rdb, ev)
+	if err := rdb.XGroupCreateMkStream(ctx, eventStream, testGroup, "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	// A consumer reads the event and crashes before acknowledging it.
+	if err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    testGroup,
+		Consumer: "crashed",
+		Streams:  []string{eventStream, ">"},
+		Count:    1,
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	h := &recordingHandler{}
+
+	// Act.
+	runRunner(t, rdb, 1, 100*time.Millisecond, h)
+
+	// Assert.
+	eventually(t, "the stuck event handled", func() (bool, error) {
+		return h.count(ev.ID) == 1, nil
+	})
+	waitForNothingPending(t, rdb)
+}
+
+func TestRunner_DeadLetters(t *testing.T) {
+	// Arrange.
+	rdb := newRedis(t)
+	ctx := context.Background()
+	ev := newEvent(t)
+	publish(t, rdb, ev)
+	if err := rdb.XAdd(ctx, &redis.XAddArgs{Stream: eventStream, Values: map[string]any{"event": "{"}}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	h := &recordingHandler{err: errors.New("boom")}
+	maxAttempts := 2
+
+	// Act.
+	runRunner(t, rdb, maxAttempts, 100*time.Millisecond, h)
+
+	// Assert.
+	dead := eventStream + ":dead:" + testGroup
+	var messages []redis.XMessage
+	eventually(t, "2 dead letters", func() (bool, error) {
+		var err error
+		messages, err = rdb.XRange(ctx, dead, "-", "+").Result()
+		return len(messages) == 2, err
+	})
+	waitForNothingPending(t, rdb)
+	if n := h.count(ev.ID); n != maxAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxAttempts, n)
+	}
+	reasons := map[string]bool{}
+	for _, msg := range messages {
+		reason, _ := msg.Values["error"].(string)
+		reasons[reason] = true
+	}
+	if !reasons["boom"] || len(reasons) != 2 {
+		t.Fatalf("expected the handler's error and the malformed event as reasons, got %v", reasons)
+	}
+}
+
+// writeEvent writes an event to the outbox in a transaction that's committed
+// or rolled back.
+func writeEvent(t *testing.T, db *pgxpool.Pool, commit bool) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	ev, err := outbox.Write(ctx, tx, domain.EventTypeCustomerCreated, uuid.New(), domain.CustomerCreatedPayload{CustomerID: uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit {
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ev.ID
+}
+
+func newEvent(t *testing.T) domain.Event {
+	t.Helper()
+	ev, err := outbox.NewEvent(domain.EventTypeCustomerCreated, uuid.New(), domain.CustomerCreatedPayload{CustomerID: uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func publish(t *testing.T, rdb *redis.Client, events ...domain.Event) {
+	t.Helper()
+	if err := job.PublishEvents(context.Background(), rdb, eventStream, events); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// streamEventIDs returns the IDs of the events on the stream, in order.
+func streamEventIDs(t *testing.T, rdb *redis.Client, stream string) []uuid.UUID {
+	t.Helper()
+	messages, err := rdb.XRange(context.Background(), stream, "-", "+").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []uuid.UUID
+	for _, msg := range messages {
+		raw, _ := msg.Values["event"].(string)
+		var ev domain.Event
+		if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, ev.ID)
+	}
+	return ids
+}
+
+// runRunner runs a testGroup consumer on the event stream until t ends.
+func runRunner(t *testing.T, rdb *redis.Client, maxAttempts int, claimIdle time.Duration, h consumer.Handler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := consumer.NewRunner(rdb, consumer.RunnerConfig{
+		Stream:      eventStream,
+		Group:       testGroup,
+		Consumer:    "integration",
+		Concurrency: 1,
+		ClaimIdle:   claimIdle,
+		MaxAttempts: maxAttempts,
+	}, h)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := runner.Run(ctx); err != nil {
+			t.Error(err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		rdb.Close() // Unblocks the runner's reads.
+		wg.Wait()
+	})
+}
+
+// waitForNothingPending waits for testGroup to acknowledge everything it
+// read.
+func waitForNothingPending(t *testing.T, rdb *redis.Client) {
+	t.Helper()
+	eventually(t, "no pending events", func() (bool, error) {
+		pending, err := rdb.XPending(context.Background(), eventStream, testGroup).Result()
+		return err == nil && pending.Count == 0, err
+	})
+}
+
+// recordingHandler records the events it's given, failing them with err.
+type recordingHandler struct {
+	mu   sync.Mutex
+	seen []uuid.UUID
+	err  error
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, ev domain.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seen = append(h.seen, ev.ID)
+	return h.err
+}
+
+func (h *recordingHandler) count(id uuid.UUID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, seen := range h.seen {
+		if seen == id {
+			n++
+		}
+	}
+	return n
+}
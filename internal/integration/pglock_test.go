@@ -0,0 +1,41 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/pglock"
+)
+
+func TestTryWith_OneAtATime(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	var innerRan bool
+
+	// Act.
+	outerRan, err := pglock.TryWith(ctx, db, pglock.KeySealAuditLog, func(*pgxpool.Conn) error {
+		var err error
+		innerRan, err = pglock.TryWith(ctx, db, pglock.KeySealAuditLog, func(*pgxpool.Conn) error { return nil })
+		return err
+	})
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outerRan || innerRan {
+		t.Fatalf("expected only the first holder to run, got %t and %t", outerRan, innerRan)
+	}
+	again, err := pglock.TryWith(ctx, db, pglock.KeySealAuditLog, func(*pgxpool.Conn) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again {
+		t.Fatal("expected the lock released after the first holder")
+	}
+}
@@ -0,0 +1,117 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+	"github.com/detod/best-wallet/internal/notify"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+func TestAuditLog_NoPlaintextPII(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	kms, err := pii.NewLocalKMS(map[string][]byte{"test": randomBytes(t, 32)}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := pii.NewCipher(kms, randomBytes(t, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A customer from before encryption.
+	id := uuid.New()
+	sql := `
+		INSERT INTO customers (id, kyc_status, first_name, last_name, email, residence_address, birth_date)
+		VALUES ($1, $2, 'Plainjane', 'Plaindoe', 'plain.jane@example.com', '1 Plain Street', '1990-01-02')`
+	if _, err := db.Exec(ctx, sql, id, domain.KYCStatusApproved); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act.
+	err = job.NewReencryptCustomers(db, cipher, 0).RunOnce(ctx)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists(t, db, `SELECT true FROM customers WHERE id = $1 AND pii_kek_id IS NOT NULL`, id) {
+		t.Fatal("expected the customer encrypted")
+	}
+	if got := count(t, db, `SELECT count(*) FROM audit_log WHERE entity_type = 'customers' AND entity_id = $1`, id.String()); got != 2 {
+		t.Fatalf("expected the insert and the re-encryption logged, got %d records", got)
+	}
+	for _, plaintext := range []string{"Plainjane", "Plaindoe", "plain.jane@example.com", "1 Plain Street", "1990-01-02"} {
+		sql := `
+			SELECT true FROM audit_log
+			WHERE entity_type = 'customers' AND entity_id = $1
+				AND (COALESCE(before::text, '') || COALESCE(after::text, '')) LIKE '%' || $2 || '%'`
+		if exists(t, db, sql, id.String(), plaintext) {
+			t.Fatalf("expected no %q in the audit log", plaintext)
+		}
+	}
+}
+
+func TestDispatchNotifications_ClearsMessage(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	customerID := uuid.New()
+	insertCustomer(t, db, customerID, "")
+	sent := insertNotification(t, db, customerID, notify.ChannelEmail)
+	failed := insertNotification(t, db, customerID, notify.ChannelSMS) // No notifier, fails for good.
+	email := &recordingNotifier{}
+	j := job.NewDispatchNotifications(db, map[notify.Channel]notify.Notifier{notify.ChannelEmail: email}, 0)
+
+	// Act.
+	_, err := j.RunOnce(ctx)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(email.messages) != 1 || email.messages[0].To != "jane@example.com" || email.messages[0].Body != "Hi Jane" {
+		t.Fatalf("expected the message sent to jane@example.com, got %+v", email.messages)
+	}
+	for id, status := range map[uuid.UUID]domain.NotificationStatus{sent: domain.NotificationStatusSent, failed: domain.NotificationStatusFailed} {
+		sql := `SELECT true FROM notification_outbox WHERE id = $1 AND status = $2 AND recipient = '' AND subject = '' AND body = ''`
+		if !exists(t, db, sql, id, status) {
+			t.Fatalf("expected notification %s %s and cleared", id, status)
+		}
+	}
+}
+
+// insertNotification inserts a pending notification to jane@example.com, due
+// now.
+func insertNotification(t *testing.T, db *pgxpool.Pool, customerID uuid.UUID, ch notify.Channel) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	sql := `
+		INSERT INTO notification_outbox (id, customer_id, template, channel, recipient, subject, body, status)
+		VALUES ($1, $2, 'kyc_approved', $3, 'jane@example.com', 'Welcome', 'Hi Jane', $4)`
+	if _, err := db.Exec(context.Background(), sql, id, customerID, ch, domain.NotificationStatusPending); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []notify.Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, msg)
+	return nil
+}
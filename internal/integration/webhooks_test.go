@@ -0,0 +1,210 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/consumer"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/job"
+	"github.com/detod/best-wallet/internal/outbox"
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/webhook"
+)
+
+func TestFanOutWebhooks_OnlyToTheCustomersApp(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	jane, john, old := uuid.New(), uuid.New(), uuid.New()
+	insertCustomer(t, db, jane, "app-a")
+	insertCustomer(t, db, john, "app-b")
+	insertCustomer(t, db, old, "") // Registered before apps were recorded.
+	subA := insertSubscription(t, db, "app-a", domain.EventTypeKYCStatusChanged, domain.EventTypeTransactionPosted)
+	subB := insertSubscription(t, db, "app-b", domain.EventTypeKYCStatusChanged, domain.EventTypeTransactionPosted)
+	insertSubscription(t, db, "app-c", domain.EventTypeKYCStatusChanged, domain.EventTypeTransactionPosted)
+	h := consumer.NewFanOutWebhooks(db)
+
+	tests := []struct {
+		name    string
+		typ     domain.EventType
+		payload any
+		want    []uuid.UUID // Subscriptions.
+	}{
+		{
+			name:    "about jane",
+			typ:     domain.EventTypeKYCStatusChanged,
+			payload: domain.KYCStatusChangedPayload{CustomerID: jane},
+			want:    []uuid.UUID{subA},
+		},
+		{
+			name: "transfer from jane to john",
+			typ:  domain.EventTypeTransactionPosted,
+			payload: domain.TransactionPostedPayload{Entries: []domain.PostedEntry{
+				{AccountID: uuid.New(), CustomerID: &jane, Amount: -100},
+				{AccountID: uuid.New(), CustomerID: &john, Amount: 100},
+			}},
+			want: []uuid.UUID{subA, subB},
+		},
+		{
+			name:    "about an old customer",
+			typ:     domain.EventTypeKYCStatusChanged,
+			payload: domain.KYCStatusChangedPayload{CustomerID: old},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := outbox.NewEvent(tt.typ, uuid.New(), tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act.
+			err = h.Handle(ctx, ev)
+
+			// Assert.
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uuid.UUID
+			rows, _ := db.Query(ctx, `SELECT subscription_id FROM webhook_deliveries WHERE event_id = $1`, ev.ID)
+			for rows.Next() {
+				var id uuid.UUID
+				if err := rows.Scan(&id); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, id)
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected deliveries to %v, got %v", tt.want, got)
+			}
+			for _, id := range tt.want {
+				if !slices.Contains(got, id) {
+					t.Fatalf("expected deliveries to %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverWebhooks_LeasesDeliveries(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	var mu sync.Mutex
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r.Header.Get(webhook.DeliveryIDHeader))
+	}))
+	t.Cleanup(srv.Close)
+	sub := insertSubscription(t, db, "app-a", domain.EventTypeAccountOpened)
+	if _, err := db.Exec(ctx, `UPDATE webhook_subscriptions SET url = $1 WHERE id = $2`, srv.URL, sub); err != nil {
+		t.Fatal(err)
+	}
+	due := insertDelivery(t, db, sub, nil)
+	leasedElsewhere := time.Now().Add(time.Minute)
+	insertDelivery(t, db, sub, &leasedElsewhere)
+
+	// Act.
+	n, err := job.NewDeliverWebhooks(db, webhook.NewSender(time.Second), time.Minute).RunOnce(ctx)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(received) != 1 || received[0] != due.String() {
+		t.Fatalf("expected only %s sent, got %d leased and %v received", due, n, received)
+	}
+	sql := `SELECT true FROM webhook_deliveries WHERE id = $1 AND status = $2 AND attempts = 1 AND lease_expires_at IS NULL`
+	if !exists(t, db, sql, due, domain.WebhookDeliveryStatusSucceeded) {
+		t.Fatal("expected the delivery recorded and its lease released")
+	}
+}
+
+// insertDelivery inserts a due delivery of the subscription, leased until
+// leaseExpiresAt unless nil.
+func insertDelivery(t *testing.T, db *pgxpool.Pool, subscriptionID uuid.UUID, leaseExpiresAt *time.Time) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	sql := `
+		INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, status, lease_expires_at)
+		VALUES ($1, $2, $3, $4, '{}', $5, $6)`
+	if _, err := db.Exec(context.Background(), sql, id, subscriptionID, uuid.New(), domain.EventTypeAccountOpened, domain.WebhookDeliveryStatusPending, leaseExpiresAt); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// insertCustomer inserts a bare customer registered by clientKeyID, none if
+// empty.
+func insertCustomer(t *testing.T, db *pgxpool.Pool, id uuid.UUID, clientKeyID string) {
+	t.Helper()
+	sql := `INSERT INTO customers (id, kyc_status, client_key_id) VALUES ($1, $2, NULLIF($3, ''))`
+	if _, err := db.Exec(context.Background(), sql, id, domain.KYCStatusPending, clientKeyID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func insertSubscription(t *testing.T, db *pgxpool.Pool, clientKeyID string, eventTypes ...domain.EventType) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	sql := `
+		INSERT INTO webhook_subscriptions (id, client_key_id, url, event_types)
+		VALUES ($1, $2, 'https://example.com/hooks', $3)`
+	if _, err := db.Exec(context.Background(), sql, id, clientKeyID, eventTypes); err != nil {
+		t.Fatal(err)
+	}
+	sql = `INSERT INTO webhook_subscription_secrets (subscription_id, secret) VALUES ($1, 'c2VjcmV0')`
+	if _, err := db.Exec(context.Background(), sql, id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestAuditLog_NoWebhookSecrets(t *testing.T) {
+	// Arrange.
+	db := newDatabase(t)
+	ctx := context.Background()
+	secret := randomHex(t, 32)
+	id := uuid.New()
+
+	// Act.
+	err := repo.NewPgx(db).Webhooks().Create(ctx, repo.NewWebhookSubscription{
+		ID:          id,
+		ClientKeyID: "app-a",
+		URL:         "https://example.com/hooks",
+		EventTypes:  []domain.EventType{domain.EventTypeAccountOpened},
+		Secret:      secret,
+	})
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists(t, db, `SELECT true FROM webhook_subscription_secrets WHERE subscription_id = $1 AND secret = $2`, id, secret) {
+		t.Fatal("expected the secret stored")
+	}
+	if got := count(t, db, `SELECT count(*) FROM audit_log WHERE entity_type = 'webhook_subscriptions' AND entity_id = $1`, id.String()); got != 1 {
+		t.Fatalf("expected the subscription logged, got %d records", got)
+	}
+	sql := `SELECT true FROM audit_log WHERE (COALESCE(before::text, '') || COALESCE(after::text, '')) LIKE '%' || $1 || '%'`
+	if exists(t, db, sql, secret) {
+		t.Fatal("expected no secret in the audit log")
+	}
+}
@@ -0,0 +1,54 @@
+// Package interest calculates interest on savings accounts. Interest accrues
+// daily on the end of day balance and is capitalized (paid out) monthly.
+package interest
+
+import (
+	"math/big"
+	"time"
+)
+
+// MicrosPerUnit is how many accrual units make a minor unit of money. Daily
+// interest on small balances is a fraction of a cent, so accruals are kept
+// in millionths of a minor unit and only rounded when capitalized.
+const MicrosPerUnit = 1_000_000
+
+// DaysPerYear is the day count convention (actual/365).
+const DaysPerYear = 365
+
+// DailyMicros returns the interest for a day on balance (in minor units) at a
+// yearly rate given in basis points, in micros, rounded down. Negative
+// balances don't earn (or cost) interest.
+func DailyMicros(balance, rateBPS int64) int64 {
+	if balance <= 0 || rateBPS <= 0 {
+		return 0
+	}
+
+	// balance * rate/10000 / 365 * 1e6, big ints because it overflows
+	// int64 for big balances.
+	n := new(big.Int).Mul(big.NewInt(balance), big.NewInt(rateBPS))
+	n.Mul(n, big.NewInt(MicrosPerUnit))
+	n.Quo(n, big.NewInt(10000*DaysPerYear))
+	return n.Int64()
+}
+
+// Capitalized returns the amount paid out for accrued micros, rounded down to
+// minor units.
+func Capitalized(micros int64) int64 {
+	return micros / MicrosPerUnit
+}
+
+// Date truncates t to its day (UTC), the unit accruals are keyed by.
+func Date(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// IsMonthEnd reports whether date is the last day of its month.
+func IsMonthEnd(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
+
+// Period formats the month of date e.g. "2024-03".
+func Period(date time.Time) string {
+	return date.Format("2006-01")
+}
@@ -0,0 +1,59 @@
+package interest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyMicros(t *testing.T) {
+	testCases := []struct {
+		name    string
+		balance int64
+		rateBPS int64
+		want    int64
+	}{
+		{"zero balance", 0, 200, 0},
+		{"negative balance", -100000, 200, 0},
+		{"zero rate", 100000, 0, 0},
+		// 1000.00 at 2% is 20.00 a year, 2000 cents / 365 = 5.479452 cents.
+		{"regular", 100000, 200, 5479452},
+		// 0.01 at 1% is a tiny fraction of a cent.
+		{"tiny", 1, 100, 27},
+		// 1 trillion at 100%, overflows int64 without big ints.
+		{"big", 1_000_000_000_000_00, 10000, 273972602739726027},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DailyMicros(tc.balance, tc.rateBPS); got != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCapitalized(t *testing.T) {
+	// A month of daily accruals on 1000.00 at 2%.
+	if got := Capitalized(31 * 5479452); got != 169 {
+		t.Fatalf("expected 169, got %d", got)
+	}
+}
+
+func TestIsMonthEnd(t *testing.T) {
+	testCases := []struct {
+		date time.Time
+		want bool
+	}{
+		{time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.date.Format(time.DateOnly), func(t *testing.T) {
+			if got := IsMonthEnd(tc.date); got != tc.want {
+				t.Fatalf("expected %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
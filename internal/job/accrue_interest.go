@@ -0,0 +1,409 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/interest"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/pglock"
+)
+
+const (
+	interestBatchSize  = 500
+	interestMaxCatchUp = 31 // Days, when the job didn't run for a while.
+)
+
+func NewAccrueInterest(
+	db *pgxpool.Pool,
+	interval time.Duration,
+) *AccrueInterest {
+	return &AccrueInterest{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// AccrueInterest accrues daily interest on savings accounts and capitalizes
+// it at the end of every month, posting it from the interest expense account.
+//
+// Every step is keyed by account and day (or month), so running it again for
+// the same date only fills in what's missing, e.g. after a crash. Each day's
+// progress is recorded in interest_runs, CatchUp redoes the days that didn't
+// finish.
+type AccrueInterest struct {
+	db       *pgxpool.Pool
+	interval time.Duration
+}
+
+// Run accrues every day up to yesterday that isn't done yet.
+func (j *AccrueInterest) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.CatchUp(ctx, time.Now()); err != nil {
+			log.Println("failed to accrue interest", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CatchUp runs every day before today that isn't done, oldest first, and
+// stops at the first one that fails so no day is skipped. It starts at the
+// first day the job ever ran, or yesterday if it never did, but goes back at
+// most interestMaxCatchUp days.
+func (j *AccrueInterest) CatchUp(ctx context.Context, today time.Time) error {
+	yesterday := interest.Date(today).AddDate(0, 0, -1)
+
+	first, err := j.dbGetFirstRunDate(ctx)
+	if err != nil {
+		return err
+	}
+	from := yesterday
+	if first != nil {
+		from = interest.Date(*first)
+	}
+	if earliest := yesterday.AddDate(0, 0, -interestMaxCatchUp); from.Before(earliest) {
+		from = earliest
+	}
+
+	runs, err := j.dbGetRuns(ctx, from, yesterday)
+	if err != nil {
+		return err
+	}
+	for _, date := range pendingInterestDates(from, yesterday, runs) {
+		if err := j.RunForDate(ctx, date); err != nil {
+			return fmt.Errorf("failed to accrue interest for %s: %w", date.Format(time.DateOnly), err)
+		}
+	}
+	return nil
+}
+
+// interestRun is how far the job got for a day.
+type interestRun struct {
+	Accrued     bool
+	Capitalized bool
+}
+
+func (r interestRun) done(date time.Time) bool {
+	return r.Accrued && (r.Capitalized || !interest.IsMonthEnd(date))
+}
+
+// pendingInterestDates returns the days from from to to that aren't done, by
+// their runs keyed by date.
+func pendingInterestDates(from, to time.Time, runs map[time.Time]interestRun) []time.Time {
+	var res []time.Time
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if !runs[date].done(date) {
+			res = append(res, date)
+		}
+	}
+	return res
+}
+
+// RunForDate accrues interest for date and, if it's the last day of a month,
+// capitalizes the month.
+func (j *AccrueInterest) RunForDate(ctx context.Context, date time.Time) error {
+	date = interest.Date(date)
+
+	ran, err := pglock.TryWith(ctx, j.db, pglock.KeyAccrueInterest, func(*pgxpool.Conn) error {
+		start := time.Now()
+		accrued, err := j.accrue(ctx, date)
+		if err != nil {
+			return err
+		}
+		if err := j.dbMarkRun(ctx, date, "accrued_at"); err != nil {
+			return err
+		}
+		log.Printf("accrue interest: accrued %d accounts for %s in %s", accrued, date.Format(time.DateOnly), time.Since(start))
+
+		if interest.IsMonthEnd(date) {
+			start := time.Now()
+			paid, err := j.capitalize(ctx, date)
+			if err != nil {
+				return err
+			}
+			if err := j.dbMarkRun(ctx, date, "capitalized_at"); err != nil {
+				return err
+			}
+			log.Printf("accrue interest: capitalized %d accounts for %s in %s", paid, interest.Period(date), time.Since(start))
+		}
+
+		return nil
+	})
+	if err == nil && !ran {
+		log.Println("accrue interest: already running elsewhere, skipping")
+	}
+	return err
+}
+
+func (j *AccrueInterest) accrue(ctx context.Context, date time.Time) (int, error) {
+	rates := map[string]int64{} // By currency.
+	var after uuid.UUID
+	var accrued int
+	for {
+		accounts, err := j.dbGetSavingsAccountsBatch(ctx, date, after)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read accounts: %w", err)
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, a := range accounts {
+			rate, ok := rates[a.Currency]
+			if !ok {
+				if rate, err = j.dbGetRate(ctx, a.Currency, date); err != nil {
+					return 0, fmt.Errorf("failed to read %s rate: %w", a.Currency, err)
+				}
+				rates[a.Currency] = rate
+			}
+
+			balance, err := j.dbGetEndOfDayBalance(ctx, a.ID, date)
+			if err != nil {
+				return 0, fmt.Errorf("failed to read balance of %s: %w", a.ID, err)
+			}
+			if err := j.dbInsertAccrual(ctx, a.ID, date, balance, rate, interest.DailyMicros(balance, rate)); err != nil {
+				return 0, fmt.Errorf("failed to accrue %s: %w", a.ID, err)
+			}
+			accrued++
+		}
+
+		after = accounts[len(accounts)-1].ID
+	}
+
+	return accrued, nil
+}
+
+func (j *AccrueInterest) capitalize(ctx context.Context, monthEnd time.Time) (int, error) {
+	period := interest.Period(monthEnd)
+	var after uuid.UUID
+	var paid int
+	for {
+		accounts, err := j.dbGetSavingsAccountsBatch(ctx, monthEnd, after)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read accounts: %w", err)
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, a := range accounts {
+			if err := j.capitalizeAccount(ctx, a, monthEnd, period); err != nil {
+				return 0, fmt.Errorf("failed to capitalize %s: %w", a.ID, err)
+			}
+			paid++
+		}
+
+		after = accounts[len(accounts)-1].ID
+	}
+
+	return paid, nil
+}
+
+func (j *AccrueInterest) capitalizeAccount(ctx context.Context, a dbGetSavingsAccountsBatchRow, monthEnd time.Time, period string) error {
+	tx, err := beginAudited(ctx, j.db, "accrue_interest")
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	done, err := j.dbCapitalizationExists(ctx, tx, a.ID, period)
+	if err != nil || done {
+		return err
+	}
+
+	// Fractions of a cent that weren't paid out last month carry over.
+	monthStart := monthEnd.AddDate(0, 0, 1-monthEnd.Day())
+	micros, err := j.dbGetAccruedMicros(ctx, tx, a.ID, monthStart, monthEnd, period)
+	if err != nil {
+		return err
+	}
+
+	amount := interest.Capitalized(micros)
+	var transactionID *uuid.UUID
+	if amount > 0 {
+		expenseID, err := ledger.InternalAccountID(ctx, tx, domain.InternalAccountInterestExpense, a.Currency)
+		if err != nil {
+			return err
+		}
+		t, _, err := ledger.Post(ctx, tx, ledger.Transaction{
+			Kind:           domain.TransactionKindInterest,
+			Amount:         amount,
+			Currency:       a.Currency,
+			Description:    "Interest " + period,
+			IdempotencyKey: "interest:" + a.ID.String() + ":" + period,
+			Legs: []ledger.Leg{
+				{AccountID: expenseID, Amount: -amount},
+				{AccountID: a.ID, Amount: amount},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		transactionID = &t.ID
+	}
+
+	if err := j.dbInsertCapitalization(ctx, tx, a.ID, period, micros, amount, transactionID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// dbGetFirstRunDate returns the first day the job ran, including days
+// accrued before runs were recorded.
+func (j *AccrueInterest) dbGetFirstRunDate(ctx context.Context) (*time.Time, error) {
+	sql := `
+		SELECT LEAST(
+			(SELECT min(run_date) FROM interest_runs),
+			(SELECT min(accrual_date) FROM interest_accruals)
+		)`
+
+	var res *time.Time
+	err := j.db.QueryRow(ctx, sql).Scan(&res)
+	return res, err
+}
+
+func (j *AccrueInterest) dbGetRuns(ctx context.Context, from, to time.Time) (map[time.Time]interestRun, error) {
+	sql := `
+		SELECT run_date, accrued_at IS NOT NULL, capitalized_at IS NOT NULL FROM interest_runs
+		WHERE run_date BETWEEN $1 AND $2`
+
+	rows, _ := j.db.Query(ctx, sql, from, to)
+	res := map[time.Time]interestRun{}
+	var date time.Time
+	var r interestRun
+	_, err := pgx.ForEachRow(rows, []any{&date, &r.Accrued, &r.Capitalized}, func() error {
+		res[interest.Date(date)] = r
+		return nil
+	})
+	return res, err
+}
+
+// dbMarkRun records that a phase of the day is done, column is accrued_at or
+// capitalized_at.
+func (j *AccrueInterest) dbMarkRun(ctx context.Context, date time.Time, column string) error {
+	sql := `
+		INSERT INTO interest_runs (run_date, ` + column + `) VALUES ($1, now())
+		ON CONFLICT (run_date) DO UPDATE SET ` + column + ` = COALESCE(interest_runs.` + column + `, now())`
+
+	_, err := j.db.Exec(ctx, sql, date)
+	return err
+}
+
+type dbGetSavingsAccountsBatchRow struct {
+	ID       uuid.UUID `db:"id"`
+	Currency string    `db:"currency"`
+}
+
+// dbGetSavingsAccountsBatch reads savings accounts that were open at the end
+// of date.
+func (j *AccrueInterest) dbGetSavingsAccountsBatch(ctx context.Context, date time.Time, after uuid.UUID) ([]dbGetSavingsAccountsBatchRow, error) {
+	sql := `
+		SELECT id, currency FROM accounts
+		WHERE kind = $1 AND product = $2 AND created_at < $3 AND id > $4
+		ORDER BY id LIMIT $5`
+
+	rows, _ := j.db.Query(ctx, sql,
+		domain.AccountKindCustomer,
+		domain.AccountProductSavings,
+		date.AddDate(0, 0, 1),
+		after,
+		interestBatchSize,
+	)
+	return pgx.CollectRows[dbGetSavingsAccountsBatchRow](rows, pgx.RowToStructByName[dbGetSavingsAccountsBatchRow])
+}
+
+// dbGetRate returns the savings rate in effect on date, zero if there's none.
+func (j *AccrueInterest) dbGetRate(ctx context.Context, currency string, date time.Time) (int64, error) {
+	sql := `
+		SELECT rate_bps FROM interest_rates
+		WHERE product = $1 AND currency = $2 AND effective_from <= $3
+		ORDER BY effective_from DESC LIMIT 1`
+
+	var res int64
+	err := j.db.QueryRow(ctx, sql, domain.AccountProductSavings, currency, date).Scan(&res)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return res, err
+}
+
+// dbGetEndOfDayBalance sums up the entries cleared until the end of date, so
+// it's the same no matter when the job runs.
+func (j *AccrueInterest) dbGetEndOfDayBalance(ctx context.Context, accountID uuid.UUID, date time.Time) (int64, error) {
+	sql := `
+		SELECT COALESCE(SUM(e.amount), 0)
+		FROM entries e JOIN transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = $1 AND t.status = $2 AND t.cleared_at < $3`
+
+	var res int64
+	err := j.db.QueryRow(ctx, sql, accountID, domain.TransactionStatusCleared, date.AddDate(0, 0, 1)).Scan(&res)
+	return res, err
+}
+
+func (j *AccrueInterest) dbInsertAccrual(ctx context.Context, accountID uuid.UUID, date time.Time, balance, rateBPS, micros int64) error {
+	sql := `
+		INSERT INTO interest_accruals (account_id, accrual_date, balance, rate_bps, amount_micros)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id, accrual_date) DO NOTHING`
+
+	_, err := j.db.Exec(ctx, sql, accountID, date, balance, rateBPS, micros)
+	return err
+}
+
+func (j *AccrueInterest) dbCapitalizationExists(ctx context.Context, tx pgx.Tx, accountID uuid.UUID, period string) (ok bool, err error) {
+	sql := `SELECT true FROM interest_capitalizations WHERE account_id = $1 AND period = $2 FOR UPDATE`
+
+	err = tx.QueryRow(ctx, sql, accountID, period).Scan(&ok)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return ok, nil
+	}
+}
+
+// dbGetAccruedMicros sums up the accruals of a month plus what wasn't paid out
+// of the previous one.
+func (j *AccrueInterest) dbGetAccruedMicros(ctx context.Context, tx pgx.Tx, accountID uuid.UUID, from, to time.Time, period string) (int64, error) {
+	sql := `
+		SELECT
+			(
+				SELECT COALESCE(SUM(amount_micros), 0) FROM interest_accruals
+				WHERE account_id = $1 AND accrual_date BETWEEN $2 AND $3
+			) + COALESCE((
+				SELECT accrued_micros - amount * $4 FROM interest_capitalizations
+				WHERE account_id = $1 AND period < $5
+				ORDER BY period DESC LIMIT 1
+			), 0)`
+
+	var res int64
+	err := tx.QueryRow(ctx, sql, accountID, from, to, interest.MicrosPerUnit, period).Scan(&res)
+	return res, err
+}
+
+func (j *AccrueInterest) dbInsertCapitalization(ctx context.Context, tx pgx.Tx, accountID uuid.UUID, period string, micros, amount int64, transactionID *uuid.UUID) error {
+	sql := `
+		INSERT INTO interest_capitalizations (account_id, period, accrued_micros, amount, transaction_id)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.Exec(ctx, sql, accountID, period, micros, amount, transactionID)
+	return err
+}
@@ -0,0 +1,62 @@
+package job
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPendingInterestDates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	febEnd := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	marFirst := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		from, to time.Time
+		runs     map[time.Time]interestRun
+		want     []time.Time
+	}{
+		{
+			name: "all done",
+			from: day(1), to: day(3),
+			runs: map[time.Time]interestRun{day(1): {Accrued: true}, day(2): {Accrued: true}, day(3): {Accrued: true}},
+		},
+		{
+			name: "never ran",
+			from: day(1), to: day(3),
+			want: []time.Time{day(1), day(2), day(3)},
+		},
+		{
+			name: "crashed half way through a day",
+			from: day(1), to: day(3),
+			runs: map[time.Time]interestRun{day(1): {Accrued: true}},
+			want: []time.Time{day(2), day(3)},
+		},
+		{
+			name: "gap",
+			from: day(1), to: day(3),
+			runs: map[time.Time]interestRun{day(1): {Accrued: true}, day(3): {Accrued: true}},
+			want: []time.Time{day(2)},
+		},
+		{
+			name: "month end accrued but not capitalized",
+			from: febEnd, to: marFirst,
+			runs: map[time.Time]interestRun{febEnd: {Accrued: true}, marFirst: {Accrued: true}},
+			want: []time.Time{febEnd},
+		},
+		{
+			name: "month end capitalized",
+			from: febEnd, to: marFirst,
+			runs: map[time.Time]interestRun{febEnd: {Accrued: true, Capitalized: true}},
+			want: []time.Time{marFirst},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pendingInterestDates(tc.from, tc.to, tc.runs); !slices.Equal(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
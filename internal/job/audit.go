@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/audit"
+)
+
+// beginAudited begins a transaction whose changes are attributed to the job,
+// see package audit.
+func beginAudited(ctx context.Context, db *pgxpool.Pool, job string) (pgx.Tx, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := audit.Set(ctx, tx, audit.System("job:"+job), job, ""); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,208 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/util"
+	"github.com/detod/best-wallet/internal/webhook"
+)
+
+const (
+	webhookBatchSize   = 50
+	webhookMaxAttempts = 12 // With backoff capped at 6h, that's about a day and a half.
+	// webhookLease covers sending a whole batch, one delivery at a time, with
+	// the sender's timeout (10s in cmd/server) for each.
+	webhookLease = 10 * time.Minute
+)
+
+func NewDeliverWebhooks(
+	db *pgxpool.Pool,
+	sender *webhook.Sender,
+	interval time.Duration,
+) *DeliverWebhooks {
+	return &DeliverWebhooks{
+		db:       db,
+		sender:   sender,
+		interval: interval,
+	}
+}
+
+// DeliverWebhooks sends pending webhook deliveries. Failed deliveries are
+// retried with exponential backoff, after webhookMaxAttempts they're marked
+// dead and can only be redelivered manually.
+//
+// Instances lease due deliveries before sending them, so it's safe to run on
+// every instance. No DB transaction is open while sending, each result is
+// recorded on its own. Subscribers must dedupe by event ID, delivery is
+// at-least-once: a delivery is sent again if the instance dies before
+// recording it.
+type DeliverWebhooks struct {
+	db       *pgxpool.Pool
+	sender   *webhook.Sender
+	interval time.Duration
+}
+
+func (j *DeliverWebhooks) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		for { // Drain everything that's due before sleeping.
+			n, err := j.RunOnce(ctx)
+			if err != nil {
+				log.Println("failed to deliver webhooks", err)
+			}
+			if err != nil || n < webhookBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce leases and sends a single batch of due deliveries and returns its
+// size.
+func (j *DeliverWebhooks) RunOnce(ctx context.Context) (int, error) {
+	// Taken before leasing, so it's never later than the lease's expiry.
+	deadline := time.Now().Add(webhookLease)
+	due, err := j.dbLeaseDue(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lease due deliveries: %w", err)
+	}
+
+	for _, d := range due {
+		if time.Now().After(deadline) {
+			// Another instance may have leased the rest, they're sent again
+			// once the lease expires otherwise.
+			break
+		}
+
+		attempts := d.Attempts + 1
+		code, err := j.sender.Send(ctx, d.URL, d.Secret, webhook.Delivery{
+			ID:        d.ID,
+			EventID:   d.EventID,
+			EventType: d.EventType,
+			Payload:   d.Payload,
+		})
+
+		now := time.Now()
+		args := dbRecordDeliveryArgs{
+			id:             d.ID,
+			leaseExpiresAt: d.LeaseExpiresAt,
+			status:         domain.WebhookDeliveryStatusSucceeded,
+			attempts:       attempts,
+			nextAttemptAt:  now,
+			deliveredAt:    &now,
+		}
+		if code != 0 {
+			args.statusCode = &code
+		}
+		if err != nil {
+			args.deliveredAt = nil
+			args.status = domain.WebhookDeliveryStatusPending
+			args.lastError = err.Error()
+			args.nextAttemptAt = now.Add(util.Backoff(attempts, 30*time.Second, 6*time.Hour))
+			if attempts >= webhookMaxAttempts {
+				args.status = domain.WebhookDeliveryStatusDead
+				log.Printf("webhook delivery %s is dead after %d attempts: %s", d.ID, attempts, err)
+			}
+		}
+		// A failure here leaves the lease to expire, the delivery is sent
+		// again then.
+		if err := j.dbRecordDelivery(ctx, args); err != nil {
+			log.Printf("failed to record webhook delivery %s: %s", d.ID, err)
+		}
+	}
+
+	return len(due), nil
+}
+
+type dbLeasedDelivery struct {
+	ID             uuid.UUID        `db:"id"`
+	EventID        uuid.UUID        `db:"event_id"`
+	EventType      domain.EventType `db:"event_type"`
+	Payload        []byte           `db:"payload"`
+	Attempts       int              `db:"attempts"`
+	LeaseExpiresAt time.Time        `db:"lease_expires_at"`
+	URL            string           `db:"url"`
+	Secret         string           `db:"secret"`
+}
+
+func (j *DeliverWebhooks) dbLeaseDue(ctx context.Context) ([]dbLeasedDelivery, error) {
+	// Deliveries of deactivated subscriptions stay pending, in case they're
+	// reactivated.
+	sql := `
+		WITH leased AS (
+			UPDATE webhook_deliveries SET lease_expires_at = now() + $1::interval
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE d.status = $2 AND d.next_attempt_at <= now() AND s.active
+					AND (d.lease_expires_at IS NULL OR d.lease_expires_at < now())
+				ORDER BY d.next_attempt_at
+				LIMIT $3
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING id, subscription_id, event_id, event_type, payload, attempts, lease_expires_at, next_attempt_at
+		)
+		SELECT l.id, l.event_id, l.event_type, l.payload, l.attempts, l.lease_expires_at, s.url, ss.secret
+		FROM leased l
+		JOIN webhook_subscriptions s ON s.id = l.subscription_id
+		JOIN webhook_subscription_secrets ss ON ss.subscription_id = s.id
+		ORDER BY l.next_attempt_at`
+
+	rows, _ := j.db.Query(ctx, sql, webhookLease.String(), domain.WebhookDeliveryStatusPending, webhookBatchSize)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[dbLeasedDelivery])
+}
+
+type dbRecordDeliveryArgs struct {
+	id             uuid.UUID
+	leaseExpiresAt time.Time
+	status         domain.WebhookDeliveryStatus
+	attempts       int
+	statusCode     *int
+	lastError      string
+	nextAttemptAt  time.Time
+	deliveredAt    *time.Time
+}
+
+// dbRecordDelivery records the result of an attempt and releases the lease,
+// unless the lease expired and the delivery was leased again since.
+func (j *DeliverWebhooks) dbRecordDelivery(ctx context.Context, args dbRecordDeliveryArgs) error {
+	sql := `
+		UPDATE webhook_deliveries SET
+			status = $1,
+			attempts = $2,
+			last_status_code = $3,
+			last_error = NULLIF($4, ''),
+			next_attempt_at = $5,
+			delivered_at = $6,
+			lease_expires_at = NULL,
+			updated_at = now()
+		WHERE id = $7 AND lease_expires_at = $8`
+
+	_, err := j.db.Exec(ctx, sql,
+		args.status,
+		args.attempts,
+		args.statusCode,
+		args.lastError,
+		args.nextAttemptAt,
+		args.deliveredAt,
+		args.id,
+		args.leaseExpiresAt,
+	)
+	return err
+}
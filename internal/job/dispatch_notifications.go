@@ -0,0 +1,185 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/notify"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+const (
+	notificationBatchSize   = 50
+	notificationMaxAttempts = 10
+	notificationSendTimeout = 15 * time.Second
+)
+
+func NewDispatchNotifications(
+	db *pgxpool.Pool,
+	notifiers map[notify.Channel]notify.Notifier,
+	interval time.Duration,
+) *DispatchNotifications {
+	return &DispatchNotifications{
+		db:        db,
+		notifiers: notifiers,
+		interval:  interval,
+	}
+}
+
+// DispatchNotifications sends pending notifications from the outbox.
+// Failures are retried with exponential backoff until notificationMaxAttempts
+// is reached, or the channel reports a permanent error, at which point the
+// notification is marked failed.
+//
+// The recipient and the rendered message are plaintext customer data, they're
+// cleared once the notification is sent or failed for good, only the
+// template and the outcome are kept.
+//
+// Rows are locked with SKIP LOCKED while being sent, so it's safe to run on
+// every instance. Delivery is at-least-once, a crash after sending but before
+// committing means the notification will be sent again.
+type DispatchNotifications struct {
+	db        *pgxpool.Pool
+	notifiers map[notify.Channel]notify.Notifier
+	interval  time.Duration
+}
+
+func (j *DispatchNotifications) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		for { // Drain everything that's due before sleeping.
+			n, err := j.RunOnce(ctx)
+			if err != nil {
+				log.Println("failed to dispatch notifications", err)
+			}
+			if err != nil || n < notificationBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processes a single batch of due notifications and returns its size.
+func (j *DispatchNotifications) RunOnce(ctx context.Context) (int, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	due, err := j.dbGetDueNotifications(ctx, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read due notifications: %w", err)
+	}
+
+	for _, n := range due {
+		err := j.send(ctx, n)
+		if err == nil {
+			if err := j.dbMarkSent(ctx, tx, n.ID); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		attempts := n.Attempts + 1
+		status := domain.NotificationStatusPending
+		if notify.IsPermanent(err) || attempts >= notificationMaxAttempts {
+			status = domain.NotificationStatusFailed
+			log.Printf("notification %s failed permanently after %d attempts: %s", n.ID, attempts, err)
+		}
+		if err := j.dbMarkFailedAttempt(ctx, tx, dbMarkFailedAttemptArgs{
+			id:            n.ID,
+			status:        status,
+			attempts:      attempts,
+			lastError:     err.Error(),
+			nextAttemptAt: time.Now().Add(util.Backoff(attempts, 30*time.Second, 6*time.Hour)),
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(due), tx.Commit(ctx)
+}
+
+func (j *DispatchNotifications) send(ctx context.Context, n dbGetDueNotificationsRow) error {
+	notifier, ok := j.notifiers[n.Channel]
+	if !ok {
+		return &notify.PermanentError{Err: fmt.Errorf("no notifier for channel %s", n.Channel)}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	defer cancel()
+
+	return notifier.Notify(ctx, notify.Message{
+		Channel: n.Channel,
+		To:      n.Recipient,
+		Subject: n.Subject,
+		Body:    n.Body,
+	})
+}
+
+type dbGetDueNotificationsRow struct {
+	ID        uuid.UUID      `db:"id"`
+	Channel   notify.Channel `db:"channel"`
+	Recipient string         `db:"recipient"`
+	Subject   string         `db:"subject"`
+	Body      string         `db:"body"`
+	Attempts  int            `db:"attempts"`
+}
+
+func (j *DispatchNotifications) dbGetDueNotifications(ctx context.Context, tx pgx.Tx) ([]dbGetDueNotificationsRow, error) {
+	sql := `
+		SELECT id, channel, recipient, subject, body, attempts FROM notification_outbox
+		WHERE status = $1 AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+
+	rows, _ := tx.Query(ctx, sql, domain.NotificationStatusPending, notificationBatchSize)
+	return pgx.CollectRows[dbGetDueNotificationsRow](rows, pgx.RowToStructByName[dbGetDueNotificationsRow])
+}
+
+func (j *DispatchNotifications) dbMarkSent(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	sql := `
+		UPDATE notification_outbox SET status = $1, attempts = attempts + 1, sent_at = now(), updated_at = now(),
+			recipient = '', subject = '', body = ''
+		WHERE id = $2`
+
+	_, err := tx.Exec(ctx, sql, domain.NotificationStatusSent, id)
+	return err
+}
+
+type dbMarkFailedAttemptArgs struct {
+	id            uuid.UUID
+	status        domain.NotificationStatus
+	attempts      int
+	lastError     string
+	nextAttemptAt time.Time
+}
+
+func (j *DispatchNotifications) dbMarkFailedAttempt(ctx context.Context, tx pgx.Tx, args dbMarkFailedAttemptArgs) error {
+	sql := `
+		UPDATE notification_outbox SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, updated_at = now(),
+			recipient = CASE WHEN $1 = $6 THEN recipient ELSE '' END,
+			subject = CASE WHEN $1 = $6 THEN subject ELSE '' END,
+			body = CASE WHEN $1 = $6 THEN body ELSE '' END
+		WHERE id = $5`
+
+	_, err := tx.Exec(ctx, sql, args.status, args.attempts, args.lastError, args.nextAttemptAt, args.id, domain.NotificationStatusPending)
+	return err
+}
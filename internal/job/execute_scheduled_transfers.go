@@ -0,0 +1,326 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+const (
+	scheduledTransferBatchSize  = 50
+	scheduledTransferLease      = time.Minute
+	scheduledTransferMaxRetries = 5 // With backoff capped at 12h, about a day.
+)
+
+func NewExecuteScheduledTransfers(
+	db *pgxpool.Pool,
+	owner string,
+	interval time.Duration,
+) *ExecuteScheduledTransfers {
+	return &ExecuteScheduledTransfers{
+		db:       db,
+		owner:    owner,
+		interval: interval,
+	}
+}
+
+// ExecuteScheduledTransfers initiates transfers of due scheduled transfers.
+//
+// Instances lease due schedules before executing them, so a schedule is
+// picked up by one instance at a time and by another one only if the lease
+// holder dies. Every occurrence is executed exactly once: its run row is
+// unique per occurrence and written in the same DB transaction as the
+// transfer (which is itself idempotent per occurrence).
+type ExecuteScheduledTransfers struct {
+	db       *pgxpool.Pool
+	owner    string // Identifies this instance in leases.
+	interval time.Duration
+}
+
+func (j *ExecuteScheduledTransfers) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		for { // Drain everything that's due before sleeping.
+			n, err := j.RunOnce(ctx)
+			if err != nil {
+				log.Println("failed to execute scheduled transfers", err)
+			}
+			if err != nil || n < scheduledTransferBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce leases and executes a single batch of due schedules and returns its
+// size.
+func (j *ExecuteScheduledTransfers) RunOnce(ctx context.Context) (int, error) {
+	ids, err := j.dbLeaseDue(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lease due scheduled transfers: %w", err)
+	}
+
+	for _, id := range ids {
+		// A failure here leaves the lease to expire, the schedule is retried
+		// then.
+		if err := j.execute(ctx, id); err != nil {
+			log.Printf("failed to execute scheduled transfer %s: %s", id, err)
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (j *ExecuteScheduledTransfers) execute(ctx context.Context, id uuid.UUID) error {
+	tx, err := beginAudited(ctx, j.db, "execute_scheduled_transfers")
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	s, found, err := j.dbLockLeased(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil // Paused, cancelled or leased by someone else in the meantime.
+	}
+
+	// Scheduled transfers count towards the customer's limits like any other
+	// transfer, going over is handled like missing funds.
+	var t ledger.Transaction
+	err = limits.Check(ctx, tx, s.CustomerID, domain.LimitKindTransferOut, s.Currency, s.Amount, time.Now())
+	if err == nil {
+		t, err = j.initiate(ctx, tx, s)
+	}
+	run := dbInsertRunArgs{
+		scheduledTransferID: s.ID,
+		occurrence:          s.NextOccurrence,
+		scheduledFor:        s.NextOccurrenceAt,
+		attempts:            s.Retries + 1,
+	}
+	var exceeded *limits.ExceededError
+	switch {
+	case err == nil:
+		run.status = domain.ScheduledTransferRunStatusExecuted
+		run.transactionID = &t.ID
+
+	case errors.Is(err, ledger.ErrInsufficientFunds), errors.As(err, &exceeded):
+		if s.OnInsufficientFunds == domain.InsufficientFundsPolicyRetry && s.Retries < scheduledTransferMaxRetries {
+			retries := s.Retries + 1
+			nextAttemptAt := time.Now().Add(util.Backoff(retries, time.Hour, 12*time.Hour))
+			if err := j.dbRetryLater(ctx, tx, s.ID, retries, nextAttemptAt); err != nil {
+				return err
+			}
+			return tx.Commit(ctx)
+		}
+		run.status = domain.ScheduledTransferRunStatusSkipped
+		run.error = err.Error()
+
+	case errors.Is(err, ledger.ErrAccountNotFound), errors.Is(err, ledger.ErrCurrencyMismatch), errors.Is(err, ledger.ErrAccountFrozen):
+		run.status = domain.ScheduledTransferRunStatusFailed
+		run.error = err.Error()
+
+	default:
+		return err
+	}
+
+	if err := j.dbInsertRun(ctx, tx, run); err != nil {
+		return err
+	}
+	if err := j.dbAdvance(ctx, tx, s); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (j *ExecuteScheduledTransfers) initiate(ctx context.Context, tx pgx.Tx, s dbScheduledTransfer) (ledger.Transaction, error) {
+	quote, err := fees.Get(ctx, tx, s.CustomerID, domain.TransactionKindTransfer, s.Currency, s.Amount, time.Now())
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	t := ledger.Transaction{
+		Kind:           domain.TransactionKindTransfer,
+		Amount:         s.Amount,
+		Currency:       s.Currency,
+		Description:    s.Description,
+		IdempotencyKey: fmt.Sprintf("scheduled:%s:%d", s.ID, s.NextOccurrence),
+		Legs: []ledger.Leg{
+			{AccountID: s.FromAccountID, Amount: -s.Amount},
+			{AccountID: s.ToAccountID, Amount: s.Amount},
+		},
+	}
+	if err := ledger.ChargeFee(ctx, tx, &t, s.FromAccountID, quote.Fee); err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	t, _, err = ledger.Initiate(ctx, tx, t)
+	return t, err
+}
+
+func (j *ExecuteScheduledTransfers) dbLeaseDue(ctx context.Context) ([]uuid.UUID, error) {
+	tx, err := beginAudited(ctx, j.db, "execute_scheduled_transfers")
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	sql := `
+		UPDATE scheduled_transfers SET lease_owner = $1, lease_expires_at = now() + $2::interval
+		WHERE id IN (
+			SELECT id FROM scheduled_transfers
+			WHERE status = $3 AND next_attempt_at <= now()
+				AND (lease_expires_at IS NULL OR lease_expires_at < now())
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`
+
+	rows, _ := tx.Query(ctx, sql,
+		j.owner,
+		scheduledTransferLease.String(),
+		domain.ScheduledTransferStatusActive,
+		scheduledTransferBatchSize,
+	)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, tx.Commit(ctx)
+}
+
+type dbScheduledTransfer struct {
+	ID                  uuid.UUID                      `db:"id"`
+	CustomerID          uuid.UUID                      `db:"customer_id"`
+	FromAccountID       uuid.UUID                      `db:"from_account_id"`
+	ToAccountID         uuid.UUID                      `db:"to_account_id"`
+	Amount              int64                          `db:"amount"`
+	Currency            string                         `db:"currency"`
+	Description         string                         `db:"description"`
+	StartAt             time.Time                      `db:"start_at"`
+	Recurrence          *string                        `db:"recurrence"`
+	OnInsufficientFunds domain.InsufficientFundsPolicy `db:"on_insufficient_funds"`
+	NextOccurrence      int                            `db:"next_occurrence"`
+	NextOccurrenceAt    time.Time                      `db:"next_occurrence_at"`
+	Retries             int                            `db:"retries"`
+}
+
+func (j *ExecuteScheduledTransfers) dbLockLeased(ctx context.Context, tx pgx.Tx, id uuid.UUID) (dbScheduledTransfer, bool, error) {
+	sql := `
+		SELECT id, customer_id, from_account_id, to_account_id, amount, currency, description, start_at,
+			recurrence, on_insufficient_funds, next_occurrence, next_occurrence_at, retries
+		FROM scheduled_transfers
+		WHERE id = $1 AND status = $2 AND lease_owner = $3
+		FOR UPDATE`
+
+	rows, _ := tx.Query(ctx, sql, id, domain.ScheduledTransferStatusActive, j.owner)
+	res, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[dbScheduledTransfer])
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return res, false, nil
+	case err != nil:
+		return res, false, err
+	default:
+		return res, true, nil
+	}
+}
+
+func (j *ExecuteScheduledTransfers) dbRetryLater(ctx context.Context, tx pgx.Tx, id uuid.UUID, retries int, nextAttemptAt time.Time) error {
+	sql := `
+		UPDATE scheduled_transfers SET
+			retries = $1,
+			next_attempt_at = $2,
+			lease_owner = NULL,
+			lease_expires_at = NULL,
+			updated_at = now()
+		WHERE id = $3`
+
+	_, err := tx.Exec(ctx, sql, retries, nextAttemptAt, id)
+	return err
+}
+
+type dbInsertRunArgs struct {
+	scheduledTransferID uuid.UUID
+	occurrence          int
+	scheduledFor        time.Time
+	status              domain.ScheduledTransferRunStatus
+	transactionID       *uuid.UUID
+	attempts            int
+	error               string
+}
+
+func (j *ExecuteScheduledTransfers) dbInsertRun(ctx context.Context, tx pgx.Tx, args dbInsertRunArgs) error {
+	sql := `
+		INSERT INTO scheduled_transfer_runs (
+			id, scheduled_transfer_id, occurrence, scheduled_for, status, transaction_id, attempts, error
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))`
+
+	_, err := tx.Exec(ctx, sql,
+		uuid.New(),
+		args.scheduledTransferID,
+		args.occurrence,
+		args.scheduledFor,
+		args.status,
+		args.transactionID,
+		args.attempts,
+		args.error,
+	)
+	return err
+}
+
+// dbAdvance moves the schedule on to its next occurrence, or completes it if
+// there's none.
+func (j *ExecuteScheduledTransfers) dbAdvance(ctx context.Context, tx pgx.Tx, s dbScheduledTransfer) error {
+	status := domain.ScheduledTransferStatusCompleted
+	var nextAt *time.Time
+	if s.Recurrence != nil {
+		r, err := util.ParseRecurrence(*s.Recurrence)
+		if err != nil {
+			return err
+		}
+		if t, ok := r.Occurrence(s.StartAt, s.NextOccurrence+1); ok {
+			status = domain.ScheduledTransferStatusActive
+			nextAt = &t
+		}
+	}
+
+	sql := `
+		UPDATE scheduled_transfers SET
+			status = $1,
+			next_occurrence = next_occurrence + 1,
+			next_occurrence_at = $2,
+			next_attempt_at = $2,
+			retries = 0,
+			lease_owner = NULL,
+			lease_expires_at = NULL,
+			updated_at = now()
+		WHERE id = $3`
+
+	_, err := tx.Exec(ctx, sql, status, nextAt, s.ID)
+	return err
+}
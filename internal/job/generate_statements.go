@@ -0,0 +1,105 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pglock"
+	"github.com/detod/best-wallet/internal/statement"
+)
+
+const statementsBatchSize = 100
+
+func NewGenerateStatements(
+	db *pgxpool.Pool,
+	interval time.Duration,
+) *GenerateStatements {
+	return &GenerateStatements{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// GenerateStatements generates last month's statement of every customer
+// account once the month is over. Accounts that already have one are
+// skipped, so it just checks for missing statements on every run.
+type GenerateStatements struct {
+	db       *pgxpool.Pool
+	interval time.Duration
+}
+
+func (j *GenerateStatements) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+		if err := j.RunForPeriod(ctx, period); err != nil {
+			log.Println("failed to generate statements", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *GenerateStatements) RunForPeriod(ctx context.Context, period string) error {
+	_, to, err := statement.ParsePeriod(period)
+	if err != nil {
+		return err
+	}
+
+	_, err = pglock.TryWith(ctx, j.db, pglock.KeyGenerateStatements, func(*pgxpool.Conn) error {
+		start := time.Now()
+		var after uuid.UUID
+		var generated int
+		for {
+			accounts, err := j.dbGetAccountsWithoutStatement(ctx, period, to, after)
+			if err != nil {
+				return fmt.Errorf("failed to read accounts: %w", err)
+			}
+			if len(accounts) == 0 {
+				break
+			}
+
+			for _, id := range accounts {
+				if err := statement.Generate(ctx, j.db, id, period, time.Now()); err != nil {
+					return fmt.Errorf("failed to generate %s statement of %s: %w", period, id, err)
+				}
+				generated++
+			}
+
+			after = accounts[len(accounts)-1]
+		}
+
+		if generated > 0 {
+			log.Printf("generate statements: generated %d for %s in %s", generated, period, time.Since(start))
+		}
+		return nil
+	})
+	return err // Not run when already running elsewhere.
+}
+
+// dbGetAccountsWithoutStatement reads customer accounts that were open during
+// the period and don't have its statement yet.
+func (j *GenerateStatements) dbGetAccountsWithoutStatement(ctx context.Context, period string, periodEnd time.Time, after uuid.UUID) ([]uuid.UUID, error) {
+	sql := `
+		SELECT a.id FROM accounts a
+		LEFT JOIN account_statements s ON s.account_id = a.id AND s.period = $1
+		WHERE a.kind = $2 AND a.created_at < $3 AND s.account_id IS NULL AND a.id > $4
+		ORDER BY a.id LIMIT $5`
+
+	rows, _ := j.db.Query(ctx, sql, period, domain.AccountKindCustomer, periodEnd, after, statementsBatchSize)
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
@@ -0,0 +1,182 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/pglock"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+const reencryptCustomersBatchSize = 100
+
+func NewReencryptCustomers(
+	db *pgxpool.Pool,
+	cipher *pii.Cipher,
+	interval time.Duration,
+) *ReencryptCustomers {
+	return &ReencryptCustomers{
+		db:       db,
+		cipher:   cipher,
+		interval: interval,
+	}
+}
+
+// ReencryptCustomers seals customer PII with a fresh data key wrapped by the
+// current KEK, for rows wrapped by an older KEK (after a rotation) and rows
+// still in plaintext (created before encryption). Once it's done, old KEKs
+// can be dropped from the configuration.
+//
+// Every row is re-encrypted in its own transaction, a row that fails (e.g.
+// a plaintext email already registered by another customer) is logged and
+// skipped until the next run.
+type ReencryptCustomers struct {
+	db       *pgxpool.Pool
+	cipher   *pii.Cipher
+	interval time.Duration
+}
+
+func (j *ReencryptCustomers) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			log.Println("failed to re-encrypt customers", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce re-encrypts every customer that's due.
+func (j *ReencryptCustomers) RunOnce(ctx context.Context) error {
+	_, err := pglock.TryWith(ctx, j.db, pglock.KeyReencryptCustomers, func(*pgxpool.Conn) error {
+		keyID := j.cipher.CurrentKeyID()
+		var after uuid.UUID
+		var done, failed int
+		for {
+			ids, err := j.dbGetDueBatch(ctx, keyID, after)
+			if err != nil {
+				return fmt.Errorf("failed to read customers: %w", err)
+			}
+			if len(ids) == 0 {
+				break
+			}
+
+			for _, id := range ids {
+				if err := j.reencrypt(ctx, id, keyID); err != nil {
+					log.Printf("failed to re-encrypt customer %s: %s", id, err)
+					failed++
+					continue
+				}
+				done++
+			}
+
+			after = ids[len(ids)-1]
+		}
+
+		if done > 0 || failed > 0 {
+			log.Printf("re-encrypt customers: re-encrypted %d, failed %d", done, failed)
+		}
+		return nil
+	})
+	return err // Not run when already running elsewhere.
+}
+
+func (j *ReencryptCustomers) reencrypt(ctx context.Context, id uuid.UUID, keyID string) error {
+	tx, err := beginAudited(ctx, j.db, "reencrypt_customers")
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	row, due, err := j.dbLockCustomer(ctx, tx, id, keyID)
+	if err != nil || !due {
+		return err // Re-encrypted in the meantime.
+	}
+	p, err := j.cipher.OpenRow(ctx, id, row)
+	if err != nil {
+		return err
+	}
+	sealed, err := j.cipher.Seal(ctx, id, p)
+	if err != nil {
+		return err
+	}
+	if err := j.dbUpdateCustomer(ctx, tx, id, sealed); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (j *ReencryptCustomers) dbGetDueBatch(ctx context.Context, keyID string, after uuid.UUID) ([]uuid.UUID, error) {
+	sql := `
+		SELECT id FROM customers
+		WHERE pii_kek_id IS DISTINCT FROM $1 AND id > $2
+		ORDER BY id LIMIT $3`
+
+	rows, _ := j.db.Query(ctx, sql, keyID, after, reencryptCustomersBatchSize)
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+func (j *ReencryptCustomers) dbLockCustomer(ctx context.Context, tx pgx.Tx, id uuid.UUID, keyID string) (pii.Row, bool, error) {
+	sql := `SELECT ` + pii.Columns("") + ` FROM customers WHERE id = $1 AND pii_kek_id IS DISTINCT FROM $2 FOR UPDATE`
+	var row pii.Row
+
+	err := tx.QueryRow(ctx, sql, id, keyID).Scan(row.ScanTargets()...)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return row, false, nil
+	case err != nil:
+		return row, false, err
+	default:
+		return row, true, nil
+	}
+}
+
+// dbUpdateCustomer stores the sealed PII and clears the plaintext, if any.
+// updated_at is left alone, nothing changed for the customer: the
+// set_updated_at trigger skips it for the rest of the transaction (see
+// 000035_keep_updated_at_on_maintenance).
+func (j *ReencryptCustomers) dbUpdateCustomer(ctx context.Context, tx pgx.Tx, id uuid.UUID, s pii.Sealed) error {
+	if _, err := tx.Exec(ctx, `SELECT set_config('bestwallet.keep_updated_at', 'on', true)`); err != nil {
+		return err
+	}
+
+	sql := `
+		UPDATE customers SET
+			first_name_enc = $1, last_name_enc = $2, email_enc = $3, residence_address_enc = $4, birth_date_enc = $5,
+			pii_data_key = $6, pii_kek_id = $7, email_bidx = $8,
+			first_name = NULL, last_name = NULL, email = NULL, residence_address = NULL, birth_date = NULL
+		WHERE id = $9`
+
+	_, err := tx.Exec(ctx, sql,
+		s.FirstName,
+		s.LastName,
+		s.Email,
+		s.ResidenceAddress,
+		s.BirthDate,
+		s.DataKey,
+		s.KeyID,
+		s.EmailIndex,
+		id,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == "customers_email_bidx_key" {
+		return errors.New("email already registered by another customer")
+	}
+	return err
+}
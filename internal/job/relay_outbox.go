@@ -0,0 +1,160 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+const outboxBatchSize = 100
+
+// outboxStreamMaxLen roughly caps the stream length. Consumers that fall
+// this far behind lose events, the outbox table still has them for replays.
+const outboxStreamMaxLen = 1_000_000
+
+func NewRelayOutbox(
+	db *pgxpool.Pool,
+	redis *redis.Client,
+	stream string,
+	interval time.Duration,
+) *RelayOutbox {
+	return &RelayOutbox{
+		db:       db,
+		redis:    redis,
+		stream:   stream,
+		interval: interval,
+	}
+}
+
+// RelayOutbox publishes events from the outbox table to a redis stream.
+//
+// Publishing and marking events as published aren't atomic, so an event can
+// be published more than once (at-least-once delivery). Consumers dedupe by
+// event ID.
+type RelayOutbox struct {
+	db       *pgxpool.Pool
+	redis    *redis.Client
+	stream   string
+	interval time.Duration
+}
+
+func (j *RelayOutbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		for { // Drain everything before sleeping.
+			n, err := j.RunOnce(ctx)
+			if err != nil {
+				log.Println("failed to relay outbox", err)
+			}
+			if err != nil || n < outboxBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce publishes a single batch of events and returns its size.
+func (j *RelayOutbox) RunOnce(ctx context.Context) (int, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	events, err := j.dbGetUnpublishedEvents(ctx, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read unpublished events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	evs := make([]domain.Event, 0, len(events))
+	for _, e := range events {
+		evs = append(evs, domain.Event{
+			ID:          e.EventID,
+			Type:        e.EventType,
+			AggregateID: e.AggregateID,
+			OccurredAt:  e.OccurredAt,
+			Payload:     e.Payload,
+		})
+	}
+	if err := PublishEvents(ctx, j.redis, j.stream, evs); err != nil {
+		return 0, fmt.Errorf("failed to publish events: %w", err)
+	}
+
+	seqs := make([]int64, 0, len(events))
+	for _, e := range events {
+		seqs = append(seqs, e.Seq)
+	}
+	if err := j.dbMarkPublished(ctx, tx, seqs); err != nil {
+		return 0, err
+	}
+
+	return len(events), tx.Commit(ctx)
+}
+
+type dbGetUnpublishedEventsRow struct {
+	Seq         int64            `db:"seq"`
+	EventID     uuid.UUID        `db:"event_id"`
+	EventType   domain.EventType `db:"event_type"`
+	AggregateID uuid.UUID        `db:"aggregate_id"`
+	Payload     json.RawMessage  `db:"payload"`
+	OccurredAt  time.Time        `db:"occurred_at"`
+}
+
+func (j *RelayOutbox) dbGetUnpublishedEvents(ctx context.Context, tx pgx.Tx) ([]dbGetUnpublishedEventsRow, error) {
+	sql := `
+		SELECT seq, event_id, event_type, aggregate_id, payload, occurred_at FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY seq
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, _ := tx.Query(ctx, sql, outboxBatchSize)
+	return pgx.CollectRows[dbGetUnpublishedEventsRow](rows, pgx.RowToStructByName[dbGetUnpublishedEventsRow])
+}
+
+func (j *RelayOutbox) dbMarkPublished(ctx context.Context, tx pgx.Tx, seqs []int64) error {
+	sql := `UPDATE outbox SET published_at = now() WHERE seq = ANY($1)`
+
+	_, err := tx.Exec(ctx, sql, seqs)
+	return err
+}
+
+// PublishEvents adds events to the stream the consumers read from. The relay
+// publishes the outbox with it, and operators replay events with it.
+func PublishEvents(ctx context.Context, rdb *redis.Client, stream string, events []domain.Event) error {
+	pipe := rdb.Pipeline()
+	for _, ev := range events {
+		raw, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			MaxLen: outboxStreamMaxLen,
+			Approx: true,
+			Values: map[string]any{"event": raw},
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,121 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/pglock"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+const rescreenBatchSize = 500
+
+func NewRescreenCustomers(
+	db *pgxpool.Pool,
+	screener *screening.Screener,
+	cipher *pii.Cipher,
+) *RescreenCustomers {
+	return &RescreenCustomers{
+		db:       db,
+		screener: screener,
+		cipher:   cipher,
+	}
+}
+
+// RescreenCustomers screens every customer against the currently loaded
+// watchlists and stores new hits for manual review. It runs whenever a
+// watchlist file is (re)loaded.
+//
+// Hits for customers that are already approved are only queued for review,
+// the job doesn't make KYC decisions on its own.
+type RescreenCustomers struct {
+	db       *pgxpool.Pool
+	screener *screening.Screener
+	cipher   *pii.Cipher
+}
+
+func (j *RescreenCustomers) Run(ctx context.Context) error {
+	ran, err := pglock.TryWith(ctx, j.db, pglock.KeyRescreenCustomers, func(*pgxpool.Conn) error {
+		start := time.Now()
+		var after uuid.UUID
+		var screened, flagged int
+		for {
+			customers, err := j.dbGetCustomersBatch(ctx, after)
+			if err != nil {
+				return fmt.Errorf("failed to read customers: %w", err)
+			}
+			if len(customers) == 0 {
+				break
+			}
+
+			for _, c := range customers {
+				hits := j.screener.Screen(screening.Subject{
+					Name:      c.FirstName + " " + c.LastName,
+					BirthDate: c.BirthDate,
+				})
+				if len(hits) > 0 {
+					if err := j.saveHits(ctx, c.ID, hits); err != nil {
+						return fmt.Errorf("failed to save hits for customer %s: %w", c.ID, err)
+					}
+					flagged++
+				}
+				screened++
+			}
+
+			after = customers[len(customers)-1].ID
+		}
+
+		log.Printf("rescreen customers: screened %d, flagged %d in %s", screened, flagged, time.Since(start))
+		return nil
+	})
+	if err == nil && !ran {
+		log.Println("rescreen customers: already running elsewhere, skipping")
+	}
+	return err
+}
+
+func (j *RescreenCustomers) saveHits(ctx context.Context, customerID uuid.UUID, hits []screening.Hit) error {
+	tx, err := beginAudited(ctx, j.db, "rescreen_customers")
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := screening.SaveHits(ctx, tx, customerID, hits); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type dbGetCustomersBatchRow struct {
+	ID        uuid.UUID
+	FirstName string
+	LastName  string
+	BirthDate time.Time
+}
+
+func (j *RescreenCustomers) dbGetCustomersBatch(ctx context.Context, after uuid.UUID) ([]dbGetCustomersBatchRow, error) {
+	sql := `
+		SELECT id, ` + pii.Columns("") + ` FROM customers
+		WHERE id > $1 ORDER BY id LIMIT $2`
+
+	rows, _ := j.db.Query(ctx, sql, after, rescreenBatchSize)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (dbGetCustomersBatchRow, error) {
+		var res dbGetCustomersBatchRow
+		var sealed pii.Row
+		if err := row.Scan(append([]any{&res.ID}, sealed.ScanTargets()...)...); err != nil {
+			return res, err
+		}
+		p, err := j.cipher.OpenRow(ctx, res.ID, sealed)
+		res.FirstName, res.LastName, res.BirthDate = p.FirstName, p.LastName, p.BirthDate
+		return res, err
+	})
+}
@@ -0,0 +1,62 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/pglock"
+)
+
+const auditLogSealBatchSize = 1000
+
+func NewSealAuditLog(
+	db *pgxpool.Pool,
+	interval time.Duration,
+) *SealAuditLog {
+	return &SealAuditLog{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// SealAuditLog hash-chains committed audit records, see audit.Seal. Chaining
+// in the background keeps the transactions that change state from queueing
+// up behind the tip of the chain.
+type SealAuditLog struct {
+	db       *pgxpool.Pool
+	interval time.Duration
+}
+
+func (j *SealAuditLog) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx); err != nil {
+			log.Println("failed to seal audit log", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce seals everything that's committed so far.
+func (j *SealAuditLog) RunOnce(ctx context.Context) error {
+	_, err := pglock.TryWith(ctx, j.db, pglock.KeySealAuditLog, func(conn *pgxpool.Conn) error {
+		for {
+			n, err := audit.Seal(ctx, conn, auditLogSealBatchSize)
+			if err != nil || n < auditLogSealBatchSize {
+				return err
+			}
+		}
+	})
+	return err // Not run when already running elsewhere.
+}
@@ -0,0 +1,103 @@
+package job
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+func NewWatchlistLoader(
+	dir string,
+	interval time.Duration,
+	screener *screening.Screener,
+	rescreen *RescreenCustomers,
+) *WatchlistLoader {
+	return &WatchlistLoader{
+		dir:      dir,
+		interval: interval,
+		screener: screener,
+		rescreen: rescreen,
+		modTimes: make(map[string]time.Time),
+	}
+}
+
+// WatchlistLoader polls a directory for new or modified watchlist files
+// (.csv, .xml), loads them into the screener and re-screens the customer base.
+type WatchlistLoader struct {
+	dir      string
+	interval time.Duration
+	screener *screening.Screener
+	rescreen *RescreenCustomers
+
+	modTimes map[string]time.Time // Not guarded, only touched by Load.
+}
+
+// Load picks up new or modified files and reports whether anything changed.
+// A file that fails to load is skipped and retried on the next call, the
+// previously loaded version of that list (if any) stays active.
+func (j *WatchlistLoader) Load() (changed bool, err error) {
+	files, err := os.ReadDir(j.dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range files {
+		ext := strings.ToLower(filepath.Ext(f.Name()))
+		if f.IsDir() || (ext != ".csv" && ext != ".xml") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return changed, err
+		}
+		path := filepath.Join(j.dir, f.Name())
+		if mt, ok := j.modTimes[path]; ok && mt.Equal(info.ModTime()) {
+			continue
+		}
+
+		l, err := screening.LoadFile(path)
+		if err != nil {
+			log.Println("failed to load watchlist", err)
+			continue
+		}
+		j.screener.Load(l)
+		j.modTimes[path] = info.ModTime()
+		changed = true
+		log.Printf("loaded watchlist %s with %d entries", l.Name, len(l.Entries))
+	}
+
+	return changed, nil
+}
+
+// Run loads lists until ctx is done. Every change triggers a re-screen,
+// including the initial load, as lists could have changed while we were down.
+func (j *WatchlistLoader) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	rescreenPending := j.screener.Loaded() // Lists loaded before Run.
+	for {
+		changed, err := j.Load()
+		if err != nil {
+			log.Println("failed to scan watchlist dir", err)
+		}
+		if changed || rescreenPending {
+			rescreenPending = false
+			if err := j.rescreen.Run(ctx); err != nil {
+				log.Println("failed to rescreen customers, will retry", err)
+				rescreenPending = true
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
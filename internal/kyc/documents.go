@@ -0,0 +1,157 @@
+package kyc
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// MaxDocumentSize is the largest document accepted, a phone photo or a
+// scanned PDF fits easily.
+const MaxDocumentSize = 10 << 20
+
+var (
+	ErrUnsupportedContentType = errors.New("unsupported document type, upload a JPEG, PNG or PDF")
+	ErrMissingDocuments       = errors.New("missing KYC documents")
+)
+
+var documentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// SniffContentType detects the document type from its content, what the
+// client claims is ignored.
+func SniffContentType(data []byte) (string, error) {
+	ct := http.DetectContentType(data)
+	if !documentContentTypes[ct] {
+		return "", fmt.Errorf("%w, got %s", ErrUnsupportedContentType, ct)
+	}
+	return ct, nil
+}
+
+type Document struct {
+	ID          uuid.UUID                `json:"id" db:"id"`
+	CustomerID  uuid.UUID                `json:"-" db:"customer_id"`
+	Kind        domain.KYCDocumentKind   `json:"kind" db:"kind"`
+	FileName    string                   `json:"file_name" db:"file_name"`
+	ContentType string                   `json:"content_type" db:"content_type"`
+	Size        int64                    `json:"size" db:"size"`
+	SHA256      []byte                   `json:"sha256" db:"sha256"`
+	BlobKey     string                   `json:"-" db:"blob_key"`
+	Status      domain.KYCDocumentStatus `json:"status" db:"status"`
+	ReviewedAt  *time.Time               `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt   time.Time                `json:"created_at" db:"created_at"`
+}
+
+// NewDocument describes an (already sniffed and size checked) file the
+// customer uploaded, waiting for review.
+func NewDocument(customerID uuid.UUID, kind domain.KYCDocumentKind, fileName, contentType string, data []byte) Document {
+	id := uuid.New()
+	sum := sha256.Sum256(data)
+	return Document{
+		ID:          id,
+		CustomerID:  customerID,
+		Kind:        kind,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      sum[:],
+		BlobKey:     "kyc/" + customerID.String() + "/" + id.String(),
+		Status:      domain.KYCDocumentStatusUploaded,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// SaveFile stores the document's file in the blob store. Save it before the
+// document: if storing the document fails, an unreferenced blob is left
+// behind rather than a document without a file.
+func SaveFile(ctx context.Context, store blob.BlobStore, d Document, data []byte) error {
+	if err := store.Put(ctx, d.BlobKey, data); err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+	return nil
+}
+
+// InsertDocument stores the document's metadata, see SaveFile.
+func InsertDocument(ctx context.Context, db DB, d Document) error {
+	sql := `
+		INSERT INTO kyc_documents (id, customer_id, kind, file_name, content_type, size, sha256, blob_key, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := db.Exec(ctx, sql,
+		d.ID,
+		d.CustomerID,
+		d.Kind,
+		d.FileName,
+		d.ContentType,
+		d.Size,
+		d.SHA256,
+		d.BlobKey,
+		d.Status,
+		d.CreatedAt,
+	)
+	return err
+}
+
+// Documents returns the customer's documents, newest first.
+func Documents(ctx context.Context, db DB, customerID uuid.UUID) ([]Document, error) {
+	sql := `
+		SELECT id, customer_id, kind, file_name, content_type, size, sha256, blob_key, status, reviewed_at, created_at
+		FROM kyc_documents WHERE customer_id = $1 ORDER BY created_at DESC`
+
+	rows, _ := db.Query(ctx, sql, customerID)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Document])
+}
+
+// MissingDocuments returns what the customer still has to upload before a
+// review can approve them: an identity document and a proof of address.
+// Documents rejected in an earlier review don't count.
+func MissingDocuments(ctx context.Context, db DB, customerID uuid.UUID) ([]string, error) {
+	docs, err := Documents(ctx, db, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return Missing(docs), nil
+}
+
+// Missing is MissingDocuments of the customer's documents.
+func Missing(docs []Document) []string {
+	var identity, address bool
+	for _, d := range docs {
+		if d.Status == domain.KYCDocumentStatusRejected {
+			continue
+		}
+		identity = identity || d.Kind.IsIdentity()
+		address = address || d.Kind == domain.KYCDocumentKindProofOfAddress
+	}
+
+	missing := []string{}
+	if !identity {
+		missing = append(missing, "identity document")
+	}
+	if !address {
+		missing = append(missing, "proof of address")
+	}
+	return missing
+}
+
+// dbReviewDocuments applies the KYC decision to the documents it was based on.
+func dbReviewDocuments(ctx context.Context, db DB, customerID uuid.UUID, status domain.KYCDocumentStatus) error {
+	sql := `
+		UPDATE kyc_documents SET status = $1, reviewed_at = now(), updated_at = now()
+		WHERE customer_id = $2 AND status = $3`
+
+	_, err := db.Exec(ctx, sql, status, customerID, domain.KYCDocumentStatusUploaded)
+	return err
+}
@@ -0,0 +1,77 @@
+package kyc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func TestSniffContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"pdf", []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n1 0 obj"), "application/pdf"},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png"},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), "image/jpeg"},
+		{"html claiming to be a pdf", []byte("<html><script>alert(1)</script>"), ""},
+		{"zip", []byte("PK\x03\x04\x14\x00"), ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			got, err := SniffContentType(tt.data)
+
+			// Assert.
+			if tt.want == "" {
+				if !errors.Is(err, ErrUnsupportedContentType) {
+					t.Fatalf("expected ErrUnsupportedContentType, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expected %s, got %q, %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestMissing(t *testing.T) {
+	doc := func(kind domain.KYCDocumentKind, status domain.KYCDocumentStatus) Document {
+		return Document{Kind: kind, Status: status}
+	}
+
+	tests := []struct {
+		name string
+		docs []Document
+		want []string
+	}{
+		{"none", nil, []string{"identity document", "proof of address"}},
+		{"identity only", []Document{doc(domain.KYCDocumentKindIDCard, domain.KYCDocumentStatusUploaded)}, []string{"proof of address"}},
+		{"rejected don't count", []Document{
+			doc(domain.KYCDocumentKindPassport, domain.KYCDocumentStatusRejected),
+			doc(domain.KYCDocumentKindProofOfAddress, domain.KYCDocumentStatusAccepted),
+		}, []string{"identity document"}},
+		{"complete", []Document{
+			doc(domain.KYCDocumentKindDrivingLicence, domain.KYCDocumentStatusUploaded),
+			doc(domain.KYCDocumentKindProofOfAddress, domain.KYCDocumentStatusUploaded),
+		}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			got := Missing(tt.docs)
+
+			// Assert.
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package kyc moves customers through the KYC state machine (see
+// domain.KYCStatus) and keeps the timeline of every move.
+package kyc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/outbox"
+)
+
+var (
+	ErrIllegalTransition = errors.New("illegal KYC status transition")
+	ErrStatusChanged     = errors.New("KYC status changed in the meantime")
+	ErrTierChanged       = errors.New("KYC tier changed in the meantime, or the customer isn't approved")
+)
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx. Transition writes more
+// than one row, pass a transaction.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Event is a step on the customer's KYC timeline.
+type Event struct {
+	From       *domain.KYCStatus `json:"from"` // Nil when the customer was created.
+	To         domain.KYCStatus  `json:"to"`
+	Reason     string            `json:"reason"`
+	Actor      audit.Actor       `json:"actor"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// Created starts the timeline of a new customer.
+func Created(ctx context.Context, db DB, customerID uuid.UUID, actor audit.Actor) error {
+	return dbInsertEvent(ctx, db, customerID, nil, domain.KYCStatusPending, "customer created", actor)
+}
+
+// Transition moves the customer from one status to another, if the state
+// machine allows it and the customer is still in the from status. It records
+// the step on the timeline and writes the kyc_status_changed event.
+//
+// Approving requires the documents (see MissingDocuments), and the decision
+// is applied to the documents waiting for review.
+func Transition(ctx context.Context, db DB, customerID uuid.UUID, from, to domain.KYCStatus, reason string, actor audit.Actor) error {
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, to)
+	}
+	if to == domain.KYCStatusApproved {
+		missing, err := MissingDocuments(ctx, db, customerID)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: %s", ErrMissingDocuments, strings.Join(missing, ", "))
+		}
+	}
+
+	sql := `
+		UPDATE customers SET kyc_status = $1, updated_at = now()
+		WHERE id = $2 AND kyc_status = $3`
+
+	res, err := db.Exec(ctx, sql, to, customerID, from)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return ErrStatusChanged
+	}
+
+	if err := dbInsertEvent(ctx, db, customerID, &from, to, reason, actor); err != nil {
+		return err
+	}
+
+	switch to {
+	case domain.KYCStatusApproved:
+		err = dbReviewDocuments(ctx, db, customerID, domain.KYCDocumentStatusAccepted)
+	case domain.KYCStatusRejected:
+		err = dbReviewDocuments(ctx, db, customerID, domain.KYCDocumentStatusRejected)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = outbox.Write(ctx, db, domain.EventTypeKYCStatusChanged, customerID, domain.KYCStatusChangedPayload{
+		CustomerID: customerID,
+		OldStatus:  from,
+		NewStatus:  to,
+		Reason:     reason,
+	})
+	return err
+}
+
+// ChangeTier moves an approved customer from one KYC tier to another, if
+// they're still in the from tier. Their limits and fees follow the new tier
+// from their next movement on.
+func ChangeTier(ctx context.Context, db DB, customerID uuid.UUID, from, to domain.KYCTier) error {
+	if !to.Valid() {
+		return fmt.Errorf("unknown KYC tier %q", to)
+	}
+
+	sql := `
+		UPDATE customers SET kyc_tier = $1, updated_at = now()
+		WHERE id = $2 AND kyc_tier = $3 AND kyc_status = $4`
+
+	res, err := db.Exec(ctx, sql, to, customerID, from, domain.KYCStatusApproved)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() != 1 {
+		return ErrTierChanged
+	}
+	return nil
+}
+
+// Timeline returns the customer's KYC steps, oldest first.
+func Timeline(ctx context.Context, db DB, customerID uuid.UUID) ([]Event, error) {
+	sql := `
+		SELECT from_status, to_status, reason, actor_type, actor_id, created_at
+		FROM customer_kyc_events WHERE customer_id = $1 ORDER BY id`
+
+	rows, _ := db.Query(ctx, sql, customerID)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Event, error) {
+		var e Event
+		err := row.Scan(&e.From, &e.To, &e.Reason, &e.Actor.Type, &e.Actor.ID, &e.OccurredAt)
+		return e, err
+	})
+}
+
+func dbInsertEvent(ctx context.Context, db DB, customerID uuid.UUID, from *domain.KYCStatus, to domain.KYCStatus, reason string, actor audit.Actor) error {
+	sql := `
+		INSERT INTO customer_kyc_events (customer_id, from_status, to_status, reason, actor_type, actor_id)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := db.Exec(ctx, sql, customerID, from, to, reason, actor.Type, actor.ID)
+	return err
+}
@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// ChargeFee adds fee to the payer's leg of t and a leg crediting it to the fee
+// revenue account, so the fee is posted in the same transaction as the
+// movement itself.
+func ChargeFee(ctx context.Context, tx pgx.Tx, t *Transaction, payer uuid.UUID, fee int64) error {
+	if fee == 0 {
+		return nil
+	}
+
+	i := -1
+	for j, l := range t.Legs {
+		if l.AccountID == payer {
+			i = j
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("payer %s has no leg", payer)
+	}
+
+	revenueID, err := InternalAccountID(ctx, tx, domain.InternalAccountFeeRevenue, t.Currency)
+	if err != nil {
+		return err
+	}
+
+	t.Fee = fee
+	t.Legs[i].Amount -= fee
+	t.Legs = append(t.Legs, Leg{AccountID: revenueID, Amount: fee})
+	return nil
+}
@@ -0,0 +1,35 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// InternalAccountNumber is the account number of an internal account. It's not
+// a valid customer account number, so clients can't address it.
+func InternalAccountNumber(name domain.InternalAccount, currency string) string {
+	return "internal:" + string(name) + ":" + currency
+}
+
+// InternalAccountID returns the ID of an internal account, creating it on
+// first use.
+func InternalAccountID(ctx context.Context, tx pgx.Tx, name domain.InternalAccount, currency string) (uuid.UUID, error) {
+	number := InternalAccountNumber(name, currency)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO accounts (id, customer_id, number, balance, kind, currency)
+		VALUES ($1, NULL, $2, 0, $3, $4)
+		ON CONFLICT (number) DO NOTHING`,
+		uuid.New(), number, domain.AccountKindInternal, currency,
+	); err != nil {
+		return uuid.Nil, err
+	}
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT id FROM accounts WHERE number = $1`, number).Scan(&id)
+	return id, err
+}
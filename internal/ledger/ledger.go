@@ -0,0 +1,417 @@
+// Package ledger records money movements with double bookkeeping.
+//
+// A transaction is a set of legs (entries) that sum up to zero. It's created
+// "pending", which reserves (holds) the debited funds, and later "cleared",
+// which posts the entries to account balances, or "failed", which releases the
+// holds. All functions take the caller's pgx.Tx, so ledger changes commit (or
+// roll back) together with whatever else the caller does.
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/outbox"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrAccountNotFound   = errors.New("account not found")
+	ErrAccountFrozen     = errors.New("account is frozen")
+	ErrCurrencyMismatch  = errors.New("currency mismatch")
+	ErrUnbalanced        = errors.New("legs don't sum up to zero")
+	ErrNotPending        = errors.New("transaction is not pending")
+
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNotReversible       = errors.New("transaction can't be reversed")
+	ErrReversalTooLarge    = errors.New("reversal exceeds the amount left to reverse")
+)
+
+// Leg moves Amount in (positive, credit) or out (negative, debit) of an account.
+type Leg struct {
+	AccountID uuid.UUID
+	Amount    int64
+}
+
+type Transaction struct {
+	ID          uuid.UUID
+	Kind        domain.TransactionKind
+	Status      domain.TransactionStatus
+	Amount      int64 // The headline amount e.g. what was transferred.
+	Fee         int64 // Charged on top of Amount, one of the legs credits it to the fee revenue account.
+	Currency    string
+	Description string
+	// IdempotencyKey is optional. Initiating a transaction with a key that was
+	// already used returns the existing transaction instead.
+	IdempotencyKey string
+	// ReversalOf links a reversal (or partial refund) to the transaction it
+	// reverses, see Reverse.
+	ReversalOf *uuid.UUID
+	Legs       []Leg
+}
+
+// Initiate validates and stores a pending transaction, reserving the funds of
+// every debited customer account. Frozen accounts can't be debited (but can
+// be credited). It returns the stored transaction and
+// whether it was created (false if the idempotency key was already used).
+//
+// Checking available balances and reserving funds is serialized per account
+// by locking the account rows until the caller's transaction ends.
+func Initiate(ctx context.Context, tx pgx.Tx, t Transaction) (Transaction, bool, error) {
+	if err := Validate(t); err != nil {
+		return Transaction{}, false, err
+	}
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	t.Status = domain.TransactionStatusPending
+
+	if t.IdempotencyKey != "" {
+		existing, found, err := dbGetTransactionByIdempotencyKey(ctx, tx, t.IdempotencyKey)
+		if err != nil || found {
+			return existing, false, err
+		}
+	}
+
+	accounts, err := lockAccounts(ctx, tx, t.Legs)
+	if err != nil {
+		return Transaction{}, false, err
+	}
+	for _, l := range t.Legs {
+		a := accounts[l.AccountID]
+		if a.Currency != t.Currency {
+			return Transaction{}, false, fmt.Errorf("%w: account %s is in %s", ErrCurrencyMismatch, l.AccountID, a.Currency)
+		}
+		if l.Amount < 0 && a.Status == domain.AccountStatusFrozen {
+			return Transaction{}, false, fmt.Errorf("%w: %s", ErrAccountFrozen, a.Number)
+		}
+		if l.Amount < 0 && a.Kind == domain.AccountKindCustomer && a.Available+l.Amount < 0 {
+			return Transaction{}, false, fmt.Errorf("%w on account %s", ErrInsufficientFunds, a.Number)
+		}
+	}
+
+	created, err := dbInsertTransaction(ctx, tx, t)
+	if err != nil {
+		return Transaction{}, false, err
+	}
+	if !created { // Lost a race with a concurrent request using the same key.
+		existing, _, err := dbGetTransactionByIdempotencyKey(ctx, tx, t.IdempotencyKey)
+		return existing, false, err
+	}
+
+	for _, l := range t.Legs {
+		if err := dbInsertEntry(ctx, tx, t.ID, l); err != nil {
+			return Transaction{}, false, err
+		}
+		if l.Amount < 0 && accounts[l.AccountID].Kind == domain.AccountKindCustomer {
+			if err := dbInsertHold(ctx, tx, t.ID, l.AccountID, -l.Amount); err != nil {
+				return Transaction{}, false, err
+			}
+		}
+	}
+
+	if _, err := outbox.Write(ctx, tx, domain.EventTypeTransactionInitiated, t.ID, domain.TransactionInitiatedPayload{
+		TransactionID: t.ID,
+		ReversalOf:    t.ReversalOf,
+	}); err != nil {
+		return Transaction{}, false, err
+	}
+
+	return t, true, nil
+}
+
+// FindByIdempotencyKey returns the transaction initiated with an idempotency
+// key, if any. Callers that check more than Initiate does (e.g. limits) use it
+// to answer retries before checking again.
+func FindByIdempotencyKey(ctx context.Context, tx pgx.Tx, key string) (Transaction, bool, error) {
+	return dbGetTransactionByIdempotencyKey(ctx, tx, key)
+}
+
+// Clear posts a pending transaction to account balances and releases its
+// holds. Returns ErrNotPending if it's already cleared or failed.
+func Clear(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	t, err := dbLockTransaction(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status != domain.TransactionStatusPending {
+		return fmt.Errorf("%w: %s is %s", ErrNotPending, id, t.Status)
+	}
+
+	accounts, err := lockAccounts(ctx, tx, t.Legs)
+	if err != nil {
+		return err
+	}
+
+	posted := make([]domain.PostedEntry, 0, len(t.Legs))
+	for _, l := range t.Legs {
+		a := accounts[l.AccountID]
+		// Holds guarantee this, if it fails something is very wrong.
+		if a.Kind == domain.AccountKindCustomer && a.Balance+l.Amount < 0 {
+			return fmt.Errorf("clearing %s would make account %s negative", id, a.Number)
+		}
+		if err := dbAddToBalance(ctx, tx, l.AccountID, l.Amount); err != nil {
+			return err
+		}
+		a.Balance += l.Amount
+		accounts[l.AccountID] = a
+
+		posted = append(posted, domain.PostedEntry{
+			AccountID:     l.AccountID,
+			AccountNumber: a.Number,
+			CustomerID:    a.CustomerID,
+			Amount:        l.Amount,
+		})
+	}
+
+	if err := dbReleaseHolds(ctx, tx, id); err != nil {
+		return err
+	}
+	if err := dbUpdateTransactionStatus(ctx, tx, id, domain.TransactionStatusCleared, ""); err != nil {
+		return err
+	}
+
+	_, err = outbox.Write(ctx, tx, domain.EventTypeTransactionPosted, id, domain.TransactionPostedPayload{
+		TransactionID: id,
+		Kind:          t.Kind,
+		Amount:        t.Amount,
+		Currency:      t.Currency,
+		ReversalOf:    t.ReversalOf,
+		Entries:       posted,
+	})
+	return err
+}
+
+// Fail marks a pending transaction as failed and releases its holds.
+// Returns ErrNotPending if it's already cleared or failed.
+func Fail(ctx context.Context, tx pgx.Tx, id uuid.UUID, reason string) error {
+	t, err := dbLockTransaction(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status != domain.TransactionStatusPending {
+		return fmt.Errorf("%w: %s is %s", ErrNotPending, id, t.Status)
+	}
+
+	if err := dbReleaseHolds(ctx, tx, id); err != nil {
+		return err
+	}
+	return dbUpdateTransactionStatus(ctx, tx, id, domain.TransactionStatusFailed, reason)
+}
+
+// Post initiates and immediately clears a transaction, for movements that
+// don't need to go through KYT (e.g. initiated by the wallet itself).
+func Post(ctx context.Context, tx pgx.Tx, t Transaction) (Transaction, bool, error) {
+	t, created, err := Initiate(ctx, tx, t)
+	if err != nil || !created {
+		return t, created, err
+	}
+	if err := Clear(ctx, tx, t.ID); err != nil {
+		return Transaction{}, false, err
+	}
+	t.Status = domain.TransactionStatusCleared
+	return t, true, nil
+}
+
+// Validate checks the amounts and legs of a transaction, Initiate does it
+// before touching the DB.
+func Validate(t Transaction) error {
+	if t.Amount <= 0 || t.Amount > domain.MaxAmount {
+		return fmt.Errorf("invalid amount %d", t.Amount)
+	}
+	if t.Fee < 0 || t.Fee > domain.MaxAmount {
+		return fmt.Errorf("invalid fee %d", t.Fee)
+	}
+	if len(t.Legs) < 2 {
+		return errors.New("a transaction needs at least 2 legs")
+	}
+
+	var sum int64
+	seen := make(map[uuid.UUID]bool, len(t.Legs))
+	for _, l := range t.Legs {
+		if l.Amount == 0 || l.Amount > domain.MaxAmount || l.Amount < -domain.MaxAmount {
+			return fmt.Errorf("invalid leg amount %d", l.Amount)
+		}
+		if seen[l.AccountID] {
+			return fmt.Errorf("account %s appears in more than one leg", l.AccountID)
+		}
+		seen[l.AccountID] = true
+		sum += l.Amount
+	}
+	if sum != 0 {
+		return ErrUnbalanced
+	}
+
+	return nil
+}
+
+type lockedAccount struct {
+	Number     string
+	CustomerID *uuid.UUID
+	Kind       domain.AccountKind
+	Status     domain.AccountStatus
+	Currency   string
+	Balance    int64 // Cleared.
+	Available  int64 // Cleared minus active holds.
+}
+
+// lockAccounts locks the accounts of all legs, always in the same order to
+// avoid deadlocks between concurrent transactions touching the same accounts.
+func lockAccounts(ctx context.Context, tx pgx.Tx, legs []Leg) (map[uuid.UUID]lockedAccount, error) {
+	ids := make([]uuid.UUID, 0, len(legs))
+	for _, l := range legs {
+		ids = append(ids, l.AccountID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+
+	res := make(map[uuid.UUID]lockedAccount, len(ids))
+	for _, id := range ids {
+		var a lockedAccount
+		err := tx.QueryRow(ctx, `
+			SELECT number, customer_id, kind, status, currency, balance FROM accounts
+			WHERE id = $1 FOR UPDATE`, id,
+		).Scan(&a.Number, &a.CustomerID, &a.Kind, &a.Status, &a.Currency, &a.Balance)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var held int64
+		if err := tx.QueryRow(ctx, `
+			SELECT COALESCE(SUM(amount), 0) FROM holds
+			WHERE account_id = $1 AND released_at IS NULL`, id,
+		).Scan(&held); err != nil {
+			return nil, err
+		}
+		a.Available = a.Balance - held
+
+		res[id] = a
+	}
+
+	return res, nil
+}
+
+func dbInsertTransaction(ctx context.Context, tx pgx.Tx, t Transaction) (created bool, err error) {
+	sql := `
+		INSERT INTO transactions (id, kind, status, amount, fee, currency, description, idempotency_key, reversal_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+		ON CONFLICT (idempotency_key) DO NOTHING`
+
+	res, err := tx.Exec(ctx, sql,
+		t.ID,
+		t.Kind,
+		t.Status,
+		t.Amount,
+		t.Fee,
+		t.Currency,
+		t.Description,
+		t.IdempotencyKey,
+		t.ReversalOf,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() == 1, nil
+}
+
+func dbInsertEntry(ctx context.Context, tx pgx.Tx, transactionID uuid.UUID, l Leg) error {
+	sql := `INSERT INTO entries (transaction_id, account_id, amount) VALUES ($1, $2, $3)`
+
+	_, err := tx.Exec(ctx, sql, transactionID, l.AccountID, l.Amount)
+	return err
+}
+
+func dbInsertHold(ctx context.Context, tx pgx.Tx, transactionID, accountID uuid.UUID, amount int64) error {
+	sql := `INSERT INTO holds (transaction_id, account_id, amount) VALUES ($1, $2, $3)`
+
+	_, err := tx.Exec(ctx, sql, transactionID, accountID, amount)
+	return err
+}
+
+func dbReleaseHolds(ctx context.Context, tx pgx.Tx, transactionID uuid.UUID) error {
+	sql := `UPDATE holds SET released_at = now() WHERE transaction_id = $1 AND released_at IS NULL`
+
+	_, err := tx.Exec(ctx, sql, transactionID)
+	return err
+}
+
+func dbAddToBalance(ctx context.Context, tx pgx.Tx, accountID uuid.UUID, amount int64) error {
+	sql := `UPDATE accounts SET balance = balance + $1, updated_at = now() WHERE id = $2`
+
+	_, err := tx.Exec(ctx, sql, amount, accountID)
+	return err
+}
+
+func dbUpdateTransactionStatus(ctx context.Context, tx pgx.Tx, id uuid.UUID, status domain.TransactionStatus, failureReason string) error {
+	var clearedAt *time.Time
+	if status == domain.TransactionStatusCleared {
+		now := time.Now()
+		clearedAt = &now
+	}
+
+	sql := `
+		UPDATE transactions SET status = $1, failure_reason = NULLIF($2, ''), cleared_at = $3, updated_at = now()
+		WHERE id = $4`
+
+	_, err := tx.Exec(ctx, sql, status, failureReason, clearedAt, id)
+	return err
+}
+
+func dbLockTransaction(ctx context.Context, tx pgx.Tx, id uuid.UUID) (Transaction, error) {
+	t := Transaction{ID: id}
+	var idempotencyKey *string
+	err := tx.QueryRow(ctx, `
+		SELECT kind, status, amount, fee, currency, description, idempotency_key, reversal_of FROM transactions
+		WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&t.Kind, &t.Status, &t.Amount, &t.Fee, &t.Currency, &t.Description, &idempotencyKey, &t.ReversalOf)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTransactionNotFound, id)
+	}
+	if err != nil {
+		return Transaction{}, err
+	}
+	if idempotencyKey != nil {
+		t.IdempotencyKey = *idempotencyKey
+	}
+
+	t.Legs, err = dbGetLegs(ctx, tx, id)
+	return t, err
+}
+
+func dbGetTransactionByIdempotencyKey(ctx context.Context, tx pgx.Tx, key string) (Transaction, bool, error) {
+	t := Transaction{IdempotencyKey: key}
+	err := tx.QueryRow(ctx, `
+		SELECT id, kind, status, amount, fee, currency, description, reversal_of FROM transactions
+		WHERE idempotency_key = $1`, key,
+	).Scan(&t.ID, &t.Kind, &t.Status, &t.Amount, &t.Fee, &t.Currency, &t.Description, &t.ReversalOf)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Transaction{}, false, nil
+	}
+	if err != nil {
+		return Transaction{}, false, err
+	}
+
+	t.Legs, err = dbGetLegs(ctx, tx, t.ID)
+	return t, true, err
+}
+
+func dbGetLegs(ctx context.Context, tx pgx.Tx, transactionID uuid.UUID) ([]Leg, error) {
+	sql := `SELECT account_id, amount FROM entries WHERE transaction_id = $1 ORDER BY id`
+
+	rows, _ := tx.Query(ctx, sql, transactionID)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Leg, error) {
+		var l Leg
+		err := row.Scan(&l.AccountID, &l.Amount)
+		return l, err
+	})
+}
@@ -0,0 +1,87 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidate_OK(t *testing.T) {
+	// Arrange.
+	tr := Transaction{
+		Amount: 100,
+		Legs: []Leg{
+			{AccountID: uuid.New(), Amount: -100},
+			{AccountID: uuid.New(), Amount: 90},
+			{AccountID: uuid.New(), Amount: 10},
+		},
+	}
+
+	// Act.
+	err := Validate(tr)
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestValidate_Unbalanced(t *testing.T) {
+	// Arrange.
+	tr := Transaction{
+		Amount: 100,
+		Legs: []Leg{
+			{AccountID: uuid.New(), Amount: -100},
+			{AccountID: uuid.New(), Amount: 99},
+		},
+	}
+
+	// Act.
+	err := Validate(tr)
+
+	// Assert.
+	if !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("expected %s, got %v", ErrUnbalanced, err)
+	}
+}
+
+func TestValidate_SingleLeg(t *testing.T) {
+	// Act.
+	err := Validate(Transaction{Amount: 100, Legs: []Leg{{AccountID: uuid.New(), Amount: 100}}})
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
+
+func TestValidate_SameAccountTwice(t *testing.T) {
+	// Arrange.
+	id := uuid.New()
+	tr := Transaction{
+		Amount: 100,
+		Legs:   []Leg{{AccountID: id, Amount: -100}, {AccountID: id, Amount: 100}},
+	}
+
+	// Act.
+	err := Validate(tr)
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
+
+func TestValidate_ZeroAmount(t *testing.T) {
+	// Act.
+	err := Validate(Transaction{
+		Amount: 0,
+		Legs:   []Leg{{AccountID: uuid.New(), Amount: -1}, {AccountID: uuid.New(), Amount: 1}},
+	})
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
@@ -0,0 +1,137 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// Reversal describes a full or partial reversal (refund) of a cleared
+// transaction.
+type Reversal struct {
+	TransactionID  uuid.UUID // The transaction being reversed.
+	Amount         int64     // Zero reverses whatever is left to reverse.
+	Description    string
+	IdempotencyKey string // Optional, see Transaction.IdempotencyKey.
+}
+
+// Reverse initiates a transaction with the entries of the original one
+// mirrored (scaled down for partial reversals) and linked to it. A transaction
+// can be reversed in several parts, but never by more than its amount in
+// total. Reversals themselves can't be reversed, and neither can manual
+// adjustments (operators correct them with another adjustment).
+//
+// The reversal is a regular pending transaction: it reserves the funds of the
+// debited customer accounts and needs to be cleared.
+func Reverse(ctx context.Context, tx pgx.Tx, r Reversal) (Transaction, bool, error) {
+	if r.IdempotencyKey != "" {
+		existing, found, err := dbGetTransactionByIdempotencyKey(ctx, tx, r.IdempotencyKey)
+		if err != nil || found {
+			return existing, false, err
+		}
+	}
+
+	// Locking the original serializes concurrent reversals of it.
+	original, err := dbLockTransaction(ctx, tx, r.TransactionID)
+	if err != nil {
+		return Transaction{}, false, err
+	}
+	if original.Status != domain.TransactionStatusCleared {
+		return Transaction{}, false, fmt.Errorf("%w: %s is %s", ErrNotReversible, original.ID, original.Status)
+	}
+	if original.ReversalOf != nil {
+		return Transaction{}, false, fmt.Errorf("%w: %s is a reversal", ErrNotReversible, original.ID)
+	}
+	if original.Kind == domain.TransactionKindAdjustment {
+		return Transaction{}, false, fmt.Errorf("%w: %s is a manual adjustment", ErrNotReversible, original.ID)
+	}
+
+	reversed, err := dbGetReversedAmount(ctx, tx, original.ID)
+	if err != nil {
+		return Transaction{}, false, err
+	}
+	left := original.Amount - reversed
+	amount := r.Amount
+	if amount == 0 {
+		amount = left
+	}
+	if left <= 0 {
+		return Transaction{}, false, fmt.Errorf("%w: %s is fully reversed", ErrNotReversible, original.ID)
+	}
+	if amount < 0 || amount > left {
+		return Transaction{}, false, fmt.Errorf("%w: %s left", ErrReversalTooLarge, domain.FormatAmount(left))
+	}
+
+	description := r.Description
+	if description == "" {
+		description = "Reversal of " + original.ID.String()
+	}
+
+	return Initiate(ctx, tx, Transaction{
+		Kind:           domain.TransactionKindReversal,
+		Amount:         amount,
+		Currency:       original.Currency,
+		Description:    description,
+		IdempotencyKey: r.IdempotencyKey,
+		ReversalOf:     &original.ID,
+		Legs:           MirrorLegs(original.Legs, original.Amount, amount),
+	})
+}
+
+// ReversedAmount returns how much of a transaction was reversed so far,
+// counting reversals that are still pending.
+func ReversedAmount(ctx context.Context, tx pgx.Tx, id uuid.UUID) (int64, error) {
+	return dbGetReversedAmount(ctx, tx, id)
+}
+
+// MirrorLegs flips the sign of every leg and scales it by amount/total. Legs
+// are rounded down, and the rounding difference is put on the largest leg so
+// they still sum up to zero. Legs that round to zero are dropped.
+func MirrorLegs(legs []Leg, total, amount int64) []Leg {
+	res := make([]Leg, 0, len(legs))
+	var sum int64
+	largest := -1
+	for _, l := range legs {
+		// Big ints because amount*leg can overflow int64.
+		scaled := new(big.Int).Mul(big.NewInt(-l.Amount), big.NewInt(amount))
+		scaled.Quo(scaled, big.NewInt(total))
+
+		m := Leg{AccountID: l.AccountID, Amount: scaled.Int64()}
+		sum += m.Amount
+		res = append(res, m)
+		if largest < 0 || abs(m.Amount) > abs(res[largest].Amount) {
+			largest = len(res) - 1
+		}
+	}
+	if largest >= 0 {
+		res[largest].Amount -= sum
+	}
+
+	nonZero := res[:0]
+	for _, l := range res {
+		if l.Amount != 0 {
+			nonZero = append(nonZero, l)
+		}
+	}
+	return nonZero
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func dbGetReversedAmount(ctx context.Context, tx pgx.Tx, id uuid.UUID) (int64, error) {
+	sql := `SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE reversal_of = $1 AND status <> $2`
+
+	var res int64
+	err := tx.QueryRow(ctx, sql, id, domain.TransactionStatusFailed).Scan(&res)
+	return res, err
+}
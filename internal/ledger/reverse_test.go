@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMirrorLegs_Full(t *testing.T) {
+	// Arrange.
+	from, to := uuid.New(), uuid.New()
+	legs := []Leg{
+		{AccountID: from, Amount: -1000},
+		{AccountID: to, Amount: 1000},
+	}
+
+	// Act.
+	res := MirrorLegs(legs, 1000, 1000)
+
+	// Assert.
+	want := []Leg{
+		{AccountID: from, Amount: 1000},
+		{AccountID: to, Amount: -1000},
+	}
+	if len(res) != len(want) {
+		t.Fatalf("expected %v, got %v", want, res)
+	}
+	for i := range want {
+		if res[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, res)
+		}
+	}
+}
+
+func TestMirrorLegs_PartialStaysBalanced(t *testing.T) {
+	// Arrange.
+	legs := []Leg{
+		{AccountID: uuid.New(), Amount: -1003},
+		{AccountID: uuid.New(), Amount: 1000},
+		{AccountID: uuid.New(), Amount: 3}, // Fee.
+	}
+
+	// Act.
+	res := MirrorLegs(legs, 1000, 333)
+
+	// Assert.
+	tr := Transaction{Amount: 333, Legs: res}
+	if err := Validate(tr); err != nil {
+		t.Fatalf("mirrored legs are invalid: %s (%v)", err, res)
+	}
+	if res[1].Amount != -333 {
+		t.Fatalf("expected the credited account to give back 333, got %d", res[1].Amount)
+	}
+}
+
+func TestMirrorLegs_DropsZeroLegs(t *testing.T) {
+	// Arrange.
+	legs := []Leg{
+		{AccountID: uuid.New(), Amount: -1001},
+		{AccountID: uuid.New(), Amount: 1000},
+		{AccountID: uuid.New(), Amount: 1},
+	}
+
+	// Act.
+	res := MirrorLegs(legs, 1000, 10)
+
+	// Assert.
+	if len(res) != 2 {
+		t.Fatalf("expected 2 legs, got %v", res)
+	}
+	if res[0].Amount+res[1].Amount != 0 {
+		t.Fatalf("expected balanced legs, got %v", res)
+	}
+}
+
+func TestMirrorLegs_NoOverflow(t *testing.T) {
+	// Arrange.
+	const big = int64(9e16)
+	legs := []Leg{
+		{AccountID: uuid.New(), Amount: -big},
+		{AccountID: uuid.New(), Amount: big},
+	}
+
+	// Act.
+	res := MirrorLegs(legs, big, big/3)
+
+	// Assert.
+	if res[0].Amount != big/3 || res[1].Amount != -big/3 {
+		t.Fatalf("expected ±%d, got %v", big/3, res)
+	}
+}
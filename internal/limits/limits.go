@@ -0,0 +1,178 @@
+// Package limits enforces per-customer transaction limits. Limits depend on
+// the customer's KYC tier and are configured in the kyc_tier_limits table, a
+// kind of movement without a configured limit is unlimited.
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+type Limit struct {
+	Kind     domain.LimitKind   `db:"kind"`
+	Period   domain.LimitPeriod `db:"period"`
+	Currency string             `db:"currency"`
+	Amount   int64              `db:"amount"`
+}
+
+// Usage is how much of a limit is used in the current period.
+type Usage struct {
+	Limit
+	Used      int64
+	Remaining int64
+}
+
+// ExceededError is returned when a movement doesn't fit in a limit.
+type ExceededError struct {
+	Usage
+	Requested int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s %s limit exceeded: %s of %s %s remaining",
+		e.Period, e.Kind, domain.FormatAmount(e.Remaining), domain.FormatAmount(e.Amount), e.Currency)
+}
+
+// Check returns an *ExceededError if moving amount doesn't fit in the
+// customer's limits for kind.
+//
+// It locks the customer row, so concurrent movements of the same customer
+// are checked one after the other. Call it in the DB transaction that
+// initiates the movement, before initiating it.
+func Check(ctx context.Context, tx pgx.Tx, customerID uuid.UUID, kind domain.LimitKind, currency string, amount int64, now time.Time) error {
+	var tier domain.KYCTier
+	err := tx.QueryRow(ctx, `SELECT kyc_tier FROM customers WHERE id = $1 FOR UPDATE`, customerID).Scan(&tier)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrCustomerNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	limits, err := dbGetLimits(ctx, tx, tier)
+	if err != nil {
+		return err
+	}
+	for _, l := range limits {
+		if l.Kind != kind || l.Currency != currency {
+			continue
+		}
+		u, err := usage(ctx, tx, customerID, l, now)
+		if err != nil {
+			return err
+		}
+		if amount > u.Remaining {
+			return &ExceededError{Usage: u, Requested: amount}
+		}
+	}
+
+	return nil
+}
+
+// GetUsage returns the customer's KYC tier and the usage of all their
+// limits.
+func GetUsage(ctx context.Context, db DB, customerID uuid.UUID, now time.Time) (domain.KYCTier, []Usage, error) {
+	var tier domain.KYCTier
+	err := db.QueryRow(ctx, `SELECT kyc_tier FROM customers WHERE id = $1`, customerID).Scan(&tier)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	limits, err := dbGetLimits(ctx, db, tier)
+	if err != nil {
+		return "", nil, err
+	}
+	res := make([]Usage, 0, len(limits))
+	for _, l := range limits {
+		u, err := usage(ctx, db, customerID, l, now)
+		if err != nil {
+			return "", nil, err
+		}
+		res = append(res, u)
+	}
+
+	return tier, res, nil
+}
+
+// PeriodStart returns when the period containing now started.
+func PeriodStart(p domain.LimitPeriod, now time.Time) time.Time {
+	now = now.UTC()
+	switch p {
+	case domain.LimitPeriodMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func usage(ctx context.Context, db DB, customerID uuid.UUID, l Limit, now time.Time) (Usage, error) {
+	used, err := dbGetUsed(ctx, db, customerID, l.Kind, l.Currency, PeriodStart(l.Period, now))
+	if err != nil {
+		return Usage{}, err
+	}
+	return newUsage(l, used), nil
+}
+
+func newUsage(l Limit, used int64) Usage {
+	return Usage{
+		Limit:     l,
+		Used:      used,
+		Remaining: max(l.Amount-used, 0),
+	}
+}
+
+func dbGetLimits(ctx context.Context, db DB, tier domain.KYCTier) ([]Limit, error) {
+	sql := `
+		SELECT kind, period, currency, amount FROM kyc_tier_limits
+		WHERE tier = $1 ORDER BY kind, period, currency`
+
+	rows, _ := db.Query(ctx, sql, tier)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Limit])
+}
+
+// dbGetUsed sums up the customer's movements of a kind since a point in
+// time. Pending movements count, failed ones don't.
+func dbGetUsed(ctx context.Context, db DB, customerID uuid.UUID, kind domain.LimitKind, currency string, since time.Time) (int64, error) {
+	// Deposits credit the customer's accounts, the rest debits them.
+	txKind, sign := domain.TransactionKindDeposit, int64(1)
+	switch kind {
+	case domain.LimitKindWithdrawal:
+		txKind, sign = domain.TransactionKindWithdrawal, -1
+	case domain.LimitKindTransferOut:
+		txKind, sign = domain.TransactionKindTransfer, -1
+	}
+
+	// Fees don't count, only the amount of the movement itself.
+	sql := `
+		SELECT COALESCE(SUM(t.amount), 0)
+		FROM transactions t
+		WHERE t.kind = $1 AND t.currency = $2 AND t.status <> $3 AND t.created_at >= $4
+			AND EXISTS (
+				SELECT 1 FROM entries e JOIN accounts a ON a.id = e.account_id
+				WHERE e.transaction_id = t.id AND a.customer_id = $5 AND e.amount * $6 > 0
+			)`
+
+	var res int64
+	err := db.QueryRow(ctx, sql, txKind, currency, domain.TransactionStatusFailed, since, customerID, sign).Scan(&res)
+	return res, err
+}
@@ -0,0 +1,65 @@
+package limits
+
+import (
+	"testing"
+	"time"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2024, 3, 15, 23, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	testCases := []struct {
+		period domain.LimitPeriod
+		want   time.Time
+	}{
+		{domain.LimitPeriodDaily, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{domain.LimitPeriodMonthly, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.period), func(t *testing.T) {
+			if got := PeriodStart(tc.period, now); !got.Equal(tc.want) {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewUsage(t *testing.T) {
+	l := Limit{Amount: 1000}
+
+	testCases := []struct {
+		name          string
+		used          int64
+		wantRemaining int64
+	}{
+		{"unused", 0, 1000},
+		{"partially used", 400, 600},
+		{"fully used", 1000, 0},
+		{"over limit", 1200, 0}, // Limits lowered after the fact.
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := newUsage(l, tc.used).Remaining; got != tc.wantRemaining {
+				t.Fatalf("expected %d remaining, got %d", tc.wantRemaining, got)
+			}
+		})
+	}
+}
+
+func TestExceededError(t *testing.T) {
+	err := &ExceededError{
+		Usage: Usage{
+			Limit:     Limit{Kind: domain.LimitKindTransferOut, Period: domain.LimitPeriodDaily, Currency: "EUR", Amount: 100000},
+			Used:      90000,
+			Remaining: 10000,
+		},
+		Requested: 20000,
+	}
+
+	want := "daily transfer_out limit exceeded: 100.00 of 1000.00 EUR remaining"
+	if err.Error() != want {
+		t.Fatalf("expected %q, got %q", want, err.Error())
+	}
+}
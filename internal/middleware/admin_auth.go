@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+const (
+	AdminIDHeader   = "BestWallet-Admin-ID"
+	AdminRoleHeader = "BestWallet-Admin-Role"
+)
+
+// AdminUserFetcher finds the active back office user a bearer token was
+// issued to.
+type AdminUserFetcher func(ctx context.Context, token string) (user domain.AdminUser, found bool, err error)
+
+// AdminAuth authenticates back office users by their bearer token. The user
+// is set on the request headers (overwriting whatever the client sent), so
+// handlers read it like the customer ID of the public API.
+func AdminAuth(fetchUser AdminUserFetcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		user, found, err := fetchUser(c.Request.Context(), token)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if !found {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Request.Header.Set(AdminIDHeader, user.ID.String())
+		c.Request.Header.Set(AdminRoleHeader, string(user.Role))
+
+		c.Next()
+	}
+}
+
+// RequireAdminRole lets through users whose role includes the given one, it
+// goes after AdminAuth.
+func RequireAdminRole(role domain.AdminRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !domain.AdminRole(c.GetHeader(AdminRoleHeader)).Includes(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, "requires the "+string(role)+" role")
+			return
+		}
+
+		c.Next()
+	}
+}
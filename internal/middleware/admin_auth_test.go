@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func TestAdminAuth(t *testing.T) {
+	operator := domain.AdminUser{ID: uuid.New(), Email: "op@example.com", Role: domain.AdminRoleOperator}
+	fetchUser := func(ctx context.Context, token string) (domain.AdminUser, bool, error) {
+		if token == "operator-token" {
+			return operator, true, nil
+		}
+		return domain.AdminUser{}, false, nil
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		spoofedRole   string
+		required      domain.AdminRole
+		wantCode      int
+	}{
+		{"missing token", "", "", domain.AdminRoleViewer, http.StatusUnauthorized},
+		{"not a bearer token", "Basic operator-token", "", domain.AdminRoleViewer, http.StatusUnauthorized},
+		{"unknown token", "Bearer nope", "", domain.AdminRoleViewer, http.StatusUnauthorized},
+		{"role included", "Bearer operator-token", "", domain.AdminRoleViewer, http.StatusNoContent},
+		{"role matches", "Bearer operator-token", "", domain.AdminRoleOperator, http.StatusNoContent},
+		{"role not included", "Bearer operator-token", "", domain.AdminRoleAdmin, http.StatusForbidden},
+		{"spoofed role header", "Bearer operator-token", "admin", domain.AdminRoleAdmin, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			var gotID string
+			router := gin.New()
+			router.GET("/sut", AdminAuth(fetchUser), RequireAdminRole(tt.required), func(c *gin.Context) {
+				gotID = c.GetHeader(AdminIDHeader)
+				c.Status(http.StatusNoContent)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/sut", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.spoofedRole != "" {
+				req.Header.Set(AdminRoleHeader, tt.spoofedRole)
+			}
+
+			// Act.
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert.
+			if resp.Code != tt.wantCode {
+				t.Fatalf("expected response code %d, got %d", tt.wantCode, resp.Code)
+			}
+			if resp.Code == http.StatusNoContent && gotID != operator.ID.String() {
+				t.Fatalf("expected admin id %s, got %q", operator.ID, gotID)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const customerIDHeader = "BestWallet-Customer-ID"
+
+// CustomerOwnerFetcher finds the key ID of the client app that registered a
+// customer, empty for some old customers.
+type CustomerOwnerFetcher func(ctx context.Context, customerID uuid.UUID) (keyID string, found bool, err error)
+
+// RequireOwnCustomerHeader lets through requests on behalf of customers
+// (BestWallet-Customer-ID) that the signing client app registered, it goes
+// after HMACVerifier. Someone else's customers are as good as not found, so
+// the response is the one handlers give for an unknown customer.
+func RequireOwnCustomerHeader(fetchOwner CustomerOwnerFetcher) gin.HandlerFunc {
+	return requireOwnCustomer(fetchOwner, http.StatusBadRequest, func(c *gin.Context) string {
+		return c.GetHeader(customerIDHeader)
+	})
+}
+
+// RequireOwnCustomerParam is RequireOwnCustomerHeader for routes that name
+// the customer in a path parameter, e.g. /customers/:id/kyc.
+func RequireOwnCustomerParam(fetchOwner CustomerOwnerFetcher, param string) gin.HandlerFunc {
+	return requireOwnCustomer(fetchOwner, http.StatusNotFound, func(c *gin.Context) string {
+		return c.Param(param)
+	})
+}
+
+// requireOwnCustomer leaves missing, malformed and unknown customer IDs to
+// the handler. Customers without an owner predate client_key_id and are let
+// through to any client app.
+func requireOwnCustomer(fetchOwner CustomerOwnerFetcher, notFoundCode int, customerID func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(customerID(c))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		owner, found, err := fetchOwner(c.Request.Context(), id)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if found && owner != "" && owner != c.GetHeader(keyIDHeader) {
+			c.AbortWithStatusJSON(notFoundCode, "customer not found")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequireOwnCustomer(t *testing.T) {
+	own, others, legacy := uuid.New(), uuid.New(), uuid.New()
+	fetchOwner := func(ctx context.Context, customerID uuid.UUID) (string, bool, error) {
+		switch customerID {
+		case own:
+			return "app", true, nil
+		case others:
+			return "other-app", true, nil
+		case legacy:
+			return "", true, nil
+		}
+		return "", false, nil
+	}
+
+	tests := []struct {
+		name       string
+		customerID string
+		wantCode   int
+	}{
+		{"own customer", own.String(), http.StatusNoContent},
+		{"someone else's customer", others.String(), http.StatusBadRequest},
+		{"customer without an owner", legacy.String(), http.StatusNoContent},
+		{"unknown customer", uuid.NewString(), http.StatusNoContent},
+		{"malformed customer id", "nope", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			router := gin.New()
+			handle := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+			router.GET("/sut", RequireOwnCustomerHeader(fetchOwner), handle)
+			router.GET("/sut/:id", RequireOwnCustomerParam(fetchOwner, "id"), handle)
+
+			reqHeader := httptest.NewRequest(http.MethodGet, "/sut", nil)
+			reqHeader.Header.Set(customerIDHeader, tt.customerID)
+			reqParam := httptest.NewRequest(http.MethodGet, "/sut/"+tt.customerID, nil)
+			for _, req := range []*http.Request{reqHeader, reqParam} {
+				req.Header.Set(keyIDHeader, "app")
+			}
+
+			// Act.
+			respHeader := httptest.NewRecorder()
+			router.ServeHTTP(respHeader, reqHeader)
+			respParam := httptest.NewRecorder()
+			router.ServeHTTP(respParam, reqParam)
+
+			// Assert.
+			if respHeader.Code != tt.wantCode {
+				t.Fatalf("expected response code %d for the header, got %d", tt.wantCode, respHeader.Code)
+			}
+			wantParamCode := tt.wantCode
+			if wantParamCode == http.StatusBadRequest {
+				wantParamCode = http.StatusNotFound
+			}
+			if respParam.Code != wantParamCode {
+				t.Fatalf("expected response code %d for the path parameter, got %d", wantParamCode, respParam.Code)
+			}
+		})
+	}
+}
@@ -1,9 +1,8 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
-	"fmt"
 	"io"
 	"net/http"
 
@@ -62,14 +61,11 @@ func HMACVerifier(fetchKey HMACKeyFetcher) gin.HandlerFunc {
 			return
 		}
 
-		msg := []byte(fmt.Sprintf("%s%s", body, key))
-		sum := util.ComputeSHA256HMAC(msg, key)
-		wantSig := base64.StdEncoding.EncodeToString(sum)
-
-		if sig != wantSig {
+		if sig != util.ComputeSignature(body, key) {
 			c.AbortWithStatus(http.StatusUnauthorized) // TODO log reason.
 			return
 		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body)) // Put it back for the handler.
 
 		c.Next()
 	}
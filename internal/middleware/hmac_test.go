@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -49,6 +50,45 @@ func TestHMACVerifier_OK(t *testing.T) {
 	}
 }
 
+func TestHMACVerifier_BodyAvailableToHandler(t *testing.T) {
+	// Arrange: secret key and id.
+	keyID := "some-key-id"
+	key, err := util.NewKeyHMAC(64)
+	if err != nil {
+		t.Fatalf("error generating a key: %s", err)
+	}
+
+	// Arrange: the SUT (system under test).
+	sut := HMACVerifier(NewHMACKeyFetcherMock(map[string][]byte{keyID: key}))
+
+	// Arrange: mock gin router, the handler reads the body again.
+	var gotBody []byte
+	router := gin.New()
+	method, path := "POST", "/sut"
+	router.Handle(method, path, sut, func(c *gin.Context) {
+		gotBody, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusNoContent)
+	})
+
+	// Arrange: signed request.
+	body := []byte(`{"amount":"12.34"}`)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Add("BestWallet-Signature", util.ComputeSignature(body, key))
+	req.Header.Add("BestWallet-Key-ID", keyID)
+
+	// Act: handle request.
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	// Assert.
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("expected response code %d, got %d", http.StatusNoContent, resp.Code)
+	}
+	if !bytes.Equal(gotBody, body) {
+		t.Fatalf("expected the handler to read %q, got %q", body, gotBody)
+	}
+}
+
 func TestHMACVerifier_MissingSignatureHeader(t *testing.T) {
 	// Arrange: secret key and id.
 	keyID := "some-key-id"
@@ -214,6 +254,52 @@ func TestHMACVerifier_EmptyBody(t *testing.T) {
 	}
 }
 
+// FuzzHMACVerifier checks that whatever the headers and the body, a request
+// gets through only with a known key ID and the body's signature, and the
+// handler then reads the body that was signed.
+func FuzzHMACVerifier(f *testing.F) {
+	// Arrange: secret key and id.
+	keyID := "some-key-id"
+	key := []byte("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	sut := HMACVerifier(NewHMACKeyFetcherMock(map[string][]byte{keyID: key}))
+
+	f.Add(keyID, "", []byte("some-body"), true)
+	f.Add(keyID, util.ComputeSignature([]byte("some-body"), key), []byte("other-body"), false)
+	f.Add("", "", []byte{}, true)
+	f.Add("some-key-id\n", "not base64!", []byte(`{"amount":"12.34"}`), false)
+
+	f.Fuzz(func(t *testing.T, gotKeyID, signature string, body []byte, sign bool) {
+		// Arrange: the signature is right when sign is set.
+		if sign {
+			signature = util.ComputeSignature(body, key)
+		}
+		var gotBody []byte
+		router := gin.New()
+		router.Handle("POST", "/sut", sut, func(c *gin.Context) {
+			gotBody, _ = io.ReadAll(c.Request.Body)
+			c.Status(http.StatusNoContent)
+		})
+		req := httptest.NewRequest("POST", "/sut", bytes.NewReader(body))
+		req.Header.Set("BestWallet-Signature", signature)
+		req.Header.Set("BestWallet-Key-ID", gotKeyID)
+
+		// Act: handle request.
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		// Assert.
+		valid := gotKeyID == keyID && signature == util.ComputeSignature(body, key)
+		switch {
+		case valid && resp.Code != http.StatusNoContent:
+			t.Fatalf("expected response code %d, got %d", http.StatusNoContent, resp.Code)
+		case valid && !bytes.Equal(gotBody, body):
+			t.Fatalf("expected the handler to read %q, got %q", body, gotBody)
+		case !valid && resp.Code != http.StatusUnauthorized:
+			t.Fatalf("expected response code %d, got %d", http.StatusUnauthorized, resp.Code)
+		}
+	})
+}
+
 func NewHMACKeyFetcherMock(m map[string][]byte) HMACKeyFetcher {
 	return func(_ context.Context, keyID string) ([]byte, bool, error) {
 		if key, ok := m[keyID]; ok {
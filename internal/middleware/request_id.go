@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength keeps client provided IDs from bloating logs and the
+// audit log.
+const maxRequestIDLength = 128
+
+// RequestID makes sure every request has an ID that ties together logs,
+// audit records and the client's own logs. A client provided ID is kept,
+// otherwise a new one is generated. Either way it's set on the request (so
+// handlers read it like any other header) and echoed in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.New().String()
+		}
+
+		c.Request.Header.Set(RequestIDHeader, id)
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
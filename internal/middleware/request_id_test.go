@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		wantKept bool
+	}{
+		{name: "generated", clientID: ""},
+		{name: "client provided", clientID: "client-req-1", wantKept: true},
+		{name: "too long", clientID: strings.Repeat("x", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			var seen string
+			router := gin.New()
+			router.GET("/sut", RequestID(), func(c *gin.Context) {
+				seen = c.GetHeader("X-Request-ID")
+				c.Status(http.StatusNoContent)
+			})
+			req := httptest.NewRequest("GET", "/sut", nil)
+			if tt.clientID != "" {
+				req.Header.Set("X-Request-ID", tt.clientID)
+			}
+			w := httptest.NewRecorder()
+
+			// Act.
+			router.ServeHTTP(w, req)
+
+			// Assert.
+			got := w.Header().Get("X-Request-ID")
+			if got != seen {
+				t.Fatalf("expected handler and response to see the same ID, got %q and %q", seen, got)
+			}
+			if tt.wantKept {
+				if got != tt.clientID {
+					t.Fatalf("expected %q, got %q", tt.clientID, got)
+				}
+				return
+			}
+			if _, err := uuid.Parse(got); err != nil {
+				t.Fatalf("expected a generated UUID, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Package migrate applies the SQL migrations in db-migrations and tells
+// whether the database schema is the one the binary was built for.
+//
+// The version is kept in golang-migrate's schema_migrations table, so
+// databases migrated by the golang-migrate CLI carry on from where they are.
+// Unlike the CLI, each migration runs in a transaction together with the
+// version bump, so a failed migration never leaves the schema dirty.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/pglock"
+)
+
+var (
+	ErrSchemaBehind = errors.New("database schema is behind, run migrations")
+	ErrDirty        = errors.New("database schema is dirty, a migration failed half way and needs fixing by hand")
+)
+
+var fileNameRegexp = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads migrations named like 000001_create_x.up.sql and
+// 000001_create_x.down.sql from the root of fsys, sorted by version. Every
+// migration needs both files.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		match := fileNameRegexp.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("malformed migration file name %q", e.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("malformed migration version in %q", e.Name())
+		}
+		sql, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(sql)
+		} else {
+			m.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func New(db *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// Latest is the version the binary needs, 0 when there are no migrations.
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+type Status struct {
+	// Version applied to the database, 0 when nothing is.
+	Version uint64
+	Dirty   bool
+	// Latest version the binary knows of.
+	Latest  uint64
+	Pending []Migration
+}
+
+// Check fails unless the database schema is at least what the binary needs.
+// A newer schema is fine, it's what a rolling deploy or a rollback looks like.
+func (s Status) Check() error {
+	if s.Dirty {
+		return fmt.Errorf("%w: version %d", ErrDirty, s.Version)
+	}
+	if s.Version < s.Latest {
+		return fmt.Errorf("%w: at version %d, need %d", ErrSchemaBehind, s.Version, s.Latest)
+	}
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	version, dirty, err := m.version(ctx, m.db)
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  m.Latest(),
+		Pending: pending(m.migrations, version),
+	}, nil
+}
+
+// Up applies all pending migrations and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+
+		for _, mig := range pending(m.migrations, version) {
+			if err := m.apply(ctx, conn, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if mig.Version > version {
+				continue
+			}
+			var prev uint64
+			if i > 0 {
+				prev = m.migrations[i-1].Version
+			}
+			if err := m.apply(ctx, conn, mig.Down, prev); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// locked runs fn holding the migrate advisory lock, waiting for it if another
+// instance has it. Only one instance migrates at a time, the others then find
+// nothing to do.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	return pglock.With(ctx, m.db, pglock.KeyMigrate, func(conn *pgxpool.Conn) error {
+		if _, err := conn.Exec(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version BIGINT NOT NULL PRIMARY KEY,
+				dirty BOOLEAN NOT NULL
+			)
+		`); err != nil {
+			return err
+		}
+
+		return fn(conn)
+	})
+}
+
+// apply runs sql and sets the schema version in one transaction. Version 0
+// means no migrations are applied.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, sql string, version uint64) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version))
+		return err
+	})
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// version reads the applied schema version, 0 when there's none.
+func (m *Migrator) version(ctx context.Context, db querier) (uint64, bool, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(version), dirty, nil
+}
+
+// pending returns the migrations newer than version.
+func pending(migrations []Migration, version uint64) []Migration {
+	i := sort.Search(len(migrations), func(i int) bool { return migrations[i].Version > version })
+	return migrations[i:]
+}
@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	dbmigrations "github.com/detod/best-wallet/db-migrations"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []uint64
+		wantErr  bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"000010_b.up.sql":   file("up b"),
+				"000010_b.down.sql": file("down b"),
+				"000002_a.up.sql":   file("up a"),
+				"000002_a.down.sql": file("down a"),
+				"embed.go":          file("package dbmigrations"),
+			},
+			versions: []uint64{2, 10},
+		},
+		{
+			name:     "empty",
+			fsys:     fstest.MapFS{},
+			versions: []uint64{},
+		},
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{
+				"000001_a.up.sql": file("up a"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed name",
+			fsys: fstest.MapFS{
+				"create_a.up.sql":   file("up a"),
+				"create_a.down.sql": file("down a"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "version zero",
+			fsys: fstest.MapFS{
+				"000000_a.up.sql":   file("up a"),
+				"000000_a.down.sql": file("down a"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "names differ",
+			fsys: fstest.MapFS{
+				"000001_a.up.sql":   file("up a"),
+				"000001_b.down.sql": file("down b"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			migrations, err := Load(tt.fsys)
+
+			// Assert.
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migrations) != len(tt.versions) {
+				t.Fatalf("expected %d migrations, got %d", len(tt.versions), len(migrations))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.versions[i] {
+					t.Fatalf("expected version %d at %d, got %d", tt.versions[i], i, m.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	// Act.
+	migrations, err := Load(dbmigrations.FS)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != uint64(i+1) {
+			t.Fatalf("expected migration %d_%s to be version %d, versions must not have gaps", m.Version, m.Name, i+1)
+		}
+	}
+}
+
+func TestPending(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 5}}
+
+	tests := []struct {
+		version uint64
+		want    int
+	}{
+		{version: 0, want: 3},
+		{version: 1, want: 2},
+		{version: 3, want: 1},
+		{version: 5, want: 0},
+		{version: 9, want: 0},
+	}
+
+	for _, tt := range tests {
+		// Act.
+		got := pending(migrations, tt.version)
+
+		// Assert.
+		if len(got) != tt.want {
+			t.Fatalf("at version %d expected %d pending, got %d", tt.version, tt.want, len(got))
+		}
+	}
+}
+
+func TestStatus_Check(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+		want   error
+	}{
+		{name: "up to date", status: Status{Version: 21, Latest: 21}},
+		{name: "ahead", status: Status{Version: 22, Latest: 21}},
+		{name: "behind", status: Status{Version: 20, Latest: 21}, want: ErrSchemaBehind},
+		{name: "empty", status: Status{Latest: 21}, want: ErrSchemaBehind},
+		{name: "dirty", status: Status{Version: 21, Dirty: true, Latest: 21}, want: ErrDirty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act.
+			err := tt.status.Check()
+
+			// Assert.
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
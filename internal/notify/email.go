@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures the SMTP email channel.
+type SMTPConfig struct {
+	Addr     string // host:port
+	From     string
+	Username string // Optional, PLAIN auth is used if set.
+	Password string
+}
+
+func NewSMTPNotifier(conf SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{conf: conf}
+}
+
+// SMTPNotifier sends plain text emails through an SMTP relay.
+type SMTPNotifier struct {
+	conf SMTPConfig
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return &PermanentError{Err: fmt.Errorf("invalid recipient %q", msg.To)}
+	}
+
+	var auth smtp.Auth
+	if n.conf.Username != "" {
+		host := n.conf.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.conf.Username, n.conf.Password, host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.conf.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	// net/smtp doesn't take a context, so at least don't start when it's done.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(n.conf.Addr, auth, n.conf.From, []string{msg.To}, []byte(b.String()))
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+func NewLogNotifier(w io.Writer) *LogNotifier {
+	return &LogNotifier{w: w}
+}
+
+// LogNotifier writes messages to w (a file, stdout...) as JSON lines instead
+// of sending them. Meant for local development.
+type LogNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type logNotifierLine struct {
+	Time    time.Time `json:"time"`
+	Channel Channel   `json:"channel"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject,omitempty"`
+	Body    string    `json:"body"`
+}
+
+func (n *LogNotifier) Notify(_ context.Context, msg Message) error {
+	line, err := json.Marshal(logNotifierLine{
+		Time:    time.Now().UTC(),
+		Channel: msg.Channel,
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err = n.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+)
+
+// Message is a rendered notification, ready to be sent.
+type Message struct {
+	Channel Channel
+	To      string // Email address or phone number, depending on the channel.
+	Subject string // Ignored by channels that don't support it (SMS).
+	Body    string
+}
+
+// Notifier delivers a message over a single channel.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// PermanentError marks a delivery failure that won't go away by retrying
+// e.g. the gateway rejected the phone number.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return "permanent: " + e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// IsPermanent reports whether err (or any error it wraps) is permanent.
+func IsPermanent(err error) bool {
+	var perm *PermanentError
+	return errors.As(err, &perm)
+}
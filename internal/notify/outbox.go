@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+// DB is satisfied by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func NewOutbox(templates *Templates, cipher *pii.Cipher) *Outbox {
+	return &Outbox{templates: templates, cipher: cipher}
+}
+
+// Outbox renders notifications and persists them in the notification_outbox
+// table, so they survive restarts. Sending is done by a background dispatcher
+// which retries failures with backoff, and clears the recipient and the
+// rendered message once it's done with them.
+type Outbox struct {
+	templates *Templates
+	cipher    *pii.Cipher // Customer names and emails are encrypted.
+}
+
+// EnqueueForCustomer renders the template in the customer's locale for every
+// channel the customer can be reached on (email always, SMS if there's a phone
+// number) and stores the messages. FirstName is added to data automatically.
+func (o *Outbox) EnqueueForCustomer(ctx context.Context, db DB, customerID uuid.UUID, name Template, data map[string]any) error {
+	var phone, locale string
+	var row pii.Row
+	err := db.QueryRow(ctx,
+		`SELECT phone, locale, `+pii.Columns("")+` FROM customers WHERE id = $1`, customerID,
+	).Scan(append([]any{&phone, &locale}, row.ScanTargets()...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("customer %s not found", customerID)
+	}
+	if err != nil {
+		return err
+	}
+	customer, err := o.cipher.OpenRow(ctx, customerID, row)
+	if err != nil {
+		return err
+	}
+
+	vars := map[string]any{"FirstName": customer.FirstName}
+	for k, v := range data {
+		vars[k] = v
+	}
+
+	recipients := map[Channel]string{ChannelEmail: customer.Email}
+	if phone != "" {
+		recipients[ChannelSMS] = phone
+	}
+
+	sql := `
+		INSERT INTO notification_outbox (id, customer_id, template, channel, recipient, subject, body, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	for ch, to := range recipients {
+		subject, body, err := o.templates.Render(locale, name, ch, vars)
+		if err != nil {
+			return fmt.Errorf("failed to render %s for %s: %w", name, ch, err)
+		}
+		if _, err := db.Exec(ctx, sql,
+			uuid.New(),
+			customerID,
+			name,
+			ch,
+			to,
+			subject,
+			body,
+			domain.NotificationStatusPending,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
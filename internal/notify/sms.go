@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SMSGatewayConfig configures the SMS channel.
+type SMSGatewayConfig struct {
+	URL    string // Messages are POSTed here as JSON.
+	Token  string // Optional, sent as a bearer token.
+	Sender string
+}
+
+func NewSMSGatewayNotifier(conf SMSGatewayConfig) *SMSGatewayNotifier {
+	return &SMSGatewayNotifier{
+		conf:   conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SMSGatewayNotifier sends text messages through a generic HTTP SMS gateway.
+type SMSGatewayNotifier struct {
+	conf   SMSGatewayConfig
+	client *http.Client
+}
+
+type smsGatewayRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+func (n *SMSGatewayNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(smsGatewayRequest{
+		From: n.conf.Sender,
+		To:   msg.To,
+		Text: msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.conf.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.conf.Token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return &PermanentError{Err: fmt.Errorf("sms gateway responded %d: %s", resp.StatusCode, respBody)}
+	default:
+		return fmt.Errorf("sms gateway responded %d: %s", resp.StatusCode, respBody)
+	}
+}
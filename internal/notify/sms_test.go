@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSMSGatewayNotifier_OK(t *testing.T) {
+	// Arrange: gateway stub.
+	var got smsGatewayRequest
+	var gotAuth string
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer gw.Close()
+
+	// Arrange: the SUT.
+	sut := NewSMSGatewayNotifier(SMSGatewayConfig{URL: gw.URL, Token: "some-token", Sender: "BestWallet"})
+
+	// Act.
+	err := sut.Notify(context.Background(), Message{Channel: ChannelSMS, To: "+38160000000", Body: "hi"})
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got.To != "+38160000000" || got.Text != "hi" || got.From != "BestWallet" {
+		t.Fatalf("unexpected gateway request %+v", got)
+	}
+	if gotAuth != "Bearer some-token" {
+		t.Fatalf("expected bearer token, got %q", gotAuth)
+	}
+}
+
+func TestSMSGatewayNotifier_ClientErrorIsPermanent(t *testing.T) {
+	// Arrange.
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer gw.Close()
+	sut := NewSMSGatewayNotifier(SMSGatewayConfig{URL: gw.URL})
+
+	// Act.
+	err := sut.Notify(context.Background(), Message{Channel: ChannelSMS, To: "invalid", Body: "hi"})
+
+	// Assert.
+	if !IsPermanent(err) {
+		t.Fatalf("expected a permanent error, got %v", err)
+	}
+}
+
+func TestSMSGatewayNotifier_ServerErrorIsRetriable(t *testing.T) {
+	// Arrange.
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer gw.Close()
+	sut := NewSMSGatewayNotifier(SMSGatewayConfig{URL: gw.URL})
+
+	// Act.
+	err := sut.Notify(context.Background(), Message{Channel: ChannelSMS, To: "+38160000000", Body: "hi"})
+
+	// Assert.
+	if err == nil || IsPermanent(err) {
+		t.Fatalf("expected a retriable error, got %v", err)
+	}
+}
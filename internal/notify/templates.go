@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+)
+
+type Template string
+
+const (
+	TemplateKYCApproved   Template = "kyc_approved"
+	TemplateKYCRejected   Template = "kyc_rejected"
+	TemplateAccountOpened Template = "account_opened"
+	TemplateMoneyReceived Template = "money_received"
+	TemplateMoneySent     Template = "money_sent"
+)
+
+// DefaultLocale is used when the customer's locale has no translation.
+const DefaultLocale = "en"
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Templates holds the localized notification templates. Every template file
+// lives in templates/<locale>/<template>.tmpl and defines "<template>.subject",
+// "<template>.email" and "<template>.sms".
+type Templates struct {
+	byLocale map[string]*template.Template
+}
+
+func LoadTemplates() (*Templates, error) {
+	locales, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{byLocale: make(map[string]*template.Template)}
+	for _, l := range locales {
+		tmpl, err := template.New(l.Name()).
+			Option("missingkey=error").
+			ParseFS(templatesFS, path.Join("templates", l.Name(), "*.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s templates: %w", l.Name(), err)
+		}
+		t.byLocale[l.Name()] = tmpl
+	}
+	if _, ok := t.byLocale[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing templates for default locale %s", DefaultLocale)
+	}
+
+	return t, nil
+}
+
+// Render renders the template for the channel in the given locale, falling
+// back to the default locale if there's no translation.
+func (t *Templates) Render(locale string, name Template, ch Channel, data any) (subject, body string, err error) {
+	tmpl := t.lookup(locale, string(name)+".subject")
+	if tmpl == nil {
+		return "", "", fmt.Errorf("unknown template %s", name)
+	}
+
+	var b strings.Builder
+	if err := tmpl.ExecuteTemplate(&b, string(name)+".subject", data); err != nil {
+		return "", "", err
+	}
+	subject = b.String()
+
+	b.Reset()
+	if err := tmpl.ExecuteTemplate(&b, string(name)+"."+string(ch), data); err != nil {
+		return "", "", err
+	}
+	body = b.String()
+
+	return subject, body, nil
+}
+
+func (t *Templates) lookup(locale, name string) *template.Template {
+	if tmpl, ok := t.byLocale[locale]; ok && tmpl.Lookup(name) != nil {
+		return tmpl
+	}
+	if tmpl := t.byLocale[DefaultLocale]; tmpl.Lookup(name) != nil {
+		return tmpl
+	}
+	return nil
+}
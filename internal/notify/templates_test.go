@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	// Arrange.
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("error loading templates: %s", err)
+	}
+
+	// Act.
+	subject, body, err := tmpl.Render("en", TemplateAccountOpened, ChannelEmail, map[string]any{
+		"FirstName":     "Jane",
+		"AccountNumber": "acc-123",
+	})
+	if err != nil {
+		t.Fatalf("error rendering: %s", err)
+	}
+
+	// Assert.
+	if subject == "" {
+		t.Fatalf("expected a subject, got none")
+	}
+	if !strings.Contains(body, "Jane") || !strings.Contains(body, "acc-123") {
+		t.Fatalf("expected body to contain the data, got %q", body)
+	}
+}
+
+func TestTemplates_RenderLocalized(t *testing.T) {
+	// Arrange.
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("error loading templates: %s", err)
+	}
+
+	// Act.
+	_, body, err := tmpl.Render("de", TemplateKYCApproved, ChannelEmail, map[string]any{"FirstName": "Jane"})
+	if err != nil {
+		t.Fatalf("error rendering: %s", err)
+	}
+
+	// Assert.
+	if !strings.HasPrefix(body, "Hallo Jane") {
+		t.Fatalf("expected german body, got %q", body)
+	}
+}
+
+func TestTemplates_UnknownLocaleFallsBackToDefault(t *testing.T) {
+	// Arrange.
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("error loading templates: %s", err)
+	}
+
+	// Act.
+	_, body, err := tmpl.Render("xx", TemplateKYCApproved, ChannelSMS, map[string]any{"FirstName": "Jane"})
+	if err != nil {
+		t.Fatalf("error rendering: %s", err)
+	}
+
+	// Assert.
+	if !strings.HasPrefix(body, "Best Wallet: your identity") {
+		t.Fatalf("expected english body, got %q", body)
+	}
+}
+
+func TestTemplates_MissingData(t *testing.T) {
+	// Arrange.
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("error loading templates: %s", err)
+	}
+
+	// Act.
+	_, _, err = tmpl.Render("en", TemplateMoneySent, ChannelEmail, map[string]any{"FirstName": "Jane"})
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
+
+func TestTemplates_UnknownTemplate(t *testing.T) {
+	// Arrange.
+	tmpl, err := LoadTemplates()
+	if err != nil {
+		t.Fatalf("error loading templates: %s", err)
+	}
+
+	// Act.
+	_, _, err = tmpl.Render("en", Template("nope"), ChannelEmail, nil)
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
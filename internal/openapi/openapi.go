@@ -0,0 +1,77 @@
+// Package openapi serves the OpenAPI 3 document of the HTTP API and a docs UI
+// rendering it.
+//
+// openapi.json is maintained by hand next to the handlers, the router tests
+// fail when a route is registered without being documented (or the other way
+// around).
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+// Spec returns the raw OpenAPI document.
+func Spec() []byte {
+	return spec
+}
+
+// Operation identifies a documented operation, the path uses gin's syntax
+// (":id" instead of "{id}") so it compares with gin.RoutesInfo.
+type Operation struct {
+	Method string
+	Path   string
+}
+
+// Operations lists the operations in the document.
+func Operations() ([]Operation, error) {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+
+	var ops []Operation
+	for path, methods := range doc.Paths {
+		for method := range methods {
+			if method == "parameters" { // Path-level parameters, not an operation.
+				continue
+			}
+			ops = append(ops, Operation{Method: strings.ToUpper(method), Path: ginPath(path)})
+		}
+	}
+	return ops, nil
+}
+
+// ServeSpec responds with the OpenAPI document.
+func ServeSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", spec)
+}
+
+// ServeDocs responds with the docs UI, it loads the document from
+// /openapi.json.
+func ServeDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docs)
+}
+
+// ginPath turns "/accounts/{number}" into "/accounts/:number".
+func ginPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			segments[i] = ":" + s[1:len(s)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGinPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/accounts", "/api/v1/accounts"},
+		{"/api/v1/accounts/{number}/statements/{period}", "/api/v1/accounts/:number/statements/:period"},
+		{"/api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver", "/api/v1/webhooks/:id/deliveries/:delivery_id/redeliver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// Act.
+			got := ginPath(tt.path)
+
+			// Assert.
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperations(t *testing.T) {
+	// Act.
+	ops, err := Operations()
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []Operation{
+		{Method: "POST", Path: "/api/v1/customers"},
+		{Method: "GET", Path: "/api/v1/accounts/:number/transactions"},
+		{Method: "DELETE", Path: "/api/v1/webhooks/:id"},
+	}
+	for _, w := range want {
+		if !slices.Contains(ops, w) {
+			t.Fatalf("expected %s %s in %v", w.Method, w.Path, ops)
+		}
+	}
+}
+
+func TestRefsResolve(t *testing.T) {
+	// Arrange.
+	var doc map[string]any
+	if err := json.Unmarshal(Spec(), &doc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Act.
+	var refs []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				if s, ok := child.(string); ok && k == "$ref" {
+					refs = append(refs, s)
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+
+	// Assert.
+	for _, ref := range refs {
+		var node any = doc
+		for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := node.(map[string]any)
+			if node = m[name]; node == nil {
+				t.Fatalf("unresolved $ref %q", ref)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// Execer is satisfied by *pgxpool.Pool, *pgx.Conn and pgx.Tx. Always pass
+// the transaction that carries the state change, otherwise the event can get
+// lost (or published for a change that was rolled back).
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Write stores an event in the outbox table. A relay publishes it to the
+// event stream after the transaction commits.
+func Write(ctx context.Context, db Execer, typ domain.EventType, aggregateID uuid.UUID, payload any) (domain.Event, error) {
+	ev, err := NewEvent(typ, aggregateID, payload)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	sql := `
+		INSERT INTO outbox (event_id, event_type, aggregate_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err = db.Exec(ctx, sql,
+		ev.ID,
+		ev.Type,
+		ev.AggregateID,
+		ev.Payload,
+		ev.OccurredAt,
+	)
+
+	return ev, err
+}
+
+// NewEvent returns the event Write would store.
+func NewEvent(typ domain.EventType, aggregateID uuid.UUID, payload any) (domain.Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	return domain.Event{
+		ID:          uuid.New(),
+		Type:        typ,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now().UTC(),
+		Payload:     raw,
+	}, nil
+}
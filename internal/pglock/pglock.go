@@ -0,0 +1,58 @@
+// Package pglock runs code that must not run on two instances at once, under
+// a Postgres advisory lock.
+package pglock
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Key identifies an advisory lock. Postgres has a single key space per
+// database, so every key is declared below where a collision shows.
+// Changing a key lets an instance on the old key and one on the new key run
+// together while a release rolls out.
+type Key int64
+
+const (
+	KeyMigrate            Key = 1
+	KeyRescreenCustomers  Key = 2
+	KeyAccrueInterest     Key = 3
+	KeyGenerateStatements Key = 4
+	KeySealAuditLog       Key = 5
+	KeyReencryptCustomers Key = 6
+)
+
+// TryWith runs fn holding the lock and reports whether it ran, it doesn't if
+// another instance holds the lock.
+func TryWith(ctx context.Context, db *pgxpool.Pool, key Key, fn func(conn *pgxpool.Conn) error) (bool, error) {
+	return withAdvisoryLock(ctx, db, key, `SELECT pg_try_advisory_lock($1)`, fn)
+}
+
+// With runs fn holding the lock, waiting for it if another instance holds
+// it.
+func With(ctx context.Context, db *pgxpool.Pool, key Key, fn func(conn *pgxpool.Conn) error) error {
+	_, err := withAdvisoryLock(ctx, db, key, `SELECT true FROM pg_advisory_lock($1)`, fn)
+	return err
+}
+
+func withAdvisoryLock(ctx context.Context, db *pgxpool.Pool, key Key, lockSQL string, fn func(conn *pgxpool.Conn) error) (bool, error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	// Advisory locks are bound to the session, so lock, run fn and unlock on
+	// the same connection.
+	var locked bool
+	if err := conn.QueryRow(ctx, lockSQL, int64(key)).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, int64(key))
+
+	return true, fn(conn)
+}
@@ -0,0 +1,82 @@
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownKey = errors.New("unknown key-encryption key")
+
+// KMS wraps (encrypts) data keys with a key-encryption key (KEK) that never
+// leaves it. It can hold several KEKs, new data keys are wrapped with the
+// current one and the others are kept for unwrapping until rotation is done.
+//
+// LocalKMS keeps the KEKs in memory, a KMS service can be plugged in by
+// implementing this interface.
+type KMS interface {
+	// CurrentKeyID is the ID of the KEK WrapKey uses.
+	CurrentKeyID() string
+	WrapKey(ctx context.Context, dataKey []byte) (wrapped []byte, keyID string, err error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKMS wraps data keys with AES-256-GCM, using KEKs provided by the
+// environment (or a file), see blob.ParseKeys for the format.
+type LocalKMS struct {
+	keks      map[string]cipher.AEAD
+	currentID string
+}
+
+// NewLocalKMS takes 32 byte KEKs by ID, currentID wraps new data keys.
+func NewLocalKMS(keks map[string][]byte, currentID string) (*LocalKMS, error) {
+	k := &LocalKMS{keks: make(map[string]cipher.AEAD, len(keks)), currentID: currentID}
+	for id, key := range keks {
+		if id == "" {
+			return nil, errors.New("empty key ID")
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q: expected 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if k.keks[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := k.keks[currentID]; !ok {
+		return nil, fmt.Errorf("current key %q not found", currentID)
+	}
+	return k, nil
+}
+
+func (k *LocalKMS) CurrentKeyID() string {
+	return k.currentID
+}
+
+// WrapKey returns nonce | ciphertext, the KEK ID is authenticated too.
+func (k *LocalKMS) WrapKey(ctx context.Context, dataKey []byte) ([]byte, string, error) {
+	aead := k.keks[k.currentID]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(dataKey)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", err
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(k.currentID)), k.currentID, nil
+}
+
+func (k *LocalKMS) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keks[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("wrapped key too short")
+	}
+	nonce, ct := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	return aead.Open(nil, nonce, ct, []byte(keyID))
+}
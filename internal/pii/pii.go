@@ -0,0 +1,182 @@
+// Package pii encrypts the personal data of customers (names, email,
+// residence address, birth date) before it's stored.
+//
+// Every customer row has its own data key (envelope encryption): the fields
+// are encrypted with it using AES-256-GCM, and the data key is stored wrapped
+// by a key-encryption key held by the KMS. Each field is bound to its row and
+// column, so ciphertext copied elsewhere fails to decrypt. Rotating the KEK
+// means re-encrypting the rows wrapped with old ones, see
+// job.ReencryptCustomers.
+//
+// Emails can't be looked up by ciphertext, so a blind index (HMAC-SHA256 of
+// the normalized email with a separate key) is stored next to it. It's
+// deterministic, which is what makes it unique, and it can't be rotated
+// without recomputing it for every customer.
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PII is the personal data of a customer, in plaintext.
+type PII struct {
+	FirstName        string
+	LastName         string
+	Email            string
+	ResidenceAddress string
+	BirthDate        time.Time
+}
+
+// Sealed is PII as stored in the customers table.
+type Sealed struct {
+	FirstName        []byte
+	LastName         []byte
+	Email            []byte
+	ResidenceAddress []byte
+	BirthDate        []byte
+	DataKey          []byte // Wrapped by the KMS.
+	KeyID            string // Of the KEK that wrapped DataKey.
+	EmailIndex       []byte
+}
+
+func NewCipher(kms KMS, indexKey []byte) (*Cipher, error) {
+	if len(indexKey) < 32 {
+		return nil, fmt.Errorf("blind index key: expected at least 32 bytes, got %d", len(indexKey))
+	}
+	return &Cipher{kms: kms, indexKey: indexKey}, nil
+}
+
+// Cipher seals and opens customer PII.
+type Cipher struct {
+	kms      KMS
+	indexKey []byte
+}
+
+// CurrentKeyID is the KEK new rows are sealed with, rows sealed with others
+// are due for re-encryption.
+func (c *Cipher) CurrentKeyID() string {
+	return c.kms.CurrentKeyID()
+}
+
+// Seal encrypts the PII of a customer with a fresh data key.
+func (c *Cipher) Seal(ctx context.Context, customerID uuid.UUID, p PII) (Sealed, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	var s Sealed
+	fields := []struct {
+		dst   *[]byte
+		name  string
+		value string
+	}{
+		{&s.FirstName, "first_name", p.FirstName},
+		{&s.LastName, "last_name", p.LastName},
+		{&s.Email, "email", p.Email},
+		{&s.ResidenceAddress, "residence_address", p.ResidenceAddress},
+		{&s.BirthDate, "birth_date", p.BirthDate.Format(time.DateOnly)},
+	}
+	for _, f := range fields {
+		if *f.dst, err = seal(aead, customerID, f.name, f.value); err != nil {
+			return Sealed{}, err
+		}
+	}
+
+	if s.DataKey, s.KeyID, err = c.kms.WrapKey(ctx, dataKey); err != nil {
+		return Sealed{}, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	s.EmailIndex = c.EmailIndex(p.Email)
+
+	return s, nil
+}
+
+// Open decrypts the PII of a customer.
+func (c *Cipher) Open(ctx context.Context, customerID uuid.UUID, s Sealed) (PII, error) {
+	dataKey, err := c.kms.UnwrapKey(ctx, s.KeyID, s.DataKey)
+	if err != nil {
+		return PII{}, fmt.Errorf("failed to unwrap data key of customer %s: %w", customerID, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return PII{}, err
+	}
+
+	var p PII
+	var birthDate string
+	fields := []struct {
+		dst   *string
+		name  string
+		value []byte
+	}{
+		{&p.FirstName, "first_name", s.FirstName},
+		{&p.LastName, "last_name", s.LastName},
+		{&p.Email, "email", s.Email},
+		{&p.ResidenceAddress, "residence_address", s.ResidenceAddress},
+		{&birthDate, "birth_date", s.BirthDate},
+	}
+	for _, f := range fields {
+		if *f.dst, err = open(aead, customerID, f.name, f.value); err != nil {
+			return PII{}, fmt.Errorf("failed to decrypt %s of customer %s: %w", f.name, customerID, err)
+		}
+	}
+	if p.BirthDate, err = time.Parse(time.DateOnly, birthDate); err != nil {
+		return PII{}, err
+	}
+
+	return p, nil
+}
+
+// EmailIndex is the blind index of an email, case and surrounding spaces
+// don't matter.
+func (c *Cipher) EmailIndex(email string) []byte {
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return mac.Sum(nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns nonce | ciphertext, authenticated with the customer ID and
+// the column name.
+func seal(aead cipher.AEAD, customerID uuid.UUID, column, value string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, []byte(value), additionalData(customerID, column)), nil
+}
+
+func open(aead cipher.AEAD, customerID uuid.UUID, column string, sealed []byte) (string, error) {
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+	nonce, ct := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ct, additionalData(customerID, column))
+	return string(plain), err
+}
+
+func additionalData(customerID uuid.UUID, column string) []byte {
+	return []byte(customerID.String() + ":" + column)
+}
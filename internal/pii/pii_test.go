@@ -0,0 +1,163 @@
+package pii
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestCipher(t *testing.T, keks map[string][]byte, currentID string) *Cipher {
+	t.Helper()
+	kms, err := NewLocalKMS(keks, currentID)
+	if err != nil {
+		t.Fatalf("NewLocalKMS: %v", err)
+	}
+	c, err := NewCipher(kms, bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return c
+}
+
+var testPII = PII{
+	FirstName:        "Ada",
+	LastName:         "Lovelace",
+	Email:            "ada@example.com",
+	ResidenceAddress: "12 St James's Square, London",
+	BirthDate:        time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC),
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	c := newTestCipher(t, map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+	id := uuid.New()
+
+	// Act.
+	s, err := c.Seal(ctx, id, testPII)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	got, err := c.Open(ctx, id, s)
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got != testPII {
+		t.Fatalf("expected %+v, got %+v", testPII, got)
+	}
+	if s.KeyID != "k1" {
+		t.Fatalf("expected key ID k1, got %q", s.KeyID)
+	}
+	if bytes.Contains(s.Email, []byte(testPII.Email)) {
+		t.Fatal("email stored in plaintext")
+	}
+}
+
+func TestCipher_OpenTampered(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCipher(t, map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+	id := uuid.New()
+	s, err := c.Seal(ctx, id, testPII)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		id   uuid.UUID
+		edit func(s *Sealed)
+	}{
+		{"other customer", uuid.New(), func(s *Sealed) {}},
+		{"swapped columns", id, func(s *Sealed) { s.FirstName, s.LastName = s.LastName, s.FirstName }},
+		{"flipped bit", id, func(s *Sealed) { s.Email = append([]byte{}, s.Email...); s.Email[len(s.Email)-1] ^= 1 }},
+		{"unknown KEK", id, func(s *Sealed) { s.KeyID = "k2" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			tampered := s
+			tt.edit(&tampered)
+
+			// Act.
+			_, err := c.Open(ctx, tt.id, tampered)
+
+			// Assert.
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestCipher_Rotation(t *testing.T) {
+	// Arrange: sealed with k1, then k2 becomes current.
+	ctx := context.Background()
+	k1, k2 := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	old := newTestCipher(t, map[string][]byte{"k1": k1}, "k1")
+	id := uuid.New()
+	s, err := old.Seal(ctx, id, testPII)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	rotated := newTestCipher(t, map[string][]byte{"k1": k1, "k2": k2}, "k2")
+
+	// Act: re-encrypt.
+	p, err := rotated.Open(ctx, id, s)
+	if err != nil {
+		t.Fatalf("Open with old KEK: %v", err)
+	}
+	resealed, err := rotated.Seal(ctx, id, p)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	// Assert: readable without k1, same blind index.
+	withoutK1 := newTestCipher(t, map[string][]byte{"k2": k2}, "k2")
+	if _, err := withoutK1.Open(ctx, id, resealed); err != nil {
+		t.Fatalf("Open after rotation: %v", err)
+	}
+	if _, err := withoutK1.Open(ctx, id, s); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for the old row, got %v", err)
+	}
+	if !bytes.Equal(s.EmailIndex, resealed.EmailIndex) {
+		t.Fatal("blind index changed with the KEK")
+	}
+}
+
+func TestCipher_EmailIndex(t *testing.T) {
+	c := newTestCipher(t, map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"ada@example.com", "ada@example.com", true},
+		{"ada@example.com", " Ada@Example.COM ", true},
+		{"ada@example.com", "ada@example.org", false},
+	}
+
+	for _, tt := range tests {
+		// Act.
+		same := bytes.Equal(c.EmailIndex(tt.a), c.EmailIndex(tt.b))
+
+		// Assert.
+		if same != tt.same {
+			t.Fatalf("%q vs %q: expected same %t, got %t", tt.a, tt.b, tt.same, same)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	if got := Columns("c"); got[:19] != "c.first_name_enc, c" {
+		t.Fatalf("unexpected columns %q", got)
+	}
+	if n := len((&Row{}).ScanTargets()); n != len(columns) {
+		t.Fatalf("%d scan targets for %d columns", n, len(columns))
+	}
+}
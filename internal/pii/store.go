@@ -0,0 +1,75 @@
+package pii
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var columns = []string{
+	"first_name_enc", "last_name_enc", "email_enc", "residence_address_enc", "birth_date_enc",
+	"pii_data_key", "pii_kek_id",
+	// Plaintext, only set on rows not encrypted yet (created before
+	// encryption), see job.ReencryptCustomers.
+	"first_name", "last_name", "email", "residence_address", "birth_date",
+}
+
+// Columns lists the customers columns Row scans, prefixed with the table
+// alias, if any.
+func Columns(alias string) string {
+	if alias == "" {
+		return strings.Join(columns, ", ")
+	}
+	return alias + "." + strings.Join(columns, ", "+alias+".")
+}
+
+// Row is the PII of a customers row, as selected by Columns.
+type Row struct {
+	Sealed Sealed
+	KeyID  *string // Nil until the row is encrypted.
+
+	firstName        *string
+	lastName         *string
+	email            *string
+	residenceAddress *string
+	birthDate        *time.Time
+}
+
+// ScanTargets are the destinations for the Columns, in order.
+func (r *Row) ScanTargets() []any {
+	return []any{
+		&r.Sealed.FirstName, &r.Sealed.LastName, &r.Sealed.Email, &r.Sealed.ResidenceAddress, &r.Sealed.BirthDate,
+		&r.Sealed.DataKey, &r.KeyID,
+		&r.firstName, &r.lastName, &r.email, &r.residenceAddress, &r.birthDate,
+	}
+}
+
+// OpenRow decrypts a row, or returns its plaintext if it isn't encrypted yet.
+func (c *Cipher) OpenRow(ctx context.Context, customerID uuid.UUID, r Row) (PII, error) {
+	if r.KeyID != nil {
+		s := r.Sealed
+		s.KeyID = *r.KeyID
+		return c.Open(ctx, customerID, s)
+	}
+
+	var p PII
+	for _, f := range []struct {
+		dst *string
+		src *string
+	}{
+		{&p.FirstName, r.firstName},
+		{&p.LastName, r.lastName},
+		{&p.Email, r.email},
+		{&p.ResidenceAddress, r.residenceAddress},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	if r.birthDate != nil {
+		p.BirthDate = *r.birthDate
+	}
+	return p, nil
+}
@@ -0,0 +1,231 @@
+// Package reconcile checks the ledger for inconsistencies: transactions whose
+// entries don't sum up to zero, cached account balances that don't match
+// their entries, holds that outlived their transaction and customer accounts
+// that went negative.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+type Kind string
+
+const (
+	KindUnbalancedTransaction Kind = "unbalanced_transaction" // Entries don't sum up to zero.
+	KindBalanceMismatch       Kind = "balance_mismatch"       // accounts.balance != sum of cleared entries.
+	KindOrphanedHold          Kind = "orphaned_hold"          // Active hold of a transaction that's not pending.
+	KindNegativeBalance       Kind = "negative_balance"       // Customer accounts can't go negative.
+)
+
+// Kinds lists every kind of discrepancy, e.g. to report zeros in metrics.
+var Kinds = []Kind{KindUnbalancedTransaction, KindBalanceMismatch, KindOrphanedHold, KindNegativeBalance}
+
+type Discrepancy struct {
+	Kind          Kind       `json:"kind"`
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"`
+	AccountID     *uuid.UUID `json:"account_id,omitempty"`
+	AccountNumber string     `json:"account_number,omitempty"`
+	Expected      int64      `json:"expected"`
+	Actual        int64      `json:"actual"`
+}
+
+func (d Discrepancy) String() string {
+	switch {
+	case d.AccountID != nil && d.TransactionID != nil:
+		return fmt.Sprintf("%s: account %s, transaction %s: expected %d, got %d", d.Kind, d.AccountNumber, d.TransactionID, d.Expected, d.Actual)
+	case d.AccountID != nil:
+		return fmt.Sprintf("%s: account %s: expected %d, got %d", d.Kind, d.AccountNumber, d.Expected, d.Actual)
+	default:
+		return fmt.Sprintf("%s: transaction %s: expected %d, got %d", d.Kind, d.TransactionID, d.Expected, d.Actual)
+	}
+}
+
+type Report struct {
+	StartedAt           time.Time     `json:"started_at"`
+	FinishedAt          time.Time     `json:"finished_at"`
+	AccountID           *uuid.UUID    `json:"account_id,omitempty"` // Set if only one account was checked.
+	TransactionsChecked int           `json:"transactions_checked"`
+	AccountsChecked     int           `json:"accounts_checked"`
+	HoldsChecked        int           `json:"holds_checked"`
+	Discrepancies       []Discrepancy `json:"discrepancies"`
+}
+
+// OK reports whether no discrepancies were found.
+func (r Report) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Counts returns the number of discrepancies per kind.
+func (r Report) Counts() map[Kind]int {
+	res := make(map[Kind]int, len(Kinds))
+	for _, k := range Kinds {
+		res[k] = 0
+	}
+	for _, d := range r.Discrepancies {
+		res[d.Kind]++
+	}
+	return res
+}
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Execer is satisfied by both pgxpool.Pool and pgx.Tx.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Options narrow down what's checked.
+type Options struct {
+	AccountID *uuid.UUID // Only check this account and its transactions.
+}
+
+// Run runs every check. Run it in a read only, repeatable read transaction,
+// so all checks see the same snapshot while money keeps moving.
+func Run(ctx context.Context, db DB, opts Options) (Report, error) {
+	r := Report{StartedAt: time.Now(), AccountID: opts.AccountID}
+
+	checks := []func(context.Context, DB, Options, *Report) error{
+		checkTransactions,
+		checkBalances,
+		checkHolds,
+	}
+	for _, check := range checks {
+		if err := check(ctx, db, opts, &r); err != nil {
+			return Report{}, err
+		}
+	}
+
+	r.FinishedAt = time.Now()
+	return r, nil
+}
+
+func checkTransactions(ctx context.Context, db DB, opts Options, r *Report) error {
+	// Transactions without entries are unbalanced too (LEFT JOIN), a
+	// transaction needs at least 2 legs.
+	rows, _ := db.Query(ctx, `
+		SELECT t.id, COALESCE(SUM(e.amount), 0), count(e.id)
+		FROM transactions t LEFT JOIN entries e ON e.transaction_id = t.id
+		WHERE $1::uuid IS NULL OR t.id IN (SELECT transaction_id FROM entries WHERE account_id = $1)
+		GROUP BY t.id`,
+		opts.AccountID,
+	)
+	var id uuid.UUID
+	var sum, legs int64
+	_, err := pgx.ForEachRow(rows, []any{&id, &sum, &legs}, func() error {
+		r.TransactionsChecked++
+		if sum != 0 || legs < 2 {
+			id := id
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{
+				Kind:          KindUnbalancedTransaction,
+				TransactionID: &id,
+				Expected:      0,
+				Actual:        sum,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check transactions: %w", err)
+	}
+	return nil
+}
+
+func checkBalances(ctx context.Context, db DB, opts Options, r *Report) error {
+	rows, _ := db.Query(ctx, `
+		SELECT a.id, a.number, a.kind, a.balance,
+			COALESCE(SUM(e.amount) FILTER (WHERE t.status = $1), 0)
+		FROM accounts a
+		LEFT JOIN entries e ON e.account_id = a.id
+		LEFT JOIN transactions t ON t.id = e.transaction_id
+		WHERE $2::uuid IS NULL OR a.id = $2
+		GROUP BY a.id`,
+		domain.TransactionStatusCleared, opts.AccountID,
+	)
+	var id uuid.UUID
+	var number string
+	var kind domain.AccountKind
+	var stored, computed int64
+	_, err := pgx.ForEachRow(rows, []any{&id, &number, &kind, &stored, &computed}, func() error {
+		r.AccountsChecked++
+		id := id
+		if stored != computed {
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{
+				Kind:          KindBalanceMismatch,
+				AccountID:     &id,
+				AccountNumber: number,
+				Expected:      computed,
+				Actual:        stored,
+			})
+		}
+		if kind == domain.AccountKindCustomer && computed < 0 {
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{
+				Kind:          KindNegativeBalance,
+				AccountID:     &id,
+				AccountNumber: number,
+				Expected:      0,
+				Actual:        computed,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check balances: %w", err)
+	}
+	return nil
+}
+
+func checkHolds(ctx context.Context, db DB, opts Options, r *Report) error {
+	rows, _ := db.Query(ctx, `
+		SELECT h.account_id, a.number, h.transaction_id, h.amount, t.status
+		FROM holds h
+		JOIN accounts a ON a.id = h.account_id
+		JOIN transactions t ON t.id = h.transaction_id
+		WHERE h.released_at IS NULL AND ($1::uuid IS NULL OR h.account_id = $1)`,
+		opts.AccountID,
+	)
+	var accountID, transactionID uuid.UUID
+	var number string
+	var amount int64
+	var status domain.TransactionStatus
+	_, err := pgx.ForEachRow(rows, []any{&accountID, &number, &transactionID, &amount, &status}, func() error {
+		r.HoldsChecked++
+		if status != domain.TransactionStatusPending {
+			accountID, transactionID := accountID, transactionID
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{
+				Kind:          KindOrphanedHold,
+				AccountID:     &accountID,
+				AccountNumber: number,
+				TransactionID: &transactionID,
+				Expected:      0,
+				Actual:        amount,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check holds: %w", err)
+	}
+	return nil
+}
+
+// Save stores the report in reconciliation_runs for auditing.
+func Save(ctx context.Context, db Execer, r Report) error {
+	_, err := db.Exec(ctx, `
+		INSERT INTO reconciliation_runs (id, account_id, started_at, finished_at, discrepancies, report)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		uuid.New(), r.AccountID, r.StartedAt, r.FinishedAt, len(r.Discrepancies), r,
+	)
+	return err
+}
@@ -0,0 +1,92 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// WriteMetrics writes the report in the Prometheus text format, e.g. for the
+// node exporter's textfile collector.
+func WriteMetrics(w io.Writer, r Report) error {
+	counts := r.Counts()
+	kinds := make([]string, 0, len(counts))
+	for k := range counts {
+		kinds = append(kinds, string(k))
+	}
+	sort.Strings(kinds)
+
+	var b bytes.Buffer
+	b.WriteString("# HELP bestwallet_reconcile_discrepancies Ledger discrepancies found by the last reconciliation.\n")
+	b.WriteString("# TYPE bestwallet_reconcile_discrepancies gauge\n")
+	for _, k := range kinds {
+		fmt.Fprintf(&b, "bestwallet_reconcile_discrepancies{kind=%q} %d\n", k, counts[Kind(k)])
+	}
+	b.WriteString("# HELP bestwallet_reconcile_checked Ledger rows checked by the last reconciliation.\n")
+	b.WriteString("# TYPE bestwallet_reconcile_checked gauge\n")
+	fmt.Fprintf(&b, "bestwallet_reconcile_checked{table=\"accounts\"} %d\n", r.AccountsChecked)
+	fmt.Fprintf(&b, "bestwallet_reconcile_checked{table=\"holds\"} %d\n", r.HoldsChecked)
+	fmt.Fprintf(&b, "bestwallet_reconcile_checked{table=\"transactions\"} %d\n", r.TransactionsChecked)
+	b.WriteString("# HELP bestwallet_reconcile_duration_seconds How long the last reconciliation took.\n")
+	b.WriteString("# TYPE bestwallet_reconcile_duration_seconds gauge\n")
+	fmt.Fprintf(&b, "bestwallet_reconcile_duration_seconds %g\n", r.FinishedAt.Sub(r.StartedAt).Seconds())
+	b.WriteString("# HELP bestwallet_reconcile_last_run_timestamp_seconds When the last reconciliation finished.\n")
+	b.WriteString("# TYPE bestwallet_reconcile_last_run_timestamp_seconds gauge\n")
+	fmt.Fprintf(&b, "bestwallet_reconcile_last_run_timestamp_seconds %d\n", r.FinishedAt.Unix())
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Alert is posted to the alert webhook when discrepancies are found.
+type Alert struct {
+	Summary       string        `json:"summary"`
+	Counts        map[Kind]int  `json:"counts"`
+	Discrepancies []Discrepancy `json:"discrepancies"` // The first maxAlertDiscrepancies.
+}
+
+const maxAlertDiscrepancies = 20
+
+// NewAlert summarizes a report with discrepancies for humans.
+func NewAlert(r Report) Alert {
+	a := Alert{
+		Summary:       fmt.Sprintf("Ledger reconciliation found %d discrepancies", len(r.Discrepancies)),
+		Counts:        r.Counts(),
+		Discrepancies: r.Discrepancies,
+	}
+	if len(a.Discrepancies) > maxAlertDiscrepancies {
+		a.Discrepancies = a.Discrepancies[:maxAlertDiscrepancies]
+	}
+	return a
+}
+
+// SendAlert posts the alert as JSON to url (e.g. an alertmanager or chat
+// webhook).
+func SendAlert(ctx context.Context, client *http.Client, url string, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook responded with %d", resp.StatusCode)
+	}
+	return nil
+}
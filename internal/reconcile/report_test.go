@@ -0,0 +1,72 @@
+package reconcile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	// Arrange.
+	id := uuid.New()
+	start := time.Unix(1700000000, 0)
+	r := Report{
+		StartedAt:           start,
+		FinishedAt:          start.Add(1500 * time.Millisecond),
+		TransactionsChecked: 10,
+		AccountsChecked:     3,
+		HoldsChecked:        1,
+		Discrepancies: []Discrepancy{
+			{Kind: KindBalanceMismatch, AccountID: &id},
+			{Kind: KindBalanceMismatch, AccountID: &id},
+			{Kind: KindOrphanedHold, AccountID: &id},
+		},
+	}
+
+	// Act.
+	var b bytes.Buffer
+	err := WriteMetrics(&b, r)
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, want := range []string{
+		`bestwallet_reconcile_discrepancies{kind="balance_mismatch"} 2`,
+		`bestwallet_reconcile_discrepancies{kind="orphaned_hold"} 1`,
+		`bestwallet_reconcile_discrepancies{kind="unbalanced_transaction"} 0`,
+		`bestwallet_reconcile_checked{table="transactions"} 10`,
+		`bestwallet_reconcile_duration_seconds 1.5`,
+		`bestwallet_reconcile_last_run_timestamp_seconds 1700000001`,
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Fatalf("expected metrics to contain %q, got:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestNewAlert_Truncates(t *testing.T) {
+	// Arrange.
+	r := Report{}
+	for i := 0; i < 30; i++ {
+		id := uuid.New()
+		r.Discrepancies = append(r.Discrepancies, Discrepancy{Kind: KindUnbalancedTransaction, TransactionID: &id})
+	}
+
+	// Act.
+	a := NewAlert(r)
+
+	// Assert.
+	if len(a.Discrepancies) != maxAlertDiscrepancies {
+		t.Fatalf("expected %d discrepancies, got %d", maxAlertDiscrepancies, len(a.Discrepancies))
+	}
+	if a.Counts[KindUnbalancedTransaction] != 30 {
+		t.Fatalf("expected counts of the whole report, got %v", a.Counts)
+	}
+	if !strings.Contains(a.Summary, "30") {
+		t.Fatalf("unexpected summary %q", a.Summary)
+	}
+}
@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+var ErrKYTAlertNotFound = errors.New("KYT alert not found")
+
+// KYCReview is a customer whose KYC waits for an operator, see
+// BackOffice.KYCReviews.
+type KYCReview struct {
+	CustomerID uuid.UUID
+	PII        pii.Row   // Open it with pii.Cipher.OpenRow.
+	Since      time.Time // When the customer last changed.
+	Hits       []ScreeningHit
+}
+
+// ScreeningHit is a watchlist match of a customer, see package screening.
+type ScreeningHit struct {
+	ID           uuid.UUID                    `db:"id"`
+	ListName     string                       `db:"list_name"`
+	EntryName    string                       `db:"entry_name"`
+	EntryKind    string                       `db:"entry_kind"`
+	Score        float64                      `db:"score"`
+	Strength     string                       `db:"strength"`
+	ReviewStatus domain.ScreeningReviewStatus `db:"review_status"`
+}
+
+// KYTAlert is a transaction held back by KYT, see consumer.ClearTransactions.
+type KYTAlert struct {
+	ID            uuid.UUID              `db:"id"`
+	TransactionID uuid.UUID              `db:"transaction_id"`
+	Kind          domain.TransactionKind `db:"kind"`
+	Amount        int64                  `db:"amount"`
+	Currency      string                 `db:"currency"`
+	Rule          string                 `db:"rule"`
+	Detail        string                 `db:"detail"`
+	Status        domain.KYTAlertStatus  `db:"status"`
+	CreatedAt     time.Time              `db:"created_at"`
+}
+
+// BackOffice is what the operators work on, see package admin.
+type BackOffice interface {
+	// CreateUser stores a user made by admin.NewUser. Returns
+	// admin.ErrEmailTaken if another user has the email.
+	CreateUser(ctx context.Context, u domain.AdminUser, token string) error
+	// FindUserByToken returns the active user the token was issued to.
+	FindUserByToken(ctx context.Context, token string) (domain.AdminUser, bool, error)
+	// KYCReviews returns up to limit customers in KYC review with open
+	// screening hits, longest waiting first. Hits are strongest first.
+	KYCReviews(ctx context.Context, limit int) ([]KYCReview, error)
+	// ReviewOpenHits records the operator's verdict on the customer's open
+	// screening hits.
+	ReviewOpenHits(ctx context.Context, customerID uuid.UUID, status domain.ScreeningReviewStatus) error
+	// KYTAlerts returns up to limit open KYT alerts, oldest first.
+	KYTAlerts(ctx context.Context, limit int) ([]KYTAlert, error)
+	// LockKYTAlert returns the alert, locked until the unit of work ends.
+	// Returns ErrKYTAlertNotFound if there's no such alert.
+	LockKYTAlert(ctx context.Context, id uuid.UUID) (KYTAlert, error)
+	// ResolveKYTAlert closes an alert with the operator's verdict.
+	ResolveKYTAlert(ctx context.Context, id uuid.UUID, status domain.KYTAlertStatus, resolvedBy uuid.UUID, note string) error
+	// RequestApproval stores a pending approval request, see
+	// admin.RequestApproval.
+	RequestApproval(ctx context.Context, kind domain.ApprovalKind, targetID string, payload any, reason string, makerID uuid.UUID) (admin.Approval, error)
+	// Approvals returns up to limit approval requests in a status, oldest
+	// first.
+	Approvals(ctx context.Context, status domain.ApprovalStatus, limit int) ([]admin.Approval, error)
+	// LockApproval returns the approval request, locked until the unit of
+	// work ends. Returns admin.ErrApprovalNotFound if there's no such
+	// request.
+	LockApproval(ctx context.Context, id uuid.UUID) (admin.Approval, error)
+	// Decide records the checker's decision on a locked request, see
+	// admin.Decide.
+	Decide(ctx context.Context, a admin.Approval, checkerID uuid.UUID, approve bool, note string) (admin.Approval, error)
+}
@@ -0,0 +1,22 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/detod/best-wallet/internal/repo"
+	"github.com/detod/best-wallet/internal/repo/repotest"
+)
+
+// TestMemoryLedger_Invariants only covers Memory, the same simulation runs
+// against Postgres (Pgx) in the integration tests, see
+// internal/integration/ledger_test.go.
+func TestMemoryLedger_Invariants(t *testing.T) {
+	seeds, steps := int64(100), 300
+	if testing.Short() {
+		seeds = 10
+	}
+
+	repotest.TestLedgerInvariants(t, func(t *testing.T) repotest.LedgerStore {
+		return repo.NewMemory()
+	}, seeds, steps)
+}
@@ -0,0 +1,1150 @@
+package repo
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/outbox"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/statement"
+)
+
+var errTxDone = errors.New("unit of work already committed or rolled back")
+
+func NewMemory() *Memory {
+	return &Memory{
+		state: &memState{d: &memData{
+			limits: make(map[domain.KYCTier][]limits.Limit),
+			fees:   make(map[feeKey]fees.Schedule),
+		}},
+	}
+}
+
+// Memory is a Store in memory, for tests. It follows the rules of the
+// Postgres one (ledger checks, limits, fees, idempotency) closely enough for
+// handlers not to tell the difference.
+//
+// Units of work run one at a time on a copy of the data, which replaces the
+// data on Commit. Like row locks in Postgres, a goroutine beginning a second
+// unit of work before finishing the first one blocks forever.
+type Memory struct {
+	work  sync.Mutex // Held by the running unit of work.
+	state *memState
+}
+
+type memState struct {
+	mu sync.Mutex
+	d  *memData
+}
+
+func (s *memState) do(fn func(d *memData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.d)
+}
+
+type memData struct {
+	customers            []memCustomer
+	accounts             []Account
+	transactions         []memTransaction
+	entries              []memEntry
+	holds                []memHold
+	statements           []memStatement
+	scheduledTransfers   []memScheduledTransfer
+	webhookSubscriptions []memWebhookSubscription
+	webhookDeliveries    []WebhookDelivery
+	freezes              []memFreeze
+	screeningHits        []memScreeningHit
+	kytAlerts            []memKYTAlert
+	approvals            []admin.Approval
+	adminUsers           []memAdminUser
+	events               []domain.Event
+	audits               []Audit
+	limits               map[domain.KYCTier][]limits.Limit
+	fees                 map[feeKey]fees.Schedule
+	lastEntryID          int64
+}
+
+type memCustomer struct {
+	Customer
+	pii       pii.Sealed
+	updatedAt time.Time
+	timeline  []kyc.Event
+	documents []kyc.Document
+}
+
+type memTransaction struct {
+	ledger.Transaction
+	createdAt        time.Time
+	adjustmentReason *domain.AdjustmentReason
+}
+
+type memFreeze struct {
+	accountID uuid.UUID
+	reason    string
+	at        time.Time
+}
+
+type memEntry struct {
+	id            int64
+	transactionID uuid.UUID
+	accountID     uuid.UUID
+	amount        int64
+}
+
+type memHold struct {
+	transactionID uuid.UUID
+	accountID     uuid.UUID
+	amount        int64
+	released      bool
+}
+
+type memStatement struct {
+	accountID uuid.UUID
+	period    string
+	csv, pdf  []byte
+}
+
+type feeKey struct {
+	tier     domain.KYCTier
+	kind     domain.TransactionKind
+	currency string
+}
+
+func (d *memData) clone() *memData {
+	res := &memData{
+		customers:            slices.Clone(d.customers),
+		accounts:             slices.Clone(d.accounts),
+		transactions:         slices.Clone(d.transactions),
+		entries:              slices.Clone(d.entries),
+		holds:                slices.Clone(d.holds),
+		statements:           slices.Clone(d.statements),
+		scheduledTransfers:   slices.Clone(d.scheduledTransfers),
+		webhookSubscriptions: slices.Clone(d.webhookSubscriptions),
+		webhookDeliveries:    slices.Clone(d.webhookDeliveries),
+		freezes:              slices.Clone(d.freezes),
+		screeningHits:        slices.Clone(d.screeningHits),
+		kytAlerts:            slices.Clone(d.kytAlerts),
+		approvals:            slices.Clone(d.approvals),
+		adminUsers:           slices.Clone(d.adminUsers),
+		events:               slices.Clone(d.events),
+		audits:               slices.Clone(d.audits),
+		limits:               make(map[domain.KYCTier][]limits.Limit, len(d.limits)),
+		fees:                 make(map[feeKey]fees.Schedule, len(d.fees)),
+		lastEntryID:          d.lastEntryID,
+	}
+	for i := range res.customers {
+		res.customers[i].timeline = slices.Clone(res.customers[i].timeline)
+		res.customers[i].documents = slices.Clone(res.customers[i].documents)
+	}
+	for tier, l := range d.limits {
+		res.limits[tier] = slices.Clone(l)
+	}
+	for k, s := range d.fees {
+		res.fees[k] = s
+	}
+	return res
+}
+
+// update changes the committed data, waiting for the running unit of work.
+func (m *Memory) update(fn func(d *memData) error) error {
+	m.work.Lock()
+	defer m.work.Unlock()
+	return m.state.do(fn)
+}
+
+func (m *Memory) Customers() Customers { return memCustomers{s: m.state} }
+func (m *Memory) Accounts() Accounts   { return memAccounts{s: m.state} }
+func (m *Memory) ScheduledTransfers() ScheduledTransfers {
+	return memScheduledTransfers{s: m.state}
+}
+func (m *Memory) Webhooks() Webhooks     { return memWebhooks{s: m.state} }
+func (m *Memory) BackOffice() BackOffice { return memBackOffice{s: m.state} }
+
+func (m *Memory) Begin(ctx context.Context, a Audit) (Tx, error) {
+	m.work.Lock()
+
+	var d *memData
+	m.state.do(func(committed *memData) error {
+		d = committed.clone()
+		return nil
+	})
+	d.audits = append(d.audits, a)
+
+	return &memTx{m: m, state: &memState{d: d}}, nil
+}
+
+type memTx struct {
+	m     *Memory
+	state *memState
+	done  bool
+}
+
+func (t *memTx) Customers() Customers { return memCustomers{s: t.state} }
+func (t *memTx) Accounts() Accounts   { return memAccounts{s: t.state} }
+func (t *memTx) Ledger() Ledger       { return memLedger{s: t.state} }
+func (t *memTx) Outbox() Outbox       { return memOutbox{s: t.state} }
+func (t *memTx) ScheduledTransfers() ScheduledTransfers {
+	return memScheduledTransfers{s: t.state}
+}
+func (t *memTx) Webhooks() Webhooks     { return memWebhooks{s: t.state} }
+func (t *memTx) BackOffice() BackOffice { return memBackOffice{s: t.state} }
+
+func (t *memTx) Commit(ctx context.Context) error {
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+
+	t.m.state.do(func(d *memData) error {
+		*d = *t.state.d
+		return nil
+	})
+	t.m.work.Unlock()
+	return nil
+}
+
+func (t *memTx) Rollback(ctx context.Context) error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	t.m.work.Unlock()
+	return nil
+}
+
+// AddCustomer stores a customer as is, e.g. with approved KYC. The tier
+// defaults to basic, like in the customers table.
+func (m *Memory) AddCustomer(c Customer) {
+	if c.KYCTier == "" {
+		c.KYCTier = domain.KYCTierBasic
+	}
+	m.update(func(d *memData) error {
+		d.customers = append(d.customers, memCustomer{Customer: c, updatedAt: time.Now()})
+		return nil
+	})
+}
+
+// AddAccount stores an account and returns it. Unset fields get the
+// defaults of the accounts table, accounts without a customer are internal.
+func (m *Memory) AddAccount(a Account) Account {
+	a = withAccountDefaults(a)
+	if a.CustomerID == nil {
+		a.Kind = domain.AccountKindInternal
+	}
+	m.update(func(d *memData) error {
+		d.accounts = append(d.accounts, a)
+		return nil
+	})
+	return a
+}
+
+// SetLimit configures a limit of a KYC tier, see package limits.
+func (m *Memory) SetLimit(tier domain.KYCTier, l limits.Limit) {
+	m.update(func(d *memData) error {
+		d.limits[tier] = append(d.limits[tier], l)
+		return nil
+	})
+}
+
+// SetFeeSchedule configures a fee, see package fees.
+func (m *Memory) SetFeeSchedule(tier domain.KYCTier, kind domain.TransactionKind, currency string, s fees.Schedule) {
+	m.update(func(d *memData) error {
+		d.fees[feeKey{tier: tier, kind: kind, currency: currency}] = s
+		return nil
+	})
+}
+
+// AddStatement stores the statement of an account for a period, like the
+// month-end job.
+func (m *Memory) AddStatement(accountID uuid.UUID, period string, csv, pdf []byte) {
+	m.update(func(d *memData) error {
+		d.statements = append(d.statements, memStatement{accountID: accountID, period: period, csv: csv, pdf: pdf})
+		return nil
+	})
+}
+
+// Clear posts a pending transaction to account balances, like ledger.Clear.
+func (m *Memory) Clear(id uuid.UUID) error {
+	return m.update(func(d *memData) error { return d.clear(id) })
+}
+
+// Fail marks a pending transaction as failed, like ledger.Fail.
+func (m *Memory) Fail(id uuid.UUID) error {
+	return m.update(func(d *memData) error { return d.fail(id) })
+}
+
+// Transactions returns the stored transactions, oldest first.
+func (m *Memory) Transactions() []ledger.Transaction {
+	var res []ledger.Transaction
+	m.state.do(func(d *memData) error {
+		for _, t := range d.transactions {
+			res = append(res, t.Transaction)
+		}
+		return nil
+	})
+	return res
+}
+
+// Held returns the funds of an account reserved by pending transactions.
+func (m *Memory) Held(accountID uuid.UUID) int64 {
+	var res int64
+	m.state.do(func(d *memData) error {
+		res = d.held(accountID)
+		return nil
+	})
+	return res
+}
+
+// LedgerState returns the committed accounts, transactions, entries and
+// holds.
+func (m *Memory) LedgerState() LedgerState {
+	var res LedgerState
+	m.state.do(func(d *memData) error {
+		res.Accounts = slices.Clone(d.accounts)
+		for _, t := range d.transactions {
+			res.Transactions = append(res.Transactions, t.Transaction)
+		}
+		for _, e := range d.entries {
+			res.Entries = append(res.Entries, LedgerEntry{TransactionID: e.transactionID, AccountID: e.accountID, Amount: e.amount})
+		}
+		for _, h := range d.holds {
+			res.Holds = append(res.Holds, LedgerHold{TransactionID: h.transactionID, AccountID: h.accountID, Amount: h.amount, Released: h.released})
+		}
+		return nil
+	})
+	return res
+}
+
+// Events returns the events written to the outbox, oldest first.
+func (m *Memory) Events() []domain.Event {
+	var res []domain.Event
+	m.state.do(func(d *memData) error {
+		res = slices.Clone(d.events)
+		return nil
+	})
+	return res
+}
+
+// Audits returns the committed units of work, oldest first.
+func (m *Memory) Audits() []Audit {
+	var res []Audit
+	m.state.do(func(d *memData) error {
+		res = slices.Clone(d.audits)
+		return nil
+	})
+	return res
+}
+
+func withAccountDefaults(a Account) Account {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Number == "" {
+		a.Number = uuid.New().String()
+	}
+	if a.Kind == "" {
+		a.Kind = domain.AccountKindCustomer
+	}
+	if a.Product == "" {
+		a.Product = domain.AccountProductCurrent
+	}
+	if a.Status == "" {
+		a.Status = domain.AccountStatusActive
+	}
+	if a.Currency == "" {
+		a.Currency = domain.DefaultCurrency
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	return a
+}
+
+func (d *memData) customer(id uuid.UUID) (*memCustomer, bool) {
+	for i := range d.customers {
+		if d.customers[i].ID == id {
+			return &d.customers[i], true
+		}
+	}
+	return nil, false
+}
+
+// reviewDocuments applies the KYC decision to the documents waiting for
+// review.
+func (c *memCustomer) reviewDocuments(status domain.KYCDocumentStatus, now time.Time) {
+	for i := range c.documents {
+		if c.documents[i].Status == domain.KYCDocumentStatusUploaded {
+			c.documents[i].Status, c.documents[i].ReviewedAt = status, &now
+		}
+	}
+}
+
+func (d *memData) accountIndex(id uuid.UUID) int {
+	return slices.IndexFunc(d.accounts, func(a Account) bool { return a.ID == id })
+}
+
+func (d *memData) transactionIndex(id uuid.UUID) int {
+	return slices.IndexFunc(d.transactions, func(t memTransaction) bool { return t.ID == id })
+}
+
+func (d *memData) pendingTransaction(id uuid.UUID) (int, error) {
+	i := d.transactionIndex(id)
+	if i < 0 {
+		return 0, fmt.Errorf("%w: %s", ledger.ErrTransactionNotFound, id)
+	}
+	if s := d.transactions[i].Status; s != domain.TransactionStatusPending {
+		return 0, fmt.Errorf("%w: %s is %s", ledger.ErrNotPending, id, s)
+	}
+	return i, nil
+}
+
+func (d *memData) clear(id uuid.UUID) error {
+	i, err := d.pendingTransaction(id)
+	if err != nil {
+		return err
+	}
+	t := d.transactions[i].Transaction
+
+	for _, l := range t.Legs {
+		a := d.accounts[d.accountIndex(l.AccountID)]
+		if a.Kind == domain.AccountKindCustomer && a.Balance+l.Amount < 0 {
+			return fmt.Errorf("clearing %s would make account %s negative", id, a.Number)
+		}
+	}
+
+	posted := make([]domain.PostedEntry, 0, len(t.Legs))
+	for _, l := range t.Legs {
+		a := &d.accounts[d.accountIndex(l.AccountID)]
+		a.Balance += l.Amount
+		posted = append(posted, domain.PostedEntry{
+			AccountID:     a.ID,
+			AccountNumber: a.Number,
+			CustomerID:    a.CustomerID,
+			Amount:        l.Amount,
+		})
+	}
+	d.releaseHolds(id)
+	d.transactions[i].Status = domain.TransactionStatusCleared
+
+	return d.writeEvent(domain.EventTypeTransactionPosted, id, domain.TransactionPostedPayload{
+		TransactionID: id,
+		Kind:          t.Kind,
+		Amount:        t.Amount,
+		Currency:      t.Currency,
+		ReversalOf:    t.ReversalOf,
+		Entries:       posted,
+	})
+}
+
+func (d *memData) fail(id uuid.UUID) error {
+	i, err := d.pendingTransaction(id)
+	if err != nil {
+		return err
+	}
+	d.releaseHolds(id)
+	d.transactions[i].Status = domain.TransactionStatusFailed
+	return nil
+}
+
+func (d *memData) byIdempotencyKey(key string) (ledger.Transaction, bool) {
+	for _, t := range d.transactions {
+		if t.IdempotencyKey == key {
+			return t.Transaction, true
+		}
+	}
+	return ledger.Transaction{}, false
+}
+
+func (d *memData) held(accountID uuid.UUID) int64 {
+	var res int64
+	for _, h := range d.holds {
+		if h.accountID == accountID && !h.released {
+			res += h.amount
+		}
+	}
+	return res
+}
+
+func (d *memData) releaseHolds(transactionID uuid.UUID) {
+	for i := range d.holds {
+		if d.holds[i].transactionID == transactionID {
+			d.holds[i].released = true
+		}
+	}
+}
+
+// touchesCustomer reports whether the transaction has an entry on an account
+// of the customer, crediting it if sign is positive or debiting it if
+// negative.
+func (d *memData) touchesCustomer(transactionID, customerID uuid.UUID, sign int64) bool {
+	for _, e := range d.entries {
+		if e.transactionID != transactionID || e.amount*sign <= 0 {
+			continue
+		}
+		if i := d.accountIndex(e.accountID); i >= 0 && d.accounts[i].BelongsTo(customerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitUsage sums up the customer's movements counting towards the limit,
+// like limits.GetUsage.
+func (d *memData) limitUsage(customerID uuid.UUID, l limits.Limit, now time.Time) limits.Usage {
+	// Deposits credit the customer's accounts, the rest debits them.
+	txKind, sign := domain.TransactionKindDeposit, int64(1)
+	switch l.Kind {
+	case domain.LimitKindWithdrawal:
+		txKind, sign = domain.TransactionKindWithdrawal, -1
+	case domain.LimitKindTransferOut:
+		txKind, sign = domain.TransactionKindTransfer, -1
+	}
+	since := limits.PeriodStart(l.Period, now)
+
+	var used int64
+	for _, t := range d.transactions {
+		if t.Kind == txKind && t.Currency == l.Currency && t.Status != domain.TransactionStatusFailed &&
+			!t.createdAt.Before(since) && d.touchesCustomer(t.ID, customerID, sign) {
+			used += t.Amount
+		}
+	}
+	return limits.Usage{Limit: l, Used: used, Remaining: max(l.Amount-used, 0)}
+}
+
+// quoteFee quotes the fee of the customer's movement, like fees.Get.
+func (d *memData) quoteFee(c *memCustomer, kind domain.TransactionKind, currency string, amount int64, now time.Time) fees.Quote {
+	s, ok := d.fees[feeKey{tier: c.KYCTier, kind: kind, currency: currency}]
+	if !ok {
+		return fees.Quote{}
+	}
+
+	monthStart := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	var used int
+	for _, t := range d.transactions {
+		if t.Kind == kind && t.Status != domain.TransactionStatusFailed &&
+			!t.createdAt.Before(monthStart) && d.touchesCustomer(t.ID, c.ID, -1) {
+			used++
+		}
+	}
+
+	return fees.Quote{
+		Fee:           s.Calculate(amount, used),
+		FreeRemaining: max(s.FreePerMonth-used-1, 0),
+	}
+}
+
+func (d *memData) writeEvent(typ domain.EventType, aggregateID uuid.UUID, payload any) error {
+	ev, err := outbox.NewEvent(typ, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	d.events = append(d.events, ev)
+	return nil
+}
+
+type memCustomers struct {
+	s *memState
+}
+
+func (r memCustomers) Create(ctx context.Context, c NewCustomer, actor audit.Actor) error {
+	return r.s.do(func(d *memData) error {
+		for _, other := range d.customers {
+			if other.pii.EmailIndex != nil && bytes.Equal(other.pii.EmailIndex, c.PII.EmailIndex) {
+				return ErrEmailTaken
+			}
+		}
+
+		d.customers = append(d.customers, memCustomer{
+			Customer: Customer{
+				ID:          c.ID,
+				KYCStatus:   domain.KYCStatusPending,
+				KYCTier:     domain.KYCTierBasic,
+				ClientKeyID: c.ClientKeyID,
+			},
+			pii:       c.PII,
+			updatedAt: time.Now(),
+			timeline: []kyc.Event{{
+				To:         domain.KYCStatusPending,
+				Reason:     "customer created",
+				Actor:      actor,
+				OccurredAt: time.Now(),
+			}},
+		})
+		return nil
+	})
+}
+
+func (r memCustomers) Get(ctx context.Context, id uuid.UUID) (res Customer, err error) {
+	err = r.s.do(func(d *memData) error {
+		c, ok := d.customer(id)
+		if !ok {
+			return ErrCustomerNotFound
+		}
+		res = c.Customer
+		return nil
+	})
+	return res, err
+}
+
+func (r memCustomers) KYCTimeline(ctx context.Context, id uuid.UUID) (res []kyc.Event, err error) {
+	err = r.s.do(func(d *memData) error {
+		if c, ok := d.customer(id); ok {
+			res = slices.Clone(c.timeline)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memCustomers) TransitionKYC(ctx context.Context, id uuid.UUID, from, to domain.KYCStatus, reason string, actor audit.Actor) error {
+	return r.s.do(func(d *memData) error {
+		if !from.CanTransitionTo(to) {
+			return fmt.Errorf("%w: %s -> %s", kyc.ErrIllegalTransition, from, to)
+		}
+		c, ok := d.customer(id)
+		if !ok || c.KYCStatus != from {
+			return kyc.ErrStatusChanged
+		}
+		if to == domain.KYCStatusApproved {
+			if missing := kyc.Missing(c.documents); len(missing) > 0 {
+				return fmt.Errorf("%w: %s", kyc.ErrMissingDocuments, strings.Join(missing, ", "))
+			}
+		}
+
+		now := time.Now()
+		c.KYCStatus, c.updatedAt = to, now
+		c.timeline = append(c.timeline, kyc.Event{From: &from, To: to, Reason: reason, Actor: actor, OccurredAt: now})
+
+		switch to {
+		case domain.KYCStatusApproved:
+			c.reviewDocuments(domain.KYCDocumentStatusAccepted, now)
+		case domain.KYCStatusRejected:
+			c.reviewDocuments(domain.KYCDocumentStatusRejected, now)
+		}
+
+		return d.writeEvent(domain.EventTypeKYCStatusChanged, id, domain.KYCStatusChangedPayload{
+			CustomerID: id,
+			OldStatus:  from,
+			NewStatus:  to,
+			Reason:     reason,
+		})
+	})
+}
+
+func (r memCustomers) ChangeTier(ctx context.Context, id uuid.UUID, from, to domain.KYCTier) error {
+	return r.s.do(func(d *memData) error {
+		if !to.Valid() {
+			return fmt.Errorf("unknown KYC tier %q", to)
+		}
+		c, ok := d.customer(id)
+		if !ok || c.KYCTier != from || c.KYCStatus != domain.KYCStatusApproved {
+			return kyc.ErrTierChanged
+		}
+		c.KYCTier, c.updatedAt = to, time.Now()
+		return nil
+	})
+}
+
+func (r memCustomers) AddDocument(ctx context.Context, doc kyc.Document) error {
+	return r.s.do(func(d *memData) error {
+		c, ok := d.customer(doc.CustomerID)
+		if !ok {
+			return ErrCustomerNotFound
+		}
+		c.documents = append(c.documents, doc)
+		return nil
+	})
+}
+
+func (r memCustomers) Documents(ctx context.Context, id uuid.UUID) (res []kyc.Document, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []kyc.Document{}
+		if c, ok := d.customer(id); ok {
+			for i := len(c.documents) - 1; i >= 0; i-- {
+				res = append(res, c.documents[i])
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memCustomers) Limits(ctx context.Context, id uuid.UUID, now time.Time) (tier domain.KYCTier, res []limits.Usage, err error) {
+	err = r.s.do(func(d *memData) error {
+		c, ok := d.customer(id)
+		if !ok {
+			return ErrCustomerNotFound
+		}
+		tier = c.KYCTier
+
+		// Ordered like the kyc_tier_limits query.
+		ls := slices.Clone(d.limits[tier])
+		slices.SortStableFunc(ls, func(a, b limits.Limit) int {
+			if c := cmp.Compare(a.Kind, b.Kind); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(a.Period, b.Period); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Currency, b.Currency)
+		})
+		res = make([]limits.Usage, 0, len(ls))
+		for _, l := range ls {
+			res = append(res, d.limitUsage(id, l, now))
+		}
+		return nil
+	})
+	return tier, res, err
+}
+
+func (r memCustomers) QuoteFee(ctx context.Context, id uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (res fees.Quote, err error) {
+	err = r.s.do(func(d *memData) error {
+		c, ok := d.customer(id)
+		if !ok {
+			return ErrCustomerNotFound
+		}
+		res = d.quoteFee(c, kind, currency, amount, now)
+		return nil
+	})
+	return res, err
+}
+
+type memAccounts struct {
+	s *memState
+}
+
+func (r memAccounts) Create(ctx context.Context, a Account) (res Account, err error) {
+	err = r.s.do(func(d *memData) error {
+		if a.CustomerID == nil {
+			return errors.New("customer_id is required")
+		}
+		if _, ok := d.customer(*a.CustomerID); !ok {
+			return fmt.Errorf("customer %s doesn't exist", a.CustomerID)
+		}
+		for _, other := range d.accounts {
+			if other.ID == a.ID || other.Number == a.Number {
+				return fmt.Errorf("account %s already exists", a.Number)
+			}
+		}
+
+		res = withAccountDefaults(Account{
+			ID:         a.ID,
+			CustomerID: a.CustomerID,
+			Number:     a.Number,
+			Product:    a.Product,
+		})
+		d.accounts = append(d.accounts, res)
+		return nil
+	})
+	return res, err
+}
+
+func (r memAccounts) GetByNumber(ctx context.Context, number string) (res Account, err error) {
+	err = r.s.do(func(d *memData) error {
+		i := slices.IndexFunc(d.accounts, func(a Account) bool { return a.Number == number })
+		if i < 0 {
+			return ErrAccountNotFound
+		}
+		res = d.accounts[i]
+		return nil
+	})
+	return res, err
+}
+
+func (r memAccounts) LockByNumber(ctx context.Context, number string) (Account, error) {
+	// Units of work run one at a time, there's nothing to lock.
+	return r.GetByNumber(ctx, number)
+}
+
+func (r memAccounts) ListByCustomer(ctx context.Context, customerID uuid.UUID) (res []Account, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []Account{}
+		for i := len(d.accounts) - 1; i >= 0; i-- { // Newest first.
+			if d.accounts[i].BelongsTo(customerID) {
+				res = append(res, d.accounts[i])
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memAccounts) History(ctx context.Context, accountID uuid.UUID, after *int64, limit int) (res []AccountEntry, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []AccountEntry{}
+		for i := len(d.entries) - 1; i >= 0 && len(res) < limit; i-- {
+			e := d.entries[i]
+			if e.accountID != accountID || (after != nil && e.id >= *after) {
+				continue
+			}
+
+			t := d.transactions[d.transactionIndex(e.transactionID)]
+			var reversed int64
+			for _, r := range d.transactions {
+				if r.ReversalOf != nil && *r.ReversalOf == t.ID && r.Status != domain.TransactionStatusFailed {
+					reversed += r.Amount
+				}
+			}
+			res = append(res, AccountEntry{
+				EntryID:          e.id,
+				TransactionID:    t.ID,
+				Kind:             t.Kind,
+				Status:           t.Status,
+				Amount:           e.amount,
+				Fee:              t.Fee,
+				Currency:         t.Currency,
+				Description:      t.Description,
+				ReversalOf:       t.ReversalOf,
+				ReversedAmount:   reversed,
+				AdjustmentReason: t.adjustmentReason,
+				CreatedAt:        t.createdAt,
+			})
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memAccounts) Statement(ctx context.Context, accountID uuid.UUID, period string, format statement.Format) (res []byte, found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		for _, st := range d.statements {
+			if st.accountID == accountID && st.period == period {
+				res, found = st.pdf, true
+				if format == statement.FormatCSV {
+					res = st.csv
+				}
+			}
+		}
+		return nil
+	})
+	return res, found, err
+}
+
+func (r memAccounts) Freeze(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.s.do(func(d *memData) error {
+		if i := d.accountIndex(id); i >= 0 {
+			d.accounts[i].Status = domain.AccountStatusFrozen
+			d.freezes = append(d.freezes, memFreeze{accountID: id, reason: reason, at: time.Now()})
+		}
+		return nil
+	})
+}
+
+func (r memAccounts) Unfreeze(ctx context.Context, id uuid.UUID) (unfrozen bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		i := d.accountIndex(id)
+		if i < 0 || d.accounts[i].Status != domain.AccountStatusFrozen {
+			return nil
+		}
+		d.accounts[i].Status, unfrozen = domain.AccountStatusActive, true
+		d.freezes = slices.DeleteFunc(d.freezes, func(f memFreeze) bool { return f.accountID == id })
+		return nil
+	})
+	return unfrozen, err
+}
+
+func (r memAccounts) ListFrozen(ctx context.Context, limit int) (res []FrozenAccount, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []FrozenAccount{}
+		for _, a := range d.accounts {
+			if a.Status != domain.AccountStatusFrozen {
+				continue
+			}
+			// Accounts stored frozen were never frozen through Freeze.
+			f := FrozenAccount{ID: a.ID, Number: a.Number, CustomerID: a.CustomerID, Currency: a.Currency, Balance: a.Balance, FrozenAt: a.CreatedAt}
+			for _, fr := range d.freezes {
+				if fr.accountID == a.ID {
+					reason := fr.reason
+					f.Reason, f.FrozenAt = &reason, fr.at
+				}
+			}
+			res = append(res, f)
+		}
+		slices.SortStableFunc(res, func(a, b FrozenAccount) int {
+			if c := a.FrozenAt.Compare(b.FrozenAt); c != 0 {
+				return c
+			}
+			return bytes.Compare(a.ID[:], b.ID[:])
+		})
+		res = res[:min(len(res), limit)]
+		return nil
+	})
+	return res, err
+}
+
+type memLedger struct {
+	s *memState
+}
+
+func (r memLedger) Initiate(ctx context.Context, t ledger.Transaction) (res ledger.Transaction, created bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		res, created, err = d.initiate(t)
+		return err
+	})
+	return res, created, err
+}
+
+func (d *memData) initiate(t ledger.Transaction) (ledger.Transaction, bool, error) {
+	if err := ledger.Validate(t); err != nil {
+		return ledger.Transaction{}, false, err
+	}
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	t.Status = domain.TransactionStatusPending
+
+	if t.IdempotencyKey != "" {
+		if existing, found := d.byIdempotencyKey(t.IdempotencyKey); found {
+			return existing, false, nil
+		}
+	}
+
+	for _, l := range t.Legs {
+		i := d.accountIndex(l.AccountID)
+		if i < 0 {
+			return ledger.Transaction{}, false, fmt.Errorf("%w: %s", ledger.ErrAccountNotFound, l.AccountID)
+		}
+		a := d.accounts[i]
+		if a.Currency != t.Currency {
+			return ledger.Transaction{}, false, fmt.Errorf("%w: account %s is in %s", ledger.ErrCurrencyMismatch, l.AccountID, a.Currency)
+		}
+		if l.Amount < 0 && a.Status == domain.AccountStatusFrozen {
+			return ledger.Transaction{}, false, fmt.Errorf("%w: %s", ledger.ErrAccountFrozen, a.Number)
+		}
+		if l.Amount < 0 && a.Kind == domain.AccountKindCustomer && a.Balance-d.held(a.ID)+l.Amount < 0 {
+			return ledger.Transaction{}, false, fmt.Errorf("%w on account %s", ledger.ErrInsufficientFunds, a.Number)
+		}
+	}
+
+	t.Legs = slices.Clone(t.Legs)
+	d.transactions = append(d.transactions, memTransaction{Transaction: t, createdAt: time.Now()})
+	for _, l := range t.Legs {
+		d.lastEntryID++
+		d.entries = append(d.entries, memEntry{
+			id:            d.lastEntryID,
+			transactionID: t.ID,
+			accountID:     l.AccountID,
+			amount:        l.Amount,
+		})
+		if l.Amount < 0 && d.accounts[d.accountIndex(l.AccountID)].Kind == domain.AccountKindCustomer {
+			d.holds = append(d.holds, memHold{transactionID: t.ID, accountID: l.AccountID, amount: -l.Amount})
+		}
+	}
+
+	if err := d.writeEvent(domain.EventTypeTransactionInitiated, t.ID, domain.TransactionInitiatedPayload{
+		TransactionID: t.ID,
+		ReversalOf:    t.ReversalOf,
+	}); err != nil {
+		return ledger.Transaction{}, false, err
+	}
+
+	return t, true, nil
+}
+
+func (r memLedger) Reverse(ctx context.Context, rev ledger.Reversal) (res ledger.Transaction, created bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		if rev.IdempotencyKey != "" {
+			if existing, found := d.byIdempotencyKey(rev.IdempotencyKey); found {
+				res = existing
+				return nil
+			}
+		}
+
+		i := d.transactionIndex(rev.TransactionID)
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ledger.ErrTransactionNotFound, rev.TransactionID)
+		}
+		original := d.transactions[i]
+		if original.Status != domain.TransactionStatusCleared {
+			return fmt.Errorf("%w: %s is %s", ledger.ErrNotReversible, original.ID, original.Status)
+		}
+		if original.ReversalOf != nil {
+			return fmt.Errorf("%w: %s is a reversal", ledger.ErrNotReversible, original.ID)
+		}
+		if original.Kind == domain.TransactionKindAdjustment {
+			return fmt.Errorf("%w: %s is a manual adjustment", ledger.ErrNotReversible, original.ID)
+		}
+
+		var reversed int64
+		for _, t := range d.transactions {
+			if t.ReversalOf != nil && *t.ReversalOf == original.ID && t.Status != domain.TransactionStatusFailed {
+				reversed += t.Amount
+			}
+		}
+		left := original.Amount - reversed
+		amount := rev.Amount
+		if amount == 0 {
+			amount = left
+		}
+		if left <= 0 {
+			return fmt.Errorf("%w: %s is fully reversed", ledger.ErrNotReversible, original.ID)
+		}
+		if amount < 0 || amount > left {
+			return fmt.Errorf("%w: %s left", ledger.ErrReversalTooLarge, domain.FormatAmount(left))
+		}
+
+		description := rev.Description
+		if description == "" {
+			description = "Reversal of " + original.ID.String()
+		}
+
+		res, created, err = d.initiate(ledger.Transaction{
+			Kind:           domain.TransactionKindReversal,
+			Amount:         amount,
+			Currency:       original.Currency,
+			Description:    description,
+			IdempotencyKey: rev.IdempotencyKey,
+			ReversalOf:     &original.ID,
+			Legs:           ledger.MirrorLegs(original.Legs, original.Amount, amount),
+		})
+		return err
+	})
+	return res, created, err
+}
+
+func (r memLedger) Clear(ctx context.Context, id uuid.UUID) error {
+	return r.s.do(func(d *memData) error { return d.clear(id) })
+}
+
+func (r memLedger) Fail(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.s.do(func(d *memData) error { return d.fail(id) })
+}
+
+func (r memLedger) PostAdjustment(ctx context.Context, a admin.Approval, p admin.AdjustmentPayload) (res ledger.Transaction, err error) {
+	if err := admin.CanPostAdjustment(a, p); err != nil {
+		return ledger.Transaction{}, err
+	}
+	suspenseID, err := r.InternalAccountID(ctx, domain.InternalAccountSuspense, p.Currency)
+	if err != nil {
+		return ledger.Transaction{}, err
+	}
+
+	err = r.s.do(func(d *memData) error {
+		t, created, err := d.initiate(admin.AdjustmentTransaction(a, p, suspenseID))
+		if err != nil || !created {
+			res = t
+			return err
+		}
+		if err := d.clear(t.ID); err != nil {
+			return err
+		}
+		i := d.transactionIndex(t.ID)
+		d.transactions[i].adjustmentReason = &p.ReasonCode
+		res = d.transactions[i].Transaction
+		return nil
+	})
+	return res, err
+}
+
+func (r memLedger) FindByIdempotencyKey(ctx context.Context, key string) (res ledger.Transaction, found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		res, found = d.byIdempotencyKey(key)
+		return nil
+	})
+	return res, found, err
+}
+
+func (r memLedger) ChargeFee(ctx context.Context, t *ledger.Transaction, payer uuid.UUID, fee int64) error {
+	if fee == 0 {
+		return nil
+	}
+
+	i := slices.IndexFunc(t.Legs, func(l ledger.Leg) bool { return l.AccountID == payer })
+	if i < 0 {
+		return fmt.Errorf("payer %s has no leg", payer)
+	}
+
+	revenueID, err := r.InternalAccountID(ctx, domain.InternalAccountFeeRevenue, t.Currency)
+	if err != nil {
+		return err
+	}
+
+	t.Fee = fee
+	t.Legs[i].Amount -= fee
+	t.Legs = append(t.Legs, ledger.Leg{AccountID: revenueID, Amount: fee})
+	return nil
+}
+
+func (r memLedger) InternalAccountID(ctx context.Context, name domain.InternalAccount, currency string) (res uuid.UUID, err error) {
+	err = r.s.do(func(d *memData) error {
+		number := ledger.InternalAccountNumber(name, currency)
+		if i := slices.IndexFunc(d.accounts, func(a Account) bool { return a.Number == number }); i >= 0 {
+			res = d.accounts[i].ID
+			return nil
+		}
+
+		a := withAccountDefaults(Account{Number: number, Kind: domain.AccountKindInternal, Currency: currency})
+		d.accounts = append(d.accounts, a)
+		res = a.ID
+		return nil
+	})
+	return res, err
+}
+
+func (r memLedger) CreditedCustomer(ctx context.Context, id, customerID uuid.UUID) (ok bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		ok = d.touchesCustomer(id, customerID, 1)
+		return nil
+	})
+	return ok, err
+}
+
+func (r memLedger) CheckLimit(ctx context.Context, customerID uuid.UUID, kind domain.LimitKind, currency string, amount int64, now time.Time) error {
+	return r.s.do(func(d *memData) error {
+		c, ok := d.customer(customerID)
+		if !ok {
+			return limits.ErrCustomerNotFound
+		}
+
+		for _, l := range d.limits[c.KYCTier] {
+			if l.Kind != kind || l.Currency != currency {
+				continue
+			}
+			u := d.limitUsage(customerID, l, now)
+			if amount > u.Remaining {
+				return &limits.ExceededError{Usage: u, Requested: amount}
+			}
+		}
+		return nil
+	})
+}
+
+func (r memLedger) QuoteFee(ctx context.Context, customerID uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (res fees.Quote, err error) {
+	err = r.s.do(func(d *memData) error {
+		c, ok := d.customer(customerID)
+		if !ok {
+			return fees.ErrCustomerNotFound
+		}
+		res = d.quoteFee(c, kind, currency, amount, now)
+		return nil
+	})
+	return res, err
+}
+
+type memOutbox struct {
+	s *memState
+}
+
+func (r memOutbox) Write(ctx context.Context, typ domain.EventType, aggregateID uuid.UUID, payload any) error {
+	return r.s.do(func(d *memData) error {
+		return d.writeEvent(typ, aggregateID, payload)
+	})
+}
@@ -0,0 +1,280 @@
+package repo
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/screening"
+)
+
+type memScreeningHit struct {
+	ScreeningHit
+	customerID uuid.UUID
+	entryID    string
+}
+
+type memKYTAlert struct {
+	id            uuid.UUID
+	transactionID uuid.UUID
+	rule          string
+	detail        string
+	status        domain.KYTAlertStatus
+	resolvedBy    *uuid.UUID
+	note          *string
+	createdAt     time.Time
+}
+
+type memAdminUser struct {
+	domain.AdminUser
+	token string
+}
+
+// AddScreeningHits stores open hits of a customer, like screening.SaveHits.
+func (m *Memory) AddScreeningHits(customerID uuid.UUID, hits ...screening.Hit) {
+	m.update(func(d *memData) error {
+		for _, h := range hits {
+			if slices.ContainsFunc(d.screeningHits, func(other memScreeningHit) bool {
+				return other.customerID == customerID && other.ListName == h.ListName && other.entryID == h.EntryID
+			}) {
+				continue
+			}
+			d.screeningHits = append(d.screeningHits, memScreeningHit{
+				ScreeningHit: ScreeningHit{
+					ID:           uuid.New(),
+					ListName:     h.ListName,
+					EntryName:    h.EntryName,
+					EntryKind:    string(h.Kind),
+					Score:        h.Score,
+					Strength:     string(h.Strength),
+					ReviewStatus: domain.ScreeningReviewStatusOpen,
+				},
+				customerID: customerID,
+				entryID:    h.EntryID,
+			})
+		}
+		return nil
+	})
+}
+
+// ScreeningHits returns the customer's hits, reviewed or not.
+func (m *Memory) ScreeningHits(customerID uuid.UUID) []ScreeningHit {
+	var res []ScreeningHit
+	m.state.do(func(d *memData) error {
+		for _, h := range d.screeningHits {
+			if h.customerID == customerID {
+				res = append(res, h.ScreeningHit)
+			}
+		}
+		return nil
+	})
+	return res
+}
+
+// AddKYTAlert holds a pending transaction back for review, like
+// consumer.ClearTransactions.
+func (m *Memory) AddKYTAlert(transactionID uuid.UUID, rule, detail string) uuid.UUID {
+	id := uuid.New()
+	m.update(func(d *memData) error {
+		d.kytAlerts = append(d.kytAlerts, memKYTAlert{
+			id:            id,
+			transactionID: transactionID,
+			rule:          rule,
+			detail:        detail,
+			status:        domain.KYTAlertStatusOpen,
+			createdAt:     time.Now(),
+		})
+		return nil
+	})
+	return id
+}
+
+// kytAlert joins the alert with its transaction, like kytAlertSelect.
+func (d *memData) kytAlert(a memKYTAlert) KYTAlert {
+	t := d.transactions[d.transactionIndex(a.transactionID)]
+	return KYTAlert{
+		ID:            a.id,
+		TransactionID: a.transactionID,
+		Kind:          t.Kind,
+		Amount:        t.Amount,
+		Currency:      t.Currency,
+		Rule:          a.rule,
+		Detail:        a.detail,
+		Status:        a.status,
+		CreatedAt:     a.createdAt,
+	}
+}
+
+type memBackOffice struct {
+	s *memState
+}
+
+func (r memBackOffice) CreateUser(ctx context.Context, u domain.AdminUser, token string) error {
+	return r.s.do(func(d *memData) error {
+		if slices.ContainsFunc(d.adminUsers, func(other memAdminUser) bool { return other.Email == u.Email }) {
+			return admin.ErrEmailTaken
+		}
+		d.adminUsers = append(d.adminUsers, memAdminUser{AdminUser: u, token: token})
+		return nil
+	})
+}
+
+func (r memBackOffice) FindUserByToken(ctx context.Context, token string) (res domain.AdminUser, found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		for _, u := range d.adminUsers {
+			if u.token == token {
+				res, found = u.AdminUser, true
+			}
+		}
+		return nil
+	})
+	return res, found, err
+}
+
+func (r memBackOffice) KYCReviews(ctx context.Context, limit int) (res []KYCReview, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []KYCReview{}
+		for _, c := range d.customers {
+			if c.KYCStatus != domain.KYCStatusInProgress {
+				continue
+			}
+			review := KYCReview{CustomerID: c.ID, Since: c.updatedAt, PII: pii.Row{Sealed: c.pii}}
+			if keyID := c.pii.KeyID; keyID != "" {
+				review.PII.KeyID = &keyID
+			}
+			for _, h := range d.screeningHits {
+				if h.customerID == c.ID && h.ReviewStatus == domain.ScreeningReviewStatusOpen {
+					review.Hits = append(review.Hits, h.ScreeningHit)
+				}
+			}
+			if len(review.Hits) == 0 {
+				continue
+			}
+			slices.SortStableFunc(review.Hits, func(a, b ScreeningHit) int { return cmp.Compare(b.Score, a.Score) })
+			res = append(res, review)
+		}
+		slices.SortStableFunc(res, func(a, b KYCReview) int {
+			if c := a.Since.Compare(b.Since); c != 0 {
+				return c
+			}
+			return bytes.Compare(a.CustomerID[:], b.CustomerID[:])
+		})
+		res = res[:min(len(res), limit)]
+		return nil
+	})
+	return res, err
+}
+
+func (r memBackOffice) ReviewOpenHits(ctx context.Context, customerID uuid.UUID, status domain.ScreeningReviewStatus) error {
+	return r.s.do(func(d *memData) error {
+		for i := range d.screeningHits {
+			if h := &d.screeningHits[i]; h.customerID == customerID && h.ReviewStatus == domain.ScreeningReviewStatusOpen {
+				h.ReviewStatus = status
+			}
+		}
+		return nil
+	})
+}
+
+func (r memBackOffice) KYTAlerts(ctx context.Context, limit int) (res []KYTAlert, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []KYTAlert{}
+		for _, a := range d.kytAlerts { // Oldest first.
+			if a.status == domain.KYTAlertStatusOpen && len(res) < limit {
+				res = append(res, d.kytAlert(a))
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memBackOffice) LockKYTAlert(ctx context.Context, id uuid.UUID) (res KYTAlert, err error) {
+	err = r.s.do(func(d *memData) error {
+		i := slices.IndexFunc(d.kytAlerts, func(a memKYTAlert) bool { return a.id == id })
+		if i < 0 {
+			return ErrKYTAlertNotFound
+		}
+		res = d.kytAlert(d.kytAlerts[i])
+		return nil
+	})
+	return res, err
+}
+
+func (r memBackOffice) ResolveKYTAlert(ctx context.Context, id uuid.UUID, status domain.KYTAlertStatus, resolvedBy uuid.UUID, note string) error {
+	return r.s.do(func(d *memData) error {
+		for i := range d.kytAlerts {
+			if a := &d.kytAlerts[i]; a.id == id {
+				a.status, a.resolvedBy, a.note = status, &resolvedBy, &note
+			}
+		}
+		return nil
+	})
+}
+
+func (r memBackOffice) RequestApproval(ctx context.Context, kind domain.ApprovalKind, targetID string, payload any, reason string, makerID uuid.UUID) (res admin.Approval, err error) {
+	res, err = admin.NewApproval(kind, targetID, payload, reason, makerID)
+	if err != nil {
+		return admin.Approval{}, err
+	}
+	err = r.s.do(func(d *memData) error {
+		if slices.ContainsFunc(d.approvals, func(a admin.Approval) bool {
+			return a.Kind == kind && a.TargetID == targetID && a.Status == domain.ApprovalStatusPending
+		}) {
+			return fmt.Errorf("%w: %s %s", admin.ErrAlreadyRequested, kind, targetID)
+		}
+		d.approvals = append(d.approvals, res)
+		return nil
+	})
+	if err != nil {
+		return admin.Approval{}, err
+	}
+	return res, nil
+}
+
+func (r memBackOffice) Approvals(ctx context.Context, status domain.ApprovalStatus, limit int) (res []admin.Approval, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []admin.Approval{}
+		for _, a := range d.approvals { // Oldest first.
+			if a.Status == status && len(res) < limit {
+				res = append(res, a)
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memBackOffice) LockApproval(ctx context.Context, id uuid.UUID) (res admin.Approval, err error) {
+	err = r.s.do(func(d *memData) error {
+		i := slices.IndexFunc(d.approvals, func(a admin.Approval) bool { return a.ID == id })
+		if i < 0 {
+			return admin.ErrApprovalNotFound
+		}
+		res = d.approvals[i]
+		return nil
+	})
+	return res, err
+}
+
+func (r memBackOffice) Decide(ctx context.Context, a admin.Approval, checkerID uuid.UUID, approve bool, note string) (admin.Approval, error) {
+	a, err := admin.Decided(a, checkerID, approve, note, time.Now())
+	if err != nil {
+		return a, err
+	}
+	err = r.s.do(func(d *memData) error {
+		if i := slices.IndexFunc(d.approvals, func(other admin.Approval) bool { return other.ID == a.ID }); i >= 0 {
+			d.approvals[i] = a
+		}
+		return nil
+	})
+	return a, err
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+type memScheduledTransfer struct {
+	ScheduledTransfer
+	fromAccountID uuid.UUID
+	toAccountID   uuid.UUID
+}
+
+func (d *memData) scheduledTransfer(id, customerID uuid.UUID) (*memScheduledTransfer, bool) {
+	for i := range d.scheduledTransfers {
+		if s := &d.scheduledTransfers[i]; s.ID == id && s.CustomerID == customerID {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func (d *memData) withAccountNumbers(s memScheduledTransfer) ScheduledTransfer {
+	res := s.ScheduledTransfer
+	if i := d.accountIndex(s.fromAccountID); i >= 0 {
+		res.FromAccount = d.accounts[i].Number
+	}
+	if i := d.accountIndex(s.toAccountID); i >= 0 {
+		res.ToAccount = d.accounts[i].Number
+	}
+	return res
+}
+
+type memScheduledTransfers struct {
+	s *memState
+}
+
+func (r memScheduledTransfers) Create(ctx context.Context, s NewScheduledTransfer) error {
+	return r.s.do(func(d *memData) error {
+		startAt := s.StartAt
+		d.scheduledTransfers = append(d.scheduledTransfers, memScheduledTransfer{
+			ScheduledTransfer: ScheduledTransfer{
+				ID:                  s.ID,
+				CustomerID:          s.CustomerID,
+				Amount:              s.Amount,
+				Currency:            s.Currency,
+				Description:         s.Description,
+				StartAt:             s.StartAt,
+				Recurrence:          s.Recurrence,
+				OnInsufficientFunds: s.OnInsufficientFunds,
+				Status:              domain.ScheduledTransferStatusActive,
+				NextRunAt:           &startAt,
+				CreatedAt:           time.Now(),
+			},
+			fromAccountID: s.FromAccountID,
+			toAccountID:   s.ToAccountID,
+		})
+		return nil
+	})
+}
+
+func (r memScheduledTransfers) ListByCustomer(ctx context.Context, customerID uuid.UUID) (res []ScheduledTransfer, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []ScheduledTransfer{}
+		for i := len(d.scheduledTransfers) - 1; i >= 0; i-- {
+			if s := d.scheduledTransfers[i]; s.CustomerID == customerID {
+				res = append(res, d.withAccountNumbers(s))
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memScheduledTransfers) Pause(ctx context.Context, id, customerID uuid.UUID) (found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		s, ok := d.scheduledTransfer(id, customerID)
+		if !ok || s.Status != domain.ScheduledTransferStatusActive {
+			return nil
+		}
+		s.Status, found = domain.ScheduledTransferStatusPaused, true
+		return nil
+	})
+	return found, err
+}
+
+func (r memScheduledTransfers) Cancel(ctx context.Context, id, customerID uuid.UUID) (found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		s, ok := d.scheduledTransfer(id, customerID)
+		if !ok || (s.Status != domain.ScheduledTransferStatusActive && s.Status != domain.ScheduledTransferStatusPaused) {
+			return nil
+		}
+		s.Status, s.NextRunAt, found = domain.ScheduledTransferStatusCancelled, nil, true
+		return nil
+	})
+	return found, err
+}
+
+func (r memScheduledTransfers) LockPaused(ctx context.Context, id, customerID uuid.UUID) (res ScheduledTransfer, found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		s, ok := d.scheduledTransfer(id, customerID)
+		if !ok || s.Status != domain.ScheduledTransferStatusPaused {
+			return nil
+		}
+		res, found = d.withAccountNumbers(*s), true
+		return nil
+	})
+	return res, found, err
+}
+
+func (r memScheduledTransfers) Resume(ctx context.Context, id uuid.UUID, status domain.ScheduledTransferStatus, nextOccurrence int, next *time.Time) error {
+	return r.s.do(func(d *memData) error {
+		for i := range d.scheduledTransfers {
+			if s := &d.scheduledTransfers[i]; s.ID == id {
+				s.Status, s.NextOccurrence, s.NextRunAt = status, nextOccurrence, next
+			}
+		}
+		return nil
+	})
+}
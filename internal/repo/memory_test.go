@@ -0,0 +1,368 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+var testActor = audit.System("test")
+
+// fundedAccount adds an approved customer with an account holding balance.
+func fundedAccount(m *Memory, balance int64) Account {
+	customerID := uuid.New()
+	m.AddCustomer(Customer{ID: customerID, KYCStatus: domain.KYCStatusApproved})
+	return m.AddAccount(Account{CustomerID: &customerID, Balance: balance})
+}
+
+func transfer(from, to Account, amount int64) ledger.Transaction {
+	return ledger.Transaction{
+		Kind:     domain.TransactionKindTransfer,
+		Amount:   amount,
+		Currency: domain.DefaultCurrency,
+		Legs: []ledger.Leg{
+			{AccountID: from.ID, Amount: -amount},
+			{AccountID: to.ID, Amount: amount},
+		},
+	}
+}
+
+// initiate runs Initiate in its own unit of work and commits it.
+func initiate(t *testing.T, m *Memory, tr ledger.Transaction) (ledger.Transaction, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := m.Begin(ctx, Audit{Actor: testActor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	res, _, err := tx.Ledger().Initiate(ctx, tr)
+	if err != nil {
+		return res, err
+	}
+	return res, tx.Commit(ctx)
+}
+
+func TestMemory_UnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		commit bool
+	}{
+		{name: "commit keeps changes", commit: true},
+		{name: "rollback discards changes", commit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			m := NewMemory()
+			id := uuid.New()
+
+			// Act.
+			tx, err := m.Begin(ctx, Audit{Actor: testActor, Action: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tx.Customers().Create(ctx, NewCustomer{ID: id}, testActor); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tx.Customers().Get(ctx, id); err != nil {
+				t.Fatalf("expected the unit of work to see its own changes, got %v", err)
+			}
+			if _, err := m.Customers().Get(ctx, id); !errors.Is(err, ErrCustomerNotFound) {
+				t.Fatalf("expected changes to be invisible before commit, got %v", err)
+			}
+			if tt.commit {
+				if err := tx.Commit(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tx.Rollback(ctx)
+
+			// Assert.
+			_, err = m.Customers().Get(ctx, id)
+			if tt.commit != (err == nil) {
+				t.Fatalf("expected the customer to exist %v, got %v", tt.commit, err)
+			}
+			if audits := m.Audits(); tt.commit != (len(audits) == 1) {
+				t.Fatalf("expected the unit of work to be audited %v, got %v", tt.commit, audits)
+			}
+		})
+	}
+}
+
+func TestMemoryCustomers_Create_EmailTaken(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := NewMemory()
+	sealed := pii.Sealed{EmailIndex: []byte("index")}
+	tx, _ := m.Begin(ctx, Audit{Actor: testActor})
+	defer tx.Rollback(ctx)
+	if err := tx.Customers().Create(ctx, NewCustomer{ID: uuid.New(), PII: sealed}, testActor); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act.
+	err := tx.Customers().Create(ctx, NewCustomer{ID: uuid.New(), PII: sealed}, testActor)
+
+	// Assert.
+	if !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("expected %v, got %v", ErrEmailTaken, err)
+	}
+}
+
+func TestMemoryLedger_Initiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		arrange func(t *testing.T, m *Memory, from, to Account) ledger.Transaction
+		wantErr error
+	}{
+		{
+			name: "reserves funds",
+			arrange: func(t *testing.T, m *Memory, from, to Account) ledger.Transaction {
+				return transfer(from, to, 100)
+			},
+		},
+		{
+			name: "insufficient funds",
+			arrange: func(t *testing.T, m *Memory, from, to Account) ledger.Transaction {
+				return transfer(from, to, 101)
+			},
+			wantErr: ledger.ErrInsufficientFunds,
+		},
+		{
+			name: "holds count",
+			arrange: func(t *testing.T, m *Memory, from, to Account) ledger.Transaction {
+				if _, err := initiate(t, m, transfer(from, to, 60)); err != nil {
+					t.Fatal(err)
+				}
+				return transfer(from, to, 60)
+			},
+			wantErr: ledger.ErrInsufficientFunds,
+		},
+		{
+			name: "frozen",
+			arrange: func(t *testing.T, m *Memory, from, to Account) ledger.Transaction {
+				frozen := m.AddAccount(Account{CustomerID: from.CustomerID, Balance: 100, Status: domain.AccountStatusFrozen})
+				return transfer(frozen, to, 10)
+			},
+			wantErr: ledger.ErrAccountFrozen,
+		},
+		{
+			name: "currency mismatch",
+			arrange: func(t *testing.T, m *Memory, from, to Account) ledger.Transaction {
+				usd := m.AddAccount(Account{CustomerID: to.CustomerID, Currency: "USD"})
+				return transfer(from, usd, 10)
+			},
+			wantErr: ledger.ErrCurrencyMismatch,
+		},
+		{
+			name: "unknown account",
+			arrange: func(t *testing.T, m *Memory, from, to Account) ledger.Transaction {
+				return transfer(from, Account{ID: uuid.New()}, 10)
+			},
+			wantErr: ledger.ErrAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange.
+			m := NewMemory()
+			from, to := fundedAccount(m, 100), fundedAccount(m, 0)
+			tr := tt.arrange(t, m, from, to)
+
+			// Act.
+			res, err := initiate(t, m, tr)
+
+			// Assert.
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if res.Status != domain.TransactionStatusPending {
+				t.Fatalf("expected pending, got %s", res.Status)
+			}
+			if got := m.Held(from.ID); got != tr.Amount {
+				t.Fatalf("expected %d held, got %d", tr.Amount, got)
+			}
+		})
+	}
+}
+
+func TestMemoryLedger_Initiate_Idempotent(t *testing.T) {
+	// Arrange.
+	m := NewMemory()
+	from, to := fundedAccount(m, 100), fundedAccount(m, 0)
+	tr := transfer(from, to, 10)
+	tr.IdempotencyKey = "key"
+	first, err := initiate(t, m, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act.
+	second, err := initiate(t, m, tr)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("expected %s, got %s", first.ID, second.ID)
+	}
+	if got := len(m.Transactions()); got != 1 {
+		t.Fatalf("expected 1 transaction, got %d", got)
+	}
+}
+
+func TestMemory_Clear(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := NewMemory()
+	from, to := fundedAccount(m, 100), fundedAccount(m, 0)
+	tr, err := initiate(t, m, transfer(from, to, 40))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act.
+	err = m.Clear(tr.ID)
+
+	// Assert.
+	if err != nil {
+		t.Fatal(err)
+	}
+	for number, want := range map[string]int64{from.Number: 60, to.Number: 40} {
+		a, err := m.Accounts().GetByNumber(ctx, number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Balance != want {
+			t.Fatalf("expected balance %d, got %d", want, a.Balance)
+		}
+	}
+	if got := m.Held(from.ID); got != 0 {
+		t.Fatalf("expected holds to be released, got %d", got)
+	}
+	if err := m.Clear(tr.ID); !errors.Is(err, ledger.ErrNotPending) {
+		t.Fatalf("expected %v, got %v", ledger.ErrNotPending, err)
+	}
+}
+
+func TestMemoryLedger_Reverse(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := NewMemory()
+	from, to := fundedAccount(m, 100), fundedAccount(m, 0)
+	tr, err := initiate(t, m, transfer(from, to, 40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Clear(tr.ID); err != nil {
+		t.Fatal(err)
+	}
+	tx, _ := m.Begin(ctx, Audit{Actor: testActor})
+	defer tx.Rollback(ctx)
+
+	// Act.
+	partial, _, err := tx.Ledger().Reverse(ctx, ledger.Reversal{TransactionID: tr.ID, Amount: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, _, err := tx.Ledger().Reverse(ctx, ledger.Reversal{TransactionID: tr.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, tooMuch := tx.Ledger().Reverse(ctx, ledger.Reversal{TransactionID: tr.ID, Amount: 1})
+
+	// Assert.
+	if partial.Amount != 15 || rest.Amount != 25 {
+		t.Fatalf("expected reversals of 15 and 25, got %d and %d", partial.Amount, rest.Amount)
+	}
+	if !errors.Is(tooMuch, ledger.ErrNotReversible) {
+		t.Fatalf("expected %v, got %v", ledger.ErrNotReversible, tooMuch)
+	}
+	credited, err := tx.Ledger().CreditedCustomer(ctx, tr.ID, *to.CustomerID)
+	if err != nil || !credited {
+		t.Fatalf("expected the transfer to credit the receiver, got %v %v", credited, err)
+	}
+}
+
+func TestMemoryLedger_CheckLimit(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := NewMemory()
+	from, to := fundedAccount(m, 1000), fundedAccount(m, 0)
+	m.SetLimit(domain.KYCTierBasic, limits.Limit{
+		Kind:     domain.LimitKindTransferOut,
+		Period:   domain.LimitPeriodDaily,
+		Currency: domain.DefaultCurrency,
+		Amount:   500,
+	})
+	if _, err := initiate(t, m, transfer(from, to, 300)); err != nil {
+		t.Fatal(err)
+	}
+	tx, _ := m.Begin(ctx, Audit{Actor: testActor})
+	defer tx.Rollback(ctx)
+
+	// Act.
+	fits := tx.Ledger().CheckLimit(ctx, *from.CustomerID, domain.LimitKindTransferOut, domain.DefaultCurrency, 200, time.Now())
+	exceeds := tx.Ledger().CheckLimit(ctx, *from.CustomerID, domain.LimitKindTransferOut, domain.DefaultCurrency, 201, time.Now())
+
+	// Assert.
+	if fits != nil {
+		t.Fatal(fits)
+	}
+	var exceeded *limits.ExceededError
+	if !errors.As(exceeds, &exceeded) || exceeded.Remaining != 200 {
+		t.Fatalf("expected 200 remaining, got %v", exceeds)
+	}
+}
+
+func TestMemoryLedger_QuoteFee(t *testing.T) {
+	// Arrange.
+	ctx := context.Background()
+	m := NewMemory()
+	from, to := fundedAccount(m, 1000), fundedAccount(m, 0)
+	m.SetFeeSchedule(domain.KYCTierBasic, domain.TransactionKindTransfer, domain.DefaultCurrency, fees.Schedule{
+		Fixed:        25,
+		FreePerMonth: 1,
+	})
+	tx, _ := m.Begin(ctx, Audit{Actor: testActor})
+	defer tx.Rollback(ctx)
+
+	// Act.
+	free, err := tx.Ledger().QuoteFee(ctx, *from.CustomerID, domain.TransactionKindTransfer, domain.DefaultCurrency, 100, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := tx.Ledger().Initiate(ctx, transfer(from, to, 100)); err != nil {
+		t.Fatal(err)
+	}
+	paid, err := tx.Ledger().QuoteFee(ctx, *from.CustomerID, domain.TransactionKindTransfer, domain.DefaultCurrency, 100, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert.
+	if free.Fee != 0 || paid.Fee != 25 {
+		t.Fatalf("expected fees 0 and 25, got %d and %d", free.Fee, paid.Fee)
+	}
+}
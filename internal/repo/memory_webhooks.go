@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+type memWebhookSubscription struct {
+	WebhookSubscription
+	clientKeyID string
+	secret      string
+}
+
+// AddWebhookDelivery stores a delivery as is, like the webhook relay. Unset
+// CreatedAt and NextAttemptAt default to now.
+func (m *Memory) AddWebhookDelivery(wd WebhookDelivery) WebhookDelivery {
+	if wd.ID == uuid.Nil {
+		wd.ID = uuid.New()
+	}
+	if wd.CreatedAt.IsZero() {
+		wd.CreatedAt = time.Now()
+	}
+	if wd.NextAttemptAt.IsZero() {
+		wd.NextAttemptAt = wd.CreatedAt
+	}
+	m.update(func(d *memData) error {
+		d.webhookDeliveries = append(d.webhookDeliveries, wd)
+		return nil
+	})
+	return wd
+}
+
+// WebhookSecret returns the secret of a subscription, empty if there's none.
+func (m *Memory) WebhookSecret(id uuid.UUID) string {
+	var res string
+	m.state.do(func(d *memData) error {
+		if s, ok := d.webhookSubscription(id); ok {
+			res = s.secret
+		}
+		return nil
+	})
+	return res
+}
+
+func (d *memData) webhookSubscription(id uuid.UUID) (*memWebhookSubscription, bool) {
+	for i := range d.webhookSubscriptions {
+		if d.webhookSubscriptions[i].ID == id {
+			return &d.webhookSubscriptions[i], true
+		}
+	}
+	return nil, false
+}
+
+func (d *memData) ownedSubscription(id uuid.UUID, clientKeyID string) (*memWebhookSubscription, bool) {
+	s, ok := d.webhookSubscription(id)
+	if !ok || s.clientKeyID != clientKeyID {
+		return nil, false
+	}
+	return s, true
+}
+
+// newerDelivery orders deliveries newest first, like the
+// webhook_deliveries queries.
+func newerDelivery(a, b WebhookDelivery) int {
+	if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(b.ID[:], a.ID[:])
+}
+
+type memWebhooks struct {
+	s *memState
+}
+
+func (r memWebhooks) Create(ctx context.Context, s NewWebhookSubscription) error {
+	return r.s.do(func(d *memData) error {
+		d.webhookSubscriptions = append(d.webhookSubscriptions, memWebhookSubscription{
+			WebhookSubscription: WebhookSubscription{
+				ID:         s.ID,
+				URL:        s.URL,
+				EventTypes: slices.Clone(s.EventTypes),
+				Active:     true,
+				CreatedAt:  time.Now(),
+			},
+			clientKeyID: s.ClientKeyID,
+			secret:      s.Secret,
+		})
+		return nil
+	})
+}
+
+func (r memWebhooks) ListByClient(ctx context.Context, clientKeyID string) (res []WebhookSubscription, err error) {
+	err = r.s.do(func(d *memData) error {
+		res = []WebhookSubscription{}
+		for i := len(d.webhookSubscriptions) - 1; i >= 0; i-- {
+			if s := d.webhookSubscriptions[i]; s.clientKeyID == clientKeyID {
+				res = append(res, s.WebhookSubscription)
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memWebhooks) Deactivate(ctx context.Context, id uuid.UUID, clientKeyID string) (found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		s, ok := d.ownedSubscription(id, clientKeyID)
+		if ok {
+			s.Active, found = false, true
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (r memWebhooks) OwnedBy(ctx context.Context, id uuid.UUID, clientKeyID string) (ok bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		_, ok = d.ownedSubscription(id, clientKeyID)
+		return nil
+	})
+	return ok, err
+}
+
+func (r memWebhooks) Deliveries(ctx context.Context, subscriptionID uuid.UUID, status domain.WebhookDeliveryStatus, after *uuid.UUID, limit int) (res []WebhookDelivery, err error) {
+	err = r.s.do(func(d *memData) error {
+		all := slices.Clone(d.webhookDeliveries)
+		slices.SortFunc(all, newerDelivery)
+
+		var cursor *WebhookDelivery
+		if after != nil {
+			if i := slices.IndexFunc(all, func(wd WebhookDelivery) bool { return wd.ID == *after }); i >= 0 {
+				cursor = &all[i]
+			}
+		}
+
+		res = []WebhookDelivery{}
+		for _, wd := range all {
+			if len(res) == limit {
+				break
+			}
+			if wd.SubscriptionID != subscriptionID || (status != "" && wd.Status != status) {
+				continue
+			}
+			// A cursor that doesn't exist matches nothing, like in SQL.
+			if after != nil && (cursor == nil || newerDelivery(*cursor, wd) >= 0) {
+				continue
+			}
+			res = append(res, wd)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (r memWebhooks) Redeliver(ctx context.Context, id, subscriptionID uuid.UUID, clientKeyID string) (found bool, err error) {
+	err = r.s.do(func(d *memData) error {
+		if _, ok := d.ownedSubscription(subscriptionID, clientKeyID); !ok {
+			return nil
+		}
+		for i := range d.webhookDeliveries {
+			if wd := &d.webhookDeliveries[i]; wd.ID == id && wd.SubscriptionID == subscriptionID {
+				wd.Status, wd.Attempts, wd.NextAttemptAt, found = domain.WebhookDeliveryStatusPending, 0, time.Now(), true
+			}
+		}
+		return nil
+	})
+	return found, err
+}
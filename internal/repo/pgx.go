@@ -0,0 +1,328 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/outbox"
+	"github.com/detod/best-wallet/internal/statement"
+)
+
+// querier is satisfied by both pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func NewPgx(
+	db *pgxpool.Pool,
+) *Pgx {
+	return &Pgx{
+		db: db,
+	}
+}
+
+// Pgx is the Store on Postgres, units of work are DB transactions.
+type Pgx struct {
+	db *pgxpool.Pool
+}
+
+func (s *Pgx) Customers() Customers { return pgxCustomers{db: s.db} }
+func (s *Pgx) Accounts() Accounts   { return pgxAccounts{db: s.db} }
+func (s *Pgx) ScheduledTransfers() ScheduledTransfers {
+	return pgxScheduledTransfers{db: s.db}
+}
+func (s *Pgx) Webhooks() Webhooks     { return pgxWebhooks{db: s.db} }
+func (s *Pgx) BackOffice() BackOffice { return pgxBackOffice{db: s.db} }
+
+func (s *Pgx) Begin(ctx context.Context, a Audit) (Tx, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := audit.Set(ctx, tx, a.Actor, a.Action, a.RequestID); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	return pgxTx{tx: tx}, nil
+}
+
+type pgxTx struct {
+	tx pgx.Tx
+}
+
+func (t pgxTx) Customers() Customers { return pgxCustomers{db: t.tx} }
+func (t pgxTx) Accounts() Accounts   { return pgxAccounts{db: t.tx} }
+func (t pgxTx) Ledger() Ledger       { return pgxLedger{tx: t.tx} }
+func (t pgxTx) Outbox() Outbox       { return pgxOutbox{db: t.tx} }
+func (t pgxTx) ScheduledTransfers() ScheduledTransfers {
+	return pgxScheduledTransfers{db: t.tx}
+}
+func (t pgxTx) Webhooks() Webhooks     { return pgxWebhooks{db: t.tx} }
+func (t pgxTx) BackOffice() BackOffice { return pgxBackOffice{db: t.tx} }
+
+func (t pgxTx) Commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
+func (t pgxTx) Rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
+
+type pgxCustomers struct {
+	db querier
+}
+
+func (r pgxCustomers) Create(ctx context.Context, c NewCustomer, actor audit.Actor) error {
+	sql := `
+		INSERT INTO customers (id, first_name_enc, last_name_enc, email_enc, residence_address_enc, birth_date_enc,
+			pii_data_key, pii_kek_id, email_bidx, phone, locale, kyc_status, client_key_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := r.db.Exec(ctx, sql,
+		c.ID,
+		c.PII.FirstName,
+		c.PII.LastName,
+		c.PII.Email,
+		c.PII.ResidenceAddress,
+		c.PII.BirthDate,
+		c.PII.DataKey,
+		c.PII.KeyID,
+		c.PII.EmailIndex,
+		c.Phone,
+		c.Locale,
+		domain.KYCStatusPending,
+		c.ClientKeyID,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "customers_email_bidx_key" {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	return kyc.Created(ctx, r.db, c.ID, actor)
+}
+
+func (r pgxCustomers) Get(ctx context.Context, id uuid.UUID) (Customer, error) {
+	sql := `SELECT kyc_status, kyc_tier, COALESCE(client_key_id, '') FROM customers WHERE id = $1`
+
+	res := Customer{ID: id}
+	err := r.db.QueryRow(ctx, sql, id).Scan(&res.KYCStatus, &res.KYCTier, &res.ClientKeyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Customer{}, ErrCustomerNotFound
+	}
+	return res, err
+}
+
+func (r pgxCustomers) KYCTimeline(ctx context.Context, id uuid.UUID) ([]kyc.Event, error) {
+	return kyc.Timeline(ctx, r.db, id)
+}
+
+func (r pgxCustomers) TransitionKYC(ctx context.Context, id uuid.UUID, from, to domain.KYCStatus, reason string, actor audit.Actor) error {
+	return kyc.Transition(ctx, r.db, id, from, to, reason, actor)
+}
+
+func (r pgxCustomers) ChangeTier(ctx context.Context, id uuid.UUID, from, to domain.KYCTier) error {
+	return kyc.ChangeTier(ctx, r.db, id, from, to)
+}
+
+func (r pgxCustomers) AddDocument(ctx context.Context, d kyc.Document) error {
+	return kyc.InsertDocument(ctx, r.db, d)
+}
+
+func (r pgxCustomers) Documents(ctx context.Context, id uuid.UUID) ([]kyc.Document, error) {
+	return kyc.Documents(ctx, r.db, id)
+}
+
+func (r pgxCustomers) Limits(ctx context.Context, id uuid.UUID, now time.Time) (domain.KYCTier, []limits.Usage, error) {
+	tier, res, err := limits.GetUsage(ctx, r.db, id, now)
+	if errors.Is(err, limits.ErrCustomerNotFound) {
+		return "", nil, ErrCustomerNotFound
+	}
+	return tier, res, err
+}
+
+func (r pgxCustomers) QuoteFee(ctx context.Context, id uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (fees.Quote, error) {
+	res, err := fees.Get(ctx, r.db, id, kind, currency, amount, now)
+	if errors.Is(err, fees.ErrCustomerNotFound) {
+		return fees.Quote{}, ErrCustomerNotFound
+	}
+	return res, err
+}
+
+type pgxAccounts struct {
+	db querier
+}
+
+const accountColumns = `id, customer_id, number, kind, product, status, currency, balance, created_at`
+
+func (r pgxAccounts) Create(ctx context.Context, a Account) (Account, error) {
+	sql := `
+		INSERT INTO accounts (id, customer_id, number, product, balance)
+		VALUES ($1, $2, $3, $4, 0)
+		RETURNING ` + accountColumns
+
+	rows, _ := r.db.Query(ctx, sql, a.ID, a.CustomerID, a.Number, a.Product)
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[Account])
+}
+
+func (r pgxAccounts) GetByNumber(ctx context.Context, number string) (Account, error) {
+	sql := `SELECT ` + accountColumns + ` FROM accounts WHERE number = $1`
+
+	rows, _ := r.db.Query(ctx, sql, number)
+	res, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Account])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, ErrAccountNotFound
+	}
+	return res, err
+}
+
+func (r pgxAccounts) LockByNumber(ctx context.Context, number string) (Account, error) {
+	sql := `SELECT ` + accountColumns + ` FROM accounts WHERE number = $1 FOR UPDATE`
+
+	rows, _ := r.db.Query(ctx, sql, number)
+	res, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[Account])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Account{}, ErrAccountNotFound
+	}
+	return res, err
+}
+
+func (r pgxAccounts) ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]Account, error) {
+	sql := `SELECT ` + accountColumns + ` FROM accounts WHERE customer_id = $1 ORDER BY created_at DESC`
+
+	rows, _ := r.db.Query(ctx, sql, customerID)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Account])
+}
+
+func (r pgxAccounts) History(ctx context.Context, accountID uuid.UUID, after *int64, limit int) ([]AccountEntry, error) {
+	sql := `
+		SELECT e.id AS entry_id, t.id AS transaction_id, t.kind, t.status, e.amount, t.fee, t.currency,
+			t.description, t.reversal_of, t.created_at, la.reason_code AS adjustment_reason,
+			(
+				SELECT COALESCE(SUM(r.amount), 0) FROM transactions r
+				WHERE r.reversal_of = t.id AND r.status <> $2
+			) AS reversed_amount
+		FROM entries e
+		JOIN transactions t ON t.id = e.transaction_id
+		LEFT JOIN ledger_adjustments la ON la.transaction_id = t.id
+		WHERE e.account_id = $1 AND ($3::bigint IS NULL OR e.id < $3)
+		ORDER BY e.id DESC
+		LIMIT $4`
+
+	rows, _ := r.db.Query(ctx, sql, accountID, domain.TransactionStatusFailed, after, limit)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[AccountEntry])
+}
+
+func (r pgxAccounts) Statement(ctx context.Context, accountID uuid.UUID, period string, format statement.Format) ([]byte, bool, error) {
+	return statement.Get(ctx, r.db, accountID, period, format)
+}
+
+func (r pgxAccounts) Freeze(ctx context.Context, id uuid.UUID, reason string) error {
+	sql := `UPDATE accounts SET status = $1, status_reason = $2, frozen_at = now(), updated_at = now() WHERE id = $3`
+
+	_, err := r.db.Exec(ctx, sql, domain.AccountStatusFrozen, reason, id)
+	return err
+}
+
+func (r pgxAccounts) Unfreeze(ctx context.Context, id uuid.UUID) (bool, error) {
+	sql := `
+		UPDATE accounts SET status = $1, status_reason = NULL, frozen_at = NULL, updated_at = now()
+		WHERE id = $2 AND status = $3`
+
+	res, err := r.db.Exec(ctx, sql, domain.AccountStatusActive, id, domain.AccountStatusFrozen)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (r pgxAccounts) ListFrozen(ctx context.Context, limit int) ([]FrozenAccount, error) {
+	sql := `
+		SELECT id, number, customer_id, currency, balance, status_reason, frozen_at FROM accounts
+		WHERE status = $1
+		ORDER BY frozen_at, id
+		LIMIT $2`
+
+	rows, _ := r.db.Query(ctx, sql, domain.AccountStatusFrozen, limit)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[FrozenAccount])
+}
+
+type pgxLedger struct {
+	tx pgx.Tx
+}
+
+func (r pgxLedger) Initiate(ctx context.Context, t ledger.Transaction) (ledger.Transaction, bool, error) {
+	return ledger.Initiate(ctx, r.tx, t)
+}
+
+func (r pgxLedger) Reverse(ctx context.Context, rev ledger.Reversal) (ledger.Transaction, bool, error) {
+	return ledger.Reverse(ctx, r.tx, rev)
+}
+
+func (r pgxLedger) Clear(ctx context.Context, id uuid.UUID) error {
+	return ledger.Clear(ctx, r.tx, id)
+}
+
+func (r pgxLedger) Fail(ctx context.Context, id uuid.UUID, reason string) error {
+	return ledger.Fail(ctx, r.tx, id, reason)
+}
+
+func (r pgxLedger) PostAdjustment(ctx context.Context, a admin.Approval, p admin.AdjustmentPayload) (ledger.Transaction, error) {
+	return admin.PostAdjustment(ctx, r.tx, a, p)
+}
+
+func (r pgxLedger) FindByIdempotencyKey(ctx context.Context, key string) (ledger.Transaction, bool, error) {
+	return ledger.FindByIdempotencyKey(ctx, r.tx, key)
+}
+
+func (r pgxLedger) ChargeFee(ctx context.Context, t *ledger.Transaction, payer uuid.UUID, fee int64) error {
+	return ledger.ChargeFee(ctx, r.tx, t, payer, fee)
+}
+
+func (r pgxLedger) InternalAccountID(ctx context.Context, name domain.InternalAccount, currency string) (uuid.UUID, error) {
+	return ledger.InternalAccountID(ctx, r.tx, name, currency)
+}
+
+func (r pgxLedger) CreditedCustomer(ctx context.Context, id, customerID uuid.UUID) (ok bool, err error) {
+	sql := `
+		SELECT true FROM entries e JOIN accounts a ON a.id = e.account_id
+		WHERE e.transaction_id = $1 AND e.amount > 0 AND a.customer_id = $2
+		LIMIT 1`
+
+	err = r.tx.QueryRow(ctx, sql, id, customerID).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
+
+func (r pgxLedger) CheckLimit(ctx context.Context, customerID uuid.UUID, kind domain.LimitKind, currency string, amount int64, now time.Time) error {
+	return limits.Check(ctx, r.tx, customerID, kind, currency, amount, now)
+}
+
+func (r pgxLedger) QuoteFee(ctx context.Context, customerID uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (fees.Quote, error) {
+	return fees.Get(ctx, r.tx, customerID, kind, currency, amount, now)
+}
+
+type pgxOutbox struct {
+	db querier
+}
+
+func (r pgxOutbox) Write(ctx context.Context, typ domain.EventType, aggregateID uuid.UUID, payload any) error {
+	_, err := outbox.Write(ctx, r.db, typ, aggregateID, payload)
+	return err
+}
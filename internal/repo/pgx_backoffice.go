@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/pii"
+)
+
+type pgxBackOffice struct {
+	db querier
+}
+
+func (r pgxBackOffice) CreateUser(ctx context.Context, u domain.AdminUser, token string) error {
+	return admin.InsertUser(ctx, r.db, u, token)
+}
+
+func (r pgxBackOffice) FindUserByToken(ctx context.Context, token string) (domain.AdminUser, bool, error) {
+	return admin.FindUserByToken(ctx, r.db, token)
+}
+
+func (r pgxBackOffice) KYCReviews(ctx context.Context, limit int) ([]KYCReview, error) {
+	sql := `
+		WITH queue AS (
+			SELECT c.id, c.updated_at, ` + pii.Columns("c") + ` FROM customers c
+			WHERE c.kyc_status = $1 AND EXISTS (
+				SELECT 1 FROM screening_hits h WHERE h.customer_id = c.id AND h.review_status = $2
+			)
+			ORDER BY c.updated_at, c.id
+			LIMIT $3
+		)
+		SELECT q.id, q.updated_at, ` + pii.Columns("q") + `,
+			h.id, h.list_name, h.entry_name, h.entry_kind, h.score, h.strength, h.review_status
+		FROM queue q JOIN screening_hits h ON h.customer_id = q.id AND h.review_status = $2
+		ORDER BY q.updated_at, q.id, h.score DESC`
+
+	rows, err := r.db.Query(ctx, sql, domain.KYCStatusInProgress, domain.ScreeningReviewStatusOpen, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []KYCReview{}
+	for rows.Next() {
+		var review KYCReview
+		var h ScreeningHit
+		targets := append([]any{&review.CustomerID, &review.Since}, review.PII.ScanTargets()...)
+		targets = append(targets, &h.ID, &h.ListName, &h.EntryName, &h.EntryKind, &h.Score, &h.Strength, &h.ReviewStatus)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		if len(res) == 0 || res[len(res)-1].CustomerID != review.CustomerID {
+			res = append(res, review)
+		}
+		last := &res[len(res)-1]
+		last.Hits = append(last.Hits, h)
+	}
+
+	return res, rows.Err()
+}
+
+func (r pgxBackOffice) ReviewOpenHits(ctx context.Context, customerID uuid.UUID, status domain.ScreeningReviewStatus) error {
+	sql := `
+		UPDATE screening_hits SET review_status = $1, updated_at = now()
+		WHERE customer_id = $2 AND review_status = $3`
+
+	_, err := r.db.Exec(ctx, sql, status, customerID, domain.ScreeningReviewStatusOpen)
+	return err
+}
+
+const kytAlertSelect = `
+	SELECT a.id, a.transaction_id, t.kind, t.amount, t.currency, a.rule, a.detail, a.status, a.created_at
+	FROM kyt_alerts a JOIN transactions t ON t.id = a.transaction_id`
+
+func (r pgxBackOffice) KYTAlerts(ctx context.Context, limit int) ([]KYTAlert, error) {
+	sql := kytAlertSelect + `
+		WHERE a.status = $1
+		ORDER BY a.created_at, a.id
+		LIMIT $2`
+
+	rows, _ := r.db.Query(ctx, sql, domain.KYTAlertStatusOpen, limit)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[KYTAlert])
+}
+
+func (r pgxBackOffice) LockKYTAlert(ctx context.Context, id uuid.UUID) (KYTAlert, error) {
+	rows, _ := r.db.Query(ctx, kytAlertSelect+` WHERE a.id = $1 FOR UPDATE OF a`, id)
+	res, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[KYTAlert])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return KYTAlert{}, ErrKYTAlertNotFound
+	}
+	return res, err
+}
+
+func (r pgxBackOffice) ResolveKYTAlert(ctx context.Context, id uuid.UUID, status domain.KYTAlertStatus, resolvedBy uuid.UUID, note string) error {
+	sql := `
+		UPDATE kyt_alerts SET status = $1, resolved_by = $2, resolution_note = $3, resolved_at = now(), updated_at = now()
+		WHERE id = $4`
+
+	_, err := r.db.Exec(ctx, sql, status, resolvedBy, note, id)
+	return err
+}
+
+func (r pgxBackOffice) RequestApproval(ctx context.Context, kind domain.ApprovalKind, targetID string, payload any, reason string, makerID uuid.UUID) (admin.Approval, error) {
+	return admin.RequestApproval(ctx, r.db, kind, targetID, payload, reason, makerID)
+}
+
+func (r pgxBackOffice) Approvals(ctx context.Context, status domain.ApprovalStatus, limit int) ([]admin.Approval, error) {
+	return admin.ListApprovals(ctx, r.db, status, limit)
+}
+
+func (r pgxBackOffice) LockApproval(ctx context.Context, id uuid.UUID) (admin.Approval, error) {
+	return admin.LockApproval(ctx, r.db, id)
+}
+
+func (r pgxBackOffice) Decide(ctx context.Context, a admin.Approval, checkerID uuid.UUID, approve bool, note string) (admin.Approval, error) {
+	return admin.Decide(ctx, r.db, a, checkerID, approve, note)
+}
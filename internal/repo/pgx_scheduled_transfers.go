@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+type pgxScheduledTransfers struct {
+	db querier
+}
+
+const scheduledTransferQuery = `
+	SELECT s.id, s.customer_id, f.number AS from_account, t.number AS to_account, s.amount, s.currency,
+		s.description, s.start_at, s.recurrence, s.on_insufficient_funds, s.status,
+		s.next_occurrence, s.next_attempt_at, s.created_at
+	FROM scheduled_transfers s
+	JOIN accounts f ON f.id = s.from_account_id
+	JOIN accounts t ON t.id = s.to_account_id`
+
+func (r pgxScheduledTransfers) Create(ctx context.Context, s NewScheduledTransfer) error {
+	sql := `
+		INSERT INTO scheduled_transfers (
+			id, customer_id, from_account_id, to_account_id, amount, currency, description,
+			start_at, recurrence, on_insufficient_funds, status, next_occurrence_at, next_attempt_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $8, $8)`
+
+	_, err := r.db.Exec(ctx, sql,
+		s.ID,
+		s.CustomerID,
+		s.FromAccountID,
+		s.ToAccountID,
+		s.Amount,
+		s.Currency,
+		s.Description,
+		s.StartAt,
+		s.Recurrence,
+		s.OnInsufficientFunds,
+		domain.ScheduledTransferStatusActive,
+	)
+	return err
+}
+
+func (r pgxScheduledTransfers) ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]ScheduledTransfer, error) {
+	sql := scheduledTransferQuery + ` WHERE s.customer_id = $1 ORDER BY s.created_at DESC`
+
+	rows, _ := r.db.Query(ctx, sql, customerID)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[ScheduledTransfer])
+}
+
+func (r pgxScheduledTransfers) Pause(ctx context.Context, id, customerID uuid.UUID) (bool, error) {
+	sql := `
+		UPDATE scheduled_transfers SET status = $1, updated_at = now()
+		WHERE id = $2 AND customer_id = $3 AND status = $4`
+
+	res, err := r.db.Exec(ctx, sql,
+		domain.ScheduledTransferStatusPaused,
+		id,
+		customerID,
+		domain.ScheduledTransferStatusActive,
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (r pgxScheduledTransfers) Cancel(ctx context.Context, id, customerID uuid.UUID) (bool, error) {
+	sql := `
+		UPDATE scheduled_transfers SET
+			status = $1,
+			next_occurrence_at = NULL,
+			next_attempt_at = NULL,
+			updated_at = now()
+		WHERE id = $2 AND customer_id = $3 AND status IN ($4, $5)`
+
+	res, err := r.db.Exec(ctx, sql,
+		domain.ScheduledTransferStatusCancelled,
+		id,
+		customerID,
+		domain.ScheduledTransferStatusActive,
+		domain.ScheduledTransferStatusPaused,
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (r pgxScheduledTransfers) LockPaused(ctx context.Context, id, customerID uuid.UUID) (ScheduledTransfer, bool, error) {
+	sql := scheduledTransferQuery + ` WHERE s.id = $1 AND s.customer_id = $2 AND s.status = $3 FOR UPDATE OF s`
+
+	rows, _ := r.db.Query(ctx, sql, id, customerID, domain.ScheduledTransferStatusPaused)
+	res, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[ScheduledTransfer])
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return res, false, nil
+	case err != nil:
+		return res, false, err
+	default:
+		return res, true, nil
+	}
+}
+
+func (r pgxScheduledTransfers) Resume(ctx context.Context, id uuid.UUID, status domain.ScheduledTransferStatus, nextOccurrence int, next *time.Time) error {
+	sql := `
+		UPDATE scheduled_transfers SET
+			status = $1,
+			next_occurrence = $2,
+			next_occurrence_at = $3,
+			next_attempt_at = $3,
+			retries = 0,
+			updated_at = now()
+		WHERE id = $4`
+
+	_, err := r.db.Exec(ctx, sql, status, nextOccurrence, next, id)
+	return err
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+type pgxWebhooks struct {
+	db querier
+}
+
+func (r pgxWebhooks) Create(ctx context.Context, s NewWebhookSubscription) error {
+	sql := `
+		INSERT INTO webhook_subscriptions (id, client_key_id, url, event_types)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := r.db.Exec(ctx, sql, s.ID, s.ClientKeyID, s.URL, s.EventTypes); err != nil {
+		return err
+	}
+
+	// Kept apart so it stays out of the audit log, see
+	// 000032_move_webhook_secrets.
+	sql = `INSERT INTO webhook_subscription_secrets (subscription_id, secret) VALUES ($1, $2)`
+
+	_, err := r.db.Exec(ctx, sql, s.ID, s.Secret)
+	return err
+}
+
+func (r pgxWebhooks) ListByClient(ctx context.Context, clientKeyID string) ([]WebhookSubscription, error) {
+	sql := `
+		SELECT id, url, event_types, active, created_at FROM webhook_subscriptions
+		WHERE client_key_id = $1 ORDER BY created_at DESC`
+
+	rows, _ := r.db.Query(ctx, sql, clientKeyID)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[WebhookSubscription])
+}
+
+func (r pgxWebhooks) Deactivate(ctx context.Context, id uuid.UUID, clientKeyID string) (bool, error) {
+	sql := `
+		UPDATE webhook_subscriptions SET active = false, updated_at = now()
+		WHERE id = $1 AND client_key_id = $2`
+
+	res, err := r.db.Exec(ctx, sql, id, clientKeyID)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (r pgxWebhooks) OwnedBy(ctx context.Context, id uuid.UUID, clientKeyID string) (ok bool, err error) {
+	sql := `SELECT true FROM webhook_subscriptions WHERE id = $1 AND client_key_id = $2`
+
+	err = r.db.QueryRow(ctx, sql, id, clientKeyID).Scan(&ok)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
+
+func (r pgxWebhooks) Deliveries(ctx context.Context, subscriptionID uuid.UUID, status domain.WebhookDeliveryStatus, after *uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	sql := `
+		SELECT id, subscription_id, event_id, event_type, payload, status, attempts, last_status_code, last_error,
+			next_attempt_at, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+			AND ($2 = '' OR status = $2)
+			AND ($3::uuid IS NULL OR (created_at, id) < (SELECT created_at, id FROM webhook_deliveries WHERE id = $3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4`
+
+	rows, _ := r.db.Query(ctx, sql, subscriptionID, status, after, limit)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[WebhookDelivery])
+}
+
+func (r pgxWebhooks) Redeliver(ctx context.Context, id, subscriptionID uuid.UUID, clientKeyID string) (bool, error) {
+	sql := `
+		UPDATE webhook_deliveries d SET status = $1, attempts = 0, next_attempt_at = now(), updated_at = now()
+		FROM webhook_subscriptions s
+		WHERE d.id = $2 AND d.subscription_id = $3 AND s.id = d.subscription_id AND s.client_key_id = $4`
+
+	res, err := r.db.Exec(ctx, sql, domain.WebhookDeliveryStatusPending, id, subscriptionID, clientKeyID)
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() == 1, nil
+}
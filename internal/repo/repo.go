@@ -0,0 +1,234 @@
+// Package repo stores customers, accounts and ledger transactions behind
+// interfaces, so handlers run the same against Postgres (Pgx) and memory
+// (Memory, for tests).
+//
+// Changes go through a unit of work (Tx), the equivalent of a DB transaction:
+// everything done through it is committed, or rolled back, together.
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/admin"
+	"github.com/detod/best-wallet/internal/audit"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/fees"
+	"github.com/detod/best-wallet/internal/kyc"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/limits"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/statement"
+)
+
+var (
+	ErrCustomerNotFound = errors.New("customer not found")
+	ErrAccountNotFound  = errors.New("account not found")
+	ErrEmailTaken       = errors.New("email already registered")
+)
+
+// Store reads outside of a unit of work and begins units of work.
+type Store interface {
+	Customers() Customers
+	Accounts() Accounts
+	ScheduledTransfers() ScheduledTransfers
+	Webhooks() Webhooks
+	BackOffice() BackOffice
+	// Begin starts a unit of work whose changes are attributed to a, see
+	// audit.Set. Always roll it back when done, it's a no-op after Commit.
+	Begin(ctx context.Context, a Audit) (Tx, error)
+}
+
+// Tx is a unit of work.
+type Tx interface {
+	Customers() Customers
+	Accounts() Accounts
+	Ledger() Ledger
+	Outbox() Outbox
+	ScheduledTransfers() ScheduledTransfers
+	Webhooks() Webhooks
+	BackOffice() BackOffice
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// Audit is who and what a unit of work's changes are attributed to.
+type Audit struct {
+	Actor     audit.Actor
+	Action    string
+	RequestID string
+}
+
+type Customer struct {
+	ID        uuid.UUID
+	KYCStatus domain.KYCStatus
+	KYCTier   domain.KYCTier
+	// ClientKeyID is the client app that registered the customer, only it can
+	// act for the customer and get the customer's events. Empty for some old
+	// customers.
+	ClientKeyID string
+}
+
+type NewCustomer struct {
+	ID          uuid.UUID
+	PII         pii.Sealed
+	Phone       string
+	Locale      string
+	ClientKeyID string
+}
+
+type Customers interface {
+	// Create stores a customer with pending KYC and starts their KYC
+	// timeline. Returns ErrEmailTaken if the email is registered already.
+	Create(ctx context.Context, c NewCustomer, actor audit.Actor) error
+	// Get returns ErrCustomerNotFound if there's no such customer.
+	Get(ctx context.Context, id uuid.UUID) (Customer, error)
+	// KYCTimeline returns the customer's KYC steps, oldest first.
+	KYCTimeline(ctx context.Context, id uuid.UUID) ([]kyc.Event, error)
+	// TransitionKYC moves the customer from one KYC status to another, see
+	// kyc.Transition for the rules and errors.
+	TransitionKYC(ctx context.Context, id uuid.UUID, from, to domain.KYCStatus, reason string, actor audit.Actor) error
+	// ChangeTier moves an approved customer from one KYC tier to another,
+	// see kyc.ChangeTier.
+	ChangeTier(ctx context.Context, id uuid.UUID, from, to domain.KYCTier) error
+	// AddDocument stores a KYC document of the customer, its file goes to
+	// the blob store first, see kyc.SaveFile.
+	AddDocument(ctx context.Context, d kyc.Document) error
+	// Documents returns the customer's KYC documents, newest first.
+	Documents(ctx context.Context, id uuid.UUID) ([]kyc.Document, error)
+	// Limits returns the customer's KYC tier and the usage of all their
+	// limits, see limits.GetUsage. Returns ErrCustomerNotFound if there's no
+	// such customer.
+	Limits(ctx context.Context, id uuid.UUID, now time.Time) (domain.KYCTier, []limits.Usage, error)
+	// QuoteFee quotes the fee of a movement the customer would make now, see
+	// fees.Get. Returns ErrCustomerNotFound if there's no such customer.
+	QuoteFee(ctx context.Context, id uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (fees.Quote, error)
+}
+
+type Account struct {
+	ID         uuid.UUID             `db:"id"`
+	CustomerID *uuid.UUID            `db:"customer_id"` // Nil for internal accounts.
+	Number     string                `db:"number"`
+	Kind       domain.AccountKind    `db:"kind"`
+	Product    domain.AccountProduct `db:"product"`
+	Status     domain.AccountStatus  `db:"status"`
+	Currency   string                `db:"currency"`
+	Balance    int64                 `db:"balance"` // Cleared, in minor units.
+	CreatedAt  time.Time             `db:"created_at"`
+}
+
+// BelongsTo reports whether the account is a personal account of the
+// customer.
+func (a Account) BelongsTo(customerID uuid.UUID) bool {
+	return a.CustomerID != nil && *a.CustomerID == customerID
+}
+
+// FrozenAccount is an account that can't be debited, see Accounts.Freeze.
+type FrozenAccount struct {
+	ID         uuid.UUID  `db:"id"`
+	Number     string     `db:"number"`
+	CustomerID *uuid.UUID `db:"customer_id"`
+	Currency   string     `db:"currency"`
+	Balance    int64      `db:"balance"`
+	Reason     *string    `db:"status_reason"`
+	FrozenAt   time.Time  `db:"frozen_at"`
+}
+
+// AccountEntry is an entry of an account's history, with its transaction.
+type AccountEntry struct {
+	EntryID        int64                    `db:"entry_id"`
+	TransactionID  uuid.UUID                `db:"transaction_id"`
+	Kind           domain.TransactionKind   `db:"kind"`
+	Status         domain.TransactionStatus `db:"status"`
+	Amount         int64                    `db:"amount"` // Negative for debits, includes the fee.
+	Fee            int64                    `db:"fee"`
+	Currency       string                   `db:"currency"`
+	Description    string                   `db:"description"`
+	ReversalOf     *uuid.UUID               `db:"reversal_of"`
+	ReversedAmount int64                    `db:"reversed_amount"` // Counting pending reversals.
+	// AdjustmentReason is set on manual adjustments made by operators.
+	AdjustmentReason *domain.AdjustmentReason `db:"adjustment_reason"`
+	CreatedAt        time.Time                `db:"created_at"`
+}
+
+type Accounts interface {
+	// Create opens a personal account of a customer with the account
+	// defaults (active, EUR, nothing on it) and returns it. Only ID,
+	// CustomerID, Number and Product of a are used.
+	Create(ctx context.Context, a Account) (Account, error)
+	// GetByNumber returns ErrAccountNotFound if there's no such account.
+	GetByNumber(ctx context.Context, number string) (Account, error)
+	// LockByNumber is GetByNumber, the account stays locked until the unit
+	// of work ends.
+	LockByNumber(ctx context.Context, number string) (Account, error)
+	// ListByCustomer returns the customer's accounts, newest first.
+	ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]Account, error)
+	// History returns up to limit entries of the account older than the
+	// entry after (if set), newest first.
+	History(ctx context.Context, accountID uuid.UUID, after *int64, limit int) ([]AccountEntry, error)
+	// Statement returns the account's statement of a period in the format,
+	// found is false until the month-end job stored it, see
+	// statement.Generate.
+	Statement(ctx context.Context, accountID uuid.UUID, period string, format statement.Format) (body []byte, found bool, err error)
+	// Freeze stops money going out of the account.
+	Freeze(ctx context.Context, id uuid.UUID, reason string) error
+	// Unfreeze reactivates a frozen account and reports whether it was
+	// frozen.
+	Unfreeze(ctx context.Context, id uuid.UUID) (bool, error)
+	// ListFrozen returns up to limit frozen accounts, longest frozen first.
+	ListFrozen(ctx context.Context, limit int) ([]FrozenAccount, error)
+}
+
+// Ledger moves money, see package ledger. The limits and fees of movements
+// are part of it since they're checked in the same unit of work.
+type Ledger interface {
+	Initiate(ctx context.Context, t ledger.Transaction) (ledger.Transaction, bool, error)
+	Reverse(ctx context.Context, r ledger.Reversal) (ledger.Transaction, bool, error)
+	// Clear posts a pending transaction, see ledger.Clear.
+	Clear(ctx context.Context, id uuid.UUID) error
+	// Fail fails a pending transaction, see ledger.Fail.
+	Fail(ctx context.Context, id uuid.UUID, reason string) error
+	// PostAdjustment posts an approved adjustment, see admin.PostAdjustment.
+	PostAdjustment(ctx context.Context, a admin.Approval, p admin.AdjustmentPayload) (ledger.Transaction, error)
+	FindByIdempotencyKey(ctx context.Context, key string) (ledger.Transaction, bool, error)
+	ChargeFee(ctx context.Context, t *ledger.Transaction, payer uuid.UUID, fee int64) error
+	InternalAccountID(ctx context.Context, name domain.InternalAccount, currency string) (uuid.UUID, error)
+	// CreditedCustomer reports whether the transaction credited an account
+	// of the customer.
+	CreditedCustomer(ctx context.Context, id, customerID uuid.UUID) (bool, error)
+	// CheckLimit returns a *limits.ExceededError if the movement doesn't fit
+	// in the customer's limits, see limits.Check.
+	CheckLimit(ctx context.Context, customerID uuid.UUID, kind domain.LimitKind, currency string, amount int64, now time.Time) error
+	// QuoteFee quotes the fee of a movement, see fees.Get.
+	QuoteFee(ctx context.Context, customerID uuid.UUID, kind domain.TransactionKind, currency string, amount int64, now time.Time) (fees.Quote, error)
+}
+
+// Outbox writes events, see package outbox.
+type Outbox interface {
+	Write(ctx context.Context, typ domain.EventType, aggregateID uuid.UUID, payload any) error
+}
+
+// LedgerState is everything a store knows about money, for checking the
+// ledger's invariants in tests, see package repotest.
+type LedgerState struct {
+	Accounts     []Account
+	Transactions []ledger.Transaction // Legs aren't needed, see Entries.
+	Entries      []LedgerEntry
+	Holds        []LedgerHold
+}
+
+type LedgerEntry struct {
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	Amount        int64
+}
+
+type LedgerHold struct {
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	Amount        int64
+	Released      bool
+}
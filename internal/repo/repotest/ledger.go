@@ -0,0 +1,309 @@
+// Package repotest checks that every repo.Store keeps the ledger's
+// invariants, so the in-memory store the handler tests run on can't drift
+// from the Postgres one unnoticed.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/ledger"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+// LedgerStore is a repo.Store the simulation can seed, clear and fail
+// transactions on (like the clearing consumer) and read back. repo.Memory
+// is one, the integration tests wrap repo.Pgx.
+type LedgerStore interface {
+	repo.Store
+	AddCustomer(c repo.Customer)
+	AddAccount(a repo.Account) repo.Account
+	Clear(id uuid.UUID) error
+	Fail(id uuid.UUID) error
+	LedgerState() repo.LedgerState
+}
+
+// TestLedgerInvariants runs random sequences of deposits, withdrawals,
+// transfers and reversals across many accounts, clearing or failing them in
+// random order, and checks after every step what must hold whatever the
+// sequence. Every seed gets a fresh store from newStore, a failing seed
+// replays the same sequence.
+func TestLedgerInvariants(t *testing.T, newStore func(t *testing.T) LedgerStore, seeds int64, steps int) {
+	for seed := int64(1); seed <= seeds; seed++ {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			// Arrange.
+			s := newLedgerSim(t, newStore(t), seed)
+
+			for step := 0; step < steps; step++ {
+				// Act.
+				op := s.step()
+
+				// Assert.
+				if err := CheckLedgerInvariants(s.store.LedgerState()); err != nil {
+					t.Fatalf("step %d (%s): %s", step, op, err)
+				}
+			}
+		})
+	}
+}
+
+// ledgerSim moves money around a store at random, the way the handlers and
+// the clearing consumer do.
+type ledgerSim struct {
+	t          *testing.T
+	rng        *rand.Rand
+	store      LedgerStore
+	settlement uuid.UUID
+	accounts   []repo.Account // Of customers, one of them is frozen.
+}
+
+func newLedgerSim(t *testing.T, store LedgerStore, seed int64) *ledgerSim {
+	s := &ledgerSim{t: t, rng: rand.New(rand.NewSource(seed)), store: store}
+
+	s.work(func(ctx context.Context, l repo.Ledger) (err error) {
+		s.settlement, err = l.InternalAccountID(ctx, domain.InternalAccountSettlement, domain.DefaultCurrency)
+		return err
+	})
+	for i := 0; i < 3+s.rng.Intn(6); i++ {
+		customerID := uuid.New()
+		s.store.AddCustomer(repo.Customer{ID: customerID, KYCStatus: domain.KYCStatusApproved})
+		for j := 0; j < 1+s.rng.Intn(2); j++ {
+			s.accounts = append(s.accounts, s.store.AddAccount(repo.Account{CustomerID: &customerID}))
+		}
+	}
+	frozenID := uuid.New()
+	s.store.AddCustomer(repo.Customer{ID: frozenID, KYCStatus: domain.KYCStatusApproved})
+	s.accounts = append(s.accounts, s.store.AddAccount(repo.Account{CustomerID: &frozenID, Status: domain.AccountStatusFrozen}))
+
+	return s
+}
+
+// step makes a random move and describes it.
+func (s *ledgerSim) step() string {
+	switch n := s.rng.Intn(100); {
+	case n < 20:
+		a, amount := s.account(), s.amount()
+		s.initiate(domain.TransactionKindDeposit, amount, 0, []ledger.Leg{
+			{AccountID: s.settlement, Amount: -amount},
+			{AccountID: a, Amount: amount},
+		})
+		return fmt.Sprintf("deposit %d to %s", amount, a)
+	case n < 35:
+		a, amount, fee := s.account(), s.amount(), s.fee()
+		s.initiate(domain.TransactionKindWithdrawal, amount, fee, []ledger.Leg{
+			{AccountID: a, Amount: -amount},
+			{AccountID: s.settlement, Amount: amount},
+		})
+		return fmt.Sprintf("withdraw %d with a fee of %d from %s", amount, fee, a)
+	case n < 55:
+		from, to, amount, fee := s.account(), s.account(), s.amount(), s.fee()
+		if from == to {
+			return "nothing, transfer to the same account"
+		}
+		s.initiate(domain.TransactionKindTransfer, amount, fee, []ledger.Leg{
+			{AccountID: from, Amount: -amount},
+			{AccountID: to, Amount: amount},
+		})
+		return fmt.Sprintf("transfer %d with a fee of %d from %s to %s", amount, fee, from, to)
+	case n < 65:
+		original, ok := s.transaction(domain.TransactionStatusCleared)
+		if !ok {
+			return "nothing, no cleared transaction"
+		}
+		var amount int64 // All that's left.
+		if s.rng.Intn(2) == 0 {
+			amount = 1 + s.rng.Int63n(original.Amount)
+		}
+		s.reverse(original.ID, amount)
+		return fmt.Sprintf("reverse %d of %s", amount, original.ID)
+	case n < 95:
+		t, ok := s.transaction(domain.TransactionStatusPending)
+		if !ok {
+			return "nothing, no pending transaction"
+		}
+		if err := s.store.Clear(t.ID); err != nil {
+			s.t.Fatalf("clear %s: %s", t.ID, err)
+		}
+		return fmt.Sprintf("clear %s", t.ID)
+	default:
+		t, ok := s.transaction(domain.TransactionStatusPending)
+		if !ok {
+			return "nothing, no pending transaction"
+		}
+		if err := s.store.Fail(t.ID); err != nil {
+			s.t.Fatalf("fail %s: %s", t.ID, err)
+		}
+		return fmt.Sprintf("fail %s", t.ID)
+	}
+}
+
+func (s *ledgerSim) account() uuid.UUID {
+	return s.accounts[s.rng.Intn(len(s.accounts))].ID
+}
+
+func (s *ledgerSim) amount() int64 {
+	return 1 + s.rng.Int63n(10000)
+}
+
+// fee is zero half of the time.
+func (s *ledgerSim) fee() int64 {
+	if s.rng.Intn(2) == 0 {
+		return 0
+	}
+	return 1 + s.rng.Int63n(100)
+}
+
+// transaction picks a transaction with the status, reversals aren't
+// reversible so they're skipped when picking cleared ones.
+func (s *ledgerSim) transaction(status domain.TransactionStatus) (ledger.Transaction, bool) {
+	var candidates []ledger.Transaction
+	for _, t := range s.store.LedgerState().Transactions {
+		if t.Status == status && (status != domain.TransactionStatusCleared || t.ReversalOf == nil) {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		return ledger.Transaction{}, false
+	}
+	return candidates[s.rng.Intn(len(candidates))], true
+}
+
+// initiate creates the transaction in a unit of work, charging the fee to
+// the first leg. Refusals the API expects are rolled back.
+func (s *ledgerSim) initiate(kind domain.TransactionKind, amount, fee int64, legs []ledger.Leg) {
+	s.work(func(ctx context.Context, l repo.Ledger) error {
+		t := ledger.Transaction{Kind: kind, Amount: amount, Currency: domain.DefaultCurrency, Legs: legs}
+		if err := l.ChargeFee(ctx, &t, legs[0].AccountID, fee); err != nil {
+			return err
+		}
+		_, _, err := l.Initiate(ctx, t)
+		return err
+	})
+}
+
+func (s *ledgerSim) reverse(id uuid.UUID, amount int64) {
+	s.work(func(ctx context.Context, l repo.Ledger) error {
+		_, _, err := l.Reverse(ctx, ledger.Reversal{TransactionID: id, Amount: amount})
+		return err
+	})
+}
+
+func (s *ledgerSim) work(fn func(ctx context.Context, l repo.Ledger) error) {
+	ctx := context.Background()
+	tx, err := s.store.Begin(ctx, repo.Audit{})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = fn(ctx, tx.Ledger())
+	switch {
+	case errors.Is(err, ledger.ErrInsufficientFunds), errors.Is(err, ledger.ErrAccountFrozen),
+		errors.Is(err, ledger.ErrNotReversible), errors.Is(err, ledger.ErrReversalTooLarge):
+		return
+	case err != nil:
+		s.t.Fatalf("unexpected error: %s", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// CheckLedgerInvariants checks what must hold for any committed state:
+//   - Money is conserved, balances sum up to zero.
+//   - Customer balances never go negative, neither with holds.
+//   - A balance is the sum of the entries of its cleared transactions.
+//   - Transactions are balanced and only pending ones hold funds.
+//   - Reversals never give back more than the original moved, per account.
+func CheckLedgerInvariants(s repo.LedgerState) error {
+	status := make(map[uuid.UUID]domain.TransactionStatus, len(s.Transactions))
+	for _, t := range s.Transactions {
+		status[t.ID] = t.Status
+	}
+	held := make(map[uuid.UUID]int64)
+	for _, h := range s.Holds {
+		if !h.Released {
+			held[h.AccountID] += h.Amount
+		}
+	}
+
+	var total int64
+	posted := make(map[uuid.UUID]int64)
+	for _, e := range s.Entries {
+		if status[e.TransactionID] == domain.TransactionStatusCleared {
+			posted[e.AccountID] += e.Amount
+		}
+	}
+	for _, a := range s.Accounts {
+		total += a.Balance
+		if a.Kind == domain.AccountKindCustomer && a.Balance-held[a.ID] < 0 {
+			return fmt.Errorf("account %s has %d with %d held", a.ID, a.Balance, held[a.ID])
+		}
+		if a.Balance != posted[a.ID] {
+			return fmt.Errorf("account %s has %d, its entries sum up to %d", a.ID, a.Balance, posted[a.ID])
+		}
+	}
+	if total != 0 {
+		return fmt.Errorf("balances sum up to %d", total)
+	}
+
+	perTransaction := make(map[uuid.UUID]int64)
+	moved := make(map[uuid.UUID]map[uuid.UUID]int64) // By transaction and account.
+	for _, e := range s.Entries {
+		perTransaction[e.TransactionID] += e.Amount
+		if moved[e.TransactionID] == nil {
+			moved[e.TransactionID] = make(map[uuid.UUID]int64)
+		}
+		moved[e.TransactionID][e.AccountID] += e.Amount
+	}
+	for id, sum := range perTransaction {
+		if sum != 0 {
+			return fmt.Errorf("transaction %s is unbalanced by %d", id, sum)
+		}
+	}
+	for _, h := range s.Holds {
+		if !h.Released && status[h.TransactionID] != domain.TransactionStatusPending {
+			return fmt.Errorf("%s transaction %s still holds funds", status[h.TransactionID], h.TransactionID)
+		}
+	}
+
+	for _, original := range s.Transactions {
+		if original.ReversalOf != nil {
+			continue
+		}
+		var reversed int64
+		refunded := make(map[uuid.UUID]int64)
+		for _, r := range s.Transactions {
+			if r.ReversalOf == nil || *r.ReversalOf != original.ID || r.Status == domain.TransactionStatusFailed {
+				continue
+			}
+			reversed += r.Amount
+			for accountID, amount := range moved[r.ID] {
+				refunded[accountID] += amount
+			}
+		}
+		if reversed > original.Amount {
+			return fmt.Errorf("transaction %s of %d is reversed by %d", original.ID, original.Amount, reversed)
+		}
+		for accountID, amount := range moved[original.ID] {
+			if r := refunded[accountID]; r*amount > 0 || abs(r) > abs(amount) {
+				return fmt.Errorf("transaction %s moved %d on account %s, its reversals %d", original.ID, amount, accountID, r)
+			}
+		}
+	}
+
+	return nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// ScheduledTransfer is a one-off transfer in the future or a recurring one,
+// executed by job.ExecuteScheduledTransfers.
+type ScheduledTransfer struct {
+	ID                  uuid.UUID                      `db:"id"`
+	CustomerID          uuid.UUID                      `db:"customer_id"`
+	FromAccount         string                         `db:"from_account"` // Number.
+	ToAccount           string                         `db:"to_account"`   // Number.
+	Amount              int64                          `db:"amount"`
+	Currency            string                         `db:"currency"`
+	Description         string                         `db:"description"`
+	StartAt             time.Time                      `db:"start_at"`
+	Recurrence          *string                        `db:"recurrence"` // Nil for one-off transfers.
+	OnInsufficientFunds domain.InsufficientFundsPolicy `db:"on_insufficient_funds"`
+	Status              domain.ScheduledTransferStatus `db:"status"`
+	NextOccurrence      int                            `db:"next_occurrence"` // 0 based.
+	NextRunAt           *time.Time                     `db:"next_attempt_at"`
+	CreatedAt           time.Time                      `db:"created_at"`
+}
+
+type NewScheduledTransfer struct {
+	ID                  uuid.UUID
+	CustomerID          uuid.UUID
+	FromAccountID       uuid.UUID
+	ToAccountID         uuid.UUID
+	Amount              int64
+	Currency            string
+	Description         string
+	StartAt             time.Time
+	Recurrence          *string
+	OnInsufficientFunds domain.InsufficientFundsPolicy
+}
+
+// ScheduledTransfers only finds the scheduled transfers of the customer
+// passed in, someone else's are as good as not found. Changes wait for the
+// scheduler if it's executing the transfer right now.
+type ScheduledTransfers interface {
+	// Create stores an active scheduled transfer whose first occurrence runs
+	// at StartAt.
+	Create(ctx context.Context, s NewScheduledTransfer) error
+	// ListByCustomer returns the customer's scheduled transfers, newest
+	// first.
+	ListByCustomer(ctx context.Context, customerID uuid.UUID) ([]ScheduledTransfer, error)
+	// Pause pauses an active scheduled transfer and reports whether there
+	// was one.
+	Pause(ctx context.Context, id, customerID uuid.UUID) (bool, error)
+	// Cancel cancels an active or paused scheduled transfer and reports
+	// whether there was one.
+	Cancel(ctx context.Context, id, customerID uuid.UUID) (bool, error)
+	// LockPaused returns a paused scheduled transfer, locked until the unit
+	// of work ends, and reports whether there was one.
+	LockPaused(ctx context.Context, id, customerID uuid.UUID) (ScheduledTransfer, bool, error)
+	// Resume sets the status and the next occurrence (nil next if there's
+	// none left) of a scheduled transfer, starting its retries over.
+	Resume(ctx context.Context, id uuid.UUID, status domain.ScheduledTransferStatus, nextOccurrence int, next *time.Time) error
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// WebhookSubscription is a client app's subscription to events, see package
+// webhook.
+type WebhookSubscription struct {
+	ID         uuid.UUID          `db:"id"`
+	URL        string             `db:"url"`
+	EventTypes []domain.EventType `db:"event_types"`
+	Active     bool               `db:"active"`
+	CreatedAt  time.Time          `db:"created_at"`
+}
+
+type NewWebhookSubscription struct {
+	ID          uuid.UUID
+	ClientKeyID string
+	URL         string
+	EventTypes  []domain.EventType
+	Secret      string // Base64, see webhook.Sign.
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription.
+type WebhookDelivery struct {
+	ID             uuid.UUID                    `db:"id"`
+	SubscriptionID uuid.UUID                    `db:"subscription_id"`
+	EventID        uuid.UUID                    `db:"event_id"`
+	EventType      domain.EventType             `db:"event_type"`
+	Payload        json.RawMessage              `db:"payload"`
+	Status         domain.WebhookDeliveryStatus `db:"status"`
+	Attempts       int                          `db:"attempts"`
+	LastStatusCode *int                         `db:"last_status_code"`
+	LastError      *string                      `db:"last_error"`
+	NextAttemptAt  time.Time                    `db:"next_attempt_at"`
+	DeliveredAt    *time.Time                   `db:"delivered_at"`
+	CreatedAt      time.Time                    `db:"created_at"`
+}
+
+// Webhooks only finds the subscriptions of the client app passed in, someone
+// else's are as good as not found.
+type Webhooks interface {
+	// Create stores an active subscription.
+	Create(ctx context.Context, s NewWebhookSubscription) error
+	// ListByClient returns the client app's subscriptions, newest first.
+	ListByClient(ctx context.Context, clientKeyID string) ([]WebhookSubscription, error)
+	// Deactivate stops deliveries to a subscription and reports whether
+	// there was one.
+	Deactivate(ctx context.Context, id uuid.UUID, clientKeyID string) (bool, error)
+	// OwnedBy reports whether the subscription belongs to the client app.
+	OwnedBy(ctx context.Context, id uuid.UUID, clientKeyID string) (bool, error)
+	// Deliveries returns up to limit deliveries of a subscription older than
+	// the delivery after (if set), newest first. An empty status means all.
+	Deliveries(ctx context.Context, subscriptionID uuid.UUID, status domain.WebhookDeliveryStatus, after *uuid.UUID, limit int) ([]WebhookDelivery, error)
+	// Redeliver makes a delivery pending again, due now and with a fresh set
+	// of attempts, and reports whether there was one.
+	Redeliver(ctx context.Context, id, subscriptionID uuid.UUID, clientKeyID string) (bool, error)
+}
@@ -0,0 +1,175 @@
+// Package router maps the HTTP API to its handlers. The server and the tests
+// that exercise the real routing build it the same way.
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/detod/best-wallet/internal/blob"
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/handler"
+	"github.com/detod/best-wallet/internal/middleware"
+	"github.com/detod/best-wallet/internal/openapi"
+	"github.com/detod/best-wallet/internal/pii"
+	"github.com/detod/best-wallet/internal/repo"
+)
+
+// Config holds what the handlers depend on.
+type Config struct {
+	DB *pgxpool.Pool
+	// Store defaults to the repo on DB, tests set a repo.Memory.
+	Store     repo.Store
+	PII       *pii.Cipher
+	Documents blob.BlobStore
+	HMACKeys  middleware.HMACKeyFetcher
+}
+
+// New returns the router serving the public API (/api/v1), the back office
+// (/admin/v1) and the API docs. Every route must be documented in
+// openapi/openapi.json.
+func New(conf Config) *gin.Engine {
+	store := conf.Store
+	if store == nil {
+		store = repo.NewPgx(conf.DB)
+	}
+
+	// Handlers.
+	createCustomer := handler.NewCreateCustomer(store, conf.PII)
+	getCustomerKYC := handler.NewGetCustomerKYC(store)
+	uploadKYCDocument := handler.NewUploadKYCDocument(store, conf.Documents)
+	listKYCDocuments := handler.NewListKYCDocuments(store)
+	createAccount := handler.NewCreateAccount(store)
+	listAccounts := handler.NewListAccounts(store)
+	deposit := handler.NewDeposit(store)
+	withdraw := handler.NewWithdraw(store)
+	transfer := handler.NewTransfer(store)
+	listAccountTransactions := handler.NewListAccountTransactions(store)
+	getStatement := handler.NewGetStatement(store)
+	getLimits := handler.NewGetLimits(store)
+	quoteFee := handler.NewQuoteFee(store)
+	reverseTransaction := handler.NewReverseTransaction(store)
+	createScheduledTransfer := handler.NewCreateScheduledTransfer(store)
+	listScheduledTransfers := handler.NewListScheduledTransfers(store)
+	pauseScheduledTransfer := handler.NewPauseScheduledTransfer(store)
+	resumeScheduledTransfer := handler.NewResumeScheduledTransfer(store)
+	cancelScheduledTransfer := handler.NewCancelScheduledTransfer(store)
+	createWebhook := handler.NewCreateWebhook(store)
+	listWebhooks := handler.NewListWebhooks(store)
+	deleteWebhook := handler.NewDeleteWebhook(store)
+	listWebhookDeliveries := handler.NewListWebhookDeliveries(store)
+	redeliverWebhook := handler.NewRedeliverWebhook(store)
+
+	// Back office handlers.
+	adminListKYCReviews := handler.NewAdminListKYCReviews(store, conf.PII)
+	adminListKYTAlerts := handler.NewAdminListKYTAlerts(store)
+	adminListFrozenAccounts := handler.NewAdminListFrozenAccounts(store)
+	adminRequestKYCApproval := handler.NewAdminRequestKYCApproval(store)
+	adminRejectKYC := handler.NewAdminRejectKYC(store)
+	adminRequestKYCTierChange := handler.NewAdminRequestKYCTierChange(store)
+	adminClearKYTAlert := handler.NewAdminResolveKYTAlert(store, domain.KYTAlertStatusCleared)
+	adminBlockKYTAlert := handler.NewAdminResolveKYTAlert(store, domain.KYTAlertStatusBlocked)
+	adminFreezeAccount := handler.NewAdminFreezeAccount(store)
+	adminRequestUnfreeze := handler.NewAdminRequestUnfreeze(store)
+	adminRequestAdjustment := handler.NewAdminRequestAdjustment(store)
+	adminListApprovals := handler.NewAdminListApprovals(store)
+	adminApprove := handler.NewAdminDecideApproval(store, true)
+	adminReject := handler.NewAdminDecideApproval(store, false)
+	adminCreateUser := handler.NewAdminCreateUser(store)
+
+	// Middleware.
+	hmacVerifier := middleware.HMACVerifier(conf.HMACKeys)
+	fetchCustomerOwner := func(ctx context.Context, customerID uuid.UUID) (string, bool, error) {
+		customer, err := store.Customers().Get(ctx, customerID)
+		if errors.Is(err, repo.ErrCustomerNotFound) {
+			return "", false, nil
+		}
+		return customer.ClientKeyID, err == nil, err
+	}
+	ownCustomer := middleware.RequireOwnCustomerHeader(fetchCustomerOwner)
+	ownCustomerParam := middleware.RequireOwnCustomerParam(fetchCustomerOwner, "id")
+	adminAuth := middleware.AdminAuth(func(ctx context.Context, token string) (domain.AdminUser, bool, error) {
+		return store.BackOffice().FindUserByToken(ctx, token)
+	})
+	viewer := middleware.RequireAdminRole(domain.AdminRoleViewer)
+	operator := middleware.RequireAdminRole(domain.AdminRoleOperator)
+	administrator := middleware.RequireAdminRole(domain.AdminRoleAdmin)
+
+	// Routing.
+	r := gin.Default()
+	r.Use(middleware.RequestID())
+	v1 := r.Group("/api/v1")
+	{
+		v1.POST("/customers", hmacVerifier, createCustomer.Handle)                                    // Create customer.
+		v1.GET("/customers/:id/kyc", hmacVerifier, ownCustomerParam, getCustomerKYC.Handle)           // KYC status with its timeline.
+		v1.POST("/customers/:id/documents", hmacVerifier, ownCustomerParam, uploadKYCDocument.Handle) // Upload a KYC document (multipart).
+		v1.GET("/customers/:id/documents", hmacVerifier, ownCustomerParam, listKYCDocuments.Handle)   // KYC documents and what's missing.
+
+		v1.POST("/accounts", hmacVerifier, ownCustomer, createAccount.Handle)                               // Open a new personal account for a customer.
+		v1.GET("/accounts", hmacVerifier, ownCustomer, listAccounts.Handle)                                 // List all accounts for a customer.
+		v1.POST("/accounts/:number/deposit", hmacVerifier, ownCustomer, deposit.Handle)                     // Money coming into the wallet.
+		v1.POST("/accounts/:number/withdraw", hmacVerifier, ownCustomer, withdraw.Handle)                   // Money leaving the wallet.
+		v1.POST("/accounts/transfer", hmacVerifier, ownCustomer, transfer.Handle)                           // Money moving within the wallet.
+		v1.GET("/accounts/:number/transactions", hmacVerifier, ownCustomer, listAccountTransactions.Handle) // Transaction history.
+		v1.GET("/accounts/:number/statements/:period", hmacVerifier, ownCustomer, getStatement.Handle)      // Monthly statement, PDF or CSV.
+
+		v1.GET("/limits", hmacVerifier, ownCustomer, getLimits.Handle)    // Transaction limits of the customer and their usage.
+		v1.GET("/fees/quote", hmacVerifier, ownCustomer, quoteFee.Handle) // What a transfer or withdrawal would cost.
+
+		v1.POST("/transactions/:id/reverse", hmacVerifier, ownCustomer, reverseTransaction.Handle) // Refund a received transaction, fully or partially.
+
+		v1.POST("/scheduled-transfers", hmacVerifier, ownCustomer, createScheduledTransfer.Handle)            // Schedule a one-off or recurring transfer.
+		v1.GET("/scheduled-transfers", hmacVerifier, ownCustomer, listScheduledTransfers.Handle)              // List the customer's scheduled transfers.
+		v1.POST("/scheduled-transfers/:id/pause", hmacVerifier, ownCustomer, pauseScheduledTransfer.Handle)   // Stop executing until resumed.
+		v1.POST("/scheduled-transfers/:id/resume", hmacVerifier, ownCustomer, resumeScheduledTransfer.Handle) // Continue with the next future occurrence.
+		v1.POST("/scheduled-transfers/:id/cancel", hmacVerifier, ownCustomer, cancelScheduledTransfer.Handle) // Stop for good.
+
+		v1.POST("/webhooks", hmacVerifier, createWebhook.Handle)                                          // Subscribe the client app to events.
+		v1.GET("/webhooks", hmacVerifier, listWebhooks.Handle)                                            // List the client app's subscriptions.
+		v1.DELETE("/webhooks/:id", hmacVerifier, deleteWebhook.Handle)                                    // Unsubscribe.
+		v1.GET("/webhooks/:id/deliveries", hmacVerifier, listWebhookDeliveries.Handle)                    // Delivery log.
+		v1.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", hmacVerifier, redeliverWebhook.Handle) // Manual redelivery.
+	}
+
+	// Back office. Sensitive actions only request an approval, another
+	// operator has to approve it before it takes effect.
+	adminV1 := r.Group("/admin/v1", adminAuth)
+	{
+		adminV1.GET("/queues/kyc-reviews", viewer, adminListKYCReviews.Handle)         // Customers with open screening hits.
+		adminV1.GET("/queues/kyt-alerts", viewer, adminListKYTAlerts.Handle)           // Transactions held back by KYT.
+		adminV1.GET("/queues/frozen-accounts", viewer, adminListFrozenAccounts.Handle) // Accounts that can't be debited.
+
+		adminV1.POST("/customers/:id/kyc/approve", operator, adminRequestKYCApproval.Handle)   // Dismiss hits and approve (needs approval).
+		adminV1.POST("/customers/:id/kyc/reject", operator, adminRejectKYC.Handle)             // Confirm hits and reject.
+		adminV1.POST("/customers/:id/kyc/tier", operator, adminRequestKYCTierChange.Handle)    // Move to another tier (needs approval).
+		adminV1.POST("/kyt-alerts/:id/clear", operator, adminClearKYTAlert.Handle)             // False positive, clear the transaction.
+		adminV1.POST("/kyt-alerts/:id/block", operator, adminBlockKYTAlert.Handle)             // Fail the transaction.
+		adminV1.POST("/accounts/:number/freeze", operator, adminFreezeAccount.Handle)          // Stop money going out.
+		adminV1.POST("/accounts/:number/unfreeze", operator, adminRequestUnfreeze.Handle)      // Lift a freeze (needs approval).
+		adminV1.POST("/accounts/:number/adjustments", operator, adminRequestAdjustment.Handle) // Correct a balance via suspense (needs approval).
+
+		adminV1.GET("/approvals", viewer, adminListApprovals.Handle)          // Approval requests, pending by default.
+		adminV1.POST("/approvals/:id/approve", operator, adminApprove.Handle) // Checker approves, the action is executed.
+		adminV1.POST("/approvals/:id/reject", operator, adminReject.Handle)   // Checker rejects.
+
+		adminV1.POST("/users", administrator, adminCreateUser.Handle) // Add a back office user.
+	}
+
+	// TODO deep healthchecks.
+	r.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "pong",
+		})
+	})
+
+	// API docs.
+	r.GET("/openapi.json", openapi.ServeSpec) // OpenAPI 3 document.
+	r.GET("/docs", openapi.ServeDocs)         // Docs UI.
+
+	return r
+}
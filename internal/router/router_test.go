@@ -0,0 +1,62 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/detod/best-wallet/internal/openapi"
+)
+
+func TestRoutesDocumented(t *testing.T) {
+	// Arrange.
+	r := New(Config{})
+	ops, err := openapi.Operations()
+	if err != nil {
+		t.Fatalf("can't read the OpenAPI document: %s", err)
+	}
+	documented := map[openapi.Operation]bool{}
+	for _, op := range ops {
+		documented[op] = true
+	}
+
+	// Act.
+	routes := r.Routes()
+
+	// Assert.
+	registered := map[openapi.Operation]bool{}
+	for _, route := range routes {
+		op := openapi.Operation{Method: route.Method, Path: route.Path}
+		registered[op] = true
+		if !documented[op] {
+			t.Errorf("%s %s is not documented in openapi.json", route.Method, route.Path)
+		}
+	}
+	for op := range documented {
+		if !registered[op] {
+			t.Errorf("%s %s is documented in openapi.json but not registered", op.Method, op.Path)
+		}
+	}
+}
+
+func TestServeSpec(t *testing.T) {
+	// Arrange.
+	r := New(Config{})
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+	// Act.
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	// Assert.
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected response code %d, got %d", http.StatusOK, resp.Code)
+	}
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &doc); err != nil || doc.OpenAPI == "" {
+		t.Fatalf("expected an OpenAPI document, got %v: %s", err, resp.Body)
+	}
+}
@@ -0,0 +1,126 @@
+package screening
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName lowercases the name, strips diacritics and punctuation and
+// collapses whitespace, so "  Jean-Luc  Picárd" becomes "jean luc picard".
+func NormalizeName(name string) string {
+	var b strings.Builder
+	space := true // Avoid leading spaces.
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r): // Combining marks i.e. diacritics.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// NameSimilarity returns a score in [0, 1] describing how similar 2 names are.
+// It's insensitive to token order ("Doe John" vs "John Doe") and tolerates a
+// missing middle name, while still penalizing it slightly.
+func NameSimilarity(a, b string) float64 {
+	ta, tb := strings.Fields(NormalizeName(a)), strings.Fields(NormalizeName(b))
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	sorted := JaroWinkler(sortedJoin(ta), sortedJoin(tb))
+
+	// Match every token of the shorter name to its best counterpart.
+	short, long := ta, tb
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	var sum float64
+	for _, s := range short {
+		var best float64
+		for _, l := range long {
+			if sim := JaroWinkler(s, l); sim > best {
+				best = sim
+			}
+		}
+		sum += best
+	}
+	penalty := 0.05 * float64(len(long)-len(short))
+	tokens := sum/float64(len(short)) - penalty
+
+	if tokens > sorted {
+		return tokens
+	}
+	return sorted
+}
+
+// JaroWinkler computes the Jaro-Winkler similarity of 2 strings in [0, 1].
+func JaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA, matchedB := make([]bool, len(ra)), make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func sortedJoin(tokens []string) string {
+	s := append([]string(nil), tokens...)
+	sort.Strings(s)
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,75 @@
+package screening
+
+import (
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	// Act.
+	got := NormalizeName("  Jean-Luc  PICÁRD ")
+
+	// Assert.
+	if want := "jean luc picard"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestJaroWinkler_Identical(t *testing.T) {
+	// Act.
+	sim := JaroWinkler("martha", "martha")
+
+	// Assert.
+	if sim != 1 {
+		t.Fatalf("expected 1, got %f", sim)
+	}
+}
+
+func TestJaroWinkler_KnownValue(t *testing.T) {
+	// Act.
+	sim := JaroWinkler("martha", "marhta")
+
+	// Assert.
+	if sim < 0.9611 || sim > 0.9612 { // Textbook example.
+		t.Fatalf("expected ~0.9611, got %f", sim)
+	}
+}
+
+func TestJaroWinkler_NothingInCommon(t *testing.T) {
+	// Act.
+	sim := JaroWinkler("abc", "xyz")
+
+	// Assert.
+	if sim != 0 {
+		t.Fatalf("expected 0, got %f", sim)
+	}
+}
+
+func TestNameSimilarity_TokenOrderDoesNotMatter(t *testing.T) {
+	// Act.
+	sim := NameSimilarity("Doe John", "john doe")
+
+	// Assert.
+	if sim != 1 {
+		t.Fatalf("expected 1, got %f", sim)
+	}
+}
+
+func TestNameSimilarity_MissingMiddleName(t *testing.T) {
+	// Act.
+	sim := NameSimilarity("Ivan Testerov", "Ivan Examplovich Testerov")
+
+	// Assert.
+	if sim < 0.9 || sim >= 1 {
+		t.Fatalf("expected high but imperfect similarity, got %f", sim)
+	}
+}
+
+func TestNameSimilarity_DifferentNames(t *testing.T) {
+	// Act.
+	sim := NameSimilarity("John Doe", "Maria Sampleton")
+
+	// Assert.
+	if sim > 0.7 {
+		t.Fatalf("expected low similarity, got %f", sim)
+	}
+}
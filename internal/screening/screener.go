@@ -0,0 +1,154 @@
+package screening
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+type HitStrength string
+
+const (
+	// HitStrengthStrong is a confident match that blocks KYC approval.
+	HitStrengthStrong HitStrength = "strong"
+	// HitStrengthWeak is a possible match that needs a human to take a look.
+	HitStrengthWeak HitStrength = "weak"
+)
+
+// Subject is the person being screened.
+type Subject struct {
+	Name      string
+	BirthDate time.Time // Zero value if unknown.
+}
+
+// Hit is a match between a subject and a watchlist entry.
+type Hit struct {
+	ListName  string
+	EntryID   string
+	EntryName string // The entry name (or alias) that matched best.
+	Kind      EntryKind
+	Score     float64
+	Strength  HitStrength
+}
+
+// Thresholds control how match scores (in [0, 1]) are classified.
+type Thresholds struct {
+	Strong float64 // Scores >= Strong are strong hits.
+	Review float64 // Scores >= Review (and < Strong) are weak hits.
+}
+
+var DefaultThresholds = Thresholds{Strong: 0.93, Review: 0.85}
+
+func (t Thresholds) Validate() error {
+	if t.Review <= 0 || t.Strong > 1 || t.Review > t.Strong {
+		return errors.New("thresholds must satisfy 0 < review <= strong <= 1")
+	}
+	return nil
+}
+
+// Screener matches subjects against all loaded watchlists. It's safe for
+// concurrent use, lists can be (re)loaded while screening is in progress.
+type Screener struct {
+	thresholds Thresholds
+
+	mu    sync.RWMutex
+	lists map[string]*Watchlist
+}
+
+func NewScreener(thresholds Thresholds) *Screener {
+	return &Screener{
+		thresholds: thresholds,
+		lists:      make(map[string]*Watchlist),
+	}
+}
+
+// Load adds the list, replacing a previously loaded list with the same name.
+func (s *Screener) Load(l *Watchlist) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists[l.Name] = l
+}
+
+// Loaded reports whether at least one list is loaded. Screening against no
+// lists always comes back clean, callers may want to treat it as an error.
+func (s *Screener) Loaded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.lists) > 0
+}
+
+// Screen returns all hits for the subject, strongest first.
+//
+// Sanction hits are classified as strong or weak based on the thresholds.
+// PEP (politically exposed person) hits are never strong, being a PEP isn't
+// prohibited, but it always requires a manual (enhanced) review.
+func (s *Screener) Screen(sub Subject) []Hit {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hits []Hit
+	for _, l := range s.lists {
+		for _, e := range l.Entries {
+			name, score := scoreEntry(sub, e)
+			if score < s.thresholds.Review {
+				continue
+			}
+
+			strength := HitStrengthWeak
+			if score >= s.thresholds.Strong && e.Kind == EntryKindSanction {
+				strength = HitStrengthStrong
+			}
+
+			hits = append(hits, Hit{
+				ListName:  l.Name,
+				EntryID:   e.ID,
+				EntryName: name,
+				Kind:      e.Kind,
+				Score:     score,
+				Strength:  strength,
+			})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits
+}
+
+// scoreEntry combines the best name similarity with the birth date evidence.
+// A matching birth date nudges the score up, a conflicting one pulls it down
+// considerably since it's strong evidence we're looking at a namesake.
+func scoreEntry(sub Subject, e Entry) (string, float64) {
+	var bestName string
+	var score float64
+	for _, n := range e.Names {
+		if sim := NameSimilarity(sub.Name, n); sim > score {
+			bestName, score = n, sim
+		}
+	}
+
+	if sub.BirthDate.IsZero() || len(e.BirthDates) == 0 {
+		return bestName, score
+	}
+
+	adj := -0.15
+	for _, bd := range e.BirthDates {
+		if m := matchBirthDate(sub.BirthDate, bd); m > adj {
+			adj = m
+		}
+	}
+
+	return bestName, min(1, max(0, score+adj))
+}
+
+func matchBirthDate(t time.Time, bd BirthDate) float64 {
+	if t.Year() != bd.Year ||
+		(bd.Month != 0 && int(t.Month()) != bd.Month) ||
+		(bd.Day != 0 && t.Day() != bd.Day) {
+		return -0.15
+	}
+	if bd.Month == 0 || bd.Day == 0 { // Partial dates are weaker evidence.
+		return 0.02
+	}
+	return 0.05
+}
@@ -0,0 +1,118 @@
+package screening
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestScreener() *Screener {
+	s := NewScreener(DefaultThresholds)
+	s.Load(&Watchlist{
+		Name: "test",
+		Entries: []Entry{
+			{
+				ID:         "S1",
+				Kind:       EntryKindSanction,
+				Names:      []string{"Ivan Examplovich Testerov", "Vanya Testerov"},
+				BirthDates: []BirthDate{{Year: 1961, Month: 4, Day: 12}},
+			},
+			{
+				ID:    "P1",
+				Kind:  EntryKindPEP,
+				Names: []string{"Jonas Placeholder"},
+			},
+		},
+	})
+	return s
+}
+
+func TestScreener_StrongHit(t *testing.T) {
+	// Arrange.
+	s := newTestScreener()
+
+	// Act.
+	hits := s.Screen(Subject{
+		Name:      "Ivan Testerov",
+		BirthDate: time.Date(1961, 4, 12, 0, 0, 0, 0, time.UTC),
+	})
+
+	// Assert.
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+	if hits[0].EntryID != "S1" || hits[0].Strength != HitStrengthStrong {
+		t.Fatalf("expected strong hit on S1, got %+v", hits[0])
+	}
+}
+
+func TestScreener_ConflictingBirthDateWeakensHit(t *testing.T) {
+	// Arrange.
+	s := newTestScreener()
+
+	// Act.
+	hits := s.Screen(Subject{
+		Name:      "Ivan Examplovich Testerov",
+		BirthDate: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	// Assert.
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+	if hits[0].Strength != HitStrengthWeak {
+		t.Fatalf("expected weak hit, got %s", hits[0].Strength)
+	}
+}
+
+func TestScreener_PEPHitIsNeverStrong(t *testing.T) {
+	// Arrange.
+	s := newTestScreener()
+
+	// Act.
+	hits := s.Screen(Subject{Name: "Jonas Placeholder"})
+
+	// Assert.
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+	if hits[0].Kind != EntryKindPEP || hits[0].Strength != HitStrengthWeak {
+		t.Fatalf("expected weak PEP hit, got %+v", hits[0])
+	}
+}
+
+func TestScreener_NoHit(t *testing.T) {
+	// Arrange.
+	s := newTestScreener()
+
+	// Act.
+	hits := s.Screen(Subject{Name: "Maria Sampleton"})
+
+	// Assert.
+	if len(hits) != 0 {
+		t.Fatalf("expected no hits, got %+v", hits)
+	}
+}
+
+func TestScreener_LoadReplacesListWithSameName(t *testing.T) {
+	// Arrange.
+	s := newTestScreener()
+
+	// Act.
+	s.Load(&Watchlist{Name: "test"})
+	hits := s.Screen(Subject{Name: "Ivan Examplovich Testerov"})
+
+	// Assert.
+	if len(hits) != 0 {
+		t.Fatalf("expected no hits after replacing the list, got %+v", hits)
+	}
+}
+
+func TestThresholds_Validate(t *testing.T) {
+	// Act.
+	err := Thresholds{Strong: 0.8, Review: 0.9}.Validate()
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
@@ -0,0 +1,53 @@
+package screening
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+// Execer is satisfied by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// SaveHits stores hits for a customer so they can be reviewed. A hit that's
+// already stored (same customer, list and entry) is left untouched, so
+// re-screening doesn't reopen reviews that were already closed.
+func SaveHits(ctx context.Context, db Execer, customerID uuid.UUID, hits []Hit) error {
+	sql := `
+		INSERT INTO screening_hits (id, customer_id, list_name, entry_id, entry_name, entry_kind, score, strength, review_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (customer_id, list_name, entry_id) DO NOTHING`
+
+	for _, h := range hits {
+		if _, err := db.Exec(ctx, sql,
+			uuid.New(),
+			customerID,
+			h.ListName,
+			h.EntryID,
+			h.EntryName,
+			h.Kind,
+			h.Score,
+			h.Strength,
+			domain.ScreeningReviewStatusOpen,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasStrongHit reports whether any of the hits is strong.
+func HasStrongHit(hits []Hit) bool {
+	for _, h := range hits {
+		if h.Strength == HitStrengthStrong {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type EntryKind string
+
+const (
+	EntryKindSanction EntryKind = "sanction"
+	EntryKindPEP      EntryKind = "pep"
+)
+
+// BirthDate is a possibly partial date of birth, as found on consolidated
+// lists. Zero Month or Day means that part is unknown.
+type BirthDate struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// Entry is a single listed person.
+type Entry struct {
+	ID         string
+	Kind       EntryKind
+	Names      []string // Primary name first, followed by aliases.
+	BirthDates []BirthDate
+}
+
+// Watchlist is a parsed consolidated list file.
+type Watchlist struct {
+	Name     string // Derived from the file name, used to replace a list on reload.
+	Entries  []Entry
+	LoadedAt time.Time
+}
+
+// LoadFile parses a watchlist file from disk. The format is picked based on
+// the file extension (.csv or .xml).
+func LoadFile(path string) (*Watchlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		entries, err = ParseCSV(f)
+	case ".xml":
+		entries, err = ParseXML(f)
+	default:
+		return nil, fmt.Errorf("unsupported watchlist format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return &Watchlist{
+		Name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Entries:  entries,
+		LoadedAt: time.Now().UTC(),
+	}, nil
+}
+
+// ParseCSV parses a CSV watchlist. The first row is a header and must contain
+// the "id" and "name" columns. Optional columns are "type" (sanction or pep,
+// defaults to sanction), "aliases" and "birth_dates" (both ";" separated).
+// Birth dates are formatted as YYYY-MM-DD, YYYY-MM or YYYY.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := cols["id"]; !ok {
+		return nil, errors.New(`missing "id" column`)
+	}
+	if _, ok := cols["name"]; !ok {
+		return nil, errors.New(`missing "name" column`)
+	}
+	get := func(rec []string, col string) string {
+		if i, ok := cols[col]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var entries []Entry
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		kind, err := parseKind(get(rec, "type"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		e := Entry{ID: get(rec, "id"), Kind: kind}
+		if e.ID == "" {
+			return nil, fmt.Errorf("line %d: empty id", line)
+		}
+		if name := get(rec, "name"); name != "" {
+			e.Names = append(e.Names, name)
+		}
+		e.Names = append(e.Names, splitList(get(rec, "aliases"))...)
+		if len(e.Names) == 0 {
+			return nil, fmt.Errorf("line %d: empty name", line)
+		}
+		for _, raw := range splitList(get(rec, "birth_dates")) {
+			bd, err := ParseBirthDate(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			e.BirthDates = append(e.BirthDates, bd)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+type xmlList struct {
+	Entries []xmlEntry `xml:"entry"`
+}
+
+type xmlEntry struct {
+	ID         string   `xml:"id,attr"`
+	Type       string   `xml:"type,attr"`
+	Names      []string `xml:"name"`
+	Aliases    []string `xml:"alias"`
+	BirthDates []string `xml:"birthDate"`
+}
+
+// ParseXML parses an XML watchlist in the following (EU consolidated list
+// inspired) shape:
+//
+//	<list>
+//	  <entry id="EU-123" type="sanction">
+//	    <name>John Doe</name>
+//	    <alias>Johnny Doe</alias>
+//	    <birthDate>1970-01-31</birthDate>
+//	  </entry>
+//	</list>
+func ParseXML(r io.Reader) ([]Entry, error) {
+	var l xmlList
+	if err := xml.NewDecoder(r).Decode(&l); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(l.Entries))
+	for i, x := range l.Entries {
+		kind, err := parseKind(x.Type)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		e := Entry{ID: strings.TrimSpace(x.ID), Kind: kind}
+		if e.ID == "" {
+			return nil, fmt.Errorf("entry %d: empty id", i)
+		}
+		for _, n := range append(x.Names, x.Aliases...) {
+			if n = strings.TrimSpace(n); n != "" {
+				e.Names = append(e.Names, n)
+			}
+		}
+		if len(e.Names) == 0 {
+			return nil, fmt.Errorf("entry %s: empty name", e.ID)
+		}
+		for _, raw := range x.BirthDates {
+			bd, err := ParseBirthDate(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("entry %s: %w", e.ID, err)
+			}
+			e.BirthDates = append(e.BirthDates, bd)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// ParseBirthDate parses YYYY-MM-DD, YYYY-MM or YYYY.
+func ParseBirthDate(s string) (BirthDate, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 {
+		return BirthDate{}, fmt.Errorf("malformed birth date %q", s)
+	}
+
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return BirthDate{}, fmt.Errorf("malformed birth date %q", s)
+		}
+		nums[i] = n
+	}
+
+	bd := BirthDate{Year: nums[0], Month: nums[1], Day: nums[2]}
+	if bd.Year < 1800 || bd.Month < 0 || bd.Month > 12 || bd.Day < 0 || bd.Day > 31 {
+		return BirthDate{}, fmt.Errorf("malformed birth date %q", s)
+	}
+
+	return bd, nil
+}
+
+func parseKind(s string) (EntryKind, error) {
+	switch EntryKind(strings.ToLower(strings.TrimSpace(s))) {
+	case "", EntryKindSanction:
+		return EntryKindSanction, nil
+	case EntryKindPEP:
+		return EntryKindPEP, nil
+	default:
+		return "", fmt.Errorf("unknown entry type %q", s)
+	}
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, p := range strings.Split(s, ";") {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,94 @@
+package screening
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV_OK(t *testing.T) {
+	// Arrange.
+	in := "id,type,name,aliases,birth_dates\n" +
+		"S1,sanction,John Doe,Johnny Doe;J. Doe,1970-01-31;1971\n" +
+		"P1,pep,Jane Roe,,\n"
+
+	// Act.
+	entries, err := ParseCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Assert.
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if len(entries[0].Names) != 3 {
+		t.Fatalf("expected 3 names, got %v", entries[0].Names)
+	}
+	if want := (BirthDate{Year: 1971}); entries[0].BirthDates[1] != want {
+		t.Fatalf("expected %+v, got %+v", want, entries[0].BirthDates[1])
+	}
+	if entries[1].Kind != EntryKindPEP {
+		t.Fatalf("expected kind %s, got %s", EntryKindPEP, entries[1].Kind)
+	}
+}
+
+func TestParseCSV_MissingNameColumn(t *testing.T) {
+	// Act.
+	_, err := ParseCSV(strings.NewReader("id,type\nS1,sanction\n"))
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
+
+func TestParseCSV_UnknownType(t *testing.T) {
+	// Act.
+	_, err := ParseCSV(strings.NewReader("id,type,name\nS1,terrorist,John Doe\n"))
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+}
+
+func TestParseXML_OK(t *testing.T) {
+	// Arrange.
+	in := `<list>
+		<entry id="EU-1">
+			<name>John Doe</name>
+			<alias>Johnny Doe</alias>
+			<birthDate>1970-01</birthDate>
+		</entry>
+	</list>`
+
+	// Act.
+	entries, err := ParseXML(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Assert.
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.ID != "EU-1" || e.Kind != EntryKindSanction || len(e.Names) != 2 {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if want := (BirthDate{Year: 1970, Month: 1}); e.BirthDates[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, e.BirthDates[0])
+	}
+}
+
+func TestParseBirthDate_Malformed(t *testing.T) {
+	for _, in := range []string{"", "1970-13-01", "70", "1970-01-01-01", "abcd"} {
+		// Act.
+		_, err := ParseBirthDate(in)
+
+		// Assert.
+		if err == nil {
+			t.Fatalf("expected an error for %q, got nil", in)
+		}
+	}
+}
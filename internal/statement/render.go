@@ -0,0 +1,115 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+// CSV renders the statement as a CSV file, one row per line. The opening and
+// closing balances are rows of their own.
+func CSV(s Statement) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	records := [][]string{
+		{"date", "transaction_id", "kind", "description", "amount", "fee", "balance", "currency"},
+		{s.From.Format(time.DateOnly), "", "opening_balance", "", "", "", domain.FormatAmount(s.OpeningBalance), s.Currency},
+	}
+	for _, l := range s.Lines {
+		records = append(records, []string{
+			l.Date.UTC().Format(time.RFC3339),
+			l.TransactionID.String(),
+			string(l.Kind),
+			l.Description,
+			signedAmount(l.Amount),
+			domain.FormatAmount(l.Fee),
+			signedAmount(l.Balance),
+			s.Currency,
+		})
+	}
+	records = append(records, []string{
+		s.To.AddDate(0, 0, -1).Format(time.DateOnly), "", "closing_balance", "", "", domain.FormatAmount(s.TotalFees), signedAmount(s.ClosingBalance), s.Currency,
+	})
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+const (
+	pdfMargin     = 40.0
+	pdfFontSize   = 8.0
+	pdfLineHeight = 12.0
+)
+
+// PDF renders the statement as a printable A4 document.
+func PDF(s Statement) []byte {
+	p := util.NewPDF()
+	y := 0.0
+	line := func(bold bool, text string) {
+		if y < pdfMargin {
+			p.AddPage()
+			y = util.PDFPageHeight - pdfMargin
+		}
+		p.Text(pdfMargin, y, pdfFontSize, bold, text)
+		y -= pdfLineHeight
+	}
+
+	line(true, "BestWallet account statement")
+	line(false, "")
+	line(false, "Account:  "+s.AccountNumber)
+	line(false, "Period:   "+s.From.Format(time.DateOnly)+" - "+s.To.AddDate(0, 0, -1).Format(time.DateOnly))
+	line(false, "Currency: "+s.Currency)
+	line(false, "")
+	line(false, "Opening balance: "+signedAmount(s.OpeningBalance))
+	line(false, "Closing balance: "+signedAmount(s.ClosingBalance))
+	line(false, "Fees:            "+domain.FormatAmount(s.TotalFees))
+	line(false, "")
+
+	header := pdfRow("Date", "Kind", "Description", "Amount", "Fee", "Balance")
+	line(true, header)
+	line(false, strings.Repeat("-", len(header)))
+	for _, l := range s.Lines {
+		line(false, pdfRow(
+			l.Date.UTC().Format(time.DateOnly),
+			string(l.Kind),
+			l.Description,
+			signedAmount(l.Amount),
+			domain.FormatAmount(l.Fee),
+			signedAmount(l.Balance),
+		))
+	}
+	if len(s.Lines) == 0 {
+		line(false, "No transactions in this period.")
+	}
+
+	return p.Bytes()
+}
+
+// pdfRow lays out a table row in fixed width columns, the font is monospaced.
+func pdfRow(date, kind, description, amount, fee, balance string) string {
+	return fmt.Sprintf("%-10s  %-10s  %-36s  %14s  %10s  %14s",
+		date, truncate(kind, 10), truncate(description, 36), amount, fee, balance)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "~"
+}
+
+func signedAmount(a int64) string {
+	if a < 0 {
+		return "-" + domain.FormatAmount(-a)
+	}
+	return domain.FormatAmount(a)
+}
@@ -0,0 +1,101 @@
+package statement
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+func testStatement() Statement {
+	from, to, _ := ParsePeriod("2024-03")
+	return Statement{
+		AccountNumber:  "5b0d1c9e-5d0f-4a43-9f2b-1c6f5a0e8d11",
+		Currency:       "EUR",
+		Period:         "2024-03",
+		From:           from,
+		To:             to,
+		OpeningBalance: 10000,
+		ClosingBalance: 8950,
+		TotalFees:      50,
+		Lines: []Line{
+			{
+				Date:          time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+				TransactionID: uuid.MustParse("0c4a4b55-8ad4-4b8f-9a39-8a3b16b5d0c1"),
+				Kind:          domain.TransactionKindWithdrawal,
+				Description:   "Rent, March",
+				Amount:        -1050,
+				Fee:           50,
+				Balance:       8950,
+			},
+		},
+	}
+}
+
+func TestCSV(t *testing.T) {
+	// Act.
+	out, err := CSV(testStatement())
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `date,transaction_id,kind,description,amount,fee,balance,currency
+2024-03-01,,opening_balance,,,,100.00,EUR
+2024-03-05T10:00:00Z,0c4a4b55-8ad4-4b8f-9a39-8a3b16b5d0c1,withdrawal,"Rent, March",-10.50,0.50,89.50,EUR
+2024-03-31,,closing_balance,,,0.50,89.50,EUR
+`
+	if string(out) != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, out)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	// Act.
+	out := PDF(testStatement())
+
+	// Assert.
+	if !bytes.HasPrefix(out, []byte("%PDF-")) {
+		t.Fatal("not a PDF")
+	}
+	for _, want := range []string{"Opening balance: 100.00", "Closing balance: 89.50", "Rent, March"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Fatalf("expected PDF to contain %q", want)
+		}
+	}
+}
+
+func TestPDF_Paginates(t *testing.T) {
+	// Arrange.
+	s := testStatement()
+	for i := 0; i < 150; i++ {
+		s.Lines = append(s.Lines, s.Lines[0])
+	}
+
+	// Act.
+	out := PDF(s)
+
+	// Assert.
+	if !bytes.Contains(out, []byte("/Count 3")) {
+		t.Fatal("expected 3 pages")
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	from, to, err := ParsePeriod("2024-12")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !from.Equal(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected range %s - %s", from, to)
+	}
+
+	for _, bad := range []string{"", "2024", "2024-13", "2024-3-1", "03-2024"} {
+		if _, _, err := ParsePeriod(bad); err != ErrInvalidPeriod {
+			t.Fatalf("expected %q to be invalid", bad)
+		}
+	}
+}
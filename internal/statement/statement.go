@@ -0,0 +1,109 @@
+// Package statement builds monthly account statements and renders them to
+// CSV and PDF.
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/detod/best-wallet/internal/domain"
+)
+
+var (
+	ErrInvalidPeriod = errors.New("period must look like 2024-03")
+	ErrPeriodOpen    = errors.New("period hasn't ended yet")
+)
+
+// DB is satisfied by both pgxpool.Pool and pgx.Tx.
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Statement covers the transactions cleared on an account during a calendar
+// month (UTC). Amounts are in minor units.
+type Statement struct {
+	AccountNumber  string
+	Currency       string
+	Period         string // e.g. 2024-03.
+	From, To       time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	TotalFees      int64
+	Lines          []Line
+}
+
+type Line struct {
+	Date          time.Time
+	TransactionID uuid.UUID
+	Kind          domain.TransactionKind
+	Description   string
+	Amount        int64 // Negative for debits, includes Fee.
+	Fee           int64 // Paid by the account.
+	Balance       int64 // After the line.
+}
+
+// ParsePeriod returns the start and (exclusive) end of a period like 2024-03.
+func ParsePeriod(period string) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+// Build reads the statement of an account for a period from the ledger.
+func Build(ctx context.Context, db DB, accountID uuid.UUID, period string) (Statement, error) {
+	from, to, err := ParsePeriod(period)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	s := Statement{Period: period, From: from, To: to}
+	if err := db.QueryRow(ctx, `SELECT number, currency FROM accounts WHERE id = $1`, accountID).Scan(&s.AccountNumber, &s.Currency); err != nil {
+		return Statement{}, fmt.Errorf("failed to read account: %w", err)
+	}
+
+	if err := db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(e.amount), 0)
+		FROM entries e JOIN transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = $1 AND t.status = $2 AND t.cleared_at < $3`,
+		accountID, domain.TransactionStatusCleared, from,
+	).Scan(&s.OpeningBalance); err != nil {
+		return Statement{}, fmt.Errorf("failed to read opening balance: %w", err)
+	}
+
+	// The fee is only the account's to pay if it was debited.
+	rows, _ := db.Query(ctx, `
+		SELECT t.cleared_at, t.id, t.kind, t.description, e.amount,
+			CASE WHEN e.amount < 0 THEN t.fee ELSE 0 END
+		FROM entries e JOIN transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = $1 AND t.status = $2 AND t.cleared_at >= $3 AND t.cleared_at < $4
+		ORDER BY t.cleared_at, e.id`,
+		accountID, domain.TransactionStatusCleared, from, to,
+	)
+	s.Lines, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (Line, error) {
+		var l Line
+		err := row.Scan(&l.Date, &l.TransactionID, &l.Kind, &l.Description, &l.Amount, &l.Fee)
+		return l, err
+	})
+	if err != nil {
+		return Statement{}, fmt.Errorf("failed to read transactions: %w", err)
+	}
+
+	s.ClosingBalance = s.OpeningBalance
+	for i := range s.Lines {
+		s.ClosingBalance += s.Lines[i].Amount
+		s.Lines[i].Balance = s.ClosingBalance
+		s.TotalFees += s.Lines[i].Fee
+	}
+
+	return s, nil
+}
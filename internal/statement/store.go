@@ -0,0 +1,66 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatPDF Format = "pdf"
+)
+
+// Generate builds, renders and stores the statement of an account for a
+// period that has ended. Generating a statement that's already stored is a
+// no-op.
+func Generate(ctx context.Context, db DB, accountID uuid.UUID, period string, now time.Time) error {
+	_, to, err := ParsePeriod(period)
+	if err != nil {
+		return err
+	}
+	if now.Before(to) {
+		return ErrPeriodOpen
+	}
+
+	s, err := Build(ctx, db, accountID, period)
+	if err != nil {
+		return err
+	}
+	csv, err := CSV(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO account_statements (account_id, period, opening_balance, closing_balance, csv, pdf)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (account_id, period) DO NOTHING`,
+		accountID, period, s.OpeningBalance, s.ClosingBalance, csv, PDF(s),
+	)
+	return err
+}
+
+// Get returns a stored statement in the given format.
+func Get(ctx context.Context, db DB, accountID uuid.UUID, period string, format Format) ([]byte, bool, error) {
+	column := "pdf"
+	if format == FormatCSV {
+		column = "csv"
+	}
+
+	var res []byte
+	err := db.QueryRow(ctx, `SELECT `+column+` FROM account_statements WHERE account_id = $1 AND period = $2`, accountID, period).Scan(&res)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	default:
+		return res, true, nil
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff returns how long to wait before the given retry attempt (starting
+// at 1). The delay doubles with every attempt, starting at base and capped at
+// limit. Up to 20% of random jitter is subtracted so retries of things that
+// failed together don't all fire at the same time.
+func Backoff(attempt int, base, limit time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := base
+	for i := 1; i < attempt && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(d)/5 + 1))
+	return d - jitter
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_Doubles(t *testing.T) {
+	// Arrange.
+	base, limit := time.Second, time.Hour
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 5: 16 * time.Second} {
+		// Act.
+		d := Backoff(attempt, base, limit)
+
+		// Assert.
+		if d > want || d < want*4/5 {
+			t.Fatalf("attempt %d: expected %s minus up to 20%% jitter, got %s", attempt, want, d)
+		}
+	}
+}
+
+func TestBackoff_Capped(t *testing.T) {
+	// Arrange.
+	base, limit := time.Second, time.Minute
+
+	// Act.
+	d := Backoff(1000, base, limit)
+
+	// Assert.
+	if d > limit || d < limit*4/5 {
+		t.Fatalf("expected %s minus up to 20%% jitter, got %s", limit, d)
+	}
+}
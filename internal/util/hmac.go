@@ -4,6 +4,7 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 )
 
@@ -29,3 +30,11 @@ func NewKeyHMAC(size int) ([]byte, error) {
 
 	return key, nil
 }
+
+// ComputeSignature signs msg with key the way client apps sign requests (and
+// the way we sign webhook deliveries), see middleware.HMACVerifier.
+func ComputeSignature(msg, key []byte) string {
+	signed := make([]byte, 0, len(msg)+len(key))
+	signed = append(append(signed, msg...), key...)
+	return base64.StdEncoding.EncodeToString(ComputeSHA256HMAC(signed, key))
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"testing"
 )
@@ -88,3 +89,21 @@ func TestNewKeyHMAC_KeysDoNotRepeat(t *testing.T) {
 		t.Fatalf("expected keys to be different but they're equal")
 	}
 }
+
+func TestComputeSignature_MatchesDocumentedScheme(t *testing.T) {
+	// Arrange.
+	msg := []byte("some-body")
+	key, err := NewKeyHMAC(64)
+	if err != nil {
+		t.Fatalf("error generating a key: %s", err)
+	}
+
+	// Act.
+	sig := ComputeSignature(msg, key)
+
+	// Assert.
+	want := base64.StdEncoding.EncodeToString(ComputeSHA256HMAC(append(append([]byte{}, msg...), key...), key))
+	if sig != want {
+		t.Fatalf("expected signature %s, got %s", want, sig)
+	}
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestPDF_Structure(t *testing.T) {
+	// Arrange.
+	p := NewPDF()
+	p.Text(40, 800, 12, true, "Statement")
+	p.AddPage()
+	p.Text(40, 800, 10, false, "Page 2")
+
+	// Act.
+	out := p.Bytes()
+
+	// Assert.
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("missing header or trailer:\n%s", out)
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Fatalf("expected 2 pages:\n%s", out)
+	}
+
+	// Every xref entry must point at the start of its object.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(entries) != 8 {
+		t.Fatalf("expected 8 objects, got %d", len(entries))
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		want := []byte(fmt.Sprintf("%d 0 obj\n", i+1))
+		if !bytes.HasPrefix(out[offset:], want) {
+			t.Fatalf("xref entry %d points at %q", i+1, out[offset:offset+10])
+		}
+	}
+}
+
+func TestPDFEscape(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"(a) \\ b", `\(a\) \\ b`},
+		{"Müller", `M\374ller`},
+		{"€ 5", "? 5"},
+		{"tab\there", "tab?here"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			if got := pdfEscape(tc.in); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+// Recurrence is a subset of the iCalendar RRULE (RFC 5545) e.g.
+// "FREQ=MONTHLY;INTERVAL=1;COUNT=12". Supported parts are FREQ (required),
+// INTERVAL, COUNT and UNTIL (20240131 or 20240131T150405Z), COUNT and UNTIL
+// are mutually exclusive.
+type Recurrence struct {
+	Freq     Frequency
+	Interval int       // >= 1
+	Count    int       // Total number of occurrences, 0 for unlimited.
+	Until    time.Time // Last possible occurrence (inclusive), zero for unlimited.
+}
+
+func ParseRecurrence(s string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("malformed recurrence part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(val))
+			switch r.Freq {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+			default:
+				return Recurrence{}, fmt.Errorf("unsupported frequency %q", val)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(val); err != nil || r.Interval < 1 {
+				return Recurrence{}, fmt.Errorf("malformed interval %q", val)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(val); err != nil || r.Count < 1 {
+				return Recurrence{}, fmt.Errorf("malformed count %q", val)
+			}
+		case "UNTIL":
+			if r.Until, err = time.Parse("20060102T150405Z", val); err != nil {
+				if r.Until, err = time.Parse("20060102", val); err != nil {
+					return Recurrence{}, fmt.Errorf("malformed until %q", val)
+				}
+				r.Until = r.Until.Add(24*time.Hour - time.Nanosecond) // The whole day.
+			}
+		default:
+			return Recurrence{}, fmt.Errorf("unsupported recurrence part %q", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return Recurrence{}, fmt.Errorf("missing FREQ")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return Recurrence{}, fmt.Errorf("COUNT and UNTIL are mutually exclusive")
+	}
+
+	return r, nil
+}
+
+// String formats the recurrence in the canonical RRULE form.
+func (r Recurrence) String() string {
+	s := fmt.Sprintf("FREQ=%s;INTERVAL=%d", r.Freq, r.Interval)
+	if r.Count > 0 {
+		s += fmt.Sprintf(";COUNT=%d", r.Count)
+	}
+	if !r.Until.IsZero() {
+		s += ";UNTIL=" + r.Until.UTC().Format("20060102T150405Z")
+	}
+	return s
+}
+
+// Occurrence returns the n-th (0 based) occurrence for a series starting at
+// start, and false if the series ends before it. Months (and years) are
+// counted from start, so a series starting on Jan 31 continues with Feb 28
+// (or 29) and then Mar 31.
+func (r Recurrence) Occurrence(start time.Time, n int) (time.Time, bool) {
+	if n < 0 || (r.Count > 0 && n >= r.Count) {
+		return time.Time{}, false
+	}
+
+	step := n * r.Interval
+	var t time.Time
+	switch r.Freq {
+	case FrequencyDaily:
+		t = start.AddDate(0, 0, step)
+	case FrequencyWeekly:
+		t = start.AddDate(0, 0, 7*step)
+	case FrequencyMonthly:
+		t = addMonthsClamped(start, step)
+	case FrequencyYearly:
+		t = addMonthsClamped(start, 12*step)
+	}
+
+	if !r.Until.IsZero() && t.After(r.Until) {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	first = first.AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
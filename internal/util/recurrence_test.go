@@ -0,0 +1,97 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence_OK(t *testing.T) {
+	// Act.
+	r, err := ParseRecurrence("FREQ=MONTHLY;INTERVAL=2;COUNT=12")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Assert.
+	if r.Freq != FrequencyMonthly || r.Interval != 2 || r.Count != 12 {
+		t.Fatalf("unexpected recurrence %+v", r)
+	}
+	if s := r.String(); s != "FREQ=MONTHLY;INTERVAL=2;COUNT=12" {
+		t.Fatalf("unexpected canonical form %s", s)
+	}
+}
+
+func TestParseRecurrence_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=DAILY;UNTIL=tomorrow",
+	} {
+		// Act.
+		_, err := ParseRecurrence(in)
+
+		// Assert.
+		if err == nil {
+			t.Fatalf("%q: expected an error, got nil", in)
+		}
+	}
+}
+
+func TestRecurrence_MonthlyClampsToEndOfMonth(t *testing.T) {
+	// Arrange.
+	r, _ := ParseRecurrence("FREQ=MONTHLY")
+	start := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	for n, want := range map[int]time.Time{
+		0:  time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+		1:  time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+		2:  time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+		3:  time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC),
+		13: time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC),
+	} {
+		// Act.
+		got, ok := r.Occurrence(start, n)
+
+		// Assert.
+		if !ok || !got.Equal(want) {
+			t.Fatalf("occurrence %d: expected %s, got %s (ok=%t)", n, want, got, ok)
+		}
+	}
+}
+
+func TestRecurrence_Count(t *testing.T) {
+	// Arrange.
+	r, _ := ParseRecurrence("FREQ=WEEKLY;INTERVAL=2;COUNT=3")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act.
+	last, lastOK := r.Occurrence(start, 2)
+	_, pastOK := r.Occurrence(start, 3)
+
+	// Assert.
+	if !lastOK || !last.Equal(time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected last occurrence %s (ok=%t)", last, lastOK)
+	}
+	if pastOK {
+		t.Fatalf("expected no occurrence past count")
+	}
+}
+
+func TestRecurrence_UntilIsInclusive(t *testing.T) {
+	// Arrange.
+	r, _ := ParseRecurrence("FREQ=DAILY;UNTIL=20240103")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act.
+	_, lastOK := r.Occurrence(start, 2)
+	_, pastOK := r.Occurrence(start, 3)
+
+	// Assert.
+	if !lastOK || pastOK {
+		t.Fatalf("expected the series to end on the until day, got lastOK=%t pastOK=%t", lastOK, pastOK)
+	}
+}
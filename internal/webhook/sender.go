@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+// Headers sent with every delivery. The signature is computed the same way
+// client apps sign their requests to us, using the subscription secret as the
+// key once decoded (it's handed out base64 encoded, like API key secrets).
+const (
+	SignatureHeader  = "BestWallet-Signature"
+	DeliveryIDHeader = "BestWallet-Delivery-ID"
+	EventIDHeader    = "BestWallet-Event-ID"
+	EventTypeHeader  = "BestWallet-Event-Type"
+)
+
+// Delivery is a single event to be delivered to a subscriber.
+type Delivery struct {
+	ID        uuid.UUID
+	EventID   uuid.UUID
+	EventType domain.EventType
+	Payload   []byte // The event, as JSON.
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			// Don't follow redirects, subscribers must register the final URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// Sender POSTs signed deliveries to subscriber URLs.
+type Sender struct {
+	client *http.Client
+}
+
+// Send delivers d to url. Any 2xx response is a success, everything else is
+// returned as an error together with the status code (0 if there was no
+// response at all).
+func (s *Sender) Send(ctx context.Context, url, secret string, d Delivery) (statusCode int, err error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return 0, fmt.Errorf("malformed secret: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BestWallet-Webhooks/1.0")
+	req.Header.Set(SignatureHeader, util.ComputeSignature(d.Payload, key))
+	req.Header.Set(DeliveryIDHeader, d.ID.String())
+	req.Header.Set(EventIDHeader, d.EventID.String())
+	req.Header.Set(EventTypeHeader, string(d.EventType))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Allow connection reuse.
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// ValidateURL checks a subscriber URL is an absolute http(s) URL.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) url")
+	}
+	return nil
+}
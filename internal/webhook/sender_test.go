@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/detod/best-wallet/internal/domain"
+	"github.com/detod/best-wallet/internal/util"
+)
+
+// someSecret is "some-key", base64 encoded like subscription secrets.
+const someSecret = "c29tZS1rZXk="
+
+func TestSender_SignedDelivery(t *testing.T) {
+	// Arrange: subscriber stub that verifies the signature like a client would.
+	key := []byte("some-key")
+	secret := base64.StdEncoding.EncodeToString(key)
+	var gotSig, gotEventType string
+	var gotBody []byte
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig, gotEventType = r.Header.Get(SignatureHeader), r.Header.Get(EventTypeHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer stub.Close()
+
+	// Arrange: the SUT.
+	sut := NewSender(time.Second)
+	d := Delivery{
+		ID:        uuid.New(),
+		EventID:   uuid.New(),
+		EventType: domain.EventTypeAccountOpened,
+		Payload:   []byte(`{"some":"event"}`),
+	}
+
+	// Act.
+	code, err := sut.Send(context.Background(), stub.URL, secret, d)
+
+	// Assert.
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, code)
+	}
+	if string(gotBody) != string(d.Payload) {
+		t.Fatalf("expected body %s, got %s", d.Payload, gotBody)
+	}
+	if want := util.ComputeSignature(gotBody, key); gotSig != want {
+		t.Fatalf("expected signature %s, got %s", want, gotSig)
+	}
+	if gotEventType != string(domain.EventTypeAccountOpened) {
+		t.Fatalf("expected event type %s, got %s", domain.EventTypeAccountOpened, gotEventType)
+	}
+}
+
+func TestSender_Non2xxIsAnError(t *testing.T) {
+	// Arrange.
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com", http.StatusFound)
+	}))
+	defer stub.Close()
+	sut := NewSender(time.Second)
+
+	// Act.
+	code, err := sut.Send(context.Background(), stub.URL, someSecret, Delivery{Payload: []byte("{}")})
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+	if code != http.StatusFound {
+		t.Fatalf("expected status %d, got %d", http.StatusFound, code)
+	}
+}
+
+func TestSender_Unreachable(t *testing.T) {
+	// Arrange: a stub that's already gone.
+	stub := httptest.NewServer(http.NotFoundHandler())
+	stub.Close()
+	sut := NewSender(time.Second)
+
+	// Act.
+	code, err := sut.Send(context.Background(), stub.URL, someSecret, Delivery{Payload: []byte("{}")})
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+	if code != 0 {
+		t.Fatalf("expected status 0, got %d", code)
+	}
+}
+
+func TestSender_MalformedSecret(t *testing.T) {
+	// Arrange.
+	called := false
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer stub.Close()
+	sut := NewSender(time.Second)
+
+	// Act.
+	_, err := sut.Send(context.Background(), stub.URL, "not base64!", Delivery{Payload: []byte("{}")})
+
+	// Assert.
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+	if called {
+		t.Fatalf("expected nothing delivered")
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for raw, wantOK := range map[string]bool{
+		"https://example.com/hooks": true,
+		"http://localhost:8080":     true,
+		"ftp://example.com":         false,
+		"/relative":                 false,
+		"https://":                  false,
+	} {
+		// Act.
+		err := ValidateURL(raw)
+
+		// Assert.
+		if (err == nil) != wantOK {
+			t.Fatalf("%s: expected ok=%t, got err %v", raw, wantOK, err)
+		}
+	}
+}